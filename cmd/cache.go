@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/redis"
+	"github.com/spf13/cobra"
+)
+
+func newCacheCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the shared Redis cache",
+	}
+
+	command.AddCommand(&cobra.Command{
+		Use:   "flush",
+		Short: "Delete every key in the Redis database",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if err := redis.InitRedis(cfg.Redis); err != nil {
+				return err
+			}
+			if err := redis.FlushDB(context.Background()); err != nil {
+				return fmt.Errorf("failed to flush cache: %w", err)
+			}
+			fmt.Println("✅ Cache flushed")
+			return nil
+		},
+	})
+	return command
+}
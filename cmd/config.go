@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+func newConfigCommand() *cobra.Command {
+	var validationErr error
+
+	command := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the effective configuration",
+		// loading must not fail on validation errors here, so they can be reported next to the values
+		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+			loaded, err := config.Load()
+			if loaded == nil {
+				return err
+			}
+			cfg, validationErr = loaded, err
+			return nil
+		},
+	}
+
+	command.AddCommand(&cobra.Command{
+		Use:   "print",
+		Short: "Print the effective configuration with secrets redacted",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			encoder := yaml.NewEncoder(os.Stdout)
+			encoder.SetIndent(2)
+			if err := encoder.Encode(cfg.Redacted()); err != nil {
+				return err
+			}
+			if err := encoder.Close(); err != nil {
+				return err
+			}
+
+			if validationErr != nil {
+				return fmt.Errorf("configuration is invalid:\n%w", validationErr)
+			}
+			return nil
+		},
+	})
+	return command
+}
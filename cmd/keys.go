@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/config"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/jwtkeys"
+	"github.com/spf13/cobra"
+)
+
+func newKeysCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "keys",
+		Short: "Manage JWT signing keys",
+	}
+
+	command.AddCommand(newKeysRotateCommand())
+	return command
+}
+
+func newKeysRotateCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rotate",
+		Short: "Replace the signing key now, e.g. after a leak; older keys keep verifying for the overlap window",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			config.Connect(cfg.Database)
+			manager := jwtkeys.NewManager(config.GetDB(), cfg.Auth)
+			if err := manager.Rotate(context.Background(), true); err != nil {
+				return err
+			}
+			fmt.Println("✅ Signing key rotated, running servers pick it up within", jwtkeys.CheckInterval)
+			return nil
+		},
+	}
+}
@@ -1,35 +1,54 @@
 package main
 
 import (
-	"log"
-
-	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
-	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/redis"
-	"github.com/Al-Khaimah/khaimah-golang-backend/internal/middlewares"
-	"github.com/Al-Khaimah/khaimah-golang-backend/internal/migrations"
-
-	"github.com/Al-Khaimah/khaimah-golang-backend/internal/routes"
+	"os"
+	"time"
 
 	"github.com/Al-Khaimah/khaimah-golang-backend/config"
-	"github.com/labstack/echo/v4"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/logger"
+	"github.com/spf13/cobra"
 )
 
-func main() {
-	e := echo.New()
-
-	base.RegisterValidator(e)
-	middlewares.RegisterAllGlobalMiddlewares(e)
+// slowQueryThreshold is the duration above which queries are logged as slow
+const slowQueryThreshold = 200 * time.Millisecond
 
-	config.Connect()
-	db := config.GetDB()
-	migrations.Migrate()
-	migrations.SeedDatabase(db)
+// cfg is loaded once before any command runs and handed to everything the command builds
+var cfg *config.Config
 
-	if err := redis.InitRedis(); err != nil {
-		log.Printf("❌ Warning: Failed to initialize Redis: %v", err)
+func main() {
+	if err := newRootCommand().Execute(); err != nil {
+		os.Exit(1)
 	}
+}
 
-	routes.RegisterAllRoutes(e, db)
+func newRootCommand() *cobra.Command {
+	root := &cobra.Command{
+		Use:          "khaimah",
+		Short:        "Al-Khaimah backend server and management commands",
+		SilenceUsage: true,
+		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+			loaded, err := config.Load()
+			if err != nil {
+				return err
+			}
+			cfg = loaded
+			if err := logger.Init(cfg.Logging, cfg.Environment); err != nil {
+				return err
+			}
+			config.QueryLogger = logger.NewGormLogger(slowQueryThreshold)
+			return nil
+		},
+	}
 
-	startServer(e)
+	root.AddCommand(
+		newServeCommand(),
+		newMigrateCommand(),
+		newSeedCommand(),
+		newClearCommand(),
+		newUserCommand(),
+		newCacheCommand(),
+		newKeysCommand(),
+		newConfigCommand(),
+	)
+	return root
 }
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/config"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/migrations"
+	"github.com/spf13/cobra"
+)
+
+func newMigrateCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "migrate",
+		Short: "Apply, roll back or list database migrations",
+	}
+
+	command.AddCommand(
+		&cobra.Command{
+			Use:   "up",
+			Short: "Apply every pending migration",
+			Args:  cobra.NoArgs,
+			RunE: func(_ *cobra.Command, _ []string) error {
+				migrator, err := newMigrator()
+				if err != nil {
+					return err
+				}
+				applied, err := migrator.Up()
+				if err != nil {
+					return err
+				}
+				fmt.Printf("✅ %d migration(s) applied\n", applied)
+				return nil
+			},
+		},
+		&cobra.Command{
+			Use:   "down [steps]",
+			Short: "Roll back the most recent migrations (default 1)",
+			Args:  cobra.MaximumNArgs(1),
+			RunE: func(_ *cobra.Command, args []string) error {
+				steps := 1
+				if len(args) > 0 {
+					var err error
+					steps, err = strconv.Atoi(args[0])
+					if err != nil || steps < 1 {
+						return fmt.Errorf("invalid number of steps %q", args[0])
+					}
+				}
+
+				migrator, err := newMigrator()
+				if err != nil {
+					return err
+				}
+				rolledBack, err := migrator.Down(steps)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("✅ %d migration(s) rolled back\n", rolledBack)
+				return nil
+			},
+		},
+		&cobra.Command{
+			Use:   "status",
+			Short: "List migrations and when they were applied",
+			Args:  cobra.NoArgs,
+			RunE: func(_ *cobra.Command, _ []string) error {
+				migrator, err := newMigrator()
+				if err != nil {
+					return err
+				}
+				statuses, err := migrator.Status()
+				if err != nil {
+					return err
+				}
+
+				writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(writer, "VERSION\tNAME\tAPPLIED AT")
+				for _, status := range statuses {
+					appliedAt := "pending"
+					if status.AppliedAt != nil {
+						appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+					}
+					fmt.Fprintf(writer, "%04d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+				}
+				return writer.Flush()
+			},
+		},
+	)
+	return command
+}
+
+func newMigrator() (*migrations.Migrator, error) {
+	config.Connect(cfg.Database)
+	return migrations.NewMigrator(config.GetDB())
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/config"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/migrations"
+	"github.com/spf13/cobra"
+)
+
+func newSeedCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "seed",
+		Short: "Seed default roles and sample podcasts",
+		Args:  cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			config.Connect(cfg.Database)
+			migrations.SeedDatabase(config.GetDB())
+		},
+	}
+}
+
+func newClearCommand() *cobra.Command {
+	var confirmed bool
+
+	command := &cobra.Command{
+		Use:   "clear",
+		Short: "Delete all data except the audit log, keeping the schema",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if !confirmed {
+				return errors.New("refusing to clear the database without --yes")
+			}
+			config.Connect(cfg.Database)
+			migrations.ClearTables(config.GetDB())
+			return nil
+		},
+	}
+
+	command.Flags().BoolVar(&confirmed, "yes", false, "confirm that all data should be deleted")
+	return command
+}
@@ -1,17 +1,197 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/Al-Khaimah/khaimah-golang-backend/config"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/health"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/jobs"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/jwtkeys"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/metrics"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/ratelimit"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/redis"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/tracing"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/middlewares"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/migrations"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/routes"
 	"github.com/labstack/echo/v4"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+	gormtracing "gorm.io/plugin/opentelemetry/tracing"
 )
 
-func startServer(e *echo.Echo, setPort ...string) {
+// readinessTimeout bounds each dependency check behind /readyz
+const readinessTimeout = 2 * time.Second
+
+func newServeCommand() *cobra.Command {
+	var port string
+	var skipMigrations bool
+
+	command := &cobra.Command{
+		Use:   "serve",
+		Short: "Start the HTTP server and background jobs",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if port != "" {
+				cfg.Server.Port = port
+			}
+			slog.Info("Configuration loaded", "report", cfg.Report())
+
+			shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing, cfg.Environment)
+			if err != nil {
+				return fmt.Errorf("failed to initialize tracing: %w", err)
+			}
+
+			trustedProxies, err := cfg.Server.TrustedProxyRanges()
+			if err != nil {
+				return fmt.Errorf("invalid trusted proxies: %w", err)
+			}
+
+			e := echo.New()
+			e.HideBanner = true
+			e.HidePort = true
+			e.IPExtractor = ratelimit.IPExtractor(trustedProxies)
+
+			base.RegisterValidator(e)
+			middlewares.RegisterAllGlobalMiddlewares(e, cfg)
+
+			config.Connect(cfg.Database)
+			db := config.GetDB()
+			if cfg.Metrics.Enabled {
+				if err := db.Use(metrics.GormPlugin{}); err != nil {
+					return fmt.Errorf("failed to register database metrics: %w", err)
+				}
+			}
+			if cfg.Tracing.Enabled() {
+				// Query variables are left out of spans because they carry personal data
+				if err := db.Use(gormtracing.NewPlugin(gormtracing.WithoutMetrics(), gormtracing.WithoutQueryVariables())); err != nil {
+					return fmt.Errorf("failed to register database tracing: %w", err)
+				}
+			}
+			if !skipMigrations {
+				migrations.Migrate()
+			}
+			migrations.SeedRoles(db)
+
+			keys, err := jwtkeys.Init(context.Background(), db, cfg.Auth)
+			if err != nil {
+				return fmt.Errorf("failed to initialize signing keys: %w", err)
+			}
+			jobs.Register(jwtkeys.RotateJobName, keys.RotateIfDue)
+			jobs.Every(jwtkeys.CheckInterval, jwtkeys.RotateJobName, "")
+
+			if err := redis.InitRedis(cfg.Redis); err != nil {
+				slog.Warn("Failed to initialize Redis", "error", err)
+			}
+
+			checker := newReadinessChecker(db)
+			routes.RegisterHealthRoutes(e, checker)
+			routes.RegisterJWKSRoutes(e, keys)
+			routes.RegisterAllRoutes(e, db, cfg)
+			jobs.Start(4)
+
+			return startServer(e, checker, shutdownTracing, ":"+cfg.Server.Port)
+		},
+	}
+
+	command.Flags().StringVar(&port, "port", "", "port to listen on, overriding PORT")
+	command.Flags().BoolVar(&skipMigrations, "skip-migrations", false, "do not apply pending migrations on startup")
+	return command
+}
+
+// newReadinessChecker checks everything a replica needs before it should receive traffic
+func newReadinessChecker(db *gorm.DB) *health.Checker {
+	checker := health.NewChecker(readinessTimeout)
+
+	checker.Add("database", func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	})
+	checker.Add("redis", redis.Ping)
+	checker.Add("migrations", func(ctx context.Context) error {
+		migrator, err := migrations.NewMigrator(db.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		pending, err := migrator.Pending()
+		if err != nil {
+			return err
+		}
+		if pending > 0 {
+			return fmt.Errorf("%d migrations pending", pending)
+		}
+		return nil
+	})
+	checker.Add("jobs", func(_ context.Context) error {
+		return jobs.Health()
+	})
+
+	return checker
+}
+
+// startServer serves until SIGINT or SIGTERM, then stops taking new work: readiness fails,
+// in-flight requests and queued jobs get up to the shutdown timeout to finish, and connections are closed
+func startServer(e *echo.Echo, checker *health.Checker, shutdownTracing func(context.Context) error, setPort ...string) error {
 	port := ":8080"
 	if len(setPort) > 0 {
 		port = setPort[0]
 	}
-	log.Println("Server running on http://" + config.GetEnv("PUBLIC_IP", "127.0.0.1") + port)
-	e.Logger.Fatal(e.Start("0.0.0.0" + port))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("Server running", "url", "http://"+cfg.Server.PublicIP+port)
+		serveErr <- e.Start("0.0.0.0" + port)
+	}()
+
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("server failed: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+	}
+	stop()
+
+	slog.Info("Shutting down, draining requests and jobs")
+	checker.SetDraining()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.ShutdownTimeoutSeconds)*time.Second)
+	defer cancel()
+
+	if err := e.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Failed to drain HTTP server", "error", err)
+	}
+	if err := jobs.Stop(shutdownCtx); err != nil {
+		slog.Error("Failed to stop background jobs", "error", err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("Failed to flush traces", "error", err)
+	}
+	if err := redis.Close(); err != nil {
+		slog.Error("Failed to close Redis", "error", err)
+	}
+	if sqlDB, err := config.GetDB().DB(); err == nil {
+		if err := sqlDB.Close(); err != nil {
+			slog.Error("Failed to close database", "error", err)
+		}
+	}
+
+	slog.Info("Server stopped")
+	return nil
 }
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/config"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	roles "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/enums"
+	roleRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/repositories"
+	roleService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/services"
+	userRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/repositories"
+	userService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/services"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+func newUserCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "user",
+		Short: "Manage user accounts",
+	}
+
+	command.AddCommand(newUserPromoteCommand(), newUserDeleteCommand())
+	return command
+}
+
+func newUserPromoteCommand() *cobra.Command {
+	var roleName string
+
+	command := &cobra.Command{
+		Use:   "promote <email>",
+		Short: "Assign a role to the user with the given email",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			config.Connect(cfg.Database)
+			db := config.GetDB()
+			userRepo := userRepository.NewUserRepository(db)
+
+			user, err := userRepo.FindOneByEmail(args[0])
+			if err != nil {
+				return err
+			}
+			if user == nil {
+				return fmt.Errorf("no user found with email %s", args[0])
+			}
+
+			newRoleService := roleService.NewRoleService(roleRepository.NewRoleRepository(db), userRepo)
+			if err := responseError(newRoleService.AssignRole(context.Background(), user.ID.String(), roleName)); err != nil {
+				return err
+			}
+			fmt.Printf("✅ %s is now %s\n", user.Email, roleName)
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(&roleName, "role", roles.RoleSuperAdmin, "role to assign")
+	return command
+}
+
+func newUserDeleteCommand() *cobra.Command {
+	var purge bool
+
+	command := &cobra.Command{
+		Use:   "delete <id>",
+		Short: "Schedule an account for deletion, or purge it immediately with --purge",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			config.Connect(cfg.Database)
+			db := config.GetDB()
+			userRepo := userRepository.NewUserRepository(db)
+
+			if purge {
+				uid, err := uuid.Parse(args[0])
+				if err != nil {
+					return fmt.Errorf("invalid user ID %q", args[0])
+				}
+				newAccountDeletionService := userService.NewAccountDeletionService(userRepo, userRepository.NewExportRepository(db))
+				if err := newAccountDeletionService.PurgeAccount(context.Background(), uid); err != nil {
+					return err
+				}
+				fmt.Printf("✅ Purged user %s\n", uid)
+				return nil
+			}
+
+			newUserService := userService.NewUserService(userRepo, userRepository.NewAuthRepository(db), userRepository.NewBookmarkRepository(db), nil, cfg)
+			if err := responseError(newUserService.DeleteUser(context.Background(), args[0])); err != nil {
+				return err
+			}
+			fmt.Printf("✅ User %s will be purged after the %s grace period\n", args[0], userService.AccountDeletionGracePeriod)
+			return nil
+		},
+	}
+
+	command.Flags().BoolVar(&purge, "purge", false, "permanently delete the account now instead of after the grace period")
+	return command
+}
+
+// responseError turns a failed service response into an error for the command line
+func responseError(response base.Response) error {
+	if response.HTTPStatus < http.StatusBadRequest {
+		return nil
+	}
+	if response.MessageDescription != "" {
+		return fmt.Errorf("%s: %s", response.MessageTitle, response.MessageDescription)
+	}
+	return errors.New(response.MessageTitle)
+}
@@ -1,22 +1,488 @@
 package config
 
 import (
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
 	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
 )
 
+const (
+	EnvironmentDevelopment = "development"
+	EnvironmentStaging     = "staging"
+	EnvironmentProduction  = "production"
+
+	TracingExporterNone   = "none"
+	TracingExporterOTLP   = "otlp"
+	TracingExporterStdout = "stdout"
+
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+
+	JWTAlgorithmHS256 = "HS256"
+	JWTAlgorithmRS256 = "RS256"
+	JWTAlgorithmES256 = "ES256"
+
+	// defaultConfigFile is read when CONFIG_FILE is not set and the file exists
+	defaultConfigFile = "config.yaml"
+	redactedValue     = "[REDACTED]"
+)
+
+// insecureSecrets are development defaults and sample values that must never reach production
+var insecureSecrets = map[string]bool{
+	"":             true,
+	"alkhaimah123": true,
+	"random_text":  true,
+	"password":     true,
+	"changeme":     true,
+}
+
+// Config holds every setting the application reads. Values come from the defaults below,
+// then the optional YAML file, then the environment (including .env), each overriding the last.
+// Fields tagged secret are redacted when the configuration is printed.
+type Config struct {
+	Environment string          `yaml:"environment" env:"APP_ENV"`
+	Server      ServerConfig    `yaml:"server"`
+	Database    DatabaseConfig  `yaml:"database"`
+	Redis       RedisConfig     `yaml:"redis"`
+	Auth        AuthConfig      `yaml:"auth"`
+	Messaging   MessagingConfig `yaml:"messaging"`
+	Exports     ExportsConfig   `yaml:"exports"`
+	Stores      StoresConfig    `yaml:"stores"`
+	Referrals   ReferralsConfig `yaml:"referrals"`
+	Metrics     MetricsConfig   `yaml:"metrics"`
+	Tracing     TracingConfig   `yaml:"tracing"`
+	Logging     LoggingConfig   `yaml:"logging"`
+	RateLimit   RateLimitConfig `yaml:"rate_limit"`
+}
+
+type ServerConfig struct {
+	Port          string `yaml:"port" env:"PORT"`
+	PublicIP      string `yaml:"public_ip" env:"PUBLIC_IP"`
+	PublicBaseURL string `yaml:"public_base_url" env:"PUBLIC_BASE_URL"`
+	// ShutdownTimeoutSeconds bounds how long in-flight requests and jobs may run after SIGTERM
+	ShutdownTimeoutSeconds int `yaml:"shutdown_timeout_seconds" env:"SHUTDOWN_TIMEOUT_SECONDS"`
+	// TrustedProxies lists, comma separated, the CIDRs of the load balancers in front of the server.
+	// The client IP is only read from X-Forwarded-For on requests coming from them.
+	TrustedProxies string `yaml:"trusted_proxies" env:"TRUSTED_PROXIES"`
+}
+
+// TrustedProxyRanges parses TrustedProxies
+func (s ServerConfig) TrustedProxyRanges() ([]*net.IPNet, error) {
+	var ranges []*net.IPNet
+	for _, cidr := range strings.Split(s.TrustedProxies, ",") {
+		if cidr = strings.TrimSpace(cidr); cidr == "" {
+			continue
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, network)
+	}
+	return ranges, nil
+}
+
+type DatabaseConfig struct {
+	Host     string `yaml:"host" env:"DB_HOST"`
+	Port     string `yaml:"port" env:"DB_PORT"`
+	User     string `yaml:"user" env:"DB_USER"`
+	Password string `yaml:"password" env:"DB_PASSWORD" secret:"true"`
+	Name     string `yaml:"name" env:"DB_NAME"`
+	SSLMode  string `yaml:"ssl_mode" env:"DB_SSLMODE"`
+}
+
+type RedisConfig struct {
+	Addr     string `yaml:"addr" env:"REDIS_ADDR"`
+	Password string `yaml:"password" env:"REDIS_PASSWORD" secret:"true"`
+	DB       int    `yaml:"db" env:"REDIS_DB"`
+}
+
+type AuthConfig struct {
+	// JWTSecret verifies tokens issued before signing keys were introduced, which carry no kid,
+	// until JWTLegacyTokensUntil
+	JWTSecret string `yaml:"jwt_secret" env:"JWT_SECRET" secret:"true"`
+	// JWTLegacyTokensUntil is when tokens without a kid stop being accepted, in RFC 3339. They
+	// never expire on their own; when it is empty they are refused.
+	JWTLegacyTokensUntil string `yaml:"jwt_legacy_tokens_until" env:"JWT_LEGACY_TOKENS_UNTIL"`
+	// JWTAlgorithm signs new tokens: ES256 or RS256 so other services can verify them from
+	// /.well-known/jwks.json, or HS256 to keep the keys private
+	JWTAlgorithm string `yaml:"jwt_algorithm" env:"JWT_ALGORITHM"`
+	// JWTKeyRotationDays is how long a signing key signs before a new one replaces it
+	JWTKeyRotationDays int `yaml:"jwt_key_rotation_days" env:"JWT_KEY_ROTATION_DAYS"`
+	// JWTKeyOverlapDays is how long tokens signed by a replaced key keep verifying, and so how
+	// long a token is valid after it is issued
+	JWTKeyOverlapDays int `yaml:"jwt_key_overlap_days" env:"JWT_KEY_OVERLAP_DAYS"`
+	// JWTKeyEncryptionKey encrypts stored private keys and falls back to JWTSecret
+	JWTKeyEncryptionKey string `yaml:"jwt_key_encryption_key" env:"JWT_KEY_ENCRYPTION_KEY" secret:"true"`
+
+	GoogleClientID  string `yaml:"google_client_id" env:"GOOGLE_CLIENT_ID"`
+	AppleClientID   string `yaml:"apple_client_id" env:"APPLE_CLIENT_ID"`
+	AppleTeamID     string `yaml:"apple_team_id" env:"APPLE_TEAM_ID"`
+	AppleKeyID      string `yaml:"apple_key_id" env:"APPLE_KEY_ID"`
+	ApplePrivateKey string `yaml:"apple_private_key" env:"APPLE_PRIVATE_KEY" secret:"true"`
+	// TwoFactorEncryptionKey encrypts stored TOTP secrets. It falls back to JWTSecret, but
+	// should be set so that rotating the JWT secret does not invalidate every enrollment.
+	TwoFactorEncryptionKey string `yaml:"two_factor_encryption_key" env:"TWO_FACTOR_ENCRYPTION_KEY" secret:"true"`
+}
+
+// SigningKeyEncryptionKey returns the key stored JWT signing keys are encrypted with
+func (a AuthConfig) SigningKeyEncryptionKey() string {
+	if a.JWTKeyEncryptionKey != "" {
+		return a.JWTKeyEncryptionKey
+	}
+	return a.JWTSecret
+}
+
+// LegacyTokenCutoff parses JWTLegacyTokensUntil. It is the zero time when that is empty.
+func (a AuthConfig) LegacyTokenCutoff() (time.Time, error) {
+	if a.JWTLegacyTokensUntil == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, a.JWTLegacyTokensUntil)
+}
+
+// TwoFactorKey returns the key TOTP secrets are encrypted with
+func (a AuthConfig) TwoFactorKey() string {
+	if a.TwoFactorEncryptionKey != "" {
+		return a.TwoFactorEncryptionKey
+	}
+	return a.JWTSecret
+}
+
+type MessagingConfig struct {
+	ResendAPIKey      string `yaml:"resend_api_key" env:"RESEND_API_KEY" secret:"true"`
+	ResendSenderEmail string `yaml:"resend_sender_email" env:"RESEND_SENDER_EMAIL"`
+	WasenderAPIToken  string `yaml:"wasender_api_token" env:"WASENDER_API_TOKEN" secret:"true"`
+	SlackWebhookURL   string `yaml:"slack_webhook_url" env:"SLACK_WEBHOOK_URL" secret:"true"`
+}
+
+type ExportsConfig struct {
+	Dir string `yaml:"dir" env:"EXPORTS_DIR"`
+}
+
+type StoresConfig struct {
+	AppleBundleID                string `yaml:"apple_bundle_id" env:"APPLE_BUNDLE_ID"`
+	AppleRootCerts               string `yaml:"apple_root_certs" env:"APPLE_ROOT_CERTS"`
+	GooglePlayPackageName        string `yaml:"google_play_package_name" env:"GOOGLE_PLAY_PACKAGE_NAME"`
+	GooglePlayServiceAccountFile string `yaml:"google_play_service_account_file" env:"GOOGLE_PLAY_SERVICE_ACCOUNT_FILE"`
+	GooglePubSubAudience         string `yaml:"google_pubsub_audience" env:"GOOGLE_PUBSUB_AUDIENCE"`
+	GooglePubSubServiceAccount   string `yaml:"google_pubsub_service_account" env:"GOOGLE_PUBSUB_SERVICE_ACCOUNT"`
+}
+
+type ReferralsConfig struct {
+	RewardDays int    `yaml:"reward_days" env:"REFERRAL_REWARD_DAYS"`
+	RewardPlan string `yaml:"reward_plan" env:"REFERRAL_REWARD_PLAN"`
+}
+
+// MetricsConfig controls the Prometheus endpoint. When Token is set, scrapers must send it as a bearer token.
+type MetricsConfig struct {
+	Enabled bool   `yaml:"enabled" env:"METRICS_ENABLED"`
+	Path    string `yaml:"path" env:"METRICS_PATH"`
+	Token   string `yaml:"token" env:"METRICS_TOKEN" secret:"true"`
+}
+
+// TracingConfig selects where OpenTelemetry spans go. The OTLP exporter also honours the
+// standard OTEL_EXPORTER_OTLP_* variables, such as headers, when they are set.
+type TracingConfig struct {
+	Exporter     string `yaml:"exporter" env:"OTEL_TRACES_EXPORTER"`
+	OTLPEndpoint string `yaml:"otlp_endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	ServiceName  string `yaml:"service_name" env:"OTEL_SERVICE_NAME"`
+}
+
+func (t TracingConfig) Enabled() bool {
+	return t.Exporter != "" && t.Exporter != TracingExporterNone
+}
+
+// LoggingConfig sets the minimum level (debug, info, warn or error) and the output format.
+// An empty format means text in development and JSON everywhere else.
+type LoggingConfig struct {
+	Level  string `yaml:"level" env:"LOG_LEVEL"`
+	Format string `yaml:"format" env:"LOG_FORMAT"`
+}
+
+// RateLimitConfig switches the per-route limits declared in the route files. Counters live in
+// Redis and fall back to process memory when Redis is unavailable.
+type RateLimitConfig struct {
+	Enabled bool `yaml:"enabled" env:"RATE_LIMIT_ENABLED"`
+}
+
+// Defaults returns the configuration used for local development. The environment is left empty:
+// it decides which checks Validate applies, so it must be set explicitly with APP_ENV.
+func Defaults() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:                   "8080",
+			PublicIP:               "127.0.0.1",
+			ShutdownTimeoutSeconds: 30,
+		},
+		Database: DatabaseConfig{
+			Host:     "localhost",
+			Port:     "5432",
+			User:     "postgres",
+			Password: "password",
+			Name:     "mydatabase",
+			SSLMode:  "disable",
+		},
+		Redis: RedisConfig{
+			Addr: "localhost:6379",
+		},
+		Auth: AuthConfig{
+			JWTSecret:          "alkhaimah123",
+			JWTAlgorithm:       JWTAlgorithmES256,
+			JWTKeyRotationDays: 30,
+			JWTKeyOverlapDays:  90,
+		},
+		Exports: ExportsConfig{
+			Dir: "exports",
+		},
+		Referrals: ReferralsConfig{
+			RewardDays: 30,
+			RewardPlan: "premium_monthly",
+		},
+		Metrics: MetricsConfig{
+			Path: "/metrics",
+		},
+		Tracing: TracingConfig{
+			Exporter:    TracingExporterNone,
+			ServiceName: "khaimah-backend",
+		},
+		Logging: LoggingConfig{
+			Level: "info",
+		},
+		RateLimit: RateLimitConfig{
+			Enabled: true,
+		},
+	}
+}
+
 func LoadEnv() {
 	if err := godotenv.Load(); err != nil {
-		log.Println("❌ Warning: No .env file found. Using default environment variables.")
+		slog.Warn("No .env file found, using the process environment")
+	}
+}
+
+// Load builds the configuration from the defaults, the YAML file at CONFIG_FILE (or ./config.yaml)
+// and the environment, then validates it. The configuration is returned even when validation fails
+// so callers can report it.
+func Load() (*Config, error) {
+	LoadEnv()
+
+	cfg := Defaults()
+
+	path := os.Getenv("CONFIG_FILE")
+	if path == "" {
+		if _, err := os.Stat(defaultConfigFile); err == nil {
+			path = defaultConfigFile
+		}
+	}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+
+	if err := applyEnv(reflect.ValueOf(cfg).Elem()); err != nil {
+		return nil, err
+	}
+
+	if cfg.Server.PublicBaseURL == "" {
+		cfg.Server.PublicBaseURL = fmt.Sprintf("http://%s:%s", cfg.Server.PublicIP, cfg.Server.Port)
+	}
+
+	return cfg, cfg.Validate()
+}
+
+// applyEnv overrides every field carrying an env tag with the environment variable, when it is set
+func applyEnv(value reflect.Value) error {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		fieldType := value.Type().Field(i)
+
+		if field.Kind() == reflect.Struct {
+			if err := applyEnv(field); err != nil {
+				return err
+			}
+			continue
+		}
+
+		key := fieldType.Tag.Get("env")
+		raw, found := os.LookupEnv(key)
+		if key == "" || !found {
+			continue
+		}
+
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(raw)
+		case reflect.Bool:
+			parsed, err := strconv.ParseBool(strings.TrimSpace(raw))
+			if err != nil {
+				return fmt.Errorf("invalid value for %s: %w", key, err)
+			}
+			field.SetBool(parsed)
+		case reflect.Int:
+			parsed, err := strconv.Atoi(strings.TrimSpace(raw))
+			if err != nil {
+				return fmt.Errorf("invalid value for %s: %w", key, err)
+			}
+			field.SetInt(int64(parsed))
+		}
+	}
+	return nil
+}
+
+// Validate checks the settings every environment needs, and refuses development defaults
+// for secrets outside development
+func (c *Config) Validate() error {
+	var errs []error
+
+	switch c.Environment {
+	case EnvironmentDevelopment, EnvironmentStaging, EnvironmentProduction:
+	case "":
+		errs = append(errs, fmt.Errorf("APP_ENV is required: set it to %s, %s or %s", EnvironmentDevelopment, EnvironmentStaging, EnvironmentProduction))
+	default:
+		errs = append(errs, fmt.Errorf("APP_ENV must be one of %s, %s or %s", EnvironmentDevelopment, EnvironmentStaging, EnvironmentProduction))
+	}
+
+	required := map[string]string{
+		"DB_HOST":    c.Database.Host,
+		"DB_PORT":    c.Database.Port,
+		"DB_USER":    c.Database.User,
+		"DB_NAME":    c.Database.Name,
+		"JWT_SECRET": c.Auth.JWTSecret,
+	}
+	if c.Environment == EnvironmentProduction {
+		required["RESEND_API_KEY"] = c.Messaging.ResendAPIKey
+		required["RESEND_SENDER_EMAIL"] = c.Messaging.ResendSenderEmail
+	}
+	for _, key := range sortedKeys(required) {
+		if strings.TrimSpace(required[key]) == "" {
+			errs = append(errs, fmt.Errorf("%s is required", key))
+		}
+	}
+
+	if c.Environment != EnvironmentDevelopment {
+		if insecureSecrets[c.Auth.JWTSecret] || len(c.Auth.JWTSecret) < 32 {
+			errs = append(errs, fmt.Errorf("JWT_SECRET must be a random value of at least 32 characters in %s", c.Environment))
+		}
+		if insecureSecrets[c.Database.Password] {
+			errs = append(errs, fmt.Errorf("DB_PASSWORD must not use a default value in %s", c.Environment))
+		}
+	}
+
+	switch c.Auth.JWTAlgorithm {
+	case JWTAlgorithmHS256, JWTAlgorithmRS256, JWTAlgorithmES256:
+	default:
+		errs = append(errs, fmt.Errorf("JWT_ALGORITHM must be %s, %s or %s", JWTAlgorithmHS256, JWTAlgorithmRS256, JWTAlgorithmES256))
+	}
+	if c.Auth.JWTKeyRotationDays <= 0 {
+		errs = append(errs, errors.New("JWT_KEY_ROTATION_DAYS must be positive"))
+	}
+	if c.Auth.JWTKeyOverlapDays <= 0 {
+		errs = append(errs, errors.New("JWT_KEY_OVERLAP_DAYS must be positive"))
+	}
+	if _, err := c.Auth.LegacyTokenCutoff(); err != nil {
+		errs = append(errs, fmt.Errorf("JWT_LEGACY_TOKENS_UNTIL must be an RFC 3339 time: %w", err))
+	}
+
+	if c.Server.ShutdownTimeoutSeconds <= 0 {
+		errs = append(errs, errors.New("SHUTDOWN_TIMEOUT_SECONDS must be positive"))
+	}
+	if _, err := c.Server.TrustedProxyRanges(); err != nil {
+		errs = append(errs, fmt.Errorf("TRUSTED_PROXIES must be a comma-separated list of CIDRs: %w", err))
+	}
+	if c.Metrics.Enabled && !strings.HasPrefix(c.Metrics.Path, "/") {
+		errs = append(errs, errors.New("METRICS_PATH must start with /"))
+	}
+	switch c.Tracing.Exporter {
+	case TracingExporterNone, TracingExporterOTLP, TracingExporterStdout:
+	default:
+		errs = append(errs, fmt.Errorf("OTEL_TRACES_EXPORTER must be one of %s, %s or %s", TracingExporterNone, TracingExporterOTLP, TracingExporterStdout))
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Logging.Level)); err != nil {
+		errs = append(errs, errors.New("LOG_LEVEL must be one of debug, info, warn or error"))
+	}
+	switch c.Logging.Format {
+	case "", LogFormatText, LogFormatJSON:
+	default:
+		errs = append(errs, fmt.Errorf("LOG_FORMAT must be %s or %s", LogFormatText, LogFormatJSON))
+	}
+	if c.Referrals.RewardDays <= 0 {
+		errs = append(errs, errors.New("REFERRAL_REWARD_DAYS must be positive"))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Config) IsProduction() bool {
+	return c.Environment == EnvironmentProduction
+}
+
+// Report summarises where the server connects and which optional integrations are configured
+func (c *Config) Report() string {
+	return fmt.Sprintf(
+		"environment=%s port=%s database=%s@%s:%s/%s redis=%s email_otp=%s whatsapp_otp=%s slack=%s app_store=%s google_play=%s metrics=%s tracing=%s rate_limit=%s",
+		c.Environment, c.Server.Port,
+		c.Database.User, c.Database.Host, c.Database.Port, c.Database.Name,
+		c.Redis.Addr,
+		enabled(c.Messaging.ResendAPIKey != ""),
+		enabled(c.Messaging.WasenderAPIToken != ""),
+		enabled(c.Messaging.SlackWebhookURL != ""),
+		enabled(c.Stores.AppleBundleID != "" && c.Stores.AppleRootCerts != ""),
+		enabled(c.Stores.GooglePlayPackageName != "" && c.Stores.GooglePlayServiceAccountFile != ""),
+		enabled(c.Metrics.Enabled),
+		c.Tracing.Exporter,
+		enabled(c.RateLimit.Enabled),
+	)
+}
+
+func enabled(configured bool) string {
+	if configured {
+		return "on"
+	}
+	return "off"
+}
+
+// Redacted returns a copy of the configuration with every non-empty secret replaced
+func (c *Config) Redacted() Config {
+	redacted := *c
+	redactSecrets(reflect.ValueOf(&redacted).Elem())
+	return redacted
+}
+
+func redactSecrets(value reflect.Value) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		if field.Kind() == reflect.Struct {
+			redactSecrets(field)
+			continue
+		}
+		if value.Type().Field(i).Tag.Get("secret") == "true" && field.Kind() == reflect.String && field.String() != "" {
+			field.SetString(redactedValue)
+		}
 	}
 }
 
-func GetEnv(key string, defaultValue ...string) string {
-	value, exists := os.LookupEnv(key)
-	if !exists && len(defaultValue) > 0 {
-		return defaultValue[0]
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
 	}
-	return value
+	sort.Strings(keys)
+	return keys
 }
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_EnvironmentOverridesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("database:\n  host: db.internal\n  name: khaimah\nreferrals:\n  reward_days: 14\n"), 0o600))
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("APP_ENV", EnvironmentDevelopment)
+	t.Setenv("DB_NAME", "khaimah_test")
+	t.Setenv("REDIS_DB", "2")
+
+	cfg, err := Load()
+
+	require.NoError(t, err)
+	assert.Equal(t, "db.internal", cfg.Database.Host)
+	assert.Equal(t, "khaimah_test", cfg.Database.Name)
+	assert.Equal(t, 14, cfg.Referrals.RewardDays)
+	assert.Equal(t, 2, cfg.Redis.DB)
+	assert.Equal(t, "5432", cfg.Database.Port)
+}
+
+func TestLoad_InvalidInteger(t *testing.T) {
+	t.Setenv("CONFIG_FILE", filepath.Join(t.TempDir(), "missing.yaml"))
+	t.Setenv("REFERRAL_REWARD_DAYS", "thirty")
+
+	_, err := Load()
+
+	assert.Error(t, err)
+}
+
+func TestLoad_RequiresEnvironment(t *testing.T) {
+	t.Setenv("APP_ENV", "")
+	require.NoError(t, os.Unsetenv("APP_ENV"))
+
+	_, err := Load()
+
+	assert.ErrorContains(t, err, "APP_ENV is required")
+}
+
+func TestValidate_DevelopmentAllowsDefaults(t *testing.T) {
+	cfg := Defaults()
+	cfg.Environment = EnvironmentDevelopment
+
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidate_ProductionRejectsDefaultSecrets(t *testing.T) {
+	cfg := Defaults()
+	cfg.Environment = EnvironmentProduction
+
+	err := cfg.Validate()
+
+	assert.ErrorContains(t, err, "JWT_SECRET")
+	assert.ErrorContains(t, err, "DB_PASSWORD")
+	assert.ErrorContains(t, err, "RESEND_API_KEY is required")
+}
+
+func TestValidate_ProductionAcceptsStrongSecrets(t *testing.T) {
+	cfg := Defaults()
+	cfg.Environment = EnvironmentProduction
+	cfg.Auth.JWTSecret = "f3b9c1d27a4e4b8e9c0d6a5f1e2b3c4d"
+	cfg.Database.Password = "s3cure-database-password"
+	cfg.Messaging.ResendAPIKey = "re_123"
+	cfg.Messaging.ResendSenderEmail = "noreply@example.com"
+
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidate_UnknownEnvironment(t *testing.T) {
+	cfg := Defaults()
+	cfg.Environment = "prod"
+
+	assert.ErrorContains(t, cfg.Validate(), "APP_ENV")
+}
+
+func TestValidate_TrustedProxies(t *testing.T) {
+	cfg := Defaults()
+	cfg.Environment = EnvironmentDevelopment
+	cfg.Server.TrustedProxies = "10.0.0.0/8, 192.168.1.10/32"
+
+	ranges, err := cfg.Server.TrustedProxyRanges()
+	require.NoError(t, err)
+	assert.Len(t, ranges, 2)
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Server.TrustedProxies = "10.0.0.1"
+	assert.ErrorContains(t, cfg.Validate(), "TRUSTED_PROXIES")
+}
+
+func TestValidate_LegacyTokenCutoff(t *testing.T) {
+	cfg := Defaults()
+	cfg.Environment = EnvironmentDevelopment
+	cfg.Auth.JWTLegacyTokensUntil = "2026-12-31T00:00:00Z"
+
+	cutoff, err := cfg.Auth.LegacyTokenCutoff()
+	require.NoError(t, err)
+	assert.Equal(t, 2026, cutoff.Year())
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Auth.JWTLegacyTokensUntil = "31/12/2026"
+	assert.ErrorContains(t, cfg.Validate(), "JWT_LEGACY_TOKENS_UNTIL")
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := Defaults()
+	cfg.Messaging.SlackWebhookURL = "https://hooks.slack.com/services/secret"
+
+	redacted := cfg.Redacted()
+
+	assert.Equal(t, redactedValue, redacted.Auth.JWTSecret)
+	assert.Equal(t, redactedValue, redacted.Database.Password)
+	assert.Equal(t, redactedValue, redacted.Messaging.SlackWebhookURL)
+	assert.Empty(t, redacted.Redis.Password)
+	assert.Equal(t, "localhost", redacted.Database.Host)
+	assert.Equal(t, "alkhaimah123", cfg.Auth.JWTSecret)
+}
+
+func TestReport(t *testing.T) {
+	cfg := Defaults()
+	cfg.Environment = EnvironmentDevelopment
+	cfg.Messaging.ResendAPIKey = "re_123"
+
+	report := cfg.Report()
+
+	assert.Contains(t, report, "environment=development")
+	assert.Contains(t, report, "database=postgres@localhost:5432/mydatabase")
+	assert.Contains(t, report, "email_otp=on")
+	assert.Contains(t, report, "slack=off")
+	assert.NotContains(t, report, "alkhaimah123")
+}
+
+func TestLoad_ParsesBooleans(t *testing.T) {
+	t.Setenv("APP_ENV", EnvironmentDevelopment)
+	t.Setenv("METRICS_ENABLED", "true")
+
+	cfg, err := Load()
+
+	require.NoError(t, err)
+	assert.True(t, cfg.Metrics.Enabled)
+	assert.Equal(t, "/metrics", cfg.Metrics.Path)
+}
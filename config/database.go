@@ -2,7 +2,8 @@ package config
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
+	"os"
 
 	"gorm.io/gorm/logger"
 
@@ -12,28 +13,24 @@ import (
 
 var DB *gorm.DB
 
-func Connect() {
-	LoadEnv()
-
-	dbUser := GetEnv("DB_USER", "postgres")
-	dbPassword := GetEnv("DB_PASSWORD", "password")
-	dbHost := GetEnv("DB_HOST", "localhost")
-	dbPort := GetEnv("DB_PORT", "5432")
-	dbName := GetEnv("DB_NAME", "mydatabase")
+// QueryLogger is the GORM logger used by Connect; the application replaces it with its structured logger
+var QueryLogger = logger.Default.LogMode(logger.Warn)
 
+func Connect(cfg DatabaseConfig) {
 	dsn := fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=UTC",
-		dbHost, dbUser, dbPassword, dbName, dbPort,
+		"host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=UTC",
+		cfg.Host, cfg.User, cfg.Password, cfg.Name, cfg.Port, cfg.SSLMode,
 	)
 
 	database, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Warn),
+		Logger: QueryLogger,
 	})
 	if err != nil {
-		log.Fatalf("❌ Failed to connect to database: %v", err)
+		slog.Error("Failed to connect to database", "error", err)
+		os.Exit(1)
 	}
 
-	log.Println("✅ Database connected successfully!")
+	slog.Info("Database connected")
 	DB = database
 }
 
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"sync"
 	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/metrics"
 )
 
 var (
@@ -128,7 +130,9 @@ func SetWithTTL(ctx context.Context, key string, value string, ttl time.Duration
 
 // Get retrieves a value by key
 func Get(ctx context.Context, key string) (string, error) {
-	return GetCache().Get(ctx, key)
+	value, err := GetCache().Get(ctx, key)
+	metrics.ObserveCacheLookup(metrics.CacheMemory, err, ErrKeyNotFound, ErrKeyExpired)
+	return value, err
 }
 
 // Delete removes a key
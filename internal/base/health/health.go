@@ -0,0 +1,131 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	StatusOK       = "ok"
+	StatusFailing  = "failing"
+	StatusDraining = "draining"
+)
+
+// Check returns an error when the dependency it probes is unavailable
+type Check func(ctx context.Context) error
+
+type CheckResult struct {
+	Status    string `json:"status"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Report is the readiness breakdown returned by /readyz
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// Checker runs the named readiness checks. Once draining it reports not ready,
+// so load balancers stop routing traffic while the server shuts down.
+type Checker struct {
+	timeout  time.Duration
+	mu       sync.RWMutex
+	checks   map[string]Check
+	draining atomic.Bool
+}
+
+// NewChecker creates a checker that gives each check at most timeout to complete
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{
+		timeout: timeout,
+		checks:  make(map[string]Check),
+	}
+}
+
+// Add registers a readiness check under name
+func (c *Checker) Add(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checks[name] = check
+}
+
+// SetDraining marks the server as shutting down
+func (c *Checker) SetDraining() {
+	c.draining.Store(true)
+}
+
+// Ready runs every check concurrently and reports whether all of them passed
+func (c *Checker) Ready(ctx context.Context) (Report, bool) {
+	c.mu.RLock()
+	names := make([]string, 0, len(c.checks))
+	for name := range c.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	checks := make([]Check, len(names))
+	for i, name := range names {
+		checks[i] = c.checks[name]
+	}
+	c.mu.RUnlock()
+
+	results := make([]CheckResult, len(names))
+	var wg sync.WaitGroup
+	for i := range names {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = c.run(ctx, checks[i])
+		}(i)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(names))}
+	for i, name := range names {
+		report.Checks[name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusFailing
+		}
+	}
+	if c.draining.Load() {
+		report.Status = StatusDraining
+	}
+
+	return report, report.Status == StatusOK
+}
+
+func (c *Checker) run(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	started := time.Now()
+	err := check(ctx)
+	result := CheckResult{Status: StatusOK, LatencyMS: time.Since(started).Milliseconds()}
+	if err != nil {
+		result.Status = StatusFailing
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Liveness reports that the process is up and serving requests. It checks no dependencies,
+// so an outage of Postgres or Redis does not get the process restarted.
+func (c *Checker) Liveness(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, map[string]string{"status": StatusOK})
+}
+
+// Readiness returns the readiness breakdown, with 503 when any check fails or the server is draining
+func (c *Checker) Readiness(ctx echo.Context) error {
+	report, ready := c.Ready(ctx.Request().Context())
+	if !ready {
+		return ctx.JSON(http.StatusServiceUnavailable, report)
+	}
+	return ctx.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,71 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReady_AllChecksPass(t *testing.T) {
+	checker := NewChecker(time.Second)
+	checker.Add("database", func(context.Context) error { return nil })
+	checker.Add("redis", func(context.Context) error { return nil })
+
+	report, ready := checker.Ready(context.Background())
+
+	assert.True(t, ready)
+	assert.Equal(t, StatusOK, report.Status)
+	assert.Len(t, report.Checks, 2)
+}
+
+func TestReady_FailingCheck(t *testing.T) {
+	checker := NewChecker(time.Second)
+	checker.Add("database", func(context.Context) error { return nil })
+	checker.Add("redis", func(context.Context) error { return errors.New("connection refused") })
+
+	report, ready := checker.Ready(context.Background())
+
+	assert.False(t, ready)
+	assert.Equal(t, StatusFailing, report.Status)
+	assert.Equal(t, StatusOK, report.Checks["database"].Status)
+	assert.Equal(t, "connection refused", report.Checks["redis"].Error)
+}
+
+func TestReady_CheckTimesOut(t *testing.T) {
+	checker := NewChecker(10 * time.Millisecond)
+	checker.Add("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	report, ready := checker.Ready(context.Background())
+
+	assert.False(t, ready)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["slow"].Error)
+}
+
+func TestReadiness_DrainingReturnsUnavailable(t *testing.T) {
+	checker := NewChecker(time.Second)
+	checker.Add("database", func(context.Context) error { return nil })
+	checker.SetDraining()
+
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(httptest.NewRequest(http.MethodGet, "/readyz", nil), rec)
+
+	require.NoError(t, checker.Readiness(ctx))
+
+	var report Report
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, StatusDraining, report.Status)
+	assert.Equal(t, StatusOK, report.Checks["database"].Status)
+}
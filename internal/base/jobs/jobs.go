@@ -0,0 +1,243 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/logger"
+)
+
+var (
+	ErrQueueFull     = errors.New("job queue is full")
+	ErrUnknownJob    = errors.New("no handler registered for job")
+	ErrQueueStopped  = errors.New("job queue is stopped")
+	ErrQueueNotReady = errors.New("job queue is not running")
+)
+
+// Handler processes a single job payload
+type Handler func(ctx context.Context, payload string) error
+
+// Job is a unit of background work identified by the name of its handler
+type Job struct {
+	Name    string
+	Payload string
+}
+
+// Queue is a simple in-process job queue backed by a buffered channel
+type Queue struct {
+	handlers map[string]Handler
+	jobs     chan Job
+	mu       sync.RWMutex
+	wg       sync.WaitGroup
+	running  bool
+	stopped  bool
+	workers  int
+	alive    int
+	// done is closed by Stop so scheduled jobs stop firing
+	done chan struct{}
+}
+
+// NewQueue creates a new queue that can hold up to size pending jobs
+func NewQueue(size int) *Queue {
+	return &Queue{
+		handlers: make(map[string]Handler),
+		jobs:     make(chan Job, size),
+		done:     make(chan struct{}),
+	}
+}
+
+// Register binds a handler to a job name
+func (q *Queue) Register(name string, handler Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.handlers[name] = handler
+}
+
+// Enqueue adds a job to the queue without blocking
+func (q *Queue) Enqueue(name, payload string) error {
+	// The read lock is held while sending so Stop cannot close the channel underneath us
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.stopped {
+		return ErrQueueStopped
+	}
+	if _, found := q.handlers[name]; !found {
+		return fmt.Errorf("%w: %s", ErrUnknownJob, name)
+	}
+
+	select {
+	case q.jobs <- Job{Name: name, Payload: payload}:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Start launches the given number of workers
+func (q *Queue) Start(workers int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.running || q.stopped {
+		return
+	}
+	q.running = true
+	q.workers = workers
+	q.alive = workers
+
+	for i := 0; i < workers; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+}
+
+// Every enqueues the named job on a fixed interval
+func (q *Queue) Every(interval time.Duration, name, payload string) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-q.done:
+				return
+			case <-ticker.C:
+				if err := q.Enqueue(name, payload); err != nil {
+					slog.Error("Failed to schedule job", "job", name, "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Pending returns the number of jobs waiting to be picked up
+func (q *Queue) Pending() int {
+	return len(q.jobs)
+}
+
+// Stop stops accepting jobs and waits for the workers to finish the jobs already queued.
+// If ctx ends first, Stop returns its error and the remaining jobs are abandoned.
+func (q *Queue) Stop(ctx context.Context) error {
+	q.mu.Lock()
+	if q.stopped {
+		q.mu.Unlock()
+		return nil
+	}
+	q.stopped = true
+	close(q.done)
+	close(q.jobs)
+	q.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to drain job queue: %w", ctx.Err())
+	}
+}
+
+// Health reports whether the queue is running with all of its workers
+func (q *Queue) Health() error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	switch {
+	case q.stopped:
+		return ErrQueueStopped
+	case !q.running:
+		return ErrQueueNotReady
+	case q.alive < q.workers:
+		return fmt.Errorf("only %d of %d job workers are running", q.alive, q.workers)
+	case len(q.jobs) == cap(q.jobs):
+		return ErrQueueFull
+	}
+	return nil
+}
+
+func (q *Queue) work() {
+	defer q.wg.Done()
+	defer func() {
+		q.mu.Lock()
+		q.alive--
+		q.mu.Unlock()
+	}()
+
+	for job := range q.jobs {
+		q.run(job)
+	}
+}
+
+// run executes a single job and recovers from panics so one bad job cannot kill a worker.
+// The handler's context carries the job name, so everything it logs can be traced back to the job.
+func (q *Queue) run(job Job) {
+	ctx := logger.With(context.Background(), "job", job.Name)
+	defer func() {
+		if r := recover(); r != nil {
+			logger.FromContext(ctx).Error("Job panicked", "panic", r)
+		}
+	}()
+
+	q.mu.RLock()
+	handler := q.handlers[job.Name]
+	q.mu.RUnlock()
+
+	if err := handler(ctx, job.Payload); err != nil {
+		logger.FromContext(ctx).Error("Job failed", "error", err)
+	}
+}
+
+// Global queue instance
+var (
+	globalQueue *Queue
+	once        sync.Once
+)
+
+// GetQueue returns the global queue instance
+func GetQueue() *Queue {
+	once.Do(func() {
+		globalQueue = NewQueue(256)
+	})
+	return globalQueue
+}
+
+// Register binds a handler to a job name on the global queue
+func Register(name string, handler Handler) {
+	GetQueue().Register(name, handler)
+}
+
+// Enqueue adds a job to the global queue
+func Enqueue(name, payload string) error {
+	return GetQueue().Enqueue(name, payload)
+}
+
+// Every schedules a recurring job on the global queue
+func Every(interval time.Duration, name, payload string) {
+	GetQueue().Every(interval, name, payload)
+}
+
+// Start launches the workers of the global queue
+func Start(workers int) {
+	GetQueue().Start(workers)
+}
+
+// Stop drains the global queue
+func Stop(ctx context.Context) error {
+	return GetQueue().Stop(ctx)
+}
+
+// Health reports the health of the global queue
+func Health() error {
+	return GetQueue().Health()
+}
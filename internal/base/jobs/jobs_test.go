@@ -0,0 +1,58 @@
+package jobs
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStop_DrainsQueuedJobs(t *testing.T) {
+	queue := NewQueue(10)
+	var processed atomic.Int32
+	queue.Register("count", func(_ context.Context, _ string) error {
+		time.Sleep(10 * time.Millisecond)
+		processed.Add(1)
+		return nil
+	})
+	queue.Start(2)
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, queue.Enqueue("count", ""))
+	}
+
+	require.NoError(t, queue.Stop(context.Background()))
+	assert.Equal(t, int32(5), processed.Load())
+	assert.ErrorIs(t, queue.Enqueue("count", ""), ErrQueueStopped)
+	assert.ErrorIs(t, queue.Health(), ErrQueueStopped)
+}
+
+func TestStop_ReturnsWhenDeadlinePasses(t *testing.T) {
+	queue := NewQueue(1)
+	release := make(chan struct{})
+	defer close(release)
+	queue.Register("block", func(_ context.Context, _ string) error {
+		<-release
+		return nil
+	})
+	queue.Start(1)
+	require.NoError(t, queue.Enqueue("block", ""))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, queue.Stop(ctx), context.DeadlineExceeded)
+}
+
+func TestHealth(t *testing.T) {
+	queue := NewQueue(1)
+	queue.Register("noop", func(_ context.Context, _ string) error { return nil })
+
+	assert.ErrorIs(t, queue.Health(), ErrQueueNotReady)
+
+	queue.Start(1)
+	assert.NoError(t, queue.Health())
+}
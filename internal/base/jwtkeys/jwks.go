@@ -0,0 +1,51 @@
+package jwtkeys
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/lestrrat-go/jwx/jwk"
+)
+
+// JWKSPath is where the public signing keys are published
+const JWKSPath = "/.well-known/jwks.json"
+
+const jwksMaxAge = 5 * time.Minute
+
+// JWKS returns the public keys that verify current tokens. HMAC keys are never included.
+func (m *Manager) JWKS() (jwk.Set, error) {
+	set := jwk.NewSet()
+	if m == nil {
+		return set, nil
+	}
+
+	for _, k := range m.publicKeys() {
+		published, err := jwk.New(k.public)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode key %s: %w", k.id, err)
+		}
+		for name, value := range map[string]interface{}{
+			jwk.KeyIDKey:     k.id,
+			jwk.AlgorithmKey: k.method.Alg(),
+			jwk.KeyUsageKey:  "sig",
+		} {
+			if err := published.Set(name, value); err != nil {
+				return nil, fmt.Errorf("failed to set %s on key %s: %w", name, k.id, err)
+			}
+		}
+		set.Add(published)
+	}
+	return set, nil
+}
+
+// JWKSHandler serves the JWKS. Verifiers may cache it for jwksMaxAge, which publishLead covers.
+func (m *Manager) JWKSHandler(c echo.Context) error {
+	set, err := m.JWKS()
+	if err != nil {
+		return err
+	}
+	c.Response().Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(jwksMaxAge.Seconds())))
+	return c.JSON(http.StatusOK, set)
+}
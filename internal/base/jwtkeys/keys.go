@@ -0,0 +1,157 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/config"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/utils"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	rsaKeyBits    = 2048
+	hmacKeyLength = 32
+)
+
+// SigningKey is a JWT signing key shared by every instance through the database. A key is
+// published before it activates, signs until the next key activates, then only verifies
+// until it expires.
+type SigningKey struct {
+	base.Model
+	KeyID     string `gorm:"type:varchar(64);uniqueIndex" json:"kid"`
+	Algorithm string `gorm:"type:varchar(10)" json:"alg"`
+	// PrivateKey is the PKCS#8 PEM private key, or the raw HMAC secret, encrypted
+	PrivateKey  string     `gorm:"type:text" json:"-"`
+	PublicKey   string     `gorm:"type:text" json:"-"`
+	ActivatesAt time.Time  `json:"activates_at"`
+	RetiredAt   *time.Time `json:"retired_at"`
+	ExpiresAt   *time.Time `gorm:"index" json:"expires_at"`
+}
+
+// key is a decoded SigningKey ready to sign or verify
+type key struct {
+	id        string
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+	// public is nil for HMAC keys, which are never published
+	public      crypto.PublicKey
+	activatesAt time.Time
+}
+
+// newSigningKey generates a key for algorithm, with the private half encrypted under encryptionKey
+func newSigningKey(algorithm, encryptionKey string) (*SigningKey, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate key ID: %w", err)
+	}
+
+	var private, public string
+	switch algorithm {
+	case config.JWTAlgorithmHS256:
+		secret := make([]byte, hmacKeyLength)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("failed to generate HMAC secret: %w", err)
+		}
+		private = base64.StdEncoding.EncodeToString(secret)
+	case config.JWTAlgorithmRS256, config.JWTAlgorithmES256:
+		signer, err := generateSigner(algorithm)
+		if err != nil {
+			return nil, err
+		}
+		privateDER, err := x509.MarshalPKCS8PrivateKey(signer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode private key: %w", err)
+		}
+		publicDER, err := x509.MarshalPKIXPublicKey(signer.Public())
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode public key: %w", err)
+		}
+		private = string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}))
+		public = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+
+	encrypted, err := utils.Encrypt(encryptionKey, private)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt signing key: %w", err)
+	}
+
+	return &SigningKey{
+		KeyID:      hex.EncodeToString(id),
+		Algorithm:  algorithm,
+		PrivateKey: encrypted,
+		PublicKey:  public,
+	}, nil
+}
+
+func generateSigner(algorithm string) (crypto.Signer, error) {
+	if algorithm == config.JWTAlgorithmRS256 {
+		signer, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate RSA key: %w", err)
+		}
+		return signer, nil
+	}
+
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ECDSA key: %w", err)
+	}
+	return signer, nil
+}
+
+// decode turns a stored key back into signing and verification keys
+func (s *SigningKey) decode(encryptionKey string) (*key, error) {
+	private, err := utils.Decrypt(encryptionKey, s.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt signing key %s: %w", s.KeyID, err)
+	}
+
+	k := &key{
+		id:          s.KeyID,
+		method:      jwt.GetSigningMethod(s.Algorithm),
+		activatesAt: s.ActivatesAt,
+	}
+	if k.method == nil {
+		return nil, fmt.Errorf("signing key %s has unsupported algorithm %q", s.KeyID, s.Algorithm)
+	}
+
+	if s.Algorithm == config.JWTAlgorithmHS256 {
+		secret, err := base64.StdEncoding.DecodeString(private)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode HMAC secret %s: %w", s.KeyID, err)
+		}
+		k.signKey, k.verifyKey = secret, secret
+		return k, nil
+	}
+
+	block, _ := pem.Decode([]byte(private))
+	if block == nil {
+		return nil, fmt.Errorf("signing key %s is not PEM encoded", s.KeyID)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key %s: %w", s.KeyID, err)
+	}
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("signing key is not a signer")
+	}
+
+	k.signKey, k.verifyKey, k.public = signer, signer.Public(), signer.Public()
+	return k, nil
+}
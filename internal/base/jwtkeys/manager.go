@@ -0,0 +1,333 @@
+// Package jwtkeys signs and verifies the API's JWTs with rotating keys identified by kid.
+// Keys live in the database so every instance signs and verifies with the same set, and
+// the public halves are published as a JWKS for other services.
+package jwtkeys
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RotateJobName is the background job that rotates the signing key once it is due
+const RotateJobName = "jwt_keys.rotate"
+
+// CheckInterval is how often each instance runs RotateJobName, which also reloads its keys
+const CheckInterval = 10 * time.Minute
+
+// publishLead is how long a scheduled key is published before it signs anything. It covers
+// CheckInterval plus the JWKS cache lifetime, so every verifier knows the key before it sees it.
+const publishLead = 15 * time.Minute
+
+// rotationLockKey identifies the Postgres advisory lock held while rotating, so replicas
+// checking at the same time create one new key between them
+const rotationLockKey int64 = 74_119_116_107_101
+
+// reloadInterval throttles reloading keys from the database when a token names an unknown kid
+const reloadInterval = 10 * time.Second
+
+var (
+	ErrNotInitialized = errors.New("signing keys are not initialized")
+	ErrUnknownKey     = errors.New("token signed by an unknown or expired key")
+)
+
+// Claims are the claims the API reads from a bearer token
+type Claims struct {
+	UserID uuid.UUID
+	// MFA is set on tokens issued after a completed two-factor challenge
+	MFA bool
+	// ImpersonatorID is the admin acting as UserID, set on impersonation tokens only
+	ImpersonatorID *uuid.UUID
+	// ReadOnly impersonation tokens may not be used for requests that change anything
+	ReadOnly bool
+}
+
+// Manager signs with the newest active key and verifies with every key that has not expired
+type Manager struct {
+	db  *gorm.DB
+	cfg config.AuthConfig
+	now func() time.Time
+	// legacyUntil is when tokens without a kid stop verifying
+	legacyUntil time.Time
+
+	mu   sync.RWMutex
+	keys map[string]*key
+	// ordered holds the same keys, most recently activated first
+	ordered    []*key
+	lastReload time.Time
+}
+
+func NewManager(db *gorm.DB, cfg config.AuthConfig) *Manager {
+	// Validated with the rest of the configuration, so an error here leaves legacy tokens refused
+	legacyUntil, _ := cfg.LegacyTokenCutoff()
+	return &Manager{
+		db:          db,
+		cfg:         cfg,
+		now:         time.Now,
+		legacyUntil: legacyUntil,
+		keys:        make(map[string]*key),
+	}
+}
+
+// TokenLifetime is how long a token is valid after it is issued. It is the overlap window, so a
+// token always expires before the key that signed it: a key keeps verifying for the overlap
+// window after its replacement activates, and it signed the token before that.
+func (m *Manager) TokenLifetime() time.Duration {
+	return time.Duration(m.cfg.JWTKeyOverlapDays) * 24 * time.Hour
+}
+
+// Load reads every key that still verifies from the database
+func (m *Manager) Load(ctx context.Context) error {
+	var stored []SigningKey
+	result := m.db.WithContext(ctx).
+		Where("expires_at IS NULL OR expires_at > ?", m.now()).
+		Order("activates_at DESC").
+		Find(&stored)
+	if result.Error != nil {
+		return fmt.Errorf("failed to load signing keys: %w", result.Error)
+	}
+
+	keys := make(map[string]*key, len(stored))
+	ordered := make([]*key, 0, len(stored))
+	for i := range stored {
+		k, err := stored[i].decode(m.cfg.SigningKeyEncryptionKey())
+		if err != nil {
+			return err
+		}
+		keys[k.id] = k
+		ordered = append(ordered, k)
+	}
+
+	m.mu.Lock()
+	m.keys, m.ordered, m.lastReload = keys, ordered, m.now()
+	m.mu.Unlock()
+	return nil
+}
+
+// Rotate creates a new signing key and retires the others, which keep verifying for the
+// overlap window once the new key activates. Unless forced it only rotates when there is no
+// key for the configured algorithm or the newest one is older than the rotation period, and
+// the new key is published for publishLead before it signs. A forced rotation, for a leaked
+// key, activates at once.
+func (m *Manager) Rotate(ctx context.Context, force bool) error {
+	now := m.now()
+	rotated := false
+
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", rotationLockKey).Error; err != nil {
+			return fmt.Errorf("failed to acquire rotation lock: %w", err)
+		}
+
+		var current SigningKey
+		result := tx.Where("retired_at IS NULL AND algorithm = ?", m.cfg.JWTAlgorithm).Order("created_at DESC").Limit(1).Find(&current)
+		if result.Error != nil {
+			return fmt.Errorf("failed to find current signing key: %w", result.Error)
+		}
+		due := current.CreatedAt.Add(time.Duration(m.cfg.JWTKeyRotationDays) * 24 * time.Hour)
+		if !force && result.RowsAffected > 0 && now.Before(due) {
+			return nil
+		}
+
+		next, err := newSigningKey(m.cfg.JWTAlgorithm, m.cfg.SigningKeyEncryptionKey())
+		if err != nil {
+			return err
+		}
+		next.ActivatesAt = now
+		if !force && result.RowsAffected > 0 {
+			next.ActivatesAt = now.Add(publishLead)
+		}
+		expiresAt := next.ActivatesAt.Add(time.Duration(m.cfg.JWTKeyOverlapDays) * 24 * time.Hour)
+		if err := tx.Model(&SigningKey{}).Where("retired_at IS NULL").Updates(map[string]interface{}{
+			"retired_at": next.ActivatesAt,
+			"expires_at": expiresAt,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to retire signing keys: %w", err)
+		}
+		if err := tx.Create(next).Error; err != nil {
+			return fmt.Errorf("failed to store signing key: %w", err)
+		}
+
+		rotated = true
+		slog.Info("Rotated JWT signing key", "kid", next.KeyID, "alg", next.Algorithm,
+			"activates_at", next.ActivatesAt, "previous_keys_expire_at", expiresAt)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if rotated {
+		return m.Load(ctx)
+	}
+	return nil
+}
+
+// RotateIfDue is the RotateJobName handler. It also reloads the keys, so instances pick up
+// rotations made by other replicas before they see tokens signed with the new key.
+func (m *Manager) RotateIfDue(ctx context.Context, _ string) error {
+	if err := m.Rotate(ctx, false); err != nil {
+		return err
+	}
+	return m.Load(ctx)
+}
+
+// Sign signs claims with the current key and names it in the kid header. Tokens without an
+// exp claim expire after TokenLifetime.
+func (m *Manager) Sign(claims jwt.MapClaims) (string, error) {
+	if m == nil {
+		return "", ErrNotInitialized
+	}
+	k := m.current()
+	if k == nil {
+		return "", ErrNotInitialized
+	}
+
+	now := m.now()
+	if _, ok := claims["iat"]; !ok {
+		claims["iat"] = now.Unix()
+	}
+	if _, ok := claims["exp"]; !ok {
+		claims["exp"] = now.Add(m.TokenLifetime()).Unix()
+	}
+	token := jwt.NewWithClaims(k.method, claims)
+	token.Header["kid"] = k.id
+	return token.SignedString(k.signKey)
+}
+
+// Parse verifies a bearer token with the key its kid names and returns its claims. Tokens
+// without a kid predate key rotation and are verified with JWT_SECRET until the legacy cutoff.
+func (m *Manager) Parse(tokenString string) (Claims, error) {
+	if m == nil {
+		return Claims{}, ErrNotInitialized
+	}
+
+	token, err := jwt.Parse(tokenString, m.verificationKey,
+		jwt.WithValidMethods([]string{config.JWTAlgorithmHS256, config.JWTAlgorithmRS256, config.JWTAlgorithmES256}),
+		jwt.WithTimeFunc(m.now))
+	if err != nil {
+		return Claims{}, fmt.Errorf("token parsing failed: %w", err)
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return Claims{}, errors.New("invalid token claims")
+	}
+
+	userIDStr, ok := claims["user_id"].(string)
+	if !ok {
+		return Claims{}, errors.New("user_id not found in token")
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return Claims{}, errors.New("invalid user ID in token")
+	}
+
+	parsed := Claims{UserID: userID}
+	parsed.MFA, _ = claims["mfa"].(bool)
+	if impersonator, ok := claims["imp"].(string); ok {
+		impersonatorID, err := uuid.Parse(impersonator)
+		if err != nil {
+			return Claims{}, errors.New("invalid impersonator ID in token")
+		}
+		parsed.ImpersonatorID = &impersonatorID
+		parsed.ReadOnly, _ = claims["imp_ro"].(bool)
+	}
+	return parsed, nil
+}
+
+// verificationKey picks the key by kid and refuses tokens whose algorithm does not match it,
+// so an asymmetric public key can never be used as an HMAC secret
+func (m *Manager) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		if token.Method.Alg() != config.JWTAlgorithmHS256 || m.cfg.JWTSecret == "" || !m.now().Before(m.legacyUntil) {
+			return nil, ErrUnknownKey
+		}
+		return []byte(m.cfg.JWTSecret), nil
+	}
+
+	k := m.lookup(kid)
+	if k == nil {
+		return nil, ErrUnknownKey
+	}
+	if token.Method.Alg() != k.method.Alg() {
+		return nil, fmt.Errorf("token algorithm %s does not match key %s", token.Method.Alg(), kid)
+	}
+	return k.verifyKey, nil
+}
+
+// lookup finds a key by kid, reloading from the database when another replica may have
+// rotated since the last load
+func (m *Manager) lookup(kid string) *key {
+	m.mu.RLock()
+	k, lastReload := m.keys[kid], m.lastReload
+	m.mu.RUnlock()
+	if k != nil || m.db == nil || m.now().Sub(lastReload) < reloadInterval {
+		return k
+	}
+
+	if err := m.Load(context.Background()); err != nil {
+		slog.Warn("Failed to reload signing keys", "error", err)
+		return nil
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.keys[kid]
+}
+
+// current is the most recently activated key for the configured algorithm. Retirement is
+// not checked: an instance that has not reloaded yet keeps signing with the previous key,
+// which still verifies for the overlap window.
+func (m *Manager) current() *key {
+	now := m.now()
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, k := range m.ordered {
+		if k.method.Alg() == m.cfg.JWTAlgorithm && !k.activatesAt.After(now) {
+			return k
+		}
+	}
+	return nil
+}
+
+// publicKeys returns the asymmetric keys that still verify, including ones not yet active,
+// newest first
+func (m *Manager) publicKeys() []*key {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := make([]*key, 0, len(m.ordered))
+	for _, k := range m.ordered {
+		if k.public != nil {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+var defaultManager *Manager
+
+// Init loads the signing keys, creating the first one when there is none, and makes the
+// manager available through Default
+func Init(ctx context.Context, db *gorm.DB, cfg config.AuthConfig) (*Manager, error) {
+	manager := NewManager(db, cfg)
+	if err := manager.RotateIfDue(ctx, ""); err != nil {
+		return nil, err
+	}
+
+	defaultManager = manager
+	return manager, nil
+}
+
+// Default returns the manager set up by Init
+func Default() *Manager {
+	return defaultManager
+}
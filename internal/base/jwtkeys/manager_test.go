@@ -0,0 +1,256 @@
+package jwtkeys
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testEncryptionKey = "test-encryption-key"
+
+// newTestManager builds a manager without a database, holding the given keys
+func newTestManager(t *testing.T, algorithm string, now time.Time, stored ...*SigningKey) *Manager {
+	t.Helper()
+	manager := NewManager(nil, config.AuthConfig{
+		JWTSecret:           "legacy-secret",
+		JWTAlgorithm:        algorithm,
+		JWTKeyOverlapDays:   90,
+		JWTKeyEncryptionKey: testEncryptionKey,
+	})
+	manager.now = func() time.Time { return now }
+
+	// Like Load, stored is expected most recently activated first
+	for _, s := range stored {
+		k, err := s.decode(testEncryptionKey)
+		require.NoError(t, err)
+		manager.keys[k.id] = k
+		manager.ordered = append(manager.ordered, k)
+	}
+	return manager
+}
+
+func newTestKey(t *testing.T, algorithm string, activatesAt time.Time) *SigningKey {
+	t.Helper()
+	stored, err := newSigningKey(algorithm, testEncryptionKey)
+	require.NoError(t, err)
+	stored.ActivatesAt = activatesAt
+	return stored
+}
+
+func TestManager_SignAndParseRoundTrip(t *testing.T) {
+	now := time.Now()
+	for _, algorithm := range []string{config.JWTAlgorithmHS256, config.JWTAlgorithmRS256, config.JWTAlgorithmES256} {
+		t.Run(algorithm, func(t *testing.T) {
+			stored := newTestKey(t, algorithm, now)
+			manager := newTestManager(t, algorithm, now, stored)
+			userID := uuid.New()
+
+			token, err := manager.Sign(jwt.MapClaims{"user_id": userID.String(), "mfa": true})
+			require.NoError(t, err)
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+			require.NoError(t, err)
+			assert.Equal(t, stored.KeyID, parsed.Header["kid"])
+			assert.Equal(t, algorithm, parsed.Method.Alg())
+
+			claims, err := manager.Parse(token)
+			require.NoError(t, err)
+			assert.Equal(t, userID, claims.UserID)
+			assert.True(t, claims.MFA)
+		})
+	}
+}
+
+func TestManager_SignsWithNewestActiveKey(t *testing.T) {
+	now := time.Now()
+	old := newTestKey(t, config.JWTAlgorithmES256, now.Add(-time.Hour))
+	pending := newTestKey(t, config.JWTAlgorithmES256, now.Add(publishLead))
+	manager := newTestManager(t, config.JWTAlgorithmES256, now, pending, old)
+
+	token, err := manager.Sign(jwt.MapClaims{"user_id": uuid.NewString()})
+	require.NoError(t, err)
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	require.NoError(t, err)
+	assert.Equal(t, old.KeyID, parsed.Header["kid"], "a key still being published must not sign")
+
+	manager.now = func() time.Time { return now.Add(publishLead) }
+	token, err = manager.Sign(jwt.MapClaims{"user_id": uuid.NewString()})
+	require.NoError(t, err)
+	parsed, _, err = jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	require.NoError(t, err)
+	assert.Equal(t, pending.KeyID, parsed.Header["kid"])
+}
+
+func TestManager_VerifiesRetiredKeysByKid(t *testing.T) {
+	now := time.Now()
+	old := newTestKey(t, config.JWTAlgorithmRS256, now.Add(-time.Hour))
+	before := newTestManager(t, config.JWTAlgorithmRS256, now, old)
+	token, err := before.Sign(jwt.MapClaims{"user_id": uuid.NewString()})
+	require.NoError(t, err)
+
+	// The algorithm changed and a new key took over, the old one still verifies
+	current := newTestKey(t, config.JWTAlgorithmES256, now)
+	after := newTestManager(t, config.JWTAlgorithmES256, now, current, old)
+
+	_, err = after.Parse(token)
+	assert.NoError(t, err)
+}
+
+func TestManager_ParsesLegacyTokensWithoutKid(t *testing.T) {
+	now := time.Now()
+	manager := newTestManager(t, config.JWTAlgorithmES256, now)
+	manager.legacyUntil = now.Add(time.Hour)
+	userID := uuid.New()
+
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": userID.String()}).
+		SignedString([]byte("legacy-secret"))
+	require.NoError(t, err)
+
+	claims, err := manager.Parse(legacy)
+	require.NoError(t, err)
+	assert.Equal(t, userID, claims.UserID)
+	assert.False(t, claims.MFA)
+}
+
+func TestManager_LegacyTokensStopAtCutoff(t *testing.T) {
+	now := time.Now()
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": uuid.NewString()}).
+		SignedString([]byte("legacy-secret"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		until  string
+		parsed bool
+	}{
+		{"no cutoff", "", false},
+		{"before cutoff", now.Add(time.Hour).Format(time.RFC3339), true},
+		{"after cutoff", now.Add(-time.Hour).Format(time.RFC3339), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := NewManager(nil, config.AuthConfig{
+				JWTSecret:            "legacy-secret",
+				JWTAlgorithm:         config.JWTAlgorithmES256,
+				JWTLegacyTokensUntil: tt.until,
+			})
+			manager.now = func() time.Time { return now }
+
+			_, err := manager.Parse(legacy)
+
+			if tt.parsed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrUnknownKey)
+			}
+		})
+	}
+}
+
+func TestManager_TokensExpireWithinOverlap(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	stored := newTestKey(t, config.JWTAlgorithmES256, now)
+	manager := newTestManager(t, config.JWTAlgorithmES256, now, stored)
+
+	token, err := manager.Sign(jwt.MapClaims{"user_id": uuid.NewString()})
+	require.NoError(t, err)
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	require.NoError(t, err)
+	expiresAt, err := parsed.Claims.GetExpirationTime()
+	require.NoError(t, err)
+	assert.True(t, now.Add(manager.TokenLifetime()).Equal(expiresAt.Time))
+
+	manager.now = func() time.Time { return now.Add(manager.TokenLifetime() - time.Minute) }
+	_, err = manager.Parse(token)
+	assert.NoError(t, err)
+
+	manager.now = func() time.Time { return now.Add(manager.TokenLifetime() + time.Minute) }
+	_, err = manager.Parse(token)
+	assert.ErrorIs(t, err, jwt.ErrTokenExpired)
+}
+
+func TestManager_RejectsUnknownKid(t *testing.T) {
+	now := time.Now()
+	other := newTestManager(t, config.JWTAlgorithmES256, now, newTestKey(t, config.JWTAlgorithmES256, now))
+	token, err := other.Sign(jwt.MapClaims{"user_id": uuid.NewString()})
+	require.NoError(t, err)
+
+	manager := newTestManager(t, config.JWTAlgorithmES256, now, newTestKey(t, config.JWTAlgorithmES256, now))
+	_, err = manager.Parse(token)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestManager_RejectsAlgorithmMismatch(t *testing.T) {
+	now := time.Now()
+	stored := newTestKey(t, config.JWTAlgorithmRS256, now)
+	manager := newTestManager(t, config.JWTAlgorithmRS256, now, stored)
+
+	// An attacker signs with HS256, naming the RSA key, hoping its public key is used as the secret
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": uuid.NewString()})
+	forged.Header["kid"] = stored.KeyID
+	token, err := forged.SignedString([]byte(stored.PublicKey))
+	require.NoError(t, err)
+
+	_, err = manager.Parse(token)
+	assert.Error(t, err)
+}
+
+func TestManager_JWKSPublishesOnlyAsymmetricKeys(t *testing.T) {
+	now := time.Now()
+	hmac := newTestKey(t, config.JWTAlgorithmHS256, now.Add(-2*time.Hour))
+	rsa := newTestKey(t, config.JWTAlgorithmRS256, now.Add(-time.Hour))
+	pending := newTestKey(t, config.JWTAlgorithmES256, now.Add(publishLead))
+	manager := newTestManager(t, config.JWTAlgorithmES256, now, pending, rsa, hmac)
+
+	set, err := manager.JWKS()
+	require.NoError(t, err)
+	require.Equal(t, 2, set.Len())
+
+	for i, want := range []*SigningKey{pending, rsa} {
+		published, ok := set.Get(i)
+		require.True(t, ok)
+		assert.Equal(t, want.KeyID, published.KeyID())
+		assert.Equal(t, want.Algorithm, published.Algorithm())
+	}
+}
+
+func TestManager_SignWithoutKeysFails(t *testing.T) {
+	_, err := newTestManager(t, config.JWTAlgorithmES256, time.Now()).Sign(jwt.MapClaims{})
+	assert.ErrorIs(t, err, ErrNotInitialized)
+
+	var manager *Manager
+	_, err = manager.Parse("token")
+	assert.ErrorIs(t, err, ErrNotInitialized)
+}
+
+func TestManager_ParsesImpersonationClaims(t *testing.T) {
+	now := time.Now()
+	manager := newTestManager(t, config.JWTAlgorithmES256, now, newTestKey(t, config.JWTAlgorithmES256, now))
+	userID, adminID := uuid.New(), uuid.New()
+
+	token, err := manager.Sign(jwt.MapClaims{"user_id": userID.String(), "imp": adminID.String(), "imp_ro": true, "exp": now.Add(time.Minute).Unix()})
+	require.NoError(t, err)
+	claims, err := manager.Parse(token)
+	require.NoError(t, err)
+	assert.Equal(t, userID, claims.UserID)
+	require.NotNil(t, claims.ImpersonatorID)
+	assert.Equal(t, adminID, *claims.ImpersonatorID)
+	assert.True(t, claims.ReadOnly)
+
+	expired, err := manager.Sign(jwt.MapClaims{"user_id": userID.String(), "imp": adminID.String(), "exp": now.Add(-time.Minute).Unix()})
+	require.NoError(t, err)
+	_, err = manager.Parse(expired)
+	assert.Error(t, err)
+
+	token, err = manager.Sign(jwt.MapClaims{"user_id": userID.String()})
+	require.NoError(t, err)
+	claims, err = manager.Parse(token)
+	require.NoError(t, err)
+	assert.Nil(t, claims.ImpersonatorID)
+}
@@ -0,0 +1,179 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/config"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type contextKey struct{}
+
+// requestFields are attached to every line logged with a request's context. The user ID is
+// filled in by the auth middleware, after the request logger has already stored the fields.
+type requestFields struct {
+	mu        sync.RWMutex
+	requestID string
+	method    string
+	route     string
+	userID    string
+	// impersonatorID is the admin acting as userID, on impersonated requests
+	impersonatorID string
+	attrs          []slog.Attr
+}
+
+// Init makes a redacting slog logger the default, and routes the standard log package through it
+func Init(cfg config.LoggingConfig, environment string) error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		return err
+	}
+
+	format := cfg.Format
+	if format == "" {
+		format = config.LogFormatJSON
+		if environment == config.EnvironmentDevelopment {
+			format = config.LogFormatText
+		}
+	}
+
+	logger := New(os.Stdout, format, level)
+	slog.SetDefault(logger)
+	log.SetFlags(0)
+	log.SetOutput(slog.NewLogLogger(logger.Handler(), slog.LevelInfo).Writer())
+	return nil
+}
+
+// New builds a logger writing format (text or json) to w, redacting personal data and
+// adding request fields from the context of each record
+func New(w io.Writer, format string, level slog.Level) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: level, ReplaceAttr: redactAttr}
+
+	var handler slog.Handler
+	if format == config.LogFormatJSON {
+		handler = slog.NewJSONHandler(w, opts)
+	} else {
+		handler = slog.NewTextHandler(w, opts)
+	}
+	return slog.New(&contextHandler{Handler: handler})
+}
+
+// WithRequest returns a context whose log lines carry the request ID, method and route
+func WithRequest(ctx context.Context, requestID, method, route string) context.Context {
+	return context.WithValue(ctx, contextKey{}, &requestFields{requestID: requestID, method: method, route: route})
+}
+
+// SetUserID records the authenticated user on the request's log fields
+func SetUserID(ctx context.Context, userID string) {
+	if fields, ok := ctx.Value(contextKey{}).(*requestFields); ok {
+		fields.mu.Lock()
+		fields.userID = userID
+		fields.mu.Unlock()
+	}
+}
+
+// SetImpersonatorID records the admin impersonating the request's user on its log fields
+func SetImpersonatorID(ctx context.Context, impersonatorID string) {
+	if fields, ok := ctx.Value(contextKey{}).(*requestFields); ok {
+		fields.mu.Lock()
+		fields.impersonatorID = impersonatorID
+		fields.mu.Unlock()
+	}
+}
+
+// With returns a context whose log lines also carry args, such as the name of a background job
+func With(ctx context.Context, args ...any) context.Context {
+	fields := &requestFields{}
+	if parent, ok := ctx.Value(contextKey{}).(*requestFields); ok {
+		parent.mu.RLock()
+		fields.requestID, fields.method, fields.route, fields.userID = parent.requestID, parent.method, parent.route, parent.userID
+		fields.impersonatorID = parent.impersonatorID
+		fields.attrs = append(fields.attrs, parent.attrs...)
+		parent.mu.RUnlock()
+	}
+	fields.attrs = append(fields.attrs, argsToAttrs(args)...)
+	return context.WithValue(ctx, contextKey{}, fields)
+}
+
+// FromContext returns the default logger bound to ctx, so every line it writes carries
+// the request fields even when called through the non-Context slog methods
+func FromContext(ctx context.Context) *slog.Logger {
+	return slog.New(&boundHandler{Handler: slog.Default().Handler(), ctx: ctx})
+}
+
+// FromEcho returns the logger for the request being handled
+func FromEcho(c echo.Context) *slog.Logger {
+	return FromContext(c.Request().Context())
+}
+
+// contextHandler adds the request fields and trace ID found in the record's context
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if fields, ok := ctx.Value(contextKey{}).(*requestFields); ok {
+		fields.mu.RLock()
+		for _, attr := range []slog.Attr{
+			slog.String("request_id", fields.requestID),
+			slog.String("method", fields.method),
+			slog.String("route", fields.route),
+			slog.String("user_id", fields.userID),
+			slog.String("impersonator_id", fields.impersonatorID),
+		} {
+			if attr.Value.String() != "" {
+				record.AddAttrs(attr)
+			}
+		}
+		record.AddAttrs(fields.attrs...)
+		fields.mu.RUnlock()
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		record.AddAttrs(slog.String("trace_id", spanContext.TraceID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+// boundHandler logs every record with the context it was created with
+type boundHandler struct {
+	slog.Handler
+	ctx context.Context
+}
+
+func (h *boundHandler) Handle(_ context.Context, record slog.Record) error {
+	return h.Handler.Handle(h.ctx, record)
+}
+
+func (h *boundHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &boundHandler{Handler: h.Handler.WithAttrs(attrs), ctx: h.ctx}
+}
+
+func (h *boundHandler) WithGroup(name string) slog.Handler {
+	return &boundHandler{Handler: h.Handler.WithGroup(name), ctx: h.ctx}
+}
+
+func argsToAttrs(args []any) []slog.Attr {
+	record := slog.NewRecord(time.Time{}, 0, "", 0)
+	record.Add(args...)
+	attrs := make([]slog.Attr, 0, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, attr)
+		return true
+	})
+	return attrs
+}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLogger(t *testing.T) (*bytes.Buffer, func() map[string]any) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(New(&buf, config.LogFormatJSON, slog.LevelDebug))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	return &buf, func() map[string]any {
+		var line map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+		buf.Reset()
+		return line
+	}
+}
+
+func TestRedact(t *testing.T) {
+	assert.Equal(t, "sent to m***@example.com", Redact("sent to mohammed@example.com"))
+	assert.Equal(t, "sent to ***66", Redact("sent to +966591434366"))
+	assert.Equal(t, "user 8f14e45f-ceea-467f-a2e5-3c2c1b1a2b3c", Redact("user 8f14e45f-ceea-467f-a2e5-3c2c1b1a2b3c"))
+}
+
+func TestRedact_MasksAcceptedMobileFormats(t *testing.T) {
+	assert.Equal(t, "***66 ***66 ***66", Redact("+966591434366 966591434366 0591434366"))
+	assert.Equal(t, "sent to ***89", Redact("sent to +447911123489"))
+}
+
+func TestRedact_KeepsOtherNumbers(t *testing.T) {
+	for _, s := range []string{
+		"order 4815162342 charged 2500000000 halalas",
+		"expires_at=1760000000 took 123456789012ns",
+		"receipt 210000123456789",
+		"promo 0123456789 and 96612345",
+	} {
+		assert.Equal(t, s, Redact(s))
+	}
+}
+
+func TestLogger_RedactsPersonalData(t *testing.T) {
+	_, next := newTestLogger(t)
+
+	slog.Info("OTP sent to mohammed@example.com", "otp", "1234", "mobile", "0591434366", "error", errors.New("invalid recipient mohammed@example.com"))
+
+	line := next()
+	assert.Equal(t, "OTP sent to m***@example.com", line["msg"])
+	assert.Equal(t, redactedValue, line["otp"])
+	assert.Equal(t, "***66", line["mobile"])
+	assert.Equal(t, "invalid recipient m***@example.com", line["error"])
+}
+
+func TestFromContext_AddsRequestFields(t *testing.T) {
+	_, next := newTestLogger(t)
+
+	ctx := WithRequest(context.Background(), "req-1", "GET", "/podcasts/:id")
+	SetUserID(ctx, "user-1")
+	FromContext(ctx).Warn("Something happened")
+
+	line := next()
+	assert.Equal(t, "req-1", line["request_id"])
+	assert.Equal(t, "GET", line["method"])
+	assert.Equal(t, "/podcasts/:id", line["route"])
+	assert.Equal(t, "user-1", line["user_id"])
+	assert.NotContains(t, line, "impersonator_id")
+
+	SetImpersonatorID(ctx, "admin-1")
+	FromContext(ctx).Warn("Something happened")
+	assert.Equal(t, "admin-1", next()["impersonator_id"])
+}
+
+func TestWith_KeepsRequestFieldsAndAddsArgs(t *testing.T) {
+	_, next := newTestLogger(t)
+
+	ctx := With(WithRequest(context.Background(), "req-1", "POST", "/jobs"), "job", "users.data_export")
+	slog.ErrorContext(ctx, "Job failed")
+
+	line := next()
+	assert.Equal(t, "req-1", line["request_id"])
+	assert.Equal(t, "users.data_export", line["job"])
+}
+
+func TestInit_RejectsUnknownLevel(t *testing.T) {
+	assert.Error(t, Init(config.LoggingConfig{Level: "loud"}, config.EnvironmentDevelopment))
+}
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+const redactedValue = "[REDACTED]"
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	// mobilePattern matches the formats mobiles are accepted in: international with a leading +,
+	// or Saudi with the 966 country code or a leading 0. Other digit runs, such as IDs, amounts
+	// and timestamps, are left alone.
+	mobilePattern = regexp.MustCompile(`(?:\+\d{8,15}|\b(?:966|0)5\d{8})\b`)
+)
+
+// secretKeys are attribute keys whose values are dropped entirely
+var secretKeys = map[string]bool{
+	"otp":           true,
+	"code":          true,
+	"password":      true,
+	"token":         true,
+	"access_token":  true,
+	"refresh_token": true,
+	"authorization": true,
+	"secret":        true,
+}
+
+// trustedKeys are identifiers added by the logger itself and never contain personal data
+var trustedKeys = map[string]bool{
+	slog.TimeKey:  true,
+	slog.LevelKey: true,
+	"request_id":  true,
+	"user_id":     true,
+	"trace_id":    true,
+}
+
+// Redact masks email addresses and phone numbers in s, keeping enough to tell values apart
+func Redact(s string) string {
+	s = emailPattern.ReplaceAllStringFunc(s, maskEmail)
+	return mobilePattern.ReplaceAllStringFunc(s, maskMobile)
+}
+
+func maskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	return email[:1] + "***" + email[at:]
+}
+
+func maskMobile(mobile string) string {
+	return "***" + mobile[len(mobile)-2:]
+}
+
+// redactAttr is the ReplaceAttr hook of every handler: it drops secrets by key and masks
+// personal data in the message and every string or error value
+func redactAttr(_ []string, attr slog.Attr) slog.Attr {
+	key := strings.ToLower(attr.Key)
+	if trustedKeys[key] {
+		return attr
+	}
+	if secretKeys[key] {
+		return slog.String(attr.Key, redactedValue)
+	}
+
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, Redact(attr.Value.String()))
+	case slog.KindAny:
+		if err, ok := attr.Value.Any().(error); ok {
+			return slog.String(attr.Key, Redact(err.Error()))
+		}
+	}
+	return attr
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const startedAtKey = "metrics:started_at"
+
+// GormPlugin times every query GORM runs and records it in DBQueryDuration
+type GormPlugin struct{}
+
+func (GormPlugin) Name() string {
+	return "metrics"
+}
+
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	return errors.Join(
+		callback.Create().Before("gorm:create").Register("metrics:before_create", start),
+		callback.Create().After("gorm:create").Register("metrics:after_create", observe("create")),
+		callback.Query().Before("gorm:query").Register("metrics:before_query", start),
+		callback.Query().After("gorm:query").Register("metrics:after_query", observe("query")),
+		callback.Update().Before("gorm:update").Register("metrics:before_update", start),
+		callback.Update().After("gorm:update").Register("metrics:after_update", observe("update")),
+		callback.Delete().Before("gorm:delete").Register("metrics:before_delete", start),
+		callback.Delete().After("gorm:delete").Register("metrics:after_delete", observe("delete")),
+		callback.Row().Before("gorm:row").Register("metrics:before_row", start),
+		callback.Row().After("gorm:row").Register("metrics:after_row", observe("row")),
+		callback.Raw().Before("gorm:raw").Register("metrics:before_raw", start),
+		callback.Raw().After("gorm:raw").Register("metrics:after_raw", observe("raw")),
+	)
+}
+
+func start(db *gorm.DB) {
+	db.InstanceSet(startedAtKey, time.Now())
+}
+
+func observe(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, found := db.InstanceGet(startedAtKey)
+		startedAt, ok := value.(time.Time)
+		if !found || !ok {
+			return
+		}
+
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		DBQueryDuration.WithLabelValues(operation, table).Observe(time.Since(startedAt).Seconds())
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			DBQueryErrors.WithLabelValues(operation, table).Inc()
+		}
+	}
+}
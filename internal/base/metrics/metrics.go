@@ -0,0 +1,124 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/jobs"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "khaimah"
+
+const (
+	CacheRedis  = "redis"
+	CacheMemory = "memory"
+
+	ResultHit   = "hit"
+	ResultMiss  = "miss"
+	ResultError = "error"
+
+	ProviderResend   = "resend"
+	ProviderWasender = "wasender"
+
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// Registry holds every collector exposed on /metrics. A dedicated registry keeps
+// collectors registered by dependencies off the endpoint.
+var Registry = prometheus.NewRegistry()
+
+var (
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Database query latency by operation and table.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	DBQueryErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_query_errors_total",
+		Help:      "Database queries that failed, by operation and table. Record-not-found is not an error.",
+	}, []string{"operation", "table"})
+
+	CacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Cache lookups by cache layer and result (hit, miss or error).",
+	}, []string{"cache", "result"})
+
+	OTPSends = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "otp_sends_total",
+		Help:      "OTP deliveries by provider and result.",
+	}, []string{"provider", "result"})
+
+	JobQueueDepth = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "job_queue_depth",
+		Help:      "Background jobs waiting to be picked up by a worker.",
+	}, func() float64 {
+		return float64(jobs.GetQueue().Pending())
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPRequestDuration,
+		DBQueryDuration,
+		DBQueryErrors,
+		CacheRequests,
+		OTPSends,
+		JobQueueDepth,
+	)
+}
+
+// Handler serves the registry in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveCacheLookup records the result of a cache read. missErrs are the errors the cache
+// returns for an absent key.
+func ObserveCacheLookup(cache string, err error, missErrs ...error) {
+	result := ResultHit
+	if err != nil {
+		result = ResultError
+		for _, missErr := range missErrs {
+			if errors.Is(err, missErr) {
+				result = ResultMiss
+				break
+			}
+		}
+	}
+	CacheRequests.WithLabelValues(cache, result).Inc()
+}
+
+// ObserveOTPSend records the outcome of an OTP delivery through provider
+func ObserveOTPSend(provider string, err error) {
+	result := ResultSuccess
+	if err != nil {
+		result = ResultFailure
+	}
+	OTPSends.WithLabelValues(provider, result).Inc()
+}
@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errNotFound = errors.New("not found")
+
+func TestObserveCacheLookup(t *testing.T) {
+	hits := testutil.ToFloat64(CacheRequests.WithLabelValues(CacheMemory, ResultHit))
+	misses := testutil.ToFloat64(CacheRequests.WithLabelValues(CacheMemory, ResultMiss))
+	failures := testutil.ToFloat64(CacheRequests.WithLabelValues(CacheMemory, ResultError))
+
+	ObserveCacheLookup(CacheMemory, nil, errNotFound)
+	ObserveCacheLookup(CacheMemory, errNotFound, errNotFound)
+	ObserveCacheLookup(CacheMemory, errors.New("connection reset"), errNotFound)
+
+	assert.Equal(t, hits+1, testutil.ToFloat64(CacheRequests.WithLabelValues(CacheMemory, ResultHit)))
+	assert.Equal(t, misses+1, testutil.ToFloat64(CacheRequests.WithLabelValues(CacheMemory, ResultMiss)))
+	assert.Equal(t, failures+1, testutil.ToFloat64(CacheRequests.WithLabelValues(CacheMemory, ResultError)))
+}
+
+func TestObserveOTPSend(t *testing.T) {
+	successes := testutil.ToFloat64(OTPSends.WithLabelValues(ProviderResend, ResultSuccess))
+	failures := testutil.ToFloat64(OTPSends.WithLabelValues(ProviderWasender, ResultFailure))
+
+	ObserveOTPSend(ProviderResend, nil)
+	ObserveOTPSend(ProviderWasender, errors.New("status 500"))
+
+	assert.Equal(t, successes+1, testutil.ToFloat64(OTPSends.WithLabelValues(ProviderResend, ResultSuccess)))
+	assert.Equal(t, failures+1, testutil.ToFloat64(OTPSends.WithLabelValues(ProviderWasender, ResultFailure)))
+}
+
+func TestHandler_ExposesCollectors(t *testing.T) {
+	ObserveOTPSend(ProviderResend, nil)
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, string(body), `khaimah_otp_sends_total{provider="resend",result="success"}`)
+	assert.Contains(t, string(body), "khaimah_job_queue_depth 0")
+}
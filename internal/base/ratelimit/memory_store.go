@@ -0,0 +1,151 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/cache"
+)
+
+// MemoryStore keeps counters in the in-memory cache. Limits only apply per instance,
+// so it is meant as a fallback when Redis is unavailable.
+type MemoryStore struct {
+	cache *cache.Cache
+	// mu makes each read-modify-write atomic, as the Lua scripts are in Redis
+	mu sync.Mutex
+}
+
+func NewMemoryStore(c *cache.Cache) *MemoryStore {
+	return &MemoryStore{cache: c}
+}
+
+func (s *MemoryStore) Allow(ctx context.Context, key string, policy Policy, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch policy.Algorithm {
+	case TokenBucket:
+		return s.tokenBucket(ctx, key, policy, now)
+	default:
+		return s.slidingWindow(ctx, key, policy, now)
+	}
+}
+
+func (s *MemoryStore) slidingWindow(ctx context.Context, key string, policy Policy, now time.Time) (Result, error) {
+	window, elapsed := windowStart(policy, now)
+	currentKey := fmt.Sprintf("%s:%d", key, window)
+	previous := s.count(ctx, fmt.Sprintf("%s:%d", key, window-1))
+	current := s.count(ctx, currentKey)
+
+	result := slidingWindowResult(policy, previous, current, elapsed)
+	if result.Allowed {
+		if err := s.cache.Set(ctx, currentKey, strconv.FormatInt(current+1, 10), 2*policy.Window); err != nil {
+			return Result{}, err
+		}
+	}
+	return result, nil
+}
+
+func (s *MemoryStore) tokenBucket(ctx context.Context, key string, policy Policy, now time.Time) (Result, error) {
+	tokens := float64(policy.Limit)
+	last := now
+	if raw, err := s.cache.Get(ctx, key); err == nil {
+		if storedTokens, storedAt, ok := parseBucket(raw); ok {
+			tokens, last = storedTokens, storedAt
+		}
+	}
+
+	tokens = refill(policy, tokens, now.Sub(last))
+	allowed := tokens >= 1
+	if allowed {
+		tokens--
+	}
+	if now.After(last) {
+		last = now
+	}
+
+	state := strconv.FormatFloat(tokens, 'f', -1, 64) + "|" + strconv.FormatInt(last.UnixMilli(), 10)
+	if err := s.cache.Set(ctx, key, state, policy.Window); err != nil {
+		return Result{}, err
+	}
+	return tokenBucketResult(policy, allowed, tokens), nil
+}
+
+func (s *MemoryStore) Increment(ctx context.Context, key string, window time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The expiry is stored alongside the count because cache.Set would otherwise restart the window
+	now := time.Now()
+	count, expiresAt := int64(0), now.Add(window)
+	if raw, err := s.cache.Get(ctx, key); err == nil {
+		if storedCount, storedExpiry, ok := parseCounter(raw); ok {
+			count, expiresAt = storedCount, storedExpiry
+		}
+	}
+	count++
+
+	state := strconv.FormatInt(count, 10) + "|" + strconv.FormatInt(expiresAt.UnixMilli(), 10)
+	if err := s.cache.Set(ctx, key, state, expiresAt.Sub(now)); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (s *MemoryStore) Count(ctx context.Context, key string) (int64, error) {
+	raw, err := s.cache.Get(ctx, key)
+	if err != nil {
+		return 0, nil
+	}
+	count, _, _ := parseCounter(raw)
+	return count, nil
+}
+
+func (s *MemoryStore) Reset(ctx context.Context, key string) error {
+	return s.cache.Delete(ctx, key)
+}
+
+func (s *MemoryStore) count(ctx context.Context, key string) int64 {
+	raw, err := s.cache.Get(ctx, key)
+	if err != nil {
+		return 0
+	}
+	count, _ := strconv.ParseInt(raw, 10, 64)
+	return count
+}
+
+func parseBucket(raw string) (float64, time.Time, bool) {
+	tokensPart, atPart, found := strings.Cut(raw, "|")
+	if !found {
+		return 0, time.Time{}, false
+	}
+	tokens, err := strconv.ParseFloat(tokensPart, 64)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+	at, err := strconv.ParseInt(atPart, 10, 64)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+	return tokens, time.UnixMilli(at), true
+}
+
+func parseCounter(raw string) (int64, time.Time, bool) {
+	countPart, expiryPart, found := strings.Cut(raw, "|")
+	if !found {
+		return 0, time.Time{}, false
+	}
+	count, err := strconv.ParseInt(countPart, 10, 64)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+	expiry, err := strconv.ParseInt(expiryPart, 10, 64)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+	return count, time.UnixMilli(expiry), true
+}
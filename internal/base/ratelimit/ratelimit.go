@@ -0,0 +1,276 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/cache"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/redis"
+	"github.com/labstack/echo/v4"
+)
+
+// Algorithm decides how requests are counted against a policy's limit
+type Algorithm string
+
+const (
+	// SlidingWindow weights the previous window's count by how much of it still overlaps the
+	// current one, so bursts at a window boundary cannot double the limit
+	SlidingWindow Algorithm = "sliding_window"
+	// TokenBucket refills Limit tokens evenly over Window and spends one per request
+	TokenBucket Algorithm = "token_bucket"
+)
+
+const keyPrefix = "ratelimit"
+
+// KeyFunc returns the identity a request is counted under. An empty key skips limiting.
+type KeyFunc func(c echo.Context) string
+
+// IPExtractor is the server's echo.IPExtractor, which ByIP relies on to tell clients apart. Any
+// client can send X-Forwarded-For, so it is only read on requests from the trusted proxies;
+// without proxies the peer address is used.
+func IPExtractor(trustedProxies []*net.IPNet) echo.IPExtractor {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, network := range trustedProxies {
+		options = append(options, echo.TrustIPRange(network))
+	}
+	return echo.ExtractIPFromXFFHeader(options...)
+}
+
+// ByIP counts requests per client IP
+func ByIP(c echo.Context) string {
+	return "ip:" + c.RealIP()
+}
+
+// ByUserID counts requests per authenticated user, falling back to the client IP
+// when the route runs before authentication
+func ByUserID(c echo.Context) string {
+	if userID, ok := c.Get("user_id").(string); ok && userID != "" {
+		return "user:" + userID
+	}
+	return ByIP(c)
+}
+
+// ByParam counts requests per value of the named path parameter and client IP
+func ByParam(name string) KeyFunc {
+	return func(c echo.Context) string {
+		return name + ":" + c.Param(name) + ":" + ByIP(c)
+	}
+}
+
+// Policy allows Limit requests per Window for every key. Name keeps the counters of
+// different policies apart, so two policies may share a key function.
+type Policy struct {
+	Name      string
+	Limit     int
+	Window    time.Duration
+	Algorithm Algorithm
+	Key       KeyFunc
+}
+
+// String formats the policy for the RateLimit-Policy header
+func (p Policy) String() string {
+	return fmt.Sprintf("%d;w=%d", p.Limit, int(math.Ceil(p.Window.Seconds())))
+}
+
+// Result is the outcome of counting one request
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// ResetAfter is how long until the current window rolls over or the bucket is full again
+	ResetAfter time.Duration
+	// RetryAfter is how long a rejected client should wait before the next request can succeed
+	RetryAfter time.Duration
+}
+
+// Store counts a request for key under policy at now
+type Store interface {
+	Allow(ctx context.Context, key string, policy Policy, now time.Time) (Result, error)
+}
+
+// CounterStore counts events the caller reports, such as failed logins, per key within a
+// fixed window that starts with the first event
+type CounterStore interface {
+	Increment(ctx context.Context, key string, window time.Duration) (int64, error)
+	Count(ctx context.Context, key string) (int64, error)
+	Reset(ctx context.Context, key string) error
+}
+
+type backend interface {
+	Store
+	CounterStore
+}
+
+// Counter names a set of event counts kept for Window after the first event
+type Counter struct {
+	Name   string
+	Window time.Duration
+}
+
+// Limiter counts requests in Redis and falls back to the in-memory cache when Redis is not
+// configured or fails, so an outage degrades limits to per-instance instead of disabling them.
+type Limiter struct {
+	redis    func() backend
+	memory   backend
+	degraded atomic.Bool
+	clock    func() time.Time
+}
+
+// NewLimiter creates a limiter backed by the shared Redis client and the global cache
+func NewLimiter() *Limiter {
+	return &Limiter{
+		redis: func() backend {
+			if client := redis.GetRedisClient(); client != nil {
+				return NewRedisStore(client)
+			}
+			return nil
+		},
+		memory: NewMemoryStore(cache.GetCache()),
+		clock:  time.Now,
+	}
+}
+
+// Allow counts one request for key under policy
+func (l *Limiter) Allow(ctx context.Context, policy Policy, key string) Result {
+	now := l.clock()
+	key = fmt.Sprintf("%s:%s:%s", keyPrefix, policy.Name, key)
+
+	if store := l.redis(); store != nil {
+		result, err := store.Allow(ctx, key, policy, now)
+		l.reportRedis(err)
+		if err == nil {
+			return result
+		}
+	}
+
+	result, _ := l.memory.Allow(ctx, key, policy, now)
+	return result
+}
+
+// Increment records one event for key and returns the number recorded in the current window
+func (l *Limiter) Increment(ctx context.Context, counter Counter, key string) int64 {
+	key = fmt.Sprintf("%s:%s:%s", keyPrefix, counter.Name, key)
+
+	if store := l.redis(); store != nil {
+		count, err := store.Increment(ctx, key, counter.Window)
+		l.reportRedis(err)
+		if err == nil {
+			return count
+		}
+	}
+
+	count, _ := l.memory.Increment(ctx, key, counter.Window)
+	return count
+}
+
+// Count returns the number of events recorded for key in the current window
+func (l *Limiter) Count(ctx context.Context, counter Counter, key string) int64 {
+	key = fmt.Sprintf("%s:%s:%s", keyPrefix, counter.Name, key)
+
+	if store := l.redis(); store != nil {
+		count, err := store.Count(ctx, key)
+		l.reportRedis(err)
+		if err == nil {
+			return count
+		}
+	}
+
+	count, _ := l.memory.Count(ctx, key)
+	return count
+}
+
+// Reset forgets the events recorded for key
+func (l *Limiter) Reset(ctx context.Context, counter Counter, key string) {
+	key = fmt.Sprintf("%s:%s:%s", keyPrefix, counter.Name, key)
+
+	if store := l.redis(); store != nil {
+		l.reportRedis(store.Reset(ctx, key))
+	}
+	_ = l.memory.Reset(ctx, key)
+}
+
+// reportRedis logs when Redis starts failing and when it recovers, rather than on every request
+func (l *Limiter) reportRedis(err error) {
+	if err == nil {
+		if l.degraded.Swap(false) {
+			slog.Info("Rate limiting is using Redis again")
+		}
+		return
+	}
+	if !l.degraded.Swap(true) {
+		slog.Warn("Rate limiting fell back to the in-memory store", "error", err)
+	}
+}
+
+var defaultLimiter = NewLimiter()
+
+// Default returns the process-wide limiter
+func Default() *Limiter {
+	return defaultLimiter
+}
+
+// slidingWindowResult decides a sliding window request from the previous and current
+// window counts, taken before this request is counted
+func slidingWindowResult(policy Policy, previous, current int64, elapsed time.Duration) Result {
+	weight := float64(policy.Window-elapsed) / float64(policy.Window)
+	estimate := float64(previous)*weight + float64(current)
+	limit := float64(policy.Limit)
+
+	result := Result{Limit: policy.Limit, ResetAfter: policy.Window - elapsed}
+	if estimate+1 <= limit {
+		result.Allowed = true
+		result.Remaining = int(math.Floor(limit - estimate - 1))
+		return result
+	}
+
+	// Wait until the previous window has slid far enough out for one more request,
+	// or until the next window when the current one alone is full
+	result.RetryAfter = policy.Window - elapsed
+	if previous > 0 && float64(current)+1 <= limit {
+		needed := 1 - (limit-1-float64(current))/float64(previous)
+		result.RetryAfter = time.Duration(needed*float64(policy.Window)) - elapsed
+	}
+	if result.RetryAfter < 0 {
+		result.RetryAfter = 0
+	}
+	return result
+}
+
+// tokenBucketResult describes a token bucket after a request was counted
+func tokenBucketResult(policy Policy, allowed bool, tokens float64) Result {
+	perToken := float64(policy.Window) / float64(policy.Limit)
+
+	result := Result{
+		Allowed:    allowed,
+		Limit:      policy.Limit,
+		Remaining:  int(math.Floor(tokens)),
+		ResetAfter: time.Duration((float64(policy.Limit) - tokens) * perToken),
+	}
+	if !allowed {
+		result.RetryAfter = time.Duration((1 - tokens) * perToken)
+	}
+	return result
+}
+
+// refill returns the tokens in a bucket after elapsed time, capped at the policy limit
+func refill(policy Policy, tokens float64, elapsed time.Duration) float64 {
+	if elapsed > 0 {
+		tokens += float64(elapsed) * float64(policy.Limit) / float64(policy.Window)
+	}
+	return math.Min(tokens, float64(policy.Limit))
+}
+
+// windowStart returns the start of the fixed window containing now and how far into it now is
+func windowStart(policy Policy, now time.Time) (int64, time.Duration) {
+	window := policy.Window.Milliseconds()
+	ms := now.UnixMilli()
+	return ms / window, time.Duration(ms%window) * time.Millisecond
+}
@@ -0,0 +1,183 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/cache"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var epoch = time.UnixMilli(0).Add(1000 * time.Hour)
+
+func TestMemoryStore_SlidingWindow(t *testing.T) {
+	store := NewMemoryStore(cache.NewCache())
+	policy := Policy{Name: "test", Limit: 3, Window: time.Minute, Algorithm: SlidingWindow}
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		result, err := store.Allow(ctx, "k", policy, epoch)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 2-i, result.Remaining)
+	}
+
+	result, err := store.Allow(ctx, "k", policy, epoch.Add(10*time.Second))
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 50*time.Second, result.RetryAfter)
+
+	// Half way through the next window, half of the previous count still applies
+	result, err = store.Allow(ctx, "k", policy, epoch.Add(90*time.Second))
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	result, err = store.Allow(ctx, "k", policy, epoch.Add(90*time.Second))
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 10*time.Second, result.RetryAfter)
+}
+
+func TestMemoryStore_TokenBucket(t *testing.T) {
+	store := NewMemoryStore(cache.NewCache())
+	policy := Policy{Name: "test", Limit: 2, Window: 10 * time.Second, Algorithm: TokenBucket}
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		result, err := store.Allow(ctx, "k", policy, epoch)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+	}
+
+	result, err := store.Allow(ctx, "k", policy, epoch)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	assert.Equal(t, 5*time.Second, result.RetryAfter)
+	assert.Equal(t, 10*time.Second, result.ResetAfter)
+
+	// One token refills every five seconds
+	result, err = store.Allow(ctx, "k", policy, epoch.Add(5*time.Second))
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+}
+
+func TestMemoryStore_KeysAreIndependent(t *testing.T) {
+	store := NewMemoryStore(cache.NewCache())
+	policy := Policy{Name: "test", Limit: 1, Window: time.Minute}
+	ctx := context.Background()
+
+	first, _ := store.Allow(ctx, "a", policy, epoch)
+	second, _ := store.Allow(ctx, "b", policy, epoch)
+
+	assert.True(t, first.Allowed)
+	assert.True(t, second.Allowed)
+}
+
+type failingStore struct{}
+
+func (failingStore) Allow(context.Context, string, Policy, time.Time) (Result, error) {
+	return Result{}, errors.New("connection refused")
+}
+
+func (failingStore) Increment(context.Context, string, time.Duration) (int64, error) {
+	return 0, errors.New("connection refused")
+}
+
+func (failingStore) Count(context.Context, string) (int64, error) {
+	return 0, errors.New("connection refused")
+}
+
+func (failingStore) Reset(context.Context, string) error {
+	return errors.New("connection refused")
+}
+
+func TestLimiter_FallsBackToMemory(t *testing.T) {
+	limiter := &Limiter{
+		redis:  func() backend { return failingStore{} },
+		memory: NewMemoryStore(cache.NewCache()),
+		clock:  func() time.Time { return epoch },
+	}
+	policy := Policy{Name: "test", Limit: 1, Window: time.Minute}
+
+	assert.True(t, limiter.Allow(context.Background(), policy, "k").Allowed)
+	assert.False(t, limiter.Allow(context.Background(), policy, "k").Allowed)
+	assert.True(t, limiter.degraded.Load())
+}
+
+func TestLimiter_CountsEventsInMemory(t *testing.T) {
+	limiter := &Limiter{
+		redis:  func() backend { return failingStore{} },
+		memory: NewMemoryStore(cache.NewCache()),
+		clock:  time.Now,
+	}
+	counter := Counter{Name: "failures", Window: time.Minute}
+	ctx := context.Background()
+
+	assert.Equal(t, int64(1), limiter.Increment(ctx, counter, "k"))
+	assert.Equal(t, int64(2), limiter.Increment(ctx, counter, "k"))
+	assert.Equal(t, int64(2), limiter.Count(ctx, counter, "k"))
+	assert.Equal(t, int64(0), limiter.Count(ctx, counter, "other"))
+
+	limiter.Reset(ctx, counter, "k")
+	assert.Equal(t, int64(0), limiter.Count(ctx, counter, "k"))
+}
+
+func TestByUserID_FallsBackToIP(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set(echo.HeaderXRealIP, "203.0.113.7")
+	c := e.NewContext(req, httptest.NewRecorder())
+
+	assert.Equal(t, "ip:203.0.113.7", ByUserID(c))
+
+	c.Set("user_id", "42")
+	assert.Equal(t, "user:42", ByUserID(c))
+}
+
+func TestByIP_IgnoresForgedForwardingHeaders(t *testing.T) {
+	_, proxies, err := net.ParseCIDR("10.0.0.0/8")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		trustedProxies []*net.IPNet
+		remoteAddr     string
+		forwardedFor   string
+		key            string
+	}{
+		{"direct client", nil, "203.0.113.7:4242", "", "ip:203.0.113.7"},
+		{"direct client forging headers", nil, "203.0.113.7:4242", "198.51.100.1", "ip:203.0.113.7"},
+		{"client behind proxy", []*net.IPNet{proxies}, "10.0.0.5:4242", "203.0.113.7", "ip:203.0.113.7"},
+		{"client behind proxy forging headers", []*net.IPNet{proxies}, "10.0.0.5:4242", "198.51.100.1, 203.0.113.7", "ip:203.0.113.7"},
+		{"untrusted peer forging headers", []*net.IPNet{proxies}, "203.0.113.7:4242", "198.51.100.1", "ip:203.0.113.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.IPExtractor = IPExtractor(tt.trustedProxies)
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwardedFor != "" {
+				req.Header.Set(echo.HeaderXForwardedFor, tt.forwardedFor)
+				req.Header.Set(echo.HeaderXRealIP, "198.51.100.2")
+			}
+			c := e.NewContext(req, httptest.NewRecorder())
+
+			assert.Equal(t, tt.key, ByIP(c))
+		})
+	}
+}
+
+func TestPolicy_String(t *testing.T) {
+	assert.Equal(t, "5;w=600", Policy{Limit: 5, Window: 10 * time.Minute}.String())
+}
@@ -0,0 +1,145 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// slidingWindowScript counts a request in the current window unless the weighted total of the
+// previous and current windows has reached the limit. It returns whether the request was
+// allowed and both counts from before it was counted.
+var slidingWindowScript = goredis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local elapsed = tonumber(ARGV[3])
+local previous = tonumber(redis.call('GET', KEYS[2]) or '0')
+local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+if previous * (window - elapsed) / window + current + 1 > limit then
+	return {0, previous, current}
+end
+redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], window * 2)
+return {1, previous, current}
+`)
+
+// tokenBucketScript refills the bucket for the time since it was last used and spends one
+// token when there is one. Tokens are returned as a string because Lua numbers are
+// truncated to integers on the way out.
+var tokenBucketScript = goredis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or limit
+local ts = tonumber(state[2]) or now
+if now > ts then
+	tokens = math.min(limit, tokens + (now - ts) * limit / window)
+end
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', math.max(now, ts))
+redis.call('PEXPIRE', KEYS[1], window)
+return {allowed, tostring(tokens)}
+`)
+
+// incrementScript starts the window on the first event so later events do not extend it
+var incrementScript = goredis.NewScript(`
+local count = redis.call('INCR', KEYS[1])
+if count == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return count
+`)
+
+// RedisStore keeps counters in Redis so every instance shares the same limits
+type RedisStore struct {
+	client goredis.Cmdable
+}
+
+func NewRedisStore(client goredis.Cmdable) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+func (s *RedisStore) Allow(ctx context.Context, key string, policy Policy, now time.Time) (Result, error) {
+	switch policy.Algorithm {
+	case TokenBucket:
+		return s.tokenBucket(ctx, key, policy, now)
+	default:
+		return s.slidingWindow(ctx, key, policy, now)
+	}
+}
+
+func (s *RedisStore) slidingWindow(ctx context.Context, key string, policy Policy, now time.Time) (Result, error) {
+	window, elapsed := windowStart(policy, now)
+	keys := []string{
+		fmt.Sprintf("%s:%d", key, window),
+		fmt.Sprintf("%s:%d", key, window-1),
+	}
+
+	values, err := slidingWindowScript.Run(ctx, s.client, keys,
+		policy.Limit, policy.Window.Milliseconds(), elapsed.Milliseconds()).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to run sliding window script: %w", err)
+	}
+	if len(values) != 3 {
+		return Result{}, fmt.Errorf("unexpected sliding window reply: %v", values)
+	}
+
+	result := slidingWindowResult(policy, values[1], values[2], elapsed)
+	result.Allowed = values[0] == 1
+	return result, nil
+}
+
+func (s *RedisStore) tokenBucket(ctx context.Context, key string, policy Policy, now time.Time) (Result, error) {
+	values, err := tokenBucketScript.Run(ctx, s.client, []string{key},
+		policy.Limit, policy.Window.Milliseconds(), now.UnixMilli()).Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to run token bucket script: %w", err)
+	}
+	if len(values) != 2 {
+		return Result{}, fmt.Errorf("unexpected token bucket reply: %v", values)
+	}
+
+	allowed, _ := values[0].(int64)
+	raw, _ := values[1].(string)
+	tokens, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to parse token bucket tokens: %w", err)
+	}
+
+	return tokenBucketResult(policy, allowed == 1, tokens), nil
+}
+
+func (s *RedisStore) Increment(ctx context.Context, key string, window time.Duration) (int64, error) {
+	count, err := incrementScript.Run(ctx, s.client, []string{key}, window.Milliseconds()).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment counter: %w", err)
+	}
+	return count, nil
+}
+
+func (s *RedisStore) Count(ctx context.Context, key string) (int64, error) {
+	count, err := s.client.Get(ctx, key).Int64()
+	if errors.Is(err, goredis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read counter: %w", err)
+	}
+	return count, nil
+}
+
+func (s *RedisStore) Reset(ctx context.Context, key string) error {
+	if err := s.client.Del(ctx, key).Err(); err != nil {
+		return fmt.Errorf("failed to reset counter: %w", err)
+	}
+	return nil
+}
@@ -2,10 +2,13 @@ package redis
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/Al-Khaimah/khaimah-golang-backend/config"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/metrics"
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -14,17 +17,18 @@ var (
 )
 
 // InitRedis initializes the Redis client
-func InitRedis() error {
-	redisAddr := config.GetEnv("REDIS_ADDR", "localhost:6379")
-	redisPassword := config.GetEnv("REDIS_PASSWORD", "")
-	redisDB := 0
-
+func InitRedis(cfg config.RedisConfig) error {
 	redisClient = redis.NewClient(&redis.Options{
-		Addr:     redisAddr,
-		Password: redisPassword,
-		DB:       redisDB,
+		Addr:     cfg.Addr,
+		Password: cfg.Password,
+		DB:       cfg.DB,
 	})
 
+	// Statements are left out of spans because keys and values carry OTPs and user identifiers
+	if err := redisotel.InstrumentTracing(redisClient, redisotel.WithDBStatement(false)); err != nil {
+		return fmt.Errorf("failed to instrument Redis tracing: %w", err)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -48,10 +52,54 @@ func SetWithTTL(ctx context.Context, key string, value interface{}, ttl time.Dur
 
 // Get retrieves a value by key
 func Get(ctx context.Context, key string) (string, error) {
-	return redisClient.Get(ctx, key).Result()
+	value, err := redisClient.Get(ctx, key).Result()
+	metrics.ObserveCacheLookup(metrics.CacheRedis, err, redis.Nil)
+	return value, err
 }
 
 // Delete removes a key
 func Delete(ctx context.Context, key string) error {
 	return redisClient.Del(ctx, key).Err()
 }
+
+// DeleteMatching removes every key matching the glob pattern, scanning rather than blocking Redis
+// with KEYS. It does nothing when Redis is not configured.
+func DeleteMatching(ctx context.Context, pattern string) error {
+	if redisClient == nil {
+		return nil
+	}
+
+	iter := redisClient.Scan(ctx, 0, pattern, 100).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return redisClient.Del(ctx, keys...).Err()
+}
+
+// FlushDB removes every key in the current database
+func FlushDB(ctx context.Context) error {
+	return redisClient.FlushDB(ctx).Err()
+}
+
+// Ping checks that Redis is reachable
+func Ping(ctx context.Context) error {
+	if redisClient == nil {
+		return errors.New("redis client is not initialized")
+	}
+	return redisClient.Ping(ctx).Err()
+}
+
+// Close closes the Redis client, if it was initialized
+func Close() error {
+	if redisClient == nil {
+		return nil
+	}
+	return redisClient.Close()
+}
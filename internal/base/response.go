@@ -1,11 +1,7 @@
 package base
 
 import (
-	"log"
 	"net/http"
-	"strconv"
-
-	"github.com/labstack/echo/v4"
 )
 
 type Response struct {
@@ -29,10 +25,6 @@ func newResponse(httpStatus int, messageType, title string, data interface{}, er
 		desc = description[0]
 	}
 
-	if messageType == ErrorStatus || errors != nil {
-		log.Printf("❌ ERROR: %s - %v", title, errors)
-	}
-
 	return Response{
 		HTTPStatus:         httpStatus,
 		MessageType:        messageType,
@@ -64,43 +56,6 @@ func SetWarningMessage(title string, description ...string) Response {
 	return newResponse(http.StatusConflict, WarningStatus, title, nil, nil, description...)
 }
 
-// @NOTE: Preferably use SetPaginatedResponse instead since it uses post-db retrieval pagination.
-func SetDataPaginated(c echo.Context, data []interface{}) Response {
-	page, err := strconv.Atoi(c.QueryParam("page"))
-	if err != nil || page < 1 {
-		page = 1
-	}
-
-	perPage, err := strconv.Atoi(c.QueryParam("perPage"))
-	if err != nil || perPage < 1 {
-		perPage = 10
-	}
-
-	totalRecords := len(data)
-	totalPages := (totalRecords + perPage - 1) / perPage
-	startIndex := (page - 1) * perPage
-	endIndex := startIndex + perPage
-
-	if startIndex > totalRecords {
-		startIndex = totalRecords
-	}
-	if endIndex > totalRecords {
-		endIndex = totalRecords
-	}
-
-	paginatedData := data[startIndex:endIndex]
-
-	responseData := map[string]interface{}{
-		"data":          paginatedData,
-		"total_records": totalRecords,
-		"total_pages":   totalPages,
-		"current_page":  page,
-		"page_size":     perPage,
-	}
-
-	return newResponse(http.StatusOK, SuccessStatus, "Success", responseData, nil)
-}
-
 func SetPaginatedResponse(data []interface{}, page, perPage, totalCount int) Response {
 	totalPages := (totalCount + perPage - 1) / perPage
 
@@ -0,0 +1,67 @@
+package totp
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret is the SHA-1 key from RFC 6238 appendix B, base32 encoded
+var rfcSecret = encoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCode_RFC6238Vectors(t *testing.T) {
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for unix, want := range vectors {
+		code, err := Code(rfcSecret, Step(time.Unix(unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, want, code, "time %d", unix)
+	}
+}
+
+func TestValidate_AcceptsAdjacentSteps(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	previous, err := Code(rfcSecret, Step(now)-1)
+	require.NoError(t, err)
+
+	step, ok := Validate(rfcSecret, previous, now)
+
+	assert.True(t, ok)
+	assert.Equal(t, Step(now)-1, step)
+}
+
+func TestValidate_RejectsOldAndMalformedCodes(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	old, err := Code(rfcSecret, Step(now)-3)
+	require.NoError(t, err)
+
+	_, ok := Validate(rfcSecret, old, now)
+	assert.False(t, ok)
+
+	_, ok = Validate(rfcSecret, "12345", now)
+	assert.False(t, ok)
+}
+
+func TestURI(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+
+	uri, err := url.Parse(URI("Khaimah", "user@example.com", secret))
+	require.NoError(t, err)
+
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/Khaimah:user@example.com", uri.Path)
+	assert.Equal(t, secret, uri.Query().Get("secret"))
+	assert.Equal(t, "Khaimah", uri.Query().Get("issuer"))
+}
@@ -12,7 +12,9 @@ import (
     "strings"
 
 	"github.com/Al-Khaimah/khaimah-golang-backend/config"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/metrics"
 	redisClient "github.com/Al-Khaimah/khaimah-golang-backend/internal/base/redis"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/tracing"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -56,9 +58,11 @@ func DeleteOTP(ctx context.Context, identifier string) error {
 }
 
 // SendEmailOTP sends an OTP via email using Resend API
-func SendEmailOTP(email, otp string, firstName ...string) error {
-	apiKey := config.GetEnv("RESEND_API_KEY")
-	senderEmail := config.GetEnv("RESEND_SENDER_EMAIL")
+func SendEmailOTP(ctx context.Context, cfg config.MessagingConfig, email, otp string, firstName ...string) (err error) {
+	defer func() { metrics.ObserveOTPSend(metrics.ProviderResend, err) }()
+
+	apiKey := cfg.ResendAPIKey
+	senderEmail := cfg.ResendSenderEmail
 	senderName := "الخيمة"
 	endpoint := "https://api.resend.com/emails"
 
@@ -276,7 +280,7 @@ func SendEmailOTP(email, otp string, firstName ...string) error {
 		return err
 	}
 
-	req, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(body))
 	if err != nil {
 		return err
 	}
@@ -284,7 +288,7 @@ func SendEmailOTP(email, otp string, firstName ...string) error {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+apiKey)
 
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := tracing.NewHTTPClient(10 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		return err
@@ -299,10 +303,12 @@ func SendEmailOTP(email, otp string, firstName ...string) error {
 }
 
 // SendMobileOTP sends an OTP via WhatsApp using WasenderAPI
-func SendMobileOTP(mobile, otp string, firstName ...string) error {
+func SendMobileOTP(ctx context.Context, cfg config.MessagingConfig, mobile, otp string, firstName ...string) (err error) {
+	defer func() { metrics.ObserveOTPSend(metrics.ProviderWasender, err) }()
+
 	formattedMobile := FormatMobileNumber(mobile)
 
-	apiToken := config.GetEnv("WASENDER_API_TOKEN")
+	apiToken := cfg.WasenderAPIToken
 	endpoint := "https://wasenderapi.com/api/send-message"
 
 	name := ""
@@ -348,14 +354,14 @@ func SendMobileOTP(mobile, otp string, firstName ...string) error {
 		return err
 	}
 
-	req, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(body))
 	if err != nil {
 		return err
 	}
 	req.Header.Set("Authorization", "Bearer "+apiToken)
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := tracing.NewHTTPClient(10 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		return err
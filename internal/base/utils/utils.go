@@ -2,49 +2,23 @@ package utils
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
-	"os"
 	"strings"
+	"time"
 
 	"github.com/Al-Khaimah/khaimah-golang-backend/config"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/tracing"
 	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/categories/models"
 	categories "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/categories/repositories"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
-func ExtractUserIDFromToken(tokenString string) (uuid.UUID, error) {
-	tokenString = strings.TrimPrefix(tokenString, "Bearer ")
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return []byte(os.Getenv("JWT_SECRET")), nil
-	})
-	if err != nil {
-		return uuid.Nil, fmt.Errorf("token parsing failed: %w", err)
-	}
-
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok || !token.Valid {
-		return uuid.Nil, errors.New("invalid token claims")
-	}
-
-	userIDStr, ok := claims["user_id"].(string)
-	if !ok {
-		return uuid.Nil, errors.New("user_id not found in token")
-	}
-
-	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
-		return uuid.Nil, errors.New("invalid user ID in token")
-	}
-
-	return userID, nil
-}
-
 func FormatEmail(email string) string {
 	return strings.ToLower(strings.TrimSpace(email))
 }
@@ -71,6 +45,19 @@ func FormatMobileNumber(mobile string) string {
 	return mobile
 }
 
+// SpreadsheetSafe stops values a client controls, such as a user agent or a name, from being run
+// as formulas when a CSV export is opened in a spreadsheet
+func SpreadsheetSafe(value string) string {
+	if value == "" {
+		return value
+	}
+	switch value[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + value
+	}
+	return value
+}
+
 func ConvertCategoriesToStringIDs(categories []models.Category) []string {
 	categoryIDs := make([]string, len(categories))
 	for i, category := range categories {
@@ -85,18 +72,18 @@ func ConvertIDsToCategories(categoryIDs []string) []models.Category {
 	for _, id := range categoryIDs {
 		uid, err := uuid.Parse(id)
 		if err != nil {
-			fmt.Printf("Warning: Invalid category ID format: %s\n", id)
+			slog.Warn("Invalid category ID format", "category_id", id)
 			continue
 		}
 
 		categoryRepo := categories.NewCategoryRepository(config.GetDB())
 		category, err := categoryRepo.FindCategoryByID(uid)
 		if err != nil {
-			fmt.Printf("Warning: Error finding category with ID %s: %v\n", id, err)
+			slog.Warn("Failed to find category", "category_id", id, "error", err)
 			continue
 		}
 		if category == nil {
-			fmt.Printf("Warning: Category with ID %s not found\n", id)
+			slog.Warn("Category not found", "category_id", id)
 			continue
 		}
 
@@ -106,8 +93,7 @@ func ConvertIDsToCategories(categoryIDs []string) []models.Category {
 	return categoryList
 }
 
-func SendSlackNotification(message string) error {
-	webhookURL := os.Getenv("SLACK_WEBHOOK_URL")
+func SendSlackNotification(ctx context.Context, webhookURL, message string) error {
 	if webhookURL == "" {
 		return fmt.Errorf("SLACK_WEBHOOK_URL environment variable not set")
 	}
@@ -118,13 +104,13 @@ func SendSlackNotification(message string) error {
 		return fmt.Errorf("failed to marshal Slack payload: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", webhookURL, bytes.NewBuffer(payloadBytes))
+	req, err := http.NewRequestWithContext(ctx, "POST", webhookURL, bytes.NewBuffer(payloadBytes))
 	if err != nil {
 		return fmt.Errorf("failed to create Slack HTTP request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
+	client := tracing.NewHTTPClient(10 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to send Slack notification request: %w", err)
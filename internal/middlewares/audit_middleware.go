@@ -0,0 +1,18 @@
+package middlewares
+
+import (
+	auditService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/audit/services"
+	"github.com/labstack/echo/v4"
+)
+
+// AuditContextMiddleware binds the caller's IP, user agent and request ID to the request context
+// for audit events. The auth middlewares add the actor once the token is verified.
+func AuditContextMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := auditService.WithRequest(c.Request().Context(), c.RealIP(), c.Request().UserAgent(), c.Response().Header().Get(echo.HeaderXRequestID))
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
@@ -3,17 +3,21 @@ package middlewares
 import (
 	"net/http"
 	"strings"
-
-	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/utils"
-	users "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/enums"
+	"time"
 
 	"github.com/Al-Khaimah/khaimah-golang-backend/config"
 	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/jwtkeys"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/logger"
+	auditService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/audit/services"
+	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/models"
 	repos "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/repositories"
+	userService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/services"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
-func AuthMiddleware(authRepo *repos.AuthRepository) echo.MiddlewareFunc {
+func AuthMiddleware(authRepo *repos.AuthRepository, keys *jwtkeys.Manager) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			authHeader := c.Request().Header.Get("Authorization")
@@ -22,36 +26,65 @@ func AuthMiddleware(authRepo *repos.AuthRepository) echo.MiddlewareFunc {
 			}
 
 			token := strings.TrimPrefix(authHeader, "Bearer ")
-			jwtSecret := config.GetEnv("JWT_SECRET", "alkhaimah123")
-			if jwtSecret == "" {
-				return c.JSON(http.StatusInternalServerError, base.SetErrorMessage("Server Error", "JWT secret is missing"))
-			}
-
-			userID, err := utils.ExtractUserIDFromToken(token)
+			claims, err := keys.Parse(token)
 			if err != nil {
 				return c.JSON(http.StatusUnauthorized, base.SetErrorMessage("Unauthorized", "Invalid token"))
 			}
 
-			authRecord, err := authRepo.FindAuthByUserID(userID)
-			if err != nil {
-				return c.JSON(http.StatusUnauthorized, base.SetErrorMessage("Unauthorized", "User authentication not found"))
-			}
-			if !authRecord.IsActive {
-				return c.JSON(http.StatusUnauthorized, base.SetErrorMessage("Unauthorized", "User is logged out"))
+			userID := claims.UserID
+			impersonated := claims.ImpersonatorID != nil
+			if impersonated {
+				if status, response, ok := checkImpersonation(c, claims); !ok {
+					return c.JSON(status, response)
+				}
 			}
 
-			userRepo := repos.NewUserRepository(config.GetDB())
-			user, _ := userRepo.FindOneByID(userID)
-
-			if user == nil {
-				return c.JSON(http.StatusUnauthorized, base.SetErrorMessage("Unauthorized", "User is deleted"))
+			user, response, ok := checkAccount(authRepo, repos.NewUserRepository(config.GetDB()), userID, impersonated)
+			if !ok {
+				return c.JSON(response.HTTPStatus, response)
 			}
 
-			isAdmin := user.UserType == users.UserTypeAdmin
+			isAdmin := len(user.Roles) > 0
 
 			c.Set("is_admin", isAdmin)
 			c.Set("user_id", userID.String())
+			logger.SetUserID(c.Request().Context(), userID.String())
+			if impersonated {
+				return serveImpersonated(c, next, claims)
+			}
+			auditService.SetActor(c.Request().Context(), userID)
 			return next(c)
 		}
 	}
 }
+
+// checkAccount loads the user a token was issued to and refuses accounts that are signed out,
+// deleted, pending deletion, banned or suspended. Impersonation does not depend on the user
+// being signed in.
+func checkAccount(authRepo *repos.AuthRepository, userRepo *repos.UserRepository, userID uuid.UUID, impersonated bool) (*models.User, base.Response, bool) {
+	authRecord, err := authRepo.FindAuthByUserID(userID)
+	if err != nil {
+		return nil, unauthorized("User authentication not found"), false
+	}
+	if !authRecord.IsActive && !impersonated {
+		return nil, unauthorized("User is logged out"), false
+	}
+
+	user, _ := userRepo.FindOneByID(userID)
+	if user == nil {
+		return nil, unauthorized("User is deleted"), false
+	}
+	if user.DeletionRequestedAt != nil {
+		return nil, unauthorized("User account is pending deletion"), false
+	}
+	if response, restricted := userService.AccountRestricted(user, time.Now()); restricted {
+		return nil, response, false
+	}
+	return user, base.Response{}, true
+}
+
+func unauthorized(description string) base.Response {
+	response := base.SetErrorMessage("Unauthorized", description)
+	response.HTTPStatus = http.StatusUnauthorized
+	return response
+}
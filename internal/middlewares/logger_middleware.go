@@ -0,0 +1,56 @@
+package middlewares
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/logger"
+	"github.com/labstack/echo/v4"
+)
+
+// RequestLoggerMiddleware binds the request ID, method and route to the request context, so
+// everything logged while handling it can be correlated, and writes one access line per request.
+// Only the route template is logged; raw paths and query strings can carry tokens and emails.
+func RequestLoggerMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			started := time.Now()
+			route := c.Path()
+			if route == "" {
+				route = "unmatched"
+			}
+			ctx := logger.WithRequest(c.Request().Context(), c.Response().Header().Get(echo.HeaderXRequestID), c.Request().Method, route)
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			err := next(c)
+			if err != nil {
+				// Let Echo write the error response now so the status below is the one the client saw
+				c.Error(err)
+			}
+
+			status := c.Response().Status
+			if (route == "/healthz" || route == "/readyz") && status < 400 {
+				return nil
+			}
+			level := slog.LevelInfo
+			switch {
+			case status >= 500:
+				level = slog.LevelError
+			case status >= 400:
+				level = slog.LevelWarn
+			}
+
+			attrs := []slog.Attr{
+				slog.Int("status", status),
+				slog.Int64("latency_ms", time.Since(started).Milliseconds()),
+				slog.Int64("bytes_out", c.Response().Size),
+				slog.String("remote_ip", c.RealIP()),
+			}
+			if err != nil {
+				attrs = append(attrs, slog.Any("error", err))
+			}
+			logger.FromContext(c.Request().Context()).LogAttrs(c.Request().Context(), level, "request completed", attrs...)
+			return nil
+		}
+	}
+}
@@ -1,13 +1,24 @@
 package middlewares
 
 import (
+	"github.com/Al-Khaimah/khaimah-golang-backend/config"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
 
-func RegisterAllGlobalMiddlewares(e *echo.Echo) {
-	e.Use(middleware.Logger())
+func RegisterAllGlobalMiddlewares(e *echo.Echo, cfg *config.Config) {
+	e.Use(middleware.RequestID())
+	e.Use(RequestLoggerMiddleware())
+	e.Use(AuditContextMiddleware())
+	if cfg.Metrics.Enabled {
+		// Registered outside Recover so requests that panic are counted as 500s
+		e.Use(MetricsMiddleware(cfg.Metrics.Path))
+		e.GET(cfg.Metrics.Path, metricsHandler(cfg.Metrics))
+	}
 	e.Use(middleware.Recover())
 	e.Use(middleware.CORS())
-	e.Use(middleware.RequestID())
+	if cfg.Tracing.Enabled() {
+		e.Use(TracingMiddleware(cfg))
+		e.Use(RequestIDSpanMiddleware())
+	}
 }
@@ -0,0 +1,77 @@
+package middlewares
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/config"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/jwtkeys"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/logger"
+	auditService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/audit/services"
+	roleRepos "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/repositories"
+	roleService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/services"
+	repos "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/repositories"
+	"github.com/labstack/echo/v4"
+)
+
+// RequirePermission only lets through users holding a role that grants every listed permission.
+// With no permissions listed, any staff role is enough. Staff must also use two-factor authentication,
+// and their account is checked like AuthMiddleware does: signed in, not pending deletion or restricted.
+func RequirePermission(keys *jwtkeys.Manager, permissions ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			authHeader := c.Request().Header.Get("Authorization")
+			if authHeader == "" {
+				return c.JSON(http.StatusUnauthorized, base.SetErrorMessage("Unauthorized", "No headers token provided"))
+			}
+
+			token := strings.TrimPrefix(authHeader, "Bearer ")
+			claims, err := keys.Parse(token)
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, base.SetErrorMessage("Unauthorized", "Invalid token"))
+			}
+			if claims.ImpersonatorID != nil {
+				return c.JSON(http.StatusForbidden, base.SetErrorMessage("Forbidden", "Impersonation tokens cannot be used for admin routes"))
+			}
+			userID := claims.UserID
+
+			db := config.GetDB()
+			if _, response, ok := checkAccount(repos.NewAuthRepository(db), repos.NewUserRepository(db), userID, false); !ok {
+				return c.JSON(response.HTTPStatus, response)
+			}
+
+			roleRepo := roleRepos.NewRoleRepository(db)
+			userRoles, err := roleRepo.FindUserRoles(userID)
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, base.SetErrorMessage("Server Error", "Failed to load user roles"))
+			}
+			if len(userRoles) == 0 {
+				return c.JSON(http.StatusForbidden, base.SetErrorMessage("Forbidden", "Require admin action"))
+			}
+			if !roleService.HasPermissions(userRoles, permissions...) {
+				return c.JSON(http.StatusForbidden, base.SetErrorMessage("Forbidden", "Missing permission: "+strings.Join(permissions, ", ")))
+			}
+
+			// Staff accounts must have two-factor authentication enabled and must have passed it
+			// when the token was issued
+			twoFactorEnabled, err := repos.NewTwoFactorRepository(db).IsEnabled(userID)
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, base.SetErrorMessage("Server Error", "Failed to load two-factor status"))
+			}
+			if !twoFactorEnabled {
+				return c.JSON(http.StatusForbidden, base.SetErrorMessage("Two-factor authentication required", "Enable it at /user/2fa/enroll, then sign in again"))
+			}
+			if !claims.MFA {
+				return c.JSON(http.StatusUnauthorized, base.SetErrorMessage("Two-factor authentication required", "Sign in again and complete the two-factor challenge"))
+			}
+
+			c.Set("is_admin", true)
+			c.Set("user_id", userID.String())
+			logger.SetUserID(c.Request().Context(), userID.String())
+			auditService.SetActor(c.Request().Context(), userID)
+			c.Set("roles", userRoles)
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package middlewares
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/config"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/jwtkeys"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// accountStore stands in for a database holding one account without roles
+type accountStore struct {
+	userID              uuid.UUID
+	isActive            bool
+	deletionRequestedAt *time.Time
+}
+
+func (s *accountStore) query(query string) ([]string, [][]driver.Value) {
+	switch {
+	case strings.HasPrefix(query, `SELECT * FROM "iam_auths"`):
+		return []string{"id", "user_id", "is_active"}, [][]driver.Value{{uuid.NewString(), s.userID.String(), s.isActive}}
+	case strings.HasPrefix(query, `SELECT * FROM "users"`):
+		var deletionRequestedAt driver.Value
+		if s.deletionRequestedAt != nil {
+			deletionRequestedAt = *s.deletionRequestedAt
+		}
+		return []string{"id", "email", "deletion_requested_at"}, [][]driver.Value{{s.userID.String(), "admin@example.com", deletionRequestedAt}}
+	}
+	return []string{"id"}, nil
+}
+
+type accountConn struct{ store *accountStore }
+
+func (c accountConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c accountConn) Close() error                        { return nil }
+func (c accountConn) Begin() (driver.Tx, error)           { return c, nil }
+func (c accountConn) Commit() error                       { return nil }
+func (c accountConn) Rollback() error                     { return nil }
+
+func (c accountConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+
+func (c accountConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	columns, rows := c.store.query(query)
+	return &accountRows{columns: columns, rows: rows}, nil
+}
+
+type accountRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *accountRows) Columns() []string { return r.columns }
+func (r *accountRows) Close() error      { return nil }
+func (r *accountRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+type accountConnector struct{ store *accountStore }
+
+func (c accountConnector) Connect(context.Context) (driver.Conn, error) { return accountConn(c), nil }
+func (c accountConnector) Driver() driver.Driver                        { return nil }
+
+func TestRequirePermission_ChecksAccount(t *testing.T) {
+	requestedAt := time.Now().Add(-time.Hour)
+	tests := []struct {
+		name   string
+		store  accountStore
+		status int
+	}{
+		{"logged out", accountStore{isActive: false}, http.StatusUnauthorized},
+		{"pending deletion", accountStore{isActive: true, deletionRequestedAt: &requestedAt}, http.StatusUnauthorized},
+		// signed in, the request gets as far as the missing role
+		{"signed in", accountStore{isActive: true}, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := tt.store
+			store.userID = uuid.New()
+			db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(accountConnector{&store})}), &gorm.Config{Logger: logger.Discard})
+			require.NoError(t, err)
+			previous := config.DB
+			config.DB = db
+			t.Cleanup(func() { config.DB = previous })
+
+			// A token from before signing keys, which the manager verifies without a database
+			keys := jwtkeys.NewManager(nil, config.AuthConfig{
+				JWTSecret:            "test-secret",
+				JWTLegacyTokensUntil: time.Now().Add(time.Hour).Format(time.RFC3339),
+			})
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": store.userID.String(), "mfa": true}).
+				SignedString([]byte("test-secret"))
+			require.NoError(t, err)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/admin/users", nil)
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+			rec := httptest.NewRecorder()
+			handler := RequirePermission(keys)(func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+			require.NoError(t, handler(e.NewContext(req, rec)))
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package middlewares
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/config"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/ratelimit"
+	"github.com/labstack/echo/v4"
+)
+
+// RateLimit rejects requests over the policy with 429 and reports the quota in the
+// RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy headers
+func RateLimit(cfg config.RateLimitConfig, policy ratelimit.Policy) echo.MiddlewareFunc {
+	if policy.Key == nil {
+		policy.Key = ratelimit.ByIP
+	}
+	if policy.Algorithm == "" {
+		policy.Algorithm = ratelimit.SlidingWindow
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if !cfg.Enabled {
+			return next
+		}
+
+		return func(c echo.Context) error {
+			key := policy.Key(c)
+			if key == "" {
+				return next(c)
+			}
+
+			result := ratelimit.Default().Allow(c.Request().Context(), policy, key)
+
+			header := c.Response().Header()
+			header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+			header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			header.Set("RateLimit-Reset", seconds(result.ResetAfter))
+			header.Set("RateLimit-Policy", policy.String())
+
+			if !result.Allowed {
+				header.Set(echo.HeaderRetryAfter, seconds(result.RetryAfter))
+				return c.JSON(http.StatusTooManyRequests, base.SetErrorMessage("Too many requests", "Please try again later"))
+			}
+			return next(c)
+		}
+	}
+}
+
+// seconds rounds up so clients never retry before the quota has recovered
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,36 @@
+package middlewares
+
+import (
+	"github.com/Al-Khaimah/khaimah-golang-backend/config"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingMiddleware starts a server span for every request, continuing the caller's trace
+// when a traceparent header is present. Probes and scrapes are not traced.
+func TracingMiddleware(cfg *config.Config) echo.MiddlewareFunc {
+	return otelecho.Middleware(cfg.Tracing.ServiceName, otelecho.WithSkipper(func(c echo.Context) bool {
+		switch c.Path() {
+		case "/healthz", "/readyz", cfg.Metrics.Path:
+			return true
+		}
+		return false
+	}))
+}
+
+// RequestIDSpanMiddleware tags the request span with the request ID, and returns the trace ID
+// so a request ID from a support ticket leads straight to its trace
+func RequestIDSpanMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			span := trace.SpanFromContext(c.Request().Context())
+			if span.SpanContext().IsValid() {
+				span.SetAttributes(attribute.String("http.request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+				c.Response().Header().Set("X-Trace-ID", span.SpanContext().TraceID().String())
+			}
+			return next(c)
+		}
+	}
+}
@@ -1,31 +1,264 @@
 package migrations
 
 import (
+	"embed"
 	"fmt"
-	"log"
-
-	categories "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/categories/models"
-	notifications "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/notifications/models"
-	podcasts "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/podcasts/models"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
 
 	"github.com/Al-Khaimah/khaimah-golang-backend/config"
-	users "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/models"
+	"gorm.io/gorm"
 )
 
+//go:embed sql/*.sql
+var migrationFiles embed.FS
+
+// migrationLockKey identifies the Postgres advisory lock held while migrations run,
+// so replicas booting at the same time apply each migration exactly once
+const migrationLockKey int64 = 72_104_101_105_109
+
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+}
+
+// SchemaMigration is a row of the schema_migrations table
+type SchemaMigration struct {
+	Version   int64     `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"type:varchar(255)"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+type Migrator struct {
+	DB         *gorm.DB
+	Migrations []Migration
+}
+
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	migrations, err := LoadMigrations(migrationFiles, "sql")
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{DB: db, Migrations: migrations}, nil
+}
+
+// LoadMigrations reads <version>_<name>.up.sql and <version>_<name>.down.sql pairs from dir, ordered by version
+func LoadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %q: %w", entry.Name(), err)
+		}
+		contents, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %q: %w", entry.Name(), err)
+		}
+
+		migration, found := byVersion[version]
+		if !found {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(contents)
+		} else {
+			migration.Down = string(contents)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down files", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Up applies every pending migration in order and returns how many were applied
+func (m *Migrator) Up() (int, error) {
+	applied := 0
+	err := m.withLock(func(conn *gorm.DB) error {
+		done, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.Migrations {
+			if _, found := done[migration.Version]; found {
+				continue
+			}
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(migration.Up).Error; err != nil {
+					return err
+				}
+				return tx.Create(&SchemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("failed to apply migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			slog.Info("Applied migration", "version", migration.Version, "name", migration.Name)
+			applied++
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down rolls back the most recently applied migrations, at most steps of them
+func (m *Migrator) Down(steps int) (int, error) {
+	rolledBack := 0
+	err := m.withLock(func(conn *gorm.DB) error {
+		done, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+		for i := len(m.Migrations) - 1; i >= 0 && rolledBack < steps; i-- {
+			migration := m.Migrations[i]
+			if _, found := done[migration.Version]; !found {
+				continue
+			}
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(migration.Down).Error; err != nil {
+					return err
+				}
+				return tx.Delete(&SchemaMigration{}, migration.Version).Error
+			})
+			if err != nil {
+				return fmt.Errorf("failed to roll back migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			slog.Info("Rolled back migration", "version", migration.Version, "name", migration.Name)
+			rolledBack++
+		}
+		return nil
+	})
+	return rolledBack, err
+}
+
+// Status lists every known migration with the time it was applied, if it has been
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+	err := m.withLock(func(conn *gorm.DB) error {
+		done, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.Migrations {
+			status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+			if row, found := done[migration.Version]; found {
+				appliedAt := row.AppliedAt
+				status.AppliedAt = &appliedAt
+			}
+			statuses = append(statuses, status)
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+// Pending returns how many known migrations have not been applied. It reads schema_migrations
+// without taking the migration lock, so it never waits on a replica that is migrating.
+func (m *Migrator) Pending() (int, error) {
+	done, err := appliedVersions(m.DB)
+	if err != nil {
+		return 0, err
+	}
+	pending := 0
+	for _, migration := range m.Migrations {
+		if _, found := done[migration.Version]; !found {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+// withLock runs fn on a single connection holding the migration advisory lock.
+// Session-level advisory locks belong to a connection, so fn must not use the pool.
+func (m *Migrator) withLock(fn func(conn *gorm.DB) error) error {
+	return m.DB.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockKey).Error; err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		defer func() {
+			if err := conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockKey).Error; err != nil {
+				slog.Error("Failed to release migration lock", "error", err)
+			}
+		}()
+
+		err := conn.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+			version bigint PRIMARY KEY,
+			name varchar(255),
+			applied_at timestamptz NOT NULL
+		)`).Error
+		if err != nil {
+			return fmt.Errorf("failed to create schema_migrations table: %w", err)
+		}
+		return fn(conn)
+	})
+}
+
+func appliedVersions(conn *gorm.DB) (map[int64]SchemaMigration, error) {
+	var rows []SchemaMigration
+	if err := conn.Order("version").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	done := make(map[int64]SchemaMigration, len(rows))
+	for _, row := range rows {
+		done[row.Version] = row
+	}
+	return done, nil
+}
+
+// Migrate applies pending migrations at startup
 func Migrate() {
-	db := config.GetDB()
-	fmt.Println("Running Migrations...")
-	err := db.AutoMigrate(
-		&users.User{},
-		&users.IamAuth{},
-		&categories.Category{},
-		&notifications.Notification{},
-		&podcasts.Podcast{},
-		&podcasts.UserPodcast{},
-		&podcasts.BookmarkPodcast{},
-	)
+	migrator, err := NewMigrator(config.GetDB())
+	if err != nil {
+		slog.Error("Migration failed", "error", err)
+		os.Exit(1)
+	}
+
+	applied, err := migrator.Up()
 	if err != nil {
-		log.Fatalf("Migration failed: %v", err)
+		slog.Error("Migration failed", "error", err)
+		os.Exit(1)
 	}
-	fmt.Println("Migrations completed successfully!")
+	slog.Info("Migrations completed", "applied", applied)
 }
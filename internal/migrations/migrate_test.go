@@ -0,0 +1,283 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestLoadMigrations_OrdersByVersion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/0010_add_tags.up.sql":    {Data: []byte("ALTER TABLE podcasts ADD COLUMN tags text;")},
+		"sql/0010_add_tags.down.sql":  {Data: []byte("ALTER TABLE podcasts DROP COLUMN tags;")},
+		"sql/0002_add_index.up.sql":   {Data: []byte("CREATE INDEX idx ON users (email);")},
+		"sql/0002_add_index.down.sql": {Data: []byte("DROP INDEX idx;")},
+		"sql/0001_baseline.up.sql":    {Data: []byte("CREATE TABLE users (id uuid);")},
+		"sql/0001_baseline.down.sql":  {Data: []byte("DROP TABLE users;")},
+	}
+
+	migrations, err := LoadMigrations(fsys, "sql")
+
+	require.NoError(t, err)
+	require.Len(t, migrations, 3)
+	assert.Equal(t, []int64{1, 2, 10}, []int64{migrations[0].Version, migrations[1].Version, migrations[2].Version})
+	assert.Equal(t, "add_tags", migrations[2].Name)
+	assert.Equal(t, "ALTER TABLE podcasts DROP COLUMN tags;", migrations[2].Down)
+}
+
+func TestLoadMigrations_MissingDown(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/0001_baseline.up.sql": {Data: []byte("CREATE TABLE users (id uuid);")},
+	}
+
+	_, err := LoadMigrations(fsys, "sql")
+
+	assert.ErrorContains(t, err, "both up and down")
+}
+
+func TestLoadMigrations_ConflictingNames(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/0001_baseline.up.sql":  {Data: []byte("CREATE TABLE users (id uuid);")},
+		"sql/0001_initial.down.sql": {Data: []byte("DROP TABLE users;")},
+	}
+
+	_, err := LoadMigrations(fsys, "sql")
+
+	assert.ErrorContains(t, err, "conflicting names")
+}
+
+func TestLoadMigrations_InvalidFileName(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/baseline.sql": {Data: []byte("CREATE TABLE users (id uuid);")},
+	}
+
+	_, err := LoadMigrations(fsys, "sql")
+
+	assert.ErrorContains(t, err, "invalid migration file name")
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := LoadMigrations(migrationFiles, "sql")
+
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+	assert.Equal(t, int64(1), migrations[0].Version)
+	assert.Equal(t, "baseline", migrations[0].Name)
+	assert.Contains(t, migrations[0].Up, `CREATE EXTENSION IF NOT EXISTS "uuid-ossp"`)
+}
+
+// legacySchema is what AutoMigrate created before versioned migrations replaced it
+const legacySchema = `
+CREATE TABLE IF NOT EXISTS users (id uuid, created_at timestamptz, updated_at timestamptz, deleted_at timestamptz, first_name varchar(100), last_name varchar(100), user_type varchar(20), email varchar(255), mobile varchar(20));
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE INDEX IF NOT EXISTS idx_users_mobile ON users (mobile);
+CREATE TABLE IF NOT EXISTS iam_auths (id uuid, created_at timestamptz, updated_at timestamptz, deleted_at timestamptz, user_id uuid, password varchar(255), is_active boolean);
+CREATE TABLE IF NOT EXISTS categories (id uuid, created_at timestamptz, updated_at timestamptz, deleted_at timestamptz, name varchar(100), description varchar(255), is_news_intensive boolean);
+CREATE TABLE IF NOT EXISTS user_categories (user_id uuid, category_id uuid);
+CREATE TABLE IF NOT EXISTS notifications (id uuid, created_at timestamptz, updated_at timestamptz, deleted_at timestamptz, user_id uuid, title varchar(255), description text, is_read boolean, type varchar(100));
+CREATE TABLE IF NOT EXISTS podcasts (id uuid, created_at timestamptz, updated_at timestamptz, deleted_at timestamptz, title varchar(255), content text, audio_url text, cover_image_url text, cover_image_description text, likes_count bigint, duration bigint, category_id uuid, fetched_from text, tags text);
+CREATE INDEX IF NOT EXISTS idx_podcasts_category_id ON podcasts (category_id);
+CREATE TABLE IF NOT EXISTS user_podcasts (id uuid, created_at timestamptz, updated_at timestamptz, deleted_at timestamptz, user_id uuid, podcast_id uuid, category_id uuid, resume_position bigint, is_completed boolean);
+CREATE TABLE IF NOT EXISTS user_bookmarks (user_id uuid, podcast_id uuid);
+CREATE TABLE IF NOT EXISTS user_downloads (user_id uuid, podcast_id uuid);
+`
+
+var (
+	createTablePattern = regexp.MustCompile(`(?s)^CREATE TABLE IF NOT EXISTS (\w+) \((.*)\)$`)
+	addColumnPattern   = regexp.MustCompile(`^ALTER TABLE (\w+) ADD COLUMN IF NOT EXISTS (\w+)`)
+	dropColumnPattern  = regexp.MustCompile(`^ALTER TABLE (\w+) DROP COLUMN IF EXISTS (\w+)`)
+	createIndexPattern = regexp.MustCompile(`^CREATE (?:UNIQUE )?INDEX IF NOT EXISTS (\w+) ON (\w+) \(([^)]*)\)`)
+)
+
+// schemaStore stands in for Postgres, tracking the tables, columns and indexes the DDL creates.
+// Like Postgres it skips CREATE TABLE IF NOT EXISTS for a table that exists, even when the
+// definition has columns the table lacks, and refuses an index on a column that does not exist.
+type schemaStore struct {
+	tables  map[string]map[string]bool
+	indexes map[string]bool
+	applied [][]driver.Value
+}
+
+func newSchemaStore(t *testing.T, script string) *schemaStore {
+	t.Helper()
+	store := &schemaStore{tables: map[string]map[string]bool{}, indexes: map[string]bool{}}
+	require.NoError(t, store.run(script))
+	return store
+}
+
+func (s *schemaStore) run(script string) error {
+	for _, statement := range splitStatements(script) {
+		if err := s.apply(statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *schemaStore) apply(statement string) error {
+	if match := createTablePattern.FindStringSubmatch(statement); match != nil {
+		if _, found := s.tables[match[1]]; found {
+			return nil
+		}
+		columns := map[string]bool{}
+		for _, definition := range strings.Split(match[2], ",") {
+			name := strings.Fields(definition)
+			if len(name) == 0 || name[0] == "CONSTRAINT" || name[0] == "PRIMARY" || strings.Contains(name[0], ")") {
+				continue
+			}
+			columns[name[0]] = true
+		}
+		s.tables[match[1]] = columns
+		return nil
+	}
+	if match := addColumnPattern.FindStringSubmatch(statement); match != nil {
+		columns, found := s.tables[match[1]]
+		if !found {
+			return fmt.Errorf("relation %q does not exist", match[1])
+		}
+		columns[match[2]] = true
+		return nil
+	}
+	if match := dropColumnPattern.FindStringSubmatch(statement); match != nil {
+		delete(s.tables[match[1]], match[2])
+		return nil
+	}
+	if match := createIndexPattern.FindStringSubmatch(statement); match != nil {
+		if s.indexes[match[1]] {
+			return nil
+		}
+		columns, found := s.tables[match[2]]
+		if !found {
+			return fmt.Errorf("relation %q does not exist", match[2])
+		}
+		for _, column := range strings.Split(match[3], ",") {
+			if column = strings.TrimSpace(column); !columns[column] {
+				return fmt.Errorf("column %q does not exist", column)
+			}
+		}
+		s.indexes[match[1]] = true
+	}
+	return nil
+}
+
+// splitStatements splits a script on semicolons outside of $$-quoted bodies
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	quoted := false
+	for i := 0; i < len(script); i++ {
+		if strings.HasPrefix(script[i:], "$$") {
+			quoted = !quoted
+			current.WriteString("$$")
+			i++
+			continue
+		}
+		if script[i] == ';' && !quoted {
+			if statement := strings.TrimSpace(stripComments(current.String())); statement != "" {
+				statements = append(statements, statement)
+			}
+			current.Reset()
+			continue
+		}
+		current.WriteByte(script[i])
+	}
+	return statements
+}
+
+func stripComments(statement string) string {
+	var lines []string
+	for _, line := range strings.Split(statement, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "--") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+type schemaConn struct{ store *schemaStore }
+
+func (c schemaConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c schemaConn) Close() error                        { return nil }
+func (c schemaConn) Begin() (driver.Tx, error)           { return c, nil }
+func (c schemaConn) Commit() error                       { return nil }
+func (c schemaConn) Rollback() error                     { return nil }
+
+func (c schemaConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if strings.HasPrefix(query, `INSERT INTO "schema_migrations"`) {
+		c.store.recordApplied(args)
+		return driver.RowsAffected(1), nil
+	}
+	if err := c.store.run(query); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (c schemaConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	switch {
+	case strings.HasPrefix(query, `SELECT * FROM "schema_migrations"`):
+		return &schemaRows{columns: []string{"version", "name", "applied_at"}, rows: append([][]driver.Value(nil), c.store.applied...)}, nil
+	case strings.HasPrefix(query, `INSERT INTO "schema_migrations"`):
+		c.store.recordApplied(args)
+	}
+	return &schemaRows{columns: []string{"version"}}, nil
+}
+
+func (s *schemaStore) recordApplied(args []driver.NamedValue) {
+	row := make([]driver.Value, len(args))
+	for i, arg := range args {
+		row[i] = arg.Value
+	}
+	s.applied = append(s.applied, row)
+}
+
+type schemaRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *schemaRows) Columns() []string { return r.columns }
+func (r *schemaRows) Close() error      { return nil }
+func (r *schemaRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+type schemaConnector struct{ store *schemaStore }
+
+func (c schemaConnector) Connect(context.Context) (driver.Conn, error) { return schemaConn(c), nil }
+func (c schemaConnector) Driver() driver.Driver                        { return nil }
+
+func TestUp_FromLegacySchema(t *testing.T) {
+	store := newSchemaStore(t, legacySchema)
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(schemaConnector{store})}), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	migrator, err := NewMigrator(db)
+	require.NoError(t, err)
+
+	applied, err := migrator.Up()
+
+	require.NoError(t, err)
+	assert.Equal(t, len(migrator.Migrations), applied)
+	assert.True(t, store.tables["users"]["deletion_requested_at"])
+	assert.True(t, store.tables["podcasts"]["is_premium"])
+	assert.True(t, store.indexes["idx_users_deletion_requested_at"])
+	assert.True(t, store.indexes["idx_podcasts_is_premium"])
+}
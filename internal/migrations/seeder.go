@@ -2,12 +2,17 @@ package migrations
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"math/rand"
 	"os"
 	"time"
 
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/jwtkeys"
 	notifications "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/notifications/models"
+	roleEnums "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/enums"
+	roleRepositories "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/repositories"
+	roleServices "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/services"
+	userEnums "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/enums"
 	users "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/models"
 
 	"github.com/bxcodec/faker/v3"
@@ -15,56 +20,81 @@ import (
 
 	categories "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/categories/models"
 	podcasts "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/podcasts/models"
+	referrals "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/referrals/models"
+	roles "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/models"
+	subscriptions "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/models"
 )
 
 func ClearTables(db *gorm.DB) {
-	fmt.Println("⚠️ Clearing existing tables...")
+	slog.Warn("Clearing existing tables")
 
+	// audit_events is left alone: it is append-only and its triggers reject deletes
 	models := []interface{}{
 		&users.User{},
 		&users.IamAuth{},
+		&users.DataExport{},
+		&users.AccountPurge{},
+		&users.TwoFactorRecoveryCode{},
+		&users.TwoFactor{},
+		&users.UserImport{},
+		&categories.CategoryDailyStat{},
 		&categories.Category{},
+		&roles.Role{},
+		&roles.Permission{},
 		&notifications.Notification{},
 		&podcasts.Podcast{},
 		&podcasts.UserPodcast{},
 		&podcasts.BookmarkPodcast{},
+		&podcasts.PodcastLike{},
+		&referrals.PromoRedemption{},
+		&referrals.PromoCode{},
+		&referrals.Referral{},
+		&referrals.ReferralCode{},
+		&subscriptions.StoreNotification{},
+		&subscriptions.SubscriptionEventLog{},
+		&subscriptions.Subscription{},
+		&subscriptions.Plan{},
+		&jwtkeys.SigningKey{},
 	}
 
 	for _, model := range models {
 		if err := db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(model).Error; err != nil {
-			fmt.Printf("❌ Failed to clear table for model %T: %v\n", model, err)
+			slog.Error("Failed to clear table", "model", fmt.Sprintf("%T", model), "error", err)
 		}
 	}
 
 	db.Exec("DELETE FROM user_categories")
 	db.Exec("DELETE FROM user_bookmarks")
 	db.Exec("DELETE FROM user_downloads")
+	db.Exec("DELETE FROM user_roles")
+	db.Exec("DELETE FROM role_permissions")
 
-	fmt.Println("✅ All table data cleared, schema is intact.")
+	slog.Info("All table data cleared, schema is intact")
 }
 
 func SeedDatabase(db *gorm.DB) {
+	SeedRoles(db)
+
 	var count int64
 	db.Model(&podcasts.Podcast{}).Count(&count)
 	if count > 5 {
-		fmt.Println("✅ Podcasts already seeded! Skipping...")
+		slog.Info("Podcasts already seeded, skipping")
 		return
 	}
 
-	fmt.Println("🌱 Seeding only podcasts table with test data...")
+	slog.Info("Seeding podcasts table with test data")
 	seedPodcasts(db)
-	fmt.Println("🎉 Podcasts seeding completed successfully!")
 }
 
 func seedPodcasts(db *gorm.DB) {
 	var categoriesList []categories.Category
 	if err := db.Find(&categoriesList).Error; err != nil {
-		fmt.Println("❌ Failed to load categories:", err)
+		slog.Error("Failed to load categories", "error", err)
 		return
 	}
 
 	if len(categoriesList) == 0 {
-		fmt.Println("❌ No categories found. Cannot seed podcasts without categories.")
+		slog.Error("No categories found, cannot seed podcasts without categories")
 		return
 	}
 
@@ -91,7 +121,7 @@ func seedPodcasts(db *gorm.DB) {
 	}
 
 	db.Create(&podcastsList)
-	fmt.Println("✅ Podcasts seeded using existing categories!")
+	slog.Info("Podcasts seeded using existing categories", "count", len(podcastsList))
 }
 
 func generateRandomWAV(filename string) {
@@ -99,7 +129,7 @@ func generateRandomWAV(filename string) {
 
 	file, err := os.Create(filename)
 	if err != nil {
-		log.Printf("❌ Failed to create WAV file: %s\n", err)
+		slog.Error("Failed to create WAV file", "error", err)
 		return
 	}
 	defer file.Close()
@@ -107,6 +137,34 @@ func generateRandomWAV(filename string) {
 	data := make([]byte, 44100*2*int(duration.Seconds()))
 	_, err = file.Write(data)
 	if err != nil {
-		log.Printf("❌ Failed to write WAV data: %s\n", err)
+		slog.Error("Failed to write WAV data", "error", err)
+	}
+}
+
+// SeedRoles creates the default roles and permissions and moves legacy admins onto the superadmin role
+func SeedRoles(db *gorm.DB) {
+	roleRepo := roleRepositories.NewRoleRepository(db)
+	if err := roleServices.SeedDefaultRoles(roleRepo); err != nil {
+		slog.Error("Failed to seed roles", "error", err)
+		return
+	}
+
+	superAdmin, err := roleRepo.FindRoleByName(roleEnums.RoleSuperAdmin)
+	if err != nil || superAdmin == nil {
+		slog.Error("Failed to load superadmin role", "error", err)
+		return
+	}
+
+	// Accounts promoted through the old user_type flag become superadmins and go back to the free tier
+	var legacyAdmins []users.User
+	db.Where("user_type = ?", userEnums.UserTypeAdmin).Find(&legacyAdmins)
+	for _, admin := range legacyAdmins {
+		if err := roleRepo.AssignRole(admin.ID, superAdmin.ID); err != nil {
+			slog.Error("Failed to migrate admin to superadmin role", "admin_id", admin.ID, "error", err)
+			continue
+		}
+		db.Model(&users.User{}).Where("id = ?", admin.ID).Update("user_type", userEnums.UserTypeFree)
 	}
+
+	slog.Info("Roles seeded")
 }
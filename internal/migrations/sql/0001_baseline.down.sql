@@ -0,0 +1,24 @@
+DROP TABLE IF EXISTS promo_redemptions;
+DROP TABLE IF EXISTS promo_codes;
+DROP TABLE IF EXISTS referrals;
+DROP TABLE IF EXISTS referral_codes;
+DROP TABLE IF EXISTS store_notifications;
+DROP TABLE IF EXISTS subscription_event_logs;
+DROP TABLE IF EXISTS subscriptions;
+DROP TABLE IF EXISTS plans;
+DROP TABLE IF EXISTS user_downloads;
+DROP TABLE IF EXISTS user_bookmarks;
+DROP TABLE IF EXISTS podcast_likes;
+DROP TABLE IF EXISTS user_podcasts;
+DROP TABLE IF EXISTS podcasts;
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS user_roles;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS roles;
+DROP TABLE IF EXISTS user_categories;
+DROP TABLE IF EXISTS categories;
+DROP TABLE IF EXISTS account_purges;
+DROP TABLE IF EXISTS data_exports;
+DROP TABLE IF EXISTS iam_auths;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,334 @@
+-- Baseline schema captured from the GORM models. Every statement is idempotent so databases
+-- previously managed by AutoMigrate adopt this version without changes. CREATE TABLE IF NOT EXISTS
+-- leaves an existing table untouched, so tables that predate the migrations keep their original
+-- columns here; columns added to them since belong in later ALTER TABLE migrations.
+
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+CREATE TABLE IF NOT EXISTS users (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    first_name varchar(100),
+    last_name varchar(100),
+    user_type varchar(20) DEFAULT 'free',
+    email varchar(255),
+    mobile varchar(20)
+);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE INDEX IF NOT EXISTS idx_users_mobile ON users (mobile);
+
+CREATE TABLE IF NOT EXISTS iam_auths (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id uuid,
+    password varchar(255),
+    is_active boolean,
+    CONSTRAINT fk_users_auth FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_iam_auths_deleted_at ON iam_auths (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_iam_auths_user_id ON iam_auths (user_id);
+
+CREATE TABLE IF NOT EXISTS data_exports (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id uuid,
+    status varchar(20) DEFAULT 'pending',
+    token varchar(64),
+    file_path text,
+    completed_at timestamptz,
+    expires_at timestamptz,
+    error text
+);
+CREATE INDEX IF NOT EXISTS idx_data_exports_deleted_at ON data_exports (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_data_exports_user_id ON data_exports (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_data_exports_token ON data_exports (token);
+
+CREATE TABLE IF NOT EXISTS account_purges (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id uuid,
+    deletion_requested_at timestamptz,
+    purged_at timestamptz,
+    rows_deleted bigint
+);
+CREATE INDEX IF NOT EXISTS idx_account_purges_deleted_at ON account_purges (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_account_purges_user_id ON account_purges (user_id);
+
+CREATE TABLE IF NOT EXISTS categories (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    name varchar(100),
+    description varchar(255),
+    is_news_intensive boolean DEFAULT true
+);
+CREATE INDEX IF NOT EXISTS idx_categories_deleted_at ON categories (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_name ON categories (name);
+
+CREATE TABLE IF NOT EXISTS user_categories (
+    user_id uuid,
+    category_id uuid,
+    PRIMARY KEY (user_id, category_id),
+    CONSTRAINT fk_user_categories_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_user_categories_category FOREIGN KEY (category_id) REFERENCES categories (id)
+);
+
+CREATE TABLE IF NOT EXISTS roles (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    name varchar(50),
+    description varchar(255)
+);
+CREATE INDEX IF NOT EXISTS idx_roles_deleted_at ON roles (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_roles_name ON roles (name);
+
+CREATE TABLE IF NOT EXISTS permissions (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    key varchar(100),
+    description varchar(255)
+);
+CREATE INDEX IF NOT EXISTS idx_permissions_deleted_at ON permissions (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_permissions_key ON permissions (key);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id uuid,
+    permission_id uuid,
+    PRIMARY KEY (role_id, permission_id),
+    CONSTRAINT fk_role_permissions_role FOREIGN KEY (role_id) REFERENCES roles (id),
+    CONSTRAINT fk_role_permissions_permission FOREIGN KEY (permission_id) REFERENCES permissions (id)
+);
+
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id uuid,
+    role_id uuid,
+    PRIMARY KEY (user_id, role_id),
+    CONSTRAINT fk_user_roles_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_user_roles_role FOREIGN KEY (role_id) REFERENCES roles (id)
+);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id uuid,
+    title varchar(255),
+    description text,
+    is_read boolean DEFAULT false,
+    type varchar(100),
+    CONSTRAINT fk_notifications_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_notifications_deleted_at ON notifications (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications (user_id);
+
+CREATE TABLE IF NOT EXISTS podcasts (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    title varchar(255),
+    content text,
+    audio_url text,
+    cover_image_url text,
+    cover_image_description text,
+    likes_count bigint DEFAULT 0,
+    duration bigint DEFAULT 0,
+    category_id uuid,
+    fetched_from text,
+    tags text
+);
+CREATE INDEX IF NOT EXISTS idx_podcasts_deleted_at ON podcasts (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_podcasts_title ON podcasts (title);
+CREATE INDEX IF NOT EXISTS idx_podcasts_category_id ON podcasts (category_id);
+
+CREATE TABLE IF NOT EXISTS user_podcasts (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id uuid,
+    podcast_id uuid,
+    category_id uuid,
+    resume_position bigint DEFAULT 0,
+    is_completed boolean DEFAULT false
+);
+CREATE INDEX IF NOT EXISTS idx_user_podcasts_deleted_at ON user_podcasts (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_user_podcasts_user_id ON user_podcasts (user_id);
+CREATE INDEX IF NOT EXISTS idx_user_podcasts_podcast_id ON user_podcasts (podcast_id);
+CREATE INDEX IF NOT EXISTS idx_user_podcasts_category_id ON user_podcasts (category_id);
+
+CREATE TABLE IF NOT EXISTS podcast_likes (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id uuid,
+    podcast_id uuid,
+    category_id uuid,
+    count bigint DEFAULT 1
+);
+CREATE INDEX IF NOT EXISTS idx_podcast_likes_deleted_at ON podcast_likes (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_podcast_likes_user_id ON podcast_likes (user_id);
+CREATE INDEX IF NOT EXISTS idx_podcast_likes_podcast_id ON podcast_likes (podcast_id);
+CREATE INDEX IF NOT EXISTS idx_podcast_likes_category_id ON podcast_likes (category_id);
+
+CREATE TABLE IF NOT EXISTS user_bookmarks (
+    user_id uuid,
+    podcast_id uuid,
+    PRIMARY KEY (user_id, podcast_id),
+    CONSTRAINT fk_user_bookmarks_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_user_bookmarks_podcast FOREIGN KEY (podcast_id) REFERENCES podcasts (id)
+);
+
+CREATE TABLE IF NOT EXISTS user_downloads (
+    user_id uuid,
+    podcast_id uuid,
+    PRIMARY KEY (user_id, podcast_id),
+    CONSTRAINT fk_user_downloads_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_user_downloads_podcast FOREIGN KEY (podcast_id) REFERENCES podcasts (id)
+);
+
+CREATE TABLE IF NOT EXISTS plans (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    code varchar(50),
+    name varchar(100),
+    period varchar(20),
+    price_cents bigint DEFAULT 0,
+    currency varchar(3) DEFAULT 'SAR',
+    trial_days bigint DEFAULT 0,
+    grace_days bigint DEFAULT 0,
+    is_active boolean DEFAULT true,
+    app_store_product_id varchar(255),
+    google_play_product_id varchar(255)
+);
+CREATE INDEX IF NOT EXISTS idx_plans_deleted_at ON plans (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_plans_code ON plans (code);
+CREATE INDEX IF NOT EXISTS idx_plans_app_store_product_id ON plans (app_store_product_id);
+CREATE INDEX IF NOT EXISTS idx_plans_google_play_product_id ON plans (google_play_product_id);
+
+CREATE TABLE IF NOT EXISTS subscriptions (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id uuid,
+    plan_id uuid,
+    status varchar(20),
+    provider varchar(20),
+    external_id varchar(255),
+    auto_renew boolean DEFAULT true,
+    current_period_start timestamptz,
+    current_period_end timestamptz,
+    grace_ends_at timestamptz,
+    canceled_at timestamptz,
+    expired_at timestamptz,
+    CONSTRAINT fk_subscriptions_plan FOREIGN KEY (plan_id) REFERENCES plans (id)
+);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_deleted_at ON subscriptions (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_user_id ON subscriptions (user_id);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_plan_id ON subscriptions (plan_id);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_status ON subscriptions (status);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_external_id ON subscriptions (external_id);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_current_period_end ON subscriptions (current_period_end);
+
+CREATE TABLE IF NOT EXISTS subscription_event_logs (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    subscription_id uuid,
+    event varchar(30),
+    from_status varchar(20),
+    to_status varchar(20),
+    provider varchar(20),
+    occurred_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_subscription_event_logs_deleted_at ON subscription_event_logs (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_subscription_event_logs_subscription_id ON subscription_event_logs (subscription_id);
+
+CREATE TABLE IF NOT EXISTS store_notifications (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    provider varchar(20),
+    notification_id varchar(255),
+    type varchar(100)
+);
+CREATE INDEX IF NOT EXISTS idx_store_notifications_deleted_at ON store_notifications (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_store_notification ON store_notifications (provider, notification_id);
+
+CREATE TABLE IF NOT EXISTS referral_codes (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id uuid,
+    code varchar(16)
+);
+CREATE INDEX IF NOT EXISTS idx_referral_codes_deleted_at ON referral_codes (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_referral_codes_user_id ON referral_codes (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_referral_codes_code ON referral_codes (code);
+
+CREATE TABLE IF NOT EXISTS referrals (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    referrer_id uuid,
+    referee_id uuid,
+    code varchar(16),
+    status varchar(20) DEFAULT 'pending',
+    reward_days bigint DEFAULT 0,
+    rewarded_at timestamptz,
+    rejected_reason text
+);
+CREATE INDEX IF NOT EXISTS idx_referrals_deleted_at ON referrals (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_referrals_referrer_id ON referrals (referrer_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_referrals_referee_id ON referrals (referee_id);
+CREATE INDEX IF NOT EXISTS idx_referrals_status ON referrals (status);
+
+CREATE TABLE IF NOT EXISTS promo_codes (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    code varchar(50),
+    plan_code varchar(50),
+    days bigint DEFAULT 0,
+    max_redemptions bigint DEFAULT 0,
+    redemptions_count bigint DEFAULT 0,
+    expires_at timestamptz,
+    is_active boolean DEFAULT true
+);
+CREATE INDEX IF NOT EXISTS idx_promo_codes_deleted_at ON promo_codes (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_promo_codes_code ON promo_codes (code);
+
+CREATE TABLE IF NOT EXISTS promo_redemptions (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    promo_code_id uuid,
+    user_id uuid
+);
+CREATE INDEX IF NOT EXISTS idx_promo_redemptions_deleted_at ON promo_redemptions (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_promo_redemption ON promo_redemptions (promo_code_id, user_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_iam_auths_locked_until;
+ALTER TABLE iam_auths DROP COLUMN IF EXISTS locked_until;
+ALTER TABLE iam_auths DROP COLUMN IF EXISTS last_failed_login_at;
+ALTER TABLE iam_auths DROP COLUMN IF EXISTS failed_login_attempts;
@@ -0,0 +1,4 @@
+ALTER TABLE iam_auths ADD COLUMN IF NOT EXISTS failed_login_attempts bigint DEFAULT 0;
+ALTER TABLE iam_auths ADD COLUMN IF NOT EXISTS last_failed_login_at timestamptz;
+ALTER TABLE iam_auths ADD COLUMN IF NOT EXISTS locked_until timestamptz;
+CREATE INDEX IF NOT EXISTS idx_iam_auths_locked_until ON iam_auths (locked_until);
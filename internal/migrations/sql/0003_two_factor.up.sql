@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS two_factors (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id uuid,
+    secret text,
+    enabled_at timestamptz,
+    last_used_step bigint
+);
+CREATE INDEX IF NOT EXISTS idx_two_factors_deleted_at ON two_factors (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_two_factors_user_id ON two_factors (user_id);
+
+CREATE TABLE IF NOT EXISTS two_factor_recovery_codes (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id uuid,
+    code_hash varchar(64),
+    used_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_two_factor_recovery_codes_deleted_at ON two_factor_recovery_codes (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_two_factor_recovery_codes_user_id ON two_factor_recovery_codes (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_two_factor_recovery_codes_code_hash ON two_factor_recovery_codes (code_hash);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS signing_keys (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    key_id varchar(64),
+    algorithm varchar(10),
+    private_key text,
+    public_key text,
+    activates_at timestamptz,
+    retired_at timestamptz,
+    expires_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_signing_keys_deleted_at ON signing_keys (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_signing_keys_key_id ON signing_keys (key_id);
+CREATE INDEX IF NOT EXISTS idx_signing_keys_expires_at ON signing_keys (expires_at);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_users_signup_method;
+ALTER TABLE users DROP COLUMN IF EXISTS signup_method;
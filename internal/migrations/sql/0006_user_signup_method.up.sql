@@ -0,0 +1,11 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS signup_method varchar(20);
+CREATE INDEX IF NOT EXISTS idx_users_signup_method ON users (signup_method);
+
+-- Backfill what can be told from existing rows: mobile OTP accounts carry a placeholder email
+-- and password signups have a password hash. Everything else is left unknown.
+UPDATE users SET signup_method = 'mobile_otp'
+WHERE signup_method IS NULL AND email LIKE 'mobile\_%@placeholder.com';
+
+UPDATE users SET signup_method = 'password'
+WHERE signup_method IS NULL
+  AND EXISTS (SELECT 1 FROM iam_auths WHERE iam_auths.user_id = users.id AND iam_auths.password <> '');
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_users_suspended_until;
+ALTER TABLE users DROP COLUMN IF EXISTS restriction_reason;
+ALTER TABLE users DROP COLUMN IF EXISTS banned_at;
+ALTER TABLE users DROP COLUMN IF EXISTS suspended_until;
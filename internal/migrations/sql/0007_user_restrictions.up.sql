@@ -0,0 +1,4 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS suspended_until timestamptz;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS banned_at timestamptz;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS restriction_reason text;
+CREATE INDEX IF NOT EXISTS idx_users_suspended_until ON users (suspended_until);
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS user_imports (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    created_by uuid,
+    file_name varchar(255),
+    status varchar(20) DEFAULT 'pending',
+    content text,
+    total_rows bigint,
+    created_count bigint,
+    skipped_count bigint,
+    failed_count bigint,
+    row_report jsonb,
+    completed_at timestamptz,
+    error text
+);
+CREATE INDEX IF NOT EXISTS idx_user_imports_deleted_at ON user_imports (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_user_imports_created_by ON user_imports (created_by);
+CREATE INDEX IF NOT EXISTS idx_user_imports_status ON user_imports (status);
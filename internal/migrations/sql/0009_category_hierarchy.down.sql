@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_categories_parent_id;
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS fk_categories_parent;
+ALTER TABLE categories DROP COLUMN IF EXISTS is_active;
+ALTER TABLE categories DROP COLUMN IF EXISTS color;
+ALTER TABLE categories DROP COLUMN IF EXISTS icon;
+ALTER TABLE categories DROP COLUMN IF EXISTS sort_order;
+ALTER TABLE categories DROP COLUMN IF EXISTS parent_id;
+ALTER TABLE categories DROP COLUMN IF EXISTS name_en;
+ALTER TABLE categories DROP COLUMN IF EXISTS name_ar;
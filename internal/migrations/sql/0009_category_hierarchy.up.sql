@@ -0,0 +1,17 @@
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS name_ar varchar(100);
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS name_en varchar(100);
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS parent_id uuid;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS sort_order bigint DEFAULT 0;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS icon varchar(255);
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS color varchar(9);
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS is_active boolean DEFAULT true;
+UPDATE categories SET sort_order = 0 WHERE sort_order IS NULL;
+UPDATE categories SET is_active = true WHERE is_active IS NULL;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_categories_parent') THEN
+        ALTER TABLE categories ADD CONSTRAINT fk_categories_parent FOREIGN KEY (parent_id) REFERENCES categories (id);
+    END IF;
+END $$;
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories (parent_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_podcast_likes_created_at;
+DROP INDEX IF EXISTS idx_user_podcasts_created_at;
+DROP INDEX IF EXISTS idx_podcasts_created_at;
+DROP TABLE IF EXISTS category_daily_stats;
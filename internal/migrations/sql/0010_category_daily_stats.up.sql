@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS category_daily_stats (
+    category_id uuid NOT NULL,
+    day date NOT NULL,
+    followers bigint,
+    podcasts_published bigint NOT NULL DEFAULT 0,
+    plays bigint NOT NULL DEFAULT 0,
+    completions bigint NOT NULL DEFAULT 0,
+    likes bigint NOT NULL DEFAULT 0,
+    listen_through_sum double precision NOT NULL DEFAULT 0,
+    listen_through_count bigint NOT NULL DEFAULT 0,
+    updated_at timestamptz,
+    PRIMARY KEY (category_id, day)
+);
+CREATE INDEX IF NOT EXISTS idx_category_daily_stats_day ON category_daily_stats (day);
+CREATE INDEX IF NOT EXISTS idx_podcasts_created_at ON podcasts (created_at);
+CREATE INDEX IF NOT EXISTS idx_user_podcasts_created_at ON user_podcasts (created_at);
+CREATE INDEX IF NOT EXISTS idx_podcast_likes_created_at ON podcast_likes (created_at);
@@ -0,0 +1,2 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deletion_requested_at timestamptz;
+CREATE INDEX IF NOT EXISTS idx_users_deletion_requested_at ON users (deletion_requested_at);
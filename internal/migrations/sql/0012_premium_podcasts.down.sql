@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_podcasts_is_premium;
+ALTER TABLE podcasts DROP COLUMN IF EXISTS is_premium;
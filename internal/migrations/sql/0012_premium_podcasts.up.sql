@@ -0,0 +1,2 @@
+ALTER TABLE podcasts ADD COLUMN IF NOT EXISTS is_premium boolean DEFAULT false;
+CREATE INDEX IF NOT EXISTS idx_podcasts_is_premium ON podcasts (is_premium);
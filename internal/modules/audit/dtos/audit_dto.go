@@ -0,0 +1,36 @@
+package audit
+
+import (
+	"encoding/json"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+)
+
+// ListAuditEventsRequestDTO filters the audit log; from and to are RFC 3339 times.
+// swagger:model ListAuditEventsRequestDTO
+type ListAuditEventsRequestDTO struct {
+	base.PaginationRequest
+	ActorID    string `query:"actor_id" validate:"omitempty,uuid" message:"actor_id must be a valid ID"`
+	Action     string `query:"action" example:"role.assigned"`
+	TargetType string `query:"target_type" example:"user"`
+	TargetID   string `query:"target_id"`
+	From       string `query:"from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00" message:"from must be an RFC 3339 time" example:"2025-01-01T00:00:00Z"`
+	To         string `query:"to" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00" message:"to must be an RFC 3339 time" example:"2025-02-01T00:00:00Z"`
+}
+
+// AuditEventDTO is one entry of the audit log.
+// swagger:model AuditEventDTO
+type AuditEventDTO struct {
+	ID         string          `json:"id" example:"abcd1234"`
+	CreatedAt  string          `json:"created_at" example:"2025-01-01T12:00:00Z"`
+	ActorID    string          `json:"actor_id,omitempty" example:"abcd1234"`
+	Action     string          `json:"action" example:"role.assigned"`
+	TargetType string          `json:"target_type,omitempty" example:"user"`
+	TargetID   string          `json:"target_id,omitempty" example:"abcd1234"`
+	IP         string          `json:"ip,omitempty" example:"203.0.113.7"`
+	UserAgent  string          `json:"user_agent,omitempty"`
+	RequestID  string          `json:"request_id,omitempty"`
+	Before     json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	After      json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+	Metadata   json.RawMessage `json:"metadata,omitempty" swaggertype:"object"`
+}
@@ -0,0 +1,31 @@
+package audit
+
+const (
+	ActionLogin           = "auth.login"
+	ActionLoginFailed     = "auth.login_failed"
+	ActionLogout          = "auth.logout"
+	ActionOTPVerified     = "auth.otp_verified"
+	ActionPasswordChanged = "user.password_changed"
+	ActionUserUnlocked    = "user.unlocked"
+	ActionUserSuspended   = "user.suspended"
+	ActionUserBanned      = "user.banned"
+	ActionUserReinstated  = "user.reinstated"
+	// ActionSuspensionEnded is recorded by the expiry job, without an actor
+	ActionSuspensionEnded = "user.suspension_ended"
+	ActionUserDeleted     = "user.deleted"
+	ActionUserPurged      = "user.purged"
+	// ActionUsersImported is recorded once an admin's CSV import has been processed
+	ActionUsersImported        = "user.imported"
+	ActionImpersonationStarted = "auth.impersonation_started"
+	// ActionImpersonatedRequest is recorded for every request made with an impersonation token
+	ActionImpersonatedRequest = "auth.impersonated_request"
+	ActionRoleAssigned        = "role.assigned"
+	ActionRoleRevoked         = "role.revoked"
+	ActionCategoryDeleted     = "category.deleted"
+)
+
+const (
+	TargetUser       = "user"
+	TargetCategory   = "category"
+	TargetUserImport = "user_import"
+)
@@ -0,0 +1,27 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditEvent records who did what to which record. The table is append-only: a trigger
+// rejects updates and deletes, so events carry no UpdatedAt or DeletedAt.
+type AuditEvent struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+	// ActorID is empty for actions taken by the system or by an unauthenticated caller
+	ActorID    *uuid.UUID `gorm:"type:uuid;index" json:"actor_id"`
+	Action     string     `gorm:"type:varchar(64);index" json:"action"`
+	TargetType string     `gorm:"type:varchar(32)" json:"target_type"`
+	TargetID   string     `gorm:"type:varchar(64)" json:"target_id"`
+	IP         string     `gorm:"type:varchar(64)" json:"ip"`
+	UserAgent  string     `gorm:"type:text" json:"user_agent"`
+	RequestID  string     `gorm:"type:varchar(64)" json:"request_id"`
+	// Before and After hold only the fields the action changed
+	Before   json.RawMessage `gorm:"type:jsonb" json:"before"`
+	After    json.RawMessage `gorm:"type:jsonb" json:"after"`
+	Metadata json.RawMessage `gorm:"type:jsonb" json:"metadata"`
+}
@@ -0,0 +1,104 @@
+package audit
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/audit/models"
+)
+
+// EventFilter narrows an audit event query; zero fields match everything
+type EventFilter struct {
+	ActorID    *uuid.UUID
+	Action     string
+	TargetType string
+	TargetID   string
+	From       *time.Time
+	To         *time.Time
+}
+
+type AuditRepository struct {
+	DB *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{
+		DB: db,
+	}
+}
+
+func (r *AuditRepository) CreateEvent(event *models.AuditEvent) error {
+	if err := r.DB.Create(event).Error; err != nil {
+		return fmt.Errorf("failed to create audit event: %w", err)
+	}
+	return nil
+}
+
+// FindEvents returns a page of matching events, newest first, with the total number of matches
+func (r *AuditRepository) FindEvents(filter EventFilter, offset, limit int) ([]models.AuditEvent, int64, error) {
+	var total int64
+	if err := r.filtered(filter).Model(&models.AuditEvent{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit events: %w", err)
+	}
+
+	var events []models.AuditEvent
+	result := r.filtered(filter).Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&events)
+	if result.Error != nil {
+		return nil, 0, fmt.Errorf("failed to fetch audit events: %w", result.Error)
+	}
+	return events, total, nil
+}
+
+// EachEventBatch passes matching events to fn in batches, newest first, without loading them all.
+// Batches continue from the last event seen, so events written meanwhile are neither repeated nor skipped.
+func (r *AuditRepository) EachEventBatch(filter EventFilter, batchSize int, fn func([]models.AuditEvent) error) error {
+	var last *models.AuditEvent
+	for {
+		query := r.filtered(filter)
+		if last != nil {
+			query = query.Where("(created_at, id) < (?, ?)", last.CreatedAt, last.ID)
+		}
+
+		var events []models.AuditEvent
+		result := query.Order("created_at DESC, id DESC").Limit(batchSize).Find(&events)
+		if result.Error != nil {
+			return fmt.Errorf("failed to fetch audit events: %w", result.Error)
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		if err := fn(events); err != nil {
+			return err
+		}
+		if len(events) < batchSize {
+			return nil
+		}
+		last = &events[len(events)-1]
+	}
+}
+
+func (r *AuditRepository) filtered(filter EventFilter) *gorm.DB {
+	query := r.DB
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	return query
+}
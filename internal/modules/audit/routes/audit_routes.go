@@ -0,0 +1,24 @@
+package audit
+
+import (
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/jwtkeys"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/middlewares"
+	auditHandler "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/audit/handlers"
+	auditRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/audit/repositories"
+	auditService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/audit/services"
+	roles "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/enums"
+)
+
+func RegisterRoutes(e *echo.Echo, db *gorm.DB) {
+	keys := jwtkeys.Default()
+
+	newAuditService := auditService.NewAuditService(auditRepository.NewAuditRepository(db))
+	newAuditHandler := auditHandler.NewAuditHandler(newAuditService)
+
+	adminAuditGroup := e.Group("/admin/audit-events", middlewares.RequirePermission(keys, roles.PermissionAuditRead))
+	adminAuditGroup.GET("", newAuditHandler.ListEvents)
+	adminAuditGroup.GET("/export", newAuditHandler.ExportEvents)
+}
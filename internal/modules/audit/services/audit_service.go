@@ -0,0 +1,251 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/config"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/logger"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/utils"
+	auditDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/audit/dtos"
+	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/audit/models"
+	auditRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/audit/repositories"
+	"github.com/google/uuid"
+)
+
+// exportBatchSize is how many events the CSV export reads from the database at a time
+const exportBatchSize = 500
+
+var exportHeader = []string{"id", "created_at", "actor_id", "action", "target_type", "target_id", "ip", "user_agent", "request_id", "before", "after", "metadata"}
+
+// Entry describes an action to record in the audit log
+type Entry struct {
+	Action string
+	// ActorID overrides the authenticated user on the context, e.g. for the user logging in
+	ActorID    *uuid.UUID
+	TargetType string
+	TargetID   string
+	// Before and After are the state of the target around the action; fields equal in both are dropped
+	Before   interface{}
+	After    interface{}
+	Metadata map[string]interface{}
+}
+
+// Record appends an event to the audit log, taking the actor, IP, user agent and request ID from
+// ctx. An action that succeeded is never failed because its event could not be written: errors
+// are logged instead.
+func Record(ctx context.Context, entry Entry) {
+	db := config.GetDB()
+	if db == nil {
+		return
+	}
+
+	event, err := newEvent(ctx, entry)
+	if err == nil {
+		// The action has already happened, so the event is written even if the client went away
+		err = auditRepository.NewAuditRepository(db.WithContext(context.WithoutCancel(ctx))).CreateEvent(event)
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to record audit event", "action", entry.Action, "target_id", entry.TargetID, "error", err)
+	}
+}
+
+func newEvent(ctx context.Context, entry Entry) (*models.AuditEvent, error) {
+	ip, userAgent, requestID, actorID := requestFromContext(ctx)
+	if entry.ActorID != nil {
+		actorID = entry.ActorID
+	}
+
+	before, after, err := diff(entry.Before, entry.After)
+	if err != nil {
+		return nil, err
+	}
+	var metadata json.RawMessage
+	if len(entry.Metadata) > 0 {
+		if metadata, err = json.Marshal(entry.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to encode audit metadata: %w", err)
+		}
+	}
+
+	return &models.AuditEvent{
+		ActorID:    actorID,
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		IP:         ip,
+		UserAgent:  userAgent,
+		RequestID:  requestID,
+		Before:     before,
+		After:      after,
+		Metadata:   metadata,
+	}, nil
+}
+
+// diff encodes before and after, and when both are objects keeps only the fields that differ
+func diff(before, after interface{}) (json.RawMessage, json.RawMessage, error) {
+	beforeJSON, err := encodeState(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	afterJSON, err := encodeState(after)
+	if err != nil {
+		return nil, nil, err
+	}
+	if beforeJSON == nil || afterJSON == nil {
+		return beforeJSON, afterJSON, nil
+	}
+
+	var beforeFields, afterFields map[string]json.RawMessage
+	if json.Unmarshal(beforeJSON, &beforeFields) != nil || json.Unmarshal(afterJSON, &afterFields) != nil {
+		return beforeJSON, afterJSON, nil
+	}
+	for field, value := range beforeFields {
+		if other, ok := afterFields[field]; ok && bytes.Equal(value, other) {
+			delete(beforeFields, field)
+			delete(afterFields, field)
+		}
+	}
+
+	if beforeJSON, err = json.Marshal(beforeFields); err != nil {
+		return nil, nil, fmt.Errorf("failed to encode audit state: %w", err)
+	}
+	if afterJSON, err = json.Marshal(afterFields); err != nil {
+		return nil, nil, fmt.Errorf("failed to encode audit state: %w", err)
+	}
+	return beforeJSON, afterJSON, nil
+}
+
+func encodeState(state interface{}) (json.RawMessage, error) {
+	if state == nil {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit state: %w", err)
+	}
+	return encoded, nil
+}
+
+type AuditService struct {
+	AuditRepo *auditRepository.AuditRepository
+}
+
+func NewAuditService(auditRepo *auditRepository.AuditRepository) *AuditService {
+	return &AuditService{
+		AuditRepo: auditRepo,
+	}
+}
+
+func (s *AuditService) ListEvents(req auditDTO.ListAuditEventsRequestDTO) base.Response {
+	filter, err := NewEventFilter(req)
+	if err != nil {
+		return base.SetErrorMessage("Invalid filter", err.Error())
+	}
+
+	events, total, err := s.AuditRepo.FindEvents(filter, (req.Page-1)*req.PerPage, req.PerPage)
+	if err != nil {
+		response := base.SetErrorMessage("Failed to fetch audit events", err)
+		response.HTTPStatus = http.StatusInternalServerError
+		return response
+	}
+
+	items := make([]interface{}, len(events))
+	for i, event := range events {
+		items[i] = mapAuditEventDTO(event)
+	}
+	return base.SetPaginatedResponse(items, req.Page, req.PerPage, int(total))
+}
+
+// ExportEvents writes every event matching filter to w as CSV, newest first
+func (s *AuditService) ExportEvents(filter auditRepository.EventFilter, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(exportHeader); err != nil {
+		return fmt.Errorf("failed to write audit export: %w", err)
+	}
+
+	err := s.AuditRepo.EachEventBatch(filter, exportBatchSize, func(events []models.AuditEvent) error {
+		for _, event := range events {
+			if err := writer.Write(exportRow(mapAuditEventDTO(event))); err != nil {
+				return fmt.Errorf("failed to write audit export: %w", err)
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// NewEventFilter turns the query parameters of a list or export request into a filter
+func NewEventFilter(req auditDTO.ListAuditEventsRequestDTO) (auditRepository.EventFilter, error) {
+	filter := auditRepository.EventFilter{
+		Action:     req.Action,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+	}
+
+	if req.ActorID != "" {
+		actorID, err := uuid.Parse(req.ActorID)
+		if err != nil {
+			return filter, fmt.Errorf("invalid actor_id: %w", err)
+		}
+		filter.ActorID = &actorID
+	}
+	if req.From != "" {
+		from, err := time.Parse(time.RFC3339, req.From)
+		if err != nil {
+			return filter, fmt.Errorf("invalid from: %w", err)
+		}
+		filter.From = &from
+	}
+	if req.To != "" {
+		to, err := time.Parse(time.RFC3339, req.To)
+		if err != nil {
+			return filter, fmt.Errorf("invalid to: %w", err)
+		}
+		filter.To = &to
+	}
+	return filter, nil
+}
+
+func mapAuditEventDTO(event models.AuditEvent) auditDTO.AuditEventDTO {
+	dto := auditDTO.AuditEventDTO{
+		ID:         event.ID.String(),
+		CreatedAt:  event.CreatedAt.UTC().Format(time.RFC3339),
+		Action:     event.Action,
+		TargetType: event.TargetType,
+		TargetID:   event.TargetID,
+		IP:         event.IP,
+		UserAgent:  event.UserAgent,
+		RequestID:  event.RequestID,
+		Before:     event.Before,
+		After:      event.After,
+		Metadata:   event.Metadata,
+	}
+	if event.ActorID != nil {
+		dto.ActorID = event.ActorID.String()
+	}
+	return dto
+}
+
+func exportRow(event auditDTO.AuditEventDTO) []string {
+	row := []string{
+		event.ID, event.CreatedAt, event.ActorID, event.Action, event.TargetType, event.TargetID,
+		event.IP, event.UserAgent, event.RequestID, string(event.Before), string(event.After), string(event.Metadata),
+	}
+	for i, value := range row {
+		row[i] = utils.SpreadsheetSafe(value)
+	}
+	return row
+}
@@ -0,0 +1,102 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	auditDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/audit/dtos"
+	audit "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/audit/enums"
+	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/audit/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff_KeepsOnlyChangedFields(t *testing.T) {
+	before, after, err := diff(
+		map[string]interface{}{"name": "News", "description": "Daily", "is_news_intensive": true},
+		map[string]interface{}{"name": "Politics", "description": "Daily", "is_news_intensive": false},
+	)
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name": "News", "is_news_intensive": true}`, string(before))
+	assert.JSONEq(t, `{"name": "Politics", "is_news_intensive": false}`, string(after))
+}
+
+func TestDiff_OneSidedState(t *testing.T) {
+	before, after, err := diff(map[string]interface{}{"name": "News"}, nil)
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name": "News"}`, string(before))
+	assert.Nil(t, after)
+}
+
+func TestNewEvent_TakesRequestDetailsFromContext(t *testing.T) {
+	adminID := uuid.New()
+	ctx := WithRequest(context.Background(), "203.0.113.7", "curl/8.0", "req-1")
+	SetActor(ctx, adminID)
+
+	event, err := newEvent(ctx, Entry{Action: audit.ActionRoleAssigned, TargetType: audit.TargetUser, TargetID: "u1"})
+
+	require.NoError(t, err)
+	require.NotNil(t, event.ActorID)
+	assert.Equal(t, adminID, *event.ActorID)
+	assert.Equal(t, "203.0.113.7", event.IP)
+	assert.Equal(t, "curl/8.0", event.UserAgent)
+	assert.Equal(t, "req-1", event.RequestID)
+	assert.Nil(t, event.Metadata)
+}
+
+func TestNewEvent_ExplicitActorWins(t *testing.T) {
+	userID := uuid.New()
+
+	event, err := newEvent(context.Background(), Entry{
+		Action:   audit.ActionLogin,
+		ActorID:  &userID,
+		Metadata: map[string]interface{}{"method": "password"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, userID, *event.ActorID)
+	assert.Empty(t, event.IP)
+	assert.JSONEq(t, `{"method": "password"}`, string(event.Metadata))
+}
+
+func TestNewEventFilter_RejectsBadTimes(t *testing.T) {
+	_, err := NewEventFilter(auditDTO.ListAuditEventsRequestDTO{From: "2025-01-01"})
+	assert.Error(t, err)
+
+	filter, err := NewEventFilter(auditDTO.ListAuditEventsRequestDTO{From: "2025-01-01T00:00:00Z", To: "2025-02-01T00:00:00+03:00"})
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), filter.From.UTC())
+	assert.Equal(t, time.Date(2025, 1, 31, 21, 0, 0, 0, time.UTC), filter.To.UTC())
+}
+
+func TestExportRow_NeutralizesSpreadsheetFormulas(t *testing.T) {
+	event := models.AuditEvent{
+		ID:        uuid.New(),
+		CreatedAt: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+		Action:    audit.ActionLogin,
+		UserAgent: "=HYPERLINK(\"http://evil.example\")",
+		Metadata:  json.RawMessage(`{"method":"password"}`),
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	require.NoError(t, writer.Write(exportRow(mapAuditEventDTO(event))))
+	writer.Flush()
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	row := records[0]
+	require.Len(t, row, len(exportHeader))
+	assert.Equal(t, "2025-01-01T12:00:00Z", row[1])
+	assert.Equal(t, "", row[2])
+	assert.Equal(t, "'=HYPERLINK(\"http://evil.example\")", row[7])
+	assert.Equal(t, `{"method":"password"}`, row[11])
+}
@@ -0,0 +1,44 @@
+package audit
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+type contextKey struct{}
+
+// requestInfo describes who is behind a request. The actor is filled in by the auth
+// middlewares, after the audit middleware has already stored the request details.
+type requestInfo struct {
+	mu        sync.RWMutex
+	ip        string
+	userAgent string
+	requestID string
+	actorID   *uuid.UUID
+}
+
+// WithRequest returns a context whose audit events carry the caller's IP, user agent and request ID
+func WithRequest(ctx context.Context, ip, userAgent, requestID string) context.Context {
+	return context.WithValue(ctx, contextKey{}, &requestInfo{ip: ip, userAgent: userAgent, requestID: requestID})
+}
+
+// SetActor records the authenticated user as the actor of audit events recorded with ctx
+func SetActor(ctx context.Context, userID uuid.UUID) {
+	if info, ok := ctx.Value(contextKey{}).(*requestInfo); ok {
+		info.mu.Lock()
+		info.actorID = &userID
+		info.mu.Unlock()
+	}
+}
+
+func requestFromContext(ctx context.Context) (ip, userAgent, requestID string, actorID *uuid.UUID) {
+	info, ok := ctx.Value(contextKey{}).(*requestInfo)
+	if !ok {
+		return "", "", "", nil
+	}
+	info.mu.RLock()
+	defer info.mu.RUnlock()
+	return info.ip, info.userAgent, info.requestID, info.actorID
+}
@@ -0,0 +1,51 @@
+package categories
+
+// CategoryAnalyticsRequestDTO picks the UTC days to report on; both ends are included
+type CategoryAnalyticsRequestDTO struct {
+	From string `query:"from" validate:"omitempty,datetime=2006-01-02" message:"from must be a date such as 2025-01-31" example:"2025-01-01"`
+	To   string `query:"to" validate:"omitempty,datetime=2006-01-02" message:"to must be a date such as 2025-01-31" example:"2025-01-31"`
+}
+
+// CategoryStatsDTO is a category's activity over a range of days, or on a single day.
+// Rates are between 0 and 1.
+type CategoryStatsDTO struct {
+	// Followers is the latest follower count in the range, and FollowersChange how it moved since the first
+	Followers         *int64  `json:"followers"`
+	FollowersChange   *int64  `json:"followers_change,omitempty"`
+	PodcastsPublished int64   `json:"podcasts_published"`
+	Plays             int64   `json:"plays"`
+	Completions       int64   `json:"completions"`
+	CompletionRate    float64 `json:"completion_rate"`
+	Likes             int64   `json:"likes"`
+	AvgListenThrough  float64 `json:"avg_listen_through"`
+}
+
+// CategoryAnalyticsDTO is one category's stats over the requested range
+type CategoryAnalyticsDTO struct {
+	CategoryID string  `json:"category_id"`
+	Name       string  `json:"name"`
+	ParentID   *string `json:"parent_id,omitempty"`
+	IsActive   bool    `json:"is_active"`
+	CategoryStatsDTO
+}
+
+// CategoryDailyStatsDTO is a category's stats on one day
+type CategoryDailyStatsDTO struct {
+	Day string `json:"day" example:"2025-01-31"`
+	CategoryStatsDTO
+}
+
+// CategoryAnalyticsReportDTO is the stats of a category over the range, followed by each day's
+type CategoryAnalyticsReportDTO struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	CategoryAnalyticsDTO
+	Days []CategoryDailyStatsDTO `json:"days"`
+}
+
+// CategoryAnalyticsListDTO is the stats of every category over the range
+type CategoryAnalyticsListDTO struct {
+	From       string                 `json:"from"`
+	To         string                 `json:"to"`
+	Categories []CategoryAnalyticsDTO `json:"categories"`
+}
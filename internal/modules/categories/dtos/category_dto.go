@@ -1,8 +1,35 @@
 package categories
 
+// Category is both the body for creating or updating a category and how one is returned.
+// On update, fields left out are unchanged, and an empty parent_id makes the category top-level.
 type Category struct {
-	ID              string `json:"id"`
-	Name            string `json:"name"`
-	Description     string `json:"description"`
-	IsNewsIntensive bool   `json:"is_news_intensive"`
+	ID              string  `json:"id"`
+	Name            string  `json:"name"`
+	NameAr          string  `json:"name_ar" validate:"omitempty,max=100"`
+	NameEn          string  `json:"name_en" validate:"omitempty,max=100"`
+	Description     string  `json:"description"`
+	IsNewsIntensive bool    `json:"is_news_intensive"`
+	ParentID        *string `json:"parent_id,omitempty" validate:"omitempty,uuid" message:"parent_id must be a valid ID"`
+	SortOrder       *int    `json:"sort_order,omitempty"`
+	Icon            string  `json:"icon" validate:"omitempty,max=255"`
+	Color           string  `json:"color" validate:"omitempty,hexcolor" message:"color must be a hex colour such as #1E88E5"`
+	IsActive        *bool   `json:"is_active,omitempty"`
+	// Children are the subcategories, in display order
+	Children []Category `json:"children,omitempty"`
+}
+
+// CategoryDeletionDTO counts what deleting a category moves to the target category, or, without
+// one, what it would leave behind.
+type CategoryDeletionDTO struct {
+	CategoryID       string `json:"category_id"`
+	TargetID         string `json:"target_id,omitempty"`
+	Podcasts         int64  `json:"podcasts"`
+	ListeningHistory int64  `json:"listening_history"`
+	Likes            int64  `json:"likes"`
+	Followers        int64  `json:"followers"`
+	// SharedFollowers already follow the target, so they are not added to it again
+	SharedFollowers int64 `json:"shared_followers"`
+	Subcategories   int64 `json:"subcategories"`
+	// TargetRequired is set when the category is in use, so it can only be deleted into a target
+	TargetRequired bool `json:"target_required"`
 }
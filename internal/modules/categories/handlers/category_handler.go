@@ -8,11 +8,12 @@ import (
 )
 
 type CategoryHandler struct {
-	CategoryService *categoryService.CategoryService
+	CategoryService  *categoryService.CategoryService
+	AnalyticsService *categoryService.CategoryAnalyticsService
 }
 
-func NewCategoryHandler(categoryService *categoryService.CategoryService) *CategoryHandler {
-	return &CategoryHandler{CategoryService: categoryService}
+func NewCategoryHandler(categoryService *categoryService.CategoryService, analyticsService *categoryService.CategoryAnalyticsService) *CategoryHandler {
+	return &CategoryHandler{CategoryService: categoryService, AnalyticsService: analyticsService}
 }
 
 func (h *CategoryHandler) GetCategories(c echo.Context) error {
@@ -20,6 +21,11 @@ func (h *CategoryHandler) GetCategories(c echo.Context) error {
 	return c.JSON(response.HTTPStatus, response)
 }
 
+func (h *CategoryHandler) GetAdminCategories(c echo.Context) error {
+	response := h.CategoryService.GetAdminCategories()
+	return c.JSON(response.HTTPStatus, response)
+}
+
 func (h *CategoryHandler) CreateCategory(c echo.Context) error {
 	var category categoryDTO.Category
 	if res, ok := base.BindAndValidate(c, &category); !ok {
@@ -41,9 +47,34 @@ func (h *CategoryHandler) UpdateCategory(c echo.Context) error {
 	return c.JSON(response.HTTPStatus, response)
 }
 
+func (h *CategoryHandler) PreviewCategoryDeletion(c echo.Context) error {
+	response := h.CategoryService.PreviewCategoryDeletion(c.Param("id"), c.QueryParam("target_id"))
+	return c.JSON(response.HTTPStatus, response)
+}
+
 func (h *CategoryHandler) DeleteCategory(c echo.Context) error {
 	categoryID := c.Param("id")
 
-	response := h.CategoryService.DeleteCategory(categoryID)
+	response := h.CategoryService.DeleteCategory(c.Request().Context(), categoryID, c.QueryParam("target_id"))
+	return c.JSON(response.HTTPStatus, response)
+}
+
+func (h *CategoryHandler) ListCategoryAnalytics(c echo.Context) error {
+	var req categoryDTO.CategoryAnalyticsRequestDTO
+	if res, ok := base.BindAndValidate(c, &req); !ok {
+		return c.JSON(res.HTTPStatus, res)
+	}
+
+	response := h.AnalyticsService.ListCategoryAnalytics(req)
+	return c.JSON(response.HTTPStatus, response)
+}
+
+func (h *CategoryHandler) GetCategoryAnalytics(c echo.Context) error {
+	var req categoryDTO.CategoryAnalyticsRequestDTO
+	if res, ok := base.BindAndValidate(c, &req); !ok {
+		return c.JSON(res.HTTPStatus, res)
+	}
+
+	response := h.AnalyticsService.GetCategoryAnalytics(c.Param("id"), req)
 	return c.JSON(response.HTTPStatus, response)
 }
@@ -2,11 +2,22 @@ package categories
 
 import (
 	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	"github.com/google/uuid"
 )
 
 type Category struct {
 	base.Model
 	Name            string `gorm:"type:varchar(100);uniqueIndex" json:"name"`
+	NameAr          string `gorm:"type:varchar(100)" json:"name_ar"`
+	NameEn          string `gorm:"type:varchar(100)" json:"name_en"`
 	Description     string `gorm:"type:varchar(255)" json:"description"`
 	IsNewsIntensive bool   `gorm:"type:bool;default:true" json:"is_news_intensive"`
+
+	// ParentID is set on subcategories; following a parent implies following its children
+	ParentID  *uuid.UUID `gorm:"type:uuid;index" json:"parent_id,omitempty"`
+	SortOrder int        `gorm:"default:0" json:"sort_order"`
+	Icon      string     `gorm:"type:varchar(255)" json:"icon"`
+	Color     string     `gorm:"type:varchar(9)" json:"color"`
+	// IsActive is false for hidden categories, which are left out of listings and recommendations
+	IsActive bool `gorm:"type:bool;default:true" json:"is_active"`
 }
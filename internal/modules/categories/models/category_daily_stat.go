@@ -0,0 +1,28 @@
+package categories
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CategoryDailyStat is the nightly rollup of one category's activity on one UTC day, so analytics
+// are read from here rather than by scanning podcasts, listening history and likes
+type CategoryDailyStat struct {
+	CategoryID uuid.UUID `gorm:"type:uuid;primaryKey" json:"category_id"`
+	Day        time.Time `gorm:"type:date;primaryKey;index" json:"day"`
+	// Followers is the follower count at the end of the day. It is nil for days rolled up later,
+	// since follows are not timestamped and the count can only be taken as the day ends.
+	Followers         *int64 `json:"followers"`
+	PodcastsPublished int64  `gorm:"not null;default:0" json:"podcasts_published"`
+	// Plays counts listeners who started one of the category's podcasts for the first time that day,
+	// and Completions how many of them have since finished it
+	Plays       int64 `gorm:"not null;default:0" json:"plays"`
+	Completions int64 `gorm:"not null;default:0" json:"completions"`
+	Likes       int64 `gorm:"not null;default:0" json:"likes"`
+	// ListenThroughSum adds up how far into the podcast each play got, from 0 to 1, over the
+	// ListenThroughCount plays whose podcast duration is known
+	ListenThroughSum   float64   `gorm:"not null;default:0" json:"listen_through_sum"`
+	ListenThroughCount int64     `gorm:"not null;default:0" json:"listen_through_count"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
@@ -1,6 +1,9 @@
 package categories
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
@@ -9,6 +12,8 @@ import (
 	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/categories/models"
 )
 
+var ErrCategoryInUse = errors.New("category still has podcasts, listeners, followers or subcategories")
+
 type CategoryRepository struct {
 	DB *gorm.DB
 }
@@ -19,9 +24,15 @@ func NewCategoryRepository(db *gorm.DB) *CategoryRepository {
 	}
 }
 
+// WithContext returns a repository whose queries run under ctx, so they are traced as part of the request
+func (r *CategoryRepository) WithContext(ctx context.Context) *CategoryRepository {
+	return &CategoryRepository{DB: r.DB.WithContext(ctx)}
+}
+
+// FindAllCategories returns every category, hidden ones included, in display order
 func (r *CategoryRepository) FindAllCategories() ([]models.Category, error) {
 	var categories []models.Category
-	result := r.DB.Find(&categories)
+	result := r.DB.Order("sort_order, name").Find(&categories)
 	if result.Error != nil {
 		return nil, fmt.Errorf("failed to fetch categories: %w", result.Error)
 	}
@@ -40,11 +51,64 @@ func (r *CategoryRepository) FindCategoryByID(categoryID uuid.UUID) (*models.Cat
 	return &category, nil
 }
 
-func (r *CategoryRepository) CreateCategory(category *models.Category) (*models.Category, error) {
-	result := r.DB.Create(category)
+// FindCategoriesByIDs returns the categories among ids that exist
+func (r *CategoryRepository) FindCategoriesByIDs(ids []uuid.UUID) ([]models.Category, error) {
+	var categories []models.Category
+	if len(ids) == 0 {
+		return categories, nil
+	}
+	result := r.DB.Where("id IN ?", ids).Find(&categories)
 	if result.Error != nil {
-		return nil, fmt.Errorf("failed to create category: %w", result.Error)
+		return nil, fmt.Errorf("failed to fetch categories: %w", result.Error)
 	}
+	return categories, nil
+}
+
+// CountChildren returns how many subcategories the category has
+func (r *CategoryRepository) CountChildren(categoryID uuid.UUID) (int64, error) {
+	var count int64
+	result := r.DB.Model(&models.Category{}).Where("parent_id = ?", categoryID).Count(&count)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to count subcategories: %w", result.Error)
+	}
+	return count, nil
+}
+
+// FindActiveCategoryIDsWithChildren expands followed categories to include their subcategories,
+// leaving out hidden categories and the subcategories of hidden parents
+func (r *CategoryRepository) FindActiveCategoryIDsWithChildren(ids []uuid.UUID) ([]uuid.UUID, error) {
+	expanded := []uuid.UUID{}
+	if len(ids) == 0 {
+		return expanded, nil
+	}
+
+	result := r.DB.Model(&models.Category{}).
+		Where("is_active").
+		Where("id IN @ids OR parent_id IN @ids", sql.Named("ids", ids)).
+		Where("parent_id IS NULL OR parent_id IN (?)", r.DB.Model(&models.Category{}).Select("id").Where("is_active")).
+		Pluck("id", &expanded)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to expand categories: %w", result.Error)
+	}
+	return expanded, nil
+}
+
+func (r *CategoryRepository) CreateCategory(category *models.Category) (*models.Category, error) {
+	// Create leaves out false flags, so their columns take the default of true; they are written afterwards
+	isActive, isNewsIntensive := category.IsActive, category.IsNewsIntensive
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(category).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Category{}).Where("id = ?", category.ID).Updates(map[string]interface{}{
+			"is_active":         isActive,
+			"is_news_intensive": isNewsIntensive,
+		}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create category: %w", err)
+	}
+	category.IsActive, category.IsNewsIntensive = isActive, isNewsIntensive
 	return category, nil
 }
 
@@ -56,10 +120,94 @@ func (r *CategoryRepository) UpdateCategory(category *models.Category) error {
 	return nil
 }
 
-func (r *CategoryRepository) DeleteCategory(categoryID uuid.UUID) error {
-	result := r.DB.Delete(&models.Category{}, categoryID)
-	if result.Error != nil {
-		return fmt.Errorf("failed to delete category: %w", result.Error)
+// CategoryUsage counts what still refers to a category
+type CategoryUsage struct {
+	Podcasts         int64
+	ListeningHistory int64
+	Likes            int64
+	Followers        int64
+	// SharedFollowers follow both the category and the one it is merged into
+	SharedFollowers int64
+	Subcategories   int64
+}
+
+// InUse reports whether deleting the category would leave anything pointing at it
+func (u CategoryUsage) InUse() bool {
+	return u.Podcasts+u.ListeningHistory+u.Likes+u.Followers+u.Subcategories > 0
+}
+
+// FindCategoryUsage counts the podcasts, listening history, likes, followers and subcategories of a
+// category, and how many of its followers already follow targetID, which may be uuid.Nil
+func (r *CategoryRepository) FindCategoryUsage(categoryID, targetID uuid.UUID) (*CategoryUsage, error) {
+	var usage CategoryUsage
+	if err := categoryUsageQuery(r.DB, categoryID, targetID).Scan(&usage).Error; err != nil {
+		return nil, fmt.Errorf("failed to count category usage: %w", err)
 	}
-	return nil
+	return &usage, nil
+}
+
+func categoryUsageQuery(db *gorm.DB, categoryID, targetID uuid.UUID) *gorm.DB {
+	return db.Raw(`
+		SELECT
+			(SELECT COUNT(*) FROM podcasts WHERE category_id = @category) AS podcasts,
+			(SELECT COUNT(*) FROM user_podcasts WHERE category_id = @category) AS listening_history,
+			(SELECT COUNT(*) FROM podcast_likes WHERE category_id = @category) AS likes,
+			(SELECT COUNT(*) FROM user_categories WHERE category_id = @category) AS followers,
+			(SELECT COUNT(*) FROM user_categories followed
+				JOIN user_categories target ON target.user_id = followed.user_id AND target.category_id = @target
+				WHERE followed.category_id = @category) AS shared_followers,
+			(SELECT COUNT(*) FROM categories WHERE parent_id = @category AND deleted_at IS NULL) AS subcategories`,
+		sql.Named("category", categoryID),
+		sql.Named("target", targetID),
+	)
+}
+
+// DeleteCategory deletes a category in one transaction. With a target, podcasts, listening
+// history and likes are moved to it, followers follow it instead and subcategories move under it;
+// without one, the category must not be in use. It returns what was moved.
+func (r *CategoryRepository) DeleteCategory(categoryID uuid.UUID, targetID *uuid.UUID) (*CategoryUsage, error) {
+	var usage CategoryUsage
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		target := uuid.Nil
+		if targetID != nil {
+			target = *targetID
+		}
+		if err := categoryUsageQuery(tx, categoryID, target).Scan(&usage).Error; err != nil {
+			return err
+		}
+
+		if targetID == nil {
+			if usage.InUse() {
+				return ErrCategoryInUse
+			}
+		} else {
+			// Rows of deleted podcasts and history are moved too, so none is left pointing at the category
+			for _, table := range []string{"podcasts", "user_podcasts", "podcast_likes"} {
+				if err := tx.Exec(fmt.Sprintf("UPDATE %s SET category_id = ? WHERE category_id = ?", table), target, categoryID).Error; err != nil {
+					return err
+				}
+			}
+			if err := tx.Exec(
+				"INSERT INTO user_categories (user_id, category_id) SELECT user_id, ? FROM user_categories WHERE category_id = ? ON CONFLICT DO NOTHING",
+				target, categoryID,
+			).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Category{}).Where("parent_id = ?", categoryID).Update("parent_id", target).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Exec("DELETE FROM user_categories WHERE category_id = ?", categoryID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Category{}, categoryID).Error
+	})
+	if errors.Is(err, ErrCategoryInUse) {
+		return &usage, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete category: %w", err)
+	}
+	return &usage, nil
 }
@@ -0,0 +1,167 @@
+package categories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/categories/models"
+)
+
+type CategoryStatsRepository struct {
+	DB *gorm.DB
+}
+
+func NewCategoryStatsRepository(db *gorm.DB) *CategoryStatsRepository {
+	return &CategoryStatsRepository{
+		DB: db,
+	}
+}
+
+// WithContext returns a repository whose queries run under ctx, so they are traced as part of the request
+func (r *CategoryStatsRepository) WithContext(ctx context.Context) *CategoryStatsRepository {
+	return &CategoryStatsRepository{DB: r.DB.WithContext(ctx)}
+}
+
+// CategoryStatsTotal adds up a category's daily stats over a range of days
+type CategoryStatsTotal struct {
+	CategoryID         uuid.UUID
+	FirstFollowers     *int64
+	LastFollowers      *int64
+	PodcastsPublished  int64
+	Plays              int64
+	Completions        int64
+	Likes              int64
+	ListenThroughSum   float64
+	ListenThroughCount int64
+}
+
+// HasFollowerSnapshot reports whether the rollup of the given day already took the follower counts
+func (r *CategoryStatsRepository) HasFollowerSnapshot(day time.Time) (bool, error) {
+	var count int64
+	result := r.DB.Model(&models.CategoryDailyStat{}).Where("day = ? AND followers IS NOT NULL", day.Format(time.DateOnly)).Limit(1).Count(&count)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to check category stats of %s: %w", day.Format(time.DateOnly), result.Error)
+	}
+	return count > 0, nil
+}
+
+// RollUpDays recomputes the stats of every category for each UTC day from `from` to `to`, both
+// included. Follower counts are taken now and recorded for the last day only when snapshotFollowers
+// is set; other days keep the counts they already have.
+func (r *CategoryStatsRepository) RollUpDays(from, to time.Time, snapshotFollowers bool) error {
+	result := r.DB.Exec(`
+		WITH days AS (
+			SELECT generate_series(CAST(@from AS date), CAST(@to AS date), interval '1 day')::date AS day
+		),
+		published AS (
+			SELECT category_id, (created_at AT TIME ZONE 'UTC')::date AS day, COUNT(*) AS podcasts
+			FROM podcasts
+			WHERE created_at >= @start AND created_at < @end AND deleted_at IS NULL
+			GROUP BY 1, 2
+		),
+		plays AS (
+			SELECT up.category_id, (up.created_at AT TIME ZONE 'UTC')::date AS day,
+				COUNT(*) AS plays,
+				COUNT(*) FILTER (WHERE up.is_completed) AS completions,
+				SUM(listen_through.ratio) AS listen_through_sum,
+				COUNT(listen_through.ratio) AS listen_through_count
+			FROM user_podcasts up
+			LEFT JOIN podcasts p ON p.id = up.podcast_id
+			CROSS JOIN LATERAL (
+				SELECT CASE WHEN up.is_completed THEN 1
+					ELSE LEAST(up.resume_position::float8 / NULLIF(p.duration, 0), 1) END AS ratio
+			) listen_through
+			WHERE up.created_at >= @start AND up.created_at < @end AND up.deleted_at IS NULL
+			GROUP BY 1, 2
+		),
+		likes AS (
+			SELECT category_id, (created_at AT TIME ZONE 'UTC')::date AS day, SUM(count) AS likes
+			FROM podcast_likes
+			WHERE created_at >= @start AND created_at < @end AND deleted_at IS NULL
+			GROUP BY 1, 2
+		),
+		followers AS (
+			SELECT category_id, COUNT(*) AS followers FROM user_categories GROUP BY 1
+		)
+		INSERT INTO category_daily_stats (category_id, day, followers, podcasts_published, plays, completions,
+			likes, listen_through_sum, listen_through_count, updated_at)
+		SELECT c.id, days.day,
+			CASE WHEN @snapshot AND days.day = CAST(@to AS date) THEN COALESCE(followers.followers, 0) END,
+			COALESCE(published.podcasts, 0),
+			COALESCE(plays.plays, 0),
+			COALESCE(plays.completions, 0),
+			COALESCE(likes.likes, 0),
+			COALESCE(plays.listen_through_sum, 0),
+			COALESCE(plays.listen_through_count, 0),
+			NOW()
+		FROM categories c
+		CROSS JOIN days
+		LEFT JOIN published ON published.category_id = c.id AND published.day = days.day
+		LEFT JOIN plays ON plays.category_id = c.id AND plays.day = days.day
+		LEFT JOIN likes ON likes.category_id = c.id AND likes.day = days.day
+		LEFT JOIN followers ON followers.category_id = c.id
+		WHERE c.deleted_at IS NULL
+		ON CONFLICT (category_id, day) DO UPDATE SET
+			followers = COALESCE(EXCLUDED.followers, category_daily_stats.followers),
+			podcasts_published = EXCLUDED.podcasts_published,
+			plays = EXCLUDED.plays,
+			completions = EXCLUDED.completions,
+			likes = EXCLUDED.likes,
+			listen_through_sum = EXCLUDED.listen_through_sum,
+			listen_through_count = EXCLUDED.listen_through_count,
+			updated_at = EXCLUDED.updated_at`,
+		sql.Named("from", from.Format(time.DateOnly)),
+		sql.Named("to", to.Format(time.DateOnly)),
+		sql.Named("start", from),
+		sql.Named("end", to.AddDate(0, 0, 1)),
+		sql.Named("snapshot", snapshotFollowers),
+	)
+	if result.Error != nil {
+		return fmt.Errorf("failed to roll up category stats: %w", result.Error)
+	}
+	return nil
+}
+
+// SumStats adds up the stats of every category, or only of categoryID when given, from `from` to
+// `to`, both included. Categories without stats in the range are left out.
+func (r *CategoryStatsRepository) SumStats(from, to time.Time, categoryID *uuid.UUID) ([]CategoryStatsTotal, error) {
+	query := r.DB.Model(&models.CategoryDailyStat{}).
+		Select(`category_id,
+			(ARRAY_AGG(followers ORDER BY day) FILTER (WHERE followers IS NOT NULL))[1] AS first_followers,
+			(ARRAY_AGG(followers ORDER BY day DESC) FILTER (WHERE followers IS NOT NULL))[1] AS last_followers,
+			SUM(podcasts_published) AS podcasts_published,
+			SUM(plays) AS plays,
+			SUM(completions) AS completions,
+			SUM(likes) AS likes,
+			SUM(listen_through_sum) AS listen_through_sum,
+			SUM(listen_through_count) AS listen_through_count`).
+		Where("day BETWEEN ? AND ?", from.Format(time.DateOnly), to.Format(time.DateOnly)).
+		Group("category_id")
+	if categoryID != nil {
+		query = query.Where("category_id = ?", *categoryID)
+	}
+
+	var totals []CategoryStatsTotal
+	if err := query.Scan(&totals).Error; err != nil {
+		return nil, fmt.Errorf("failed to sum category stats: %w", err)
+	}
+	return totals, nil
+}
+
+// FindDailyStats returns a category's stats for each rolled up day from `from` to `to`, oldest first
+func (r *CategoryStatsRepository) FindDailyStats(categoryID uuid.UUID, from, to time.Time) ([]models.CategoryDailyStat, error) {
+	var stats []models.CategoryDailyStat
+	result := r.DB.
+		Where("category_id = ? AND day BETWEEN ? AND ?", categoryID, from.Format(time.DateOnly), to.Format(time.DateOnly)).
+		Order("day").
+		Find(&stats)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to fetch daily stats of category %s: %w", categoryID, result.Error)
+	}
+	return stats, nil
+}
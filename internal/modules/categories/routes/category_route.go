@@ -4,21 +4,37 @@ import (
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
 
+	"github.com/Al-Khaimah/khaimah-golang-backend/config"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/jobs"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/jwtkeys"
 	middlewares "github.com/Al-Khaimah/khaimah-golang-backend/internal/middlewares"
 	categoryHandler "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/categories/handlers"
 	categoryRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/categories/repositories"
 	categoryService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/categories/services"
+	roles "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/enums"
 )
 
-func RegisterRoutes(e *echo.Echo, db *gorm.DB) {
+func RegisterRoutes(e *echo.Echo, db *gorm.DB, cfg *config.Config) {
+	keys := jwtkeys.Default()
+
 	newCategoryRepository := categoryRepository.NewCategoryRepository(db)
 	newCategoryService := categoryService.NewCategoryService(newCategoryRepository)
-	newCategoryHandler := categoryHandler.NewCategoryHandler(newCategoryService)
+	newCategoryAnalyticsService := categoryService.NewCategoryAnalyticsService(newCategoryRepository, categoryRepository.NewCategoryStatsRepository(db))
+	newCategoryHandler := categoryHandler.NewCategoryHandler(newCategoryService, newCategoryAnalyticsService)
+
+	jobs.Register(categoryService.CategoryStatsJobName, newCategoryAnalyticsService.RollUpCategoryStats)
+	jobs.Every(categoryService.CategoryStatsInterval, categoryService.CategoryStatsJobName, "")
 
 	e.GET("/categories", newCategoryHandler.GetCategories)
 
-	adminCategoryGroup := e.Group("/admin/categories", middlewares.AdminMiddleware())
+	adminCategoryGroup := e.Group("/admin/categories", middlewares.RequirePermission(keys, roles.PermissionCategoriesWrite))
+	adminCategoryGroup.GET("/", newCategoryHandler.GetAdminCategories)
 	adminCategoryGroup.POST("/", newCategoryHandler.CreateCategory)
 	adminCategoryGroup.PUT("/:id", newCategoryHandler.UpdateCategory)
+	adminCategoryGroup.GET("/:id/deletion-preview", newCategoryHandler.PreviewCategoryDeletion)
 	adminCategoryGroup.DELETE("/:id", newCategoryHandler.DeleteCategory)
+
+	adminAnalyticsGroup := e.Group("/admin/categories", middlewares.RequirePermission(keys, roles.PermissionAnalyticsRead))
+	adminAnalyticsGroup.GET("/analytics", newCategoryHandler.ListCategoryAnalytics)
+	adminAnalyticsGroup.GET("/:id/analytics", newCategoryHandler.GetCategoryAnalytics)
 }
@@ -0,0 +1,219 @@
+package categories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	base "github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/logger"
+	categoryDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/categories/dtos"
+	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/categories/models"
+	categoryRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/categories/repositories"
+	"github.com/google/uuid"
+)
+
+const (
+	CategoryStatsJobName = "category-stats-rollup"
+	// CategoryStatsInterval is how often the rollup job checks for a finished day to roll up. It
+	// rolls each day up once, shortly after midnight UTC, or at startup if that run was missed.
+	CategoryStatsInterval = time.Hour
+
+	// categoryStatsWindow is how many days each rollup recomputes, so listens completed and podcasts
+	// liked in the days after are still counted
+	categoryStatsWindow = 7
+	// defaultAnalyticsDays is the range reported when none is given, ending with yesterday
+	defaultAnalyticsDays = 30
+	maxAnalyticsDays     = 366
+)
+
+type CategoryAnalyticsService struct {
+	CategoryRepo *categoryRepository.CategoryRepository
+	StatsRepo    *categoryRepository.CategoryStatsRepository
+}
+
+func NewCategoryAnalyticsService(categoryRepo *categoryRepository.CategoryRepository, statsRepo *categoryRepository.CategoryStatsRepository) *CategoryAnalyticsService {
+	return &CategoryAnalyticsService{CategoryRepo: categoryRepo, StatsRepo: statsRepo}
+}
+
+// RollUpCategoryStats rolls up the days that ended since the last run, taking the follower counts
+// for yesterday. It does nothing once yesterday has been rolled up.
+func (s *CategoryAnalyticsService) RollUpCategoryStats(ctx context.Context, _ string) error {
+	statsRepo := s.StatsRepo.WithContext(ctx)
+	yesterday := startOfDay(time.Now()).AddDate(0, 0, -1)
+
+	done, err := statsRepo.HasFollowerSnapshot(yesterday)
+	if err != nil {
+		return err
+	}
+	if done {
+		return nil
+	}
+
+	from := yesterday.AddDate(0, 0, -(categoryStatsWindow - 1))
+	if err := statsRepo.RollUpDays(from, yesterday, true); err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Info("Rolled up category stats", "from", from.Format(time.DateOnly), "to", yesterday.Format(time.DateOnly))
+	return nil
+}
+
+// ListCategoryAnalytics returns the stats of every category, hidden ones included, over the range
+func (s *CategoryAnalyticsService) ListCategoryAnalytics(req categoryDTO.CategoryAnalyticsRequestDTO) base.Response {
+	from, to, err := analyticsRange(req, time.Now())
+	if err != nil {
+		return base.SetErrorMessage("Invalid date range", err.Error())
+	}
+
+	categories, err := s.CategoryRepo.FindAllCategories()
+	if err != nil {
+		return base.SetErrorMessage("Failed to fetch categories", err)
+	}
+	totals, err := s.StatsRepo.SumStats(from, to, nil)
+	if err != nil {
+		return base.SetErrorMessage("Failed to fetch category analytics", err)
+	}
+
+	byCategory := make(map[uuid.UUID]categoryRepository.CategoryStatsTotal, len(totals))
+	for _, total := range totals {
+		byCategory[total.CategoryID] = total
+	}
+
+	list := categoryDTO.CategoryAnalyticsListDTO{
+		From:       from.Format(time.DateOnly),
+		To:         to.Format(time.DateOnly),
+		Categories: make([]categoryDTO.CategoryAnalyticsDTO, 0, len(categories)),
+	}
+	for _, category := range categories {
+		list.Categories = append(list.Categories, mapCategoryAnalyticsDTO(category, byCategory[category.ID]))
+	}
+	return base.SetData(list)
+}
+
+// GetCategoryAnalytics returns a category's stats over the range and for each day of it
+func (s *CategoryAnalyticsService) GetCategoryAnalytics(categoryID string, req categoryDTO.CategoryAnalyticsRequestDTO) base.Response {
+	uid, err := uuid.Parse(categoryID)
+	if err != nil {
+		return base.SetErrorMessage("Invalid Category ID", err)
+	}
+	from, to, err := analyticsRange(req, time.Now())
+	if err != nil {
+		return base.SetErrorMessage("Invalid date range", err.Error())
+	}
+
+	category, err := s.CategoryRepo.FindCategoryByID(uid)
+	if err != nil {
+		return base.SetErrorMessage("Failed to fetch category", err)
+	}
+	if category == nil {
+		return base.SetErrorMessage("Category not found", "No category exists with this ID")
+	}
+
+	totals, err := s.StatsRepo.SumStats(from, to, &uid)
+	if err != nil {
+		return base.SetErrorMessage("Failed to fetch category analytics", err)
+	}
+	days, err := s.StatsRepo.FindDailyStats(uid, from, to)
+	if err != nil {
+		return base.SetErrorMessage("Failed to fetch category analytics", err)
+	}
+
+	var total categoryRepository.CategoryStatsTotal
+	if len(totals) > 0 {
+		total = totals[0]
+	}
+	report := categoryDTO.CategoryAnalyticsReportDTO{
+		From:                 from.Format(time.DateOnly),
+		To:                   to.Format(time.DateOnly),
+		CategoryAnalyticsDTO: mapCategoryAnalyticsDTO(*category, total),
+		Days:                 make([]categoryDTO.CategoryDailyStatsDTO, 0, len(days)),
+	}
+	for _, day := range days {
+		report.Days = append(report.Days, mapCategoryDailyStatsDTO(day))
+	}
+	return base.SetData(report)
+}
+
+// analyticsRange turns the requested dates into a range of whole UTC days. Days default to the
+// last defaultAnalyticsDays up to yesterday, the latest day that has been rolled up.
+func analyticsRange(req categoryDTO.CategoryAnalyticsRequestDTO, now time.Time) (time.Time, time.Time, error) {
+	to := startOfDay(now).AddDate(0, 0, -1)
+	if req.To != "" {
+		parsed, err := time.Parse(time.DateOnly, req.To)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("to must be a date such as 2025-01-31")
+		}
+		to = parsed
+	}
+
+	from := to.AddDate(0, 0, -(defaultAnalyticsDays - 1))
+	if req.From != "" {
+		parsed, err := time.Parse(time.DateOnly, req.From)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("from must be a date such as 2025-01-31")
+		}
+		from = parsed
+	}
+
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, errors.New("from must not be after to")
+	}
+	if to.Sub(from) >= maxAnalyticsDays*24*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("the range cannot be longer than %d days", maxAnalyticsDays)
+	}
+	return from, to, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+func mapCategoryAnalyticsDTO(category models.Category, total categoryRepository.CategoryStatsTotal) categoryDTO.CategoryAnalyticsDTO {
+	dto := categoryDTO.CategoryAnalyticsDTO{
+		CategoryID: category.ID.String(),
+		Name:       category.Name,
+		IsActive:   category.IsActive,
+		CategoryStatsDTO: categoryDTO.CategoryStatsDTO{
+			Followers:         total.LastFollowers,
+			PodcastsPublished: total.PodcastsPublished,
+			Plays:             total.Plays,
+			Completions:       total.Completions,
+			CompletionRate:    ratio(float64(total.Completions), total.Plays),
+			Likes:             total.Likes,
+			AvgListenThrough:  ratio(total.ListenThroughSum, total.ListenThroughCount),
+		},
+	}
+	if category.ParentID != nil {
+		parentID := category.ParentID.String()
+		dto.ParentID = &parentID
+	}
+	if total.FirstFollowers != nil && total.LastFollowers != nil {
+		change := *total.LastFollowers - *total.FirstFollowers
+		dto.FollowersChange = &change
+	}
+	return dto
+}
+
+func mapCategoryDailyStatsDTO(stat models.CategoryDailyStat) categoryDTO.CategoryDailyStatsDTO {
+	return categoryDTO.CategoryDailyStatsDTO{
+		Day: stat.Day.Format(time.DateOnly),
+		CategoryStatsDTO: categoryDTO.CategoryStatsDTO{
+			Followers:         stat.Followers,
+			PodcastsPublished: stat.PodcastsPublished,
+			Plays:             stat.Plays,
+			Completions:       stat.Completions,
+			CompletionRate:    ratio(float64(stat.Completions), stat.Plays),
+			Likes:             stat.Likes,
+			AvgListenThrough:  ratio(stat.ListenThroughSum, stat.ListenThroughCount),
+		},
+	}
+}
+
+// ratio divides sum by count, giving 0 when there is nothing to divide
+func ratio(sum float64, count int64) float64 {
+	if count == 0 {
+		return 0
+	}
+	return sum / float64(count)
+}
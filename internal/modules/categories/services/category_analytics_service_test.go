@@ -0,0 +1,73 @@
+package categories
+
+import (
+	"testing"
+	"time"
+
+	categoryDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/categories/dtos"
+	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/categories/models"
+	categoryRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/categories/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyticsRange_DefaultsToLastThirtyDaysUpToYesterday(t *testing.T) {
+	now := time.Date(2025, 3, 10, 1, 30, 0, 0, time.FixedZone("AST", 3*60*60))
+
+	from, to, err := analyticsRange(categoryDTO.CategoryAnalyticsRequestDTO{}, now)
+
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 8, 0, 0, 0, 0, time.UTC), to)
+	assert.Equal(t, time.Date(2025, 2, 7, 0, 0, 0, 0, time.UTC), from)
+}
+
+func TestAnalyticsRange_UsesRequestedDays(t *testing.T) {
+	from, to, err := analyticsRange(categoryDTO.CategoryAnalyticsRequestDTO{From: "2025-01-01", To: "2025-01-01"}, time.Now())
+
+	require.NoError(t, err)
+	assert.Equal(t, from, to)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), from)
+}
+
+func TestAnalyticsRange_RejectsInvalidRanges(t *testing.T) {
+	_, _, err := analyticsRange(categoryDTO.CategoryAnalyticsRequestDTO{From: "2025-02-01", To: "2025-01-01"}, time.Now())
+	assert.ErrorContains(t, err, "must not be after")
+
+	_, _, err = analyticsRange(categoryDTO.CategoryAnalyticsRequestDTO{From: "2024-01-01", To: "2025-01-01"}, time.Now())
+	assert.ErrorContains(t, err, "cannot be longer")
+
+	_, _, err = analyticsRange(categoryDTO.CategoryAnalyticsRequestDTO{From: "2024-01-02", To: "2025-01-01"}, time.Now())
+	assert.NoError(t, err)
+}
+
+func TestMapCategoryAnalyticsDTO_ComputesRatesAndFollowerChange(t *testing.T) {
+	sports := category("sports", nil, true)
+	football := category("football", &sports, false)
+	first, last := int64(40), int64(55)
+
+	dto := mapCategoryAnalyticsDTO(football, categoryRepository.CategoryStatsTotal{
+		CategoryID:         football.ID,
+		FirstFollowers:     &first,
+		LastFollowers:      &last,
+		Plays:              8,
+		Completions:        2,
+		ListenThroughSum:   3,
+		ListenThroughCount: 6,
+	})
+
+	assert.Equal(t, sports.ID.String(), *dto.ParentID)
+	assert.False(t, dto.IsActive)
+	assert.Equal(t, int64(55), *dto.Followers)
+	assert.Equal(t, int64(15), *dto.FollowersChange)
+	assert.Equal(t, 0.25, dto.CompletionRate)
+	assert.Equal(t, 0.5, dto.AvgListenThrough)
+}
+
+func TestMapCategoryDailyStatsDTO_WithoutActivity(t *testing.T) {
+	dto := mapCategoryDailyStatsDTO(models.CategoryDailyStat{Day: time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)})
+
+	assert.Equal(t, "2025-01-31", dto.Day)
+	assert.Nil(t, dto.Followers)
+	assert.Zero(t, dto.CompletionRate)
+	assert.Zero(t, dto.AvgListenThrough)
+}
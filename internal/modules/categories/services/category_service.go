@@ -1,13 +1,24 @@
 package categories
 
 import (
+	"context"
+	"errors"
+	"net/http"
+
 	base "github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/logger"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/redis"
+	audit "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/audit/enums"
+	auditService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/audit/services"
 	categoryDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/categories/dtos"
 	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/categories/models"
 	categoryRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/categories/repositories"
 	"github.com/google/uuid"
 )
 
+// recommendationsCacheKeyPrefix starts the keys of cached podcast recommendations
+const recommendationsCacheKeyPrefix = "recommended_podcasts:"
+
 type CategoryService struct {
 	CategoryRepo *categoryRepository.CategoryRepository
 }
@@ -16,33 +27,53 @@ func NewCategoryService(categoryRepo *categoryRepository.CategoryRepository) *Ca
 	return &CategoryService{CategoryRepo: categoryRepo}
 }
 
+// GetAllCategories returns the visible categories as a tree of top-level categories and their
+// subcategories, in display order
 func (s *CategoryService) GetAllCategories() base.Response {
+	return s.listCategories(true)
+}
+
+// GetAdminCategories returns every category as a tree, hidden ones included
+func (s *CategoryService) GetAdminCategories() base.Response {
+	return s.listCategories(false)
+}
+
+func (s *CategoryService) listCategories(activeOnly bool) base.Response {
 	categories, err := s.CategoryRepo.FindAllCategories()
 	if err != nil {
 		return base.SetErrorMessage("Failed to fetch categories", err)
 	}
-	if len(categories) == 0 {
-		return base.SetData([]categoryDTO.Category{}, "No categories found")
-	}
 
-	var categoryResponse []interface{}
-	for _, category := range categories {
-		categoryResponse = append(categoryResponse, categoryDTO.Category{
-			ID:              category.ID.String(),
-			Name:            category.Name,
-			Description:     category.Description,
-			IsNewsIntensive: category.IsNewsIntensive,
-		})
+	tree := buildCategoryTree(categories, activeOnly)
+	if len(tree) == 0 {
+		return base.SetData([]categoryDTO.Category{}, "No categories found")
 	}
-
-	return base.SetData(categoryResponse)
+	return base.SetData(tree)
 }
 
 func (s *CategoryService) CreateCategory(categoryData categoryDTO.Category) base.Response {
 	newCategory := &models.Category{
 		Name:            categoryData.Name,
+		NameAr:          categoryData.NameAr,
+		NameEn:          categoryData.NameEn,
 		Description:     categoryData.Description,
 		IsNewsIntensive: categoryData.IsNewsIntensive,
+		Icon:            categoryData.Icon,
+		Color:           categoryData.Color,
+		IsActive:        true,
+	}
+	if categoryData.SortOrder != nil {
+		newCategory.SortOrder = *categoryData.SortOrder
+	}
+	if categoryData.IsActive != nil {
+		newCategory.IsActive = *categoryData.IsActive
+	}
+	if categoryData.ParentID != nil && *categoryData.ParentID != "" {
+		parentID, response, ok := s.checkParent(uuid.Nil, *categoryData.ParentID)
+		if !ok {
+			return response
+		}
+		newCategory.ParentID = parentID
 	}
 
 	createdCategory, err := s.CategoryRepo.CreateCategory(newCategory)
@@ -54,14 +85,7 @@ func (s *CategoryService) CreateCategory(categoryData categoryDTO.Category) base
 		return base.SetErrorMessage("Failed to create category", "Category creation returned nil")
 	}
 
-	categoryResponse := categoryDTO.Category{
-		ID:              createdCategory.ID.String(),
-		Name:            createdCategory.Name,
-		Description:     createdCategory.Description,
-		IsNewsIntensive: createdCategory.IsNewsIntensive,
-	}
-
-	return base.SetData(categoryResponse, "Category created successfully")
+	return base.SetData(mapCategoryDTO(*createdCategory), "Category created successfully")
 }
 
 func (s *CategoryService) UpdateCategory(categoryID string, updateData categoryDTO.Category) base.Response {
@@ -81,44 +105,271 @@ func (s *CategoryService) UpdateCategory(categoryID string, updateData categoryD
 	if updateData.Name != "" {
 		category.Name = updateData.Name
 	}
+	if updateData.NameAr != "" {
+		category.NameAr = updateData.NameAr
+	}
+	if updateData.NameEn != "" {
+		category.NameEn = updateData.NameEn
+	}
 	if updateData.Description != "" {
 		category.Description = updateData.Description
 	}
 	category.IsNewsIntensive = updateData.IsNewsIntensive
+	if updateData.Icon != "" {
+		category.Icon = updateData.Icon
+	}
+	if updateData.Color != "" {
+		category.Color = updateData.Color
+	}
+	if updateData.SortOrder != nil {
+		category.SortOrder = *updateData.SortOrder
+	}
+	if updateData.IsActive != nil {
+		category.IsActive = *updateData.IsActive
+	}
+	if updateData.ParentID != nil {
+		if *updateData.ParentID == "" {
+			category.ParentID = nil
+		} else {
+			parentID, response, ok := s.checkParent(category.ID, *updateData.ParentID)
+			if !ok {
+				return response
+			}
+			category.ParentID = parentID
+		}
+	}
 
 	err = s.CategoryRepo.UpdateCategory(category)
 	if err != nil {
 		return base.SetErrorMessage("Failed to update category", err)
 	}
 
-	categoryResponse := categoryDTO.Category{
-		ID:              category.ID.String(),
-		Name:            category.Name,
-		Description:     category.Description,
-		IsNewsIntensive: category.IsNewsIntensive,
+	return base.SetData(mapCategoryDTO(*category), "Category updated successfully")
+}
+
+// checkParent validates the parent given for a category, which is uuid.Nil when it is being
+// created. Categories are two levels deep: a subcategory cannot have subcategories of its own.
+func (s *CategoryService) checkParent(categoryID uuid.UUID, parentID string) (*uuid.UUID, base.Response, bool) {
+	pid, err := uuid.Parse(parentID)
+	if err != nil {
+		return nil, base.SetErrorMessage("Invalid parent category ID", err), false
+	}
+	if pid == categoryID {
+		return nil, base.SetErrorMessage("Invalid parent category", "A category cannot be its own parent"), false
+	}
+
+	parent, err := s.CategoryRepo.FindCategoryByID(pid)
+	if err != nil {
+		return nil, base.SetErrorMessage("Failed to fetch parent category", err), false
+	}
+	if parent == nil {
+		return nil, base.SetErrorMessage("Parent category not found", "No category exists with this ID"), false
+	}
+	if parent.ParentID != nil {
+		return nil, base.SetErrorMessage("Invalid parent category", "A subcategory cannot have subcategories"), false
 	}
 
-	return base.SetData(categoryResponse, "Category updated successfully")
+	if categoryID != uuid.Nil {
+		children, err := s.CategoryRepo.CountChildren(categoryID)
+		if err != nil {
+			return nil, base.SetErrorMessage("Failed to fetch subcategories", err), false
+		}
+		if children > 0 {
+			return nil, base.SetErrorMessage("Invalid parent category", "A category with subcategories cannot become a subcategory"), false
+		}
+	}
+	return &pid, base.Response{}, true
 }
 
-func (s *CategoryService) DeleteCategory(categoryID string) base.Response {
+// PreviewCategoryDeletion counts what deleting the category would move to the target category,
+// which may be empty, so an admin can check before deleting
+func (s *CategoryService) PreviewCategoryDeletion(categoryID, targetID string) base.Response {
+	category, target, response, ok := s.findDeletion(categoryID, targetID)
+	if !ok {
+		return response
+	}
+
+	targetUUID := uuid.Nil
+	if target != nil {
+		targetUUID = target.ID
+	}
+	usage, err := s.CategoryRepo.FindCategoryUsage(category.ID, targetUUID)
+	if err != nil {
+		return base.SetErrorMessage("Failed to count category usage", err)
+	}
+
+	return base.SetData(mapCategoryDeletionDTO(category, target, *usage))
+}
+
+// DeleteCategory deletes a category. When it is in use a target category is required: its podcasts,
+// listening history and likes are reassigned to the target, its followers follow the target instead
+// and its subcategories move under it, all in one transaction.
+func (s *CategoryService) DeleteCategory(ctx context.Context, categoryID, targetID string) base.Response {
+	category, target, response, ok := s.findDeletion(categoryID, targetID)
+	if !ok {
+		return response
+	}
+
+	var targetUUID *uuid.UUID
+	if target != nil {
+		targetUUID = &target.ID
+	}
+	usage, err := s.CategoryRepo.DeleteCategory(category.ID, targetUUID)
+	if errors.Is(err, categoryRepository.ErrCategoryInUse) {
+		response := base.SetErrorMessage("Category is in use", "Choose a target_id to move its podcasts, followers and subcategories to")
+		response.HTTPStatus = http.StatusConflict
+		return response
+	}
+	if err != nil {
+		return base.SetErrorMessage("Failed to delete category", err)
+	}
+
+	deletion := mapCategoryDeletionDTO(category, target, *usage)
+	invalidateRecommendations(ctx, category.ID, targetUUID)
+	auditService.Record(ctx, auditService.Entry{
+		Action:     audit.ActionCategoryDeleted,
+		TargetType: audit.TargetCategory,
+		TargetID:   category.ID.String(),
+		Before:     mapCategoryDTO(*category),
+		Metadata: map[string]interface{}{
+			"target_id":         deletion.TargetID,
+			"podcasts":          deletion.Podcasts,
+			"listening_history": deletion.ListeningHistory,
+			"likes":             deletion.Likes,
+			"followers":         deletion.Followers,
+			"subcategories":     deletion.Subcategories,
+		},
+	})
+
+	return base.SetData(deletion, "Category deleted successfully")
+}
+
+// findDeletion looks up the category to delete and the optional target it is merged into. The
+// target keeps categories two levels deep: it must be top-level to take over subcategories.
+func (s *CategoryService) findDeletion(categoryID, targetID string) (*models.Category, *models.Category, base.Response, bool) {
 	uid, err := uuid.Parse(categoryID)
 	if err != nil {
-		return base.SetErrorMessage("Invalid Category ID", err)
+		return nil, nil, base.SetErrorMessage("Invalid Category ID", err), false
 	}
 
 	category, err := s.CategoryRepo.FindCategoryByID(uid)
 	if err != nil {
-		return base.SetErrorMessage("Failed to fetch category", err)
+		return nil, nil, base.SetErrorMessage("Failed to fetch category", err), false
 	}
 	if category == nil {
-		return base.SetErrorMessage("Category not found", "No category exists with this ID")
+		return nil, nil, base.SetErrorMessage("Category not found", "No category exists with this ID"), false
+	}
+	if targetID == "" {
+		return category, nil, base.Response{}, true
 	}
 
-	err = s.CategoryRepo.DeleteCategory(uid)
+	tid, err := uuid.Parse(targetID)
 	if err != nil {
-		return base.SetErrorMessage("Failed to delete category", err)
+		return nil, nil, base.SetErrorMessage("Invalid target category ID", err), false
+	}
+	if tid == uid {
+		return nil, nil, base.SetErrorMessage("Invalid target category", "A category cannot be merged into itself"), false
+	}
+	target, err := s.CategoryRepo.FindCategoryByID(tid)
+	if err != nil {
+		return nil, nil, base.SetErrorMessage("Failed to fetch target category", err), false
+	}
+	if target == nil {
+		return nil, nil, base.SetErrorMessage("Target category not found", "No category exists with this ID"), false
+	}
+	if target.ParentID != nil {
+		children, err := s.CategoryRepo.CountChildren(uid)
+		if err != nil {
+			return nil, nil, base.SetErrorMessage("Failed to fetch subcategories", err), false
+		}
+		if children > 0 {
+			return nil, nil, base.SetErrorMessage("Invalid target category", "A category with subcategories can only be merged into a top-level category"), false
+		}
+	}
+	return category, target, base.Response{}, true
+}
+
+// invalidateRecommendations drops the cached recommendations of users following the deleted
+// category or its target; recommendation cache keys end with the IDs of the followed categories
+func invalidateRecommendations(ctx context.Context, categoryID uuid.UUID, targetID *uuid.UUID) {
+	ids := []uuid.UUID{categoryID}
+	if targetID != nil {
+		ids = append(ids, *targetID)
+	}
+	for _, id := range ids {
+		if err := redis.DeleteMatching(ctx, recommendationsCacheKeyPrefix+"*"+id.String()+"*"); err != nil {
+			logger.FromContext(ctx).Warn("Failed to invalidate cached recommendations", "category_id", id, "error", err)
+		}
+	}
+}
+
+func mapCategoryDeletionDTO(category, target *models.Category, usage categoryRepository.CategoryUsage) categoryDTO.CategoryDeletionDTO {
+	dto := categoryDTO.CategoryDeletionDTO{
+		CategoryID:       category.ID.String(),
+		Podcasts:         usage.Podcasts,
+		ListeningHistory: usage.ListeningHistory,
+		Likes:            usage.Likes,
+		Followers:        usage.Followers,
+		SharedFollowers:  usage.SharedFollowers,
+		Subcategories:    usage.Subcategories,
+		TargetRequired:   usage.InUse(),
+	}
+	if target != nil {
+		dto.TargetID = target.ID.String()
+	}
+	return dto
+}
+
+// buildCategoryTree nests subcategories under their parents, keeping the order of categories.
+// With activeOnly, hidden categories and the subcategories of hidden parents are left out.
+// A subcategory whose parent no longer exists is shown at the top level.
+func buildCategoryTree(categories []models.Category, activeOnly bool) []categoryDTO.Category {
+	exists := make(map[uuid.UUID]bool, len(categories))
+	for _, category := range categories {
+		exists[category.ID] = true
+	}
+	shown := make(map[uuid.UUID]bool, len(categories))
+	for _, category := range categories {
+		shown[category.ID] = !activeOnly || category.IsActive
 	}
 
-	return base.SetSuccessMessage("Category deleted successfully")
+	children := make(map[uuid.UUID][]categoryDTO.Category)
+	for _, category := range categories {
+		if category.ParentID != nil && exists[*category.ParentID] && shown[category.ID] && shown[*category.ParentID] {
+			children[*category.ParentID] = append(children[*category.ParentID], mapCategoryDTO(category))
+		}
+	}
+
+	tree := []categoryDTO.Category{}
+	for _, category := range categories {
+		if !shown[category.ID] || (category.ParentID != nil && exists[*category.ParentID]) {
+			continue
+		}
+		dto := mapCategoryDTO(category)
+		dto.Children = children[category.ID]
+		tree = append(tree, dto)
+	}
+	return tree
+}
+
+func mapCategoryDTO(category models.Category) categoryDTO.Category {
+	sortOrder := category.SortOrder
+	isActive := category.IsActive
+	dto := categoryDTO.Category{
+		ID:              category.ID.String(),
+		Name:            category.Name,
+		NameAr:          category.NameAr,
+		NameEn:          category.NameEn,
+		Description:     category.Description,
+		IsNewsIntensive: category.IsNewsIntensive,
+		SortOrder:       &sortOrder,
+		Icon:            category.Icon,
+		Color:           category.Color,
+		IsActive:        &isActive,
+	}
+	if category.ParentID != nil {
+		parentID := category.ParentID.String()
+		dto.ParentID = &parentID
+	}
+	return dto
 }
@@ -0,0 +1,99 @@
+package categories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/categories/models"
+	categoryRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/categories/repositories"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func category(name string, parent *models.Category, active bool) models.Category {
+	c := models.Category{Model: base.Model{ID: uuid.New()}, Name: name, IsActive: active}
+	if parent != nil {
+		c.ParentID = &parent.ID
+	}
+	return c
+}
+
+func TestBuildCategoryTree_NestsAndKeepsOrder(t *testing.T) {
+	news := category("news", nil, true)
+	sports := category("sports", nil, true)
+	football := category("football", &sports, true)
+	tennis := category("tennis", &sports, true)
+	local := category("local", &news, true)
+	orphan := category("orphan", nil, true)
+	deletedParent := uuid.New()
+	orphan.ParentID = &deletedParent
+
+	tree := buildCategoryTree([]models.Category{news, tennis, sports, local, football, orphan}, true)
+
+	require.Len(t, tree, 3)
+	assert.Equal(t, []string{"news", "sports", "orphan"}, []string{tree[0].Name, tree[1].Name, tree[2].Name})
+	require.Len(t, tree[0].Children, 1)
+	assert.Equal(t, "local", tree[0].Children[0].Name)
+	assert.Equal(t, news.ID.String(), *tree[0].Children[0].ParentID)
+	require.Len(t, tree[1].Children, 2)
+	assert.Equal(t, "tennis", tree[1].Children[0].Name)
+	assert.Equal(t, "football", tree[1].Children[1].Name)
+}
+
+func TestBuildCategoryTree_HidesInactive(t *testing.T) {
+	news := category("news", nil, false)
+	local := category("local", &news, true)
+	sports := category("sports", nil, true)
+	football := category("football", &sports, false)
+
+	public := buildCategoryTree([]models.Category{news, local, sports, football}, true)
+	require.Len(t, public, 1)
+	assert.Equal(t, "sports", public[0].Name)
+	assert.Empty(t, public[0].Children)
+
+	admin := buildCategoryTree([]models.Category{news, local, sports, football}, false)
+	require.Len(t, admin, 2)
+	assert.False(t, *admin[0].IsActive)
+	assert.Len(t, admin[0].Children, 1)
+	assert.Len(t, admin[1].Children, 1)
+}
+
+func TestCheckParent_RejectsSelfAndBadIDs(t *testing.T) {
+	service := CategoryService{}
+	id := uuid.New()
+
+	_, response, ok := service.checkParent(id, id.String())
+	assert.False(t, ok)
+	assert.Equal(t, "Invalid parent category", response.MessageTitle)
+
+	_, response, ok = service.checkParent(id, "sports")
+	assert.False(t, ok)
+	assert.Equal(t, "Invalid parent category ID", response.MessageTitle)
+}
+
+func TestDeleteCategory_InvalidID(t *testing.T) {
+	service := CategoryService{}
+
+	response := service.DeleteCategory(context.Background(), "sports", "")
+	assert.Equal(t, "Invalid Category ID", response.MessageTitle)
+
+	response = service.PreviewCategoryDeletion("sports", uuid.New().String())
+	assert.Equal(t, "Invalid Category ID", response.MessageTitle)
+}
+
+func TestMapCategoryDeletionDTO_RequiresTargetWhenInUse(t *testing.T) {
+	source := category("football", nil, true)
+	target := category("sports", nil, true)
+
+	unused := mapCategoryDeletionDTO(&source, nil, categoryRepository.CategoryUsage{})
+	assert.False(t, unused.TargetRequired)
+	assert.Empty(t, unused.TargetID)
+
+	merged := mapCategoryDeletionDTO(&source, &target, categoryRepository.CategoryUsage{Followers: 3, SharedFollowers: 1})
+	assert.True(t, merged.TargetRequired)
+	assert.Equal(t, target.ID.String(), merged.TargetID)
+	assert.Equal(t, int64(3), merged.Followers)
+	assert.Equal(t, int64(1), merged.SharedFollowers)
+}
@@ -1 +1,5 @@
 package notifications
+
+const (
+	NotificationTypeDataExport = "data_export"
+)
@@ -1 +1,36 @@
 package notifications
+
+import (
+	"fmt"
+
+	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/notifications/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type NotificationRepository struct {
+	DB *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{
+		DB: db,
+	}
+}
+
+func (r *NotificationRepository) CreateNotification(notification *models.Notification) error {
+	result := r.DB.Create(notification)
+	if result.Error != nil {
+		return fmt.Errorf("failed to create notification: %w", result.Error)
+	}
+	return nil
+}
+
+func (r *NotificationRepository) FindUserNotifications(userID uuid.UUID) ([]models.Notification, error) {
+	var notifications []models.Notification
+	result := r.DB.Where("user_id = ?", userID).Order("created_at DESC").Find(&notifications)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to fetch notifications: %w", result.Error)
+	}
+	return notifications, nil
+}
@@ -22,12 +22,16 @@ type PodcastDto struct {
 	IsBookmarked          bool   `json:"is_bookmarked"`
 	IsCompleted           bool   `json:"is_completed"`
 	IsTrending            bool   `json:"is_trending"`
+	IsPremium             bool   `json:"is_premium"`
+	IsLocked              bool   `json:"is_locked"`
 	CreatedAt             string `json:"created_at,omitempty"`
 	UpdatedAt             string `json:"updated_at,omitempty"`
 	DeletedAt             string `json:"deleted_at,omitempty"`
 }
 
-func MapToPodcastDTO(podcast podcastsModels.Podcast, userID uuid.UUID) PodcastDto {
+// MapToPodcastDTO maps a podcast for the given user. canAccessPremium is whether the user holds the
+// premium entitlement; callers resolve it once per request rather than once per podcast.
+func MapToPodcastDTO(podcast podcastsModels.Podcast, userID uuid.UUID, canAccessPremium bool) PodcastDto {
 	var isDownloaded, isBookmarked, isCompleted, isTrending bool
 
 	r := podcastRepository.NewPodcastRepository(config.GetDB())
@@ -38,10 +42,17 @@ func MapToPodcastDTO(podcast podcastsModels.Podcast, userID uuid.UUID) PodcastDt
 	}
 	isTrending, _ = r.IsTrending(podcast.ID)
 
+	// premium audio is only exposed to users holding the premium entitlement
+	audioURL := podcast.AudioURL
+	isLocked := podcast.IsPremium && !canAccessPremium
+	if isLocked {
+		audioURL = ""
+	}
+
 	return PodcastDto{
 		ID:            podcast.ID.String(),
 		Title:         podcast.Title,
-		AudioURL:      podcast.AudioURL,
+		AudioURL:      audioURL,
 		CoverImageURL: podcast.CoverImageURL,
 		LikesCount:    podcast.LikesCount,
 		Duration:      podcast.Duration,
@@ -50,6 +61,8 @@ func MapToPodcastDTO(podcast podcastsModels.Podcast, userID uuid.UUID) PodcastDt
 		IsBookmarked:  isBookmarked,
 		IsCompleted:   isCompleted,
 		IsTrending:    isTrending,
+		IsPremium:     podcast.IsPremium,
+		IsLocked:      isLocked,
 		CreatedAt:     podcast.CreatedAt.Format("2006-01-02 15:04:05"),
 	}
 }
@@ -68,6 +81,10 @@ type GetPodcastDetailsRequestDto struct {
 	ID string `json:"id" param:"id" validate:"required,uuid" message:"ID must be a valid ID format"`
 }
 
+type SetPodcastPremiumRequestDto struct {
+	IsPremium bool `json:"is_premium"`
+}
+
 type LikePodcastRequestDto struct {
 	AddLikes int `json:"add_likes" validate:"omitempty,min=1"`
 }
@@ -51,7 +51,7 @@ func (h *PodcastHandler) GetRecommendedPodcasts(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, err.Error())
 	}
 
-	response := h.PodcastService.GetRecommendedPodcasts(userID, userCategoriesIDs)
+	response := h.PodcastService.GetRecommendedPodcasts(c.Request().Context(), userID, userCategoriesIDs)
 	return c.JSON(response.HTTPStatus, response)
 }
 
@@ -85,7 +85,8 @@ func (h *PodcastHandler) LikePodcast(c echo.Context) error {
 	}
 
 	podcastID := c.Param("podcast_id")
-	response := h.PodcastService.LikePodcast(podcastID, reqDto.AddLikes)
+	userID, _ := c.Get("user_id").(string)
+	response := h.PodcastService.LikePodcast(userID, podcastID, reqDto.AddLikes)
 	return c.JSON(response.HTTPStatus, response)
 }
 
@@ -116,6 +117,29 @@ func (h *PodcastHandler) DownloadPodcast(c echo.Context) error {
 	return c.JSON(response.HTTPStatus, response)
 }
 
+func (h *PodcastHandler) StreamPodcast(c echo.Context) error {
+	userID, ok := c.Get("user_id").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, base.SetErrorMessage("Unauthorized", "Invalid or missing user ID"))
+	}
+
+	audioURL, response := h.PodcastService.StreamPodcast(userID, c.Param("podcast_id"))
+	if audioURL == "" {
+		return c.JSON(response.HTTPStatus, response)
+	}
+	return c.Redirect(http.StatusFound, audioURL)
+}
+
+func (h *PodcastHandler) SetPodcastPremium(c echo.Context) error {
+	var reqDto podcastsDto.SetPodcastPremiumRequestDto
+	if res, ok := base.BindAndValidate(c, &reqDto); !ok {
+		return c.JSON(res.HTTPStatus, res)
+	}
+
+	response := h.PodcastService.SetPodcastPremium(c.Param("podcast_id"), reqDto.IsPremium)
+	return c.JSON(response.HTTPStatus, response)
+}
+
 func (h *PodcastHandler) TrackUserPodcast(c echo.Context) error {
 	podcastID := c.Param("podcast_id")
 	userID := c.Get("user_id").(string)
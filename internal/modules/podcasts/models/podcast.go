@@ -24,6 +24,7 @@ type Podcast struct {
 	CategoryID            uuid.UUID   `gorm:"type:uuid;index" json:"category_id"`
 	FetchedFrom           FetchedFrom `gorm:"type:text" json:"fetched_from"`
 	Tags                  string      `gorm:"type:text" json:"tags"`
+	IsPremium             bool        `gorm:"default:false;index" json:"is_premium"`
 }
 
 type UserPodcast struct {
@@ -35,6 +36,14 @@ type UserPodcast struct {
 	IsCompleted    bool      `gorm:"default:false" json:"is_completed"`
 }
 
+type PodcastLike struct {
+	base.Model
+	UserID     uuid.UUID `gorm:"type:uuid;index" json:"user_id"`
+	PodcastID  uuid.UUID `gorm:"type:uuid;index" json:"podcast_id"`
+	CategoryID uuid.UUID `gorm:"type:uuid;index" json:"category_id"`
+	Count      int       `gorm:"default:1" json:"count"`
+}
+
 type BookmarkPodcast struct {
 	UserID    uuid.UUID `gorm:"primaryKey;type:uuid"`
 	PodcastID uuid.UUID `gorm:"primaryKey;type:uuid"`
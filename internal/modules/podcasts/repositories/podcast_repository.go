@@ -1,6 +1,7 @@
 package podcasts
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -18,6 +19,11 @@ func NewPodcastRepository(DB *gorm.DB) *PodcastRepository {
 	return &PodcastRepository{DB: DB}
 }
 
+// WithContext returns a repository whose queries run under ctx, so they are traced as part of the request
+func (r *PodcastRepository) WithContext(ctx context.Context) *PodcastRepository {
+	return &PodcastRepository{DB: r.DB.WithContext(ctx)}
+}
+
 func (r *PodcastRepository) GetAllPodcasts(offset int, limit int) ([]podcastsModels.Podcast, int, error) {
 	var podcasts []podcastsModels.Podcast
 	var totalCount int64
@@ -103,6 +109,14 @@ func (r *PodcastRepository) FindPodcastByID(podcastID uuid.UUID) (*podcastsModel
 	return &podcast, nil
 }
 
+func (r *PodcastRepository) UpdatePodcastPremium(podcastID uuid.UUID, isPremium bool) error {
+	result := r.DB.Model(&podcastsModels.Podcast{}).Where("id = ?", podcastID).Update("is_premium", isPremium)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update podcast premium flag: %w", result.Error)
+	}
+	return nil
+}
+
 func (r *PodcastRepository) IncrementLikesCount(podcastID uuid.UUID, addLikes int) (int, error) {
 	var podcast podcastsModels.Podcast
 
@@ -121,12 +135,12 @@ func (r *PodcastRepository) IncrementLikesCount(podcastID uuid.UUID, addLikes in
 	return podcast.LikesCount, nil
 }
 
-func (r *PodcastRepository) FindPodcastsByCategoryID(categoryID uuid.UUID, offset int, limit int) ([]podcastsModels.Podcast, int, error) {
+func (r *PodcastRepository) FindPodcastsByCategoryIDs(categoryIDs []uuid.UUID, offset int, limit int) ([]podcastsModels.Podcast, int, error) {
 	var podcasts []podcastsModels.Podcast
 	var totalCount int64
 
 	result := r.DB.Model(&podcastsModels.Podcast{}).
-		Where("category_id = ?", categoryID).
+		Where("category_id IN ?", categoryIDs).
 		Offset(offset).
 		Limit(limit).
 		Order("created_at DESC").
@@ -136,7 +150,7 @@ func (r *PodcastRepository) FindPodcastsByCategoryID(categoryID uuid.UUID, offse
 	}
 
 	result = r.DB.Model(&podcastsModels.Podcast{}).
-		Where("category_id = ?", categoryID).
+		Where("category_id IN ?", categoryIDs).
 		Count(&totalCount)
 	if result.Error != nil {
 		return nil, 0, fmt.Errorf("failed to find podcasts by category ID: %w", result.Error)
@@ -270,3 +284,28 @@ func (r *PodcastRepository) IsTrending(podcastID uuid.UUID) (bool, error) {
 
 	return isTrending, nil
 }
+
+func (r *PodcastRepository) CreatePodcastLike(like *podcastsModels.PodcastLike) error {
+	if err := r.DB.Create(like).Error; err != nil {
+		return fmt.Errorf("failed to record podcast like: %w", err)
+	}
+	return nil
+}
+
+func (r *PodcastRepository) FindUserLikes(userID uuid.UUID) ([]podcastsModels.PodcastLike, error) {
+	var likes []podcastsModels.PodcastLike
+	result := r.DB.Where("user_id = ?", userID).Order("created_at DESC").Find(&likes)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get user likes: %w", result.Error)
+	}
+	return likes, nil
+}
+
+func (r *PodcastRepository) FindUserPodcasts(userID uuid.UUID) ([]podcastsModels.UserPodcast, error) {
+	var userPodcasts []podcastsModels.UserPodcast
+	result := r.DB.Where("user_id = ?", userID).Order("updated_at DESC").Find(&userPodcasts)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get user listening history: %w", result.Error)
+	}
+	return userPodcasts, nil
+}
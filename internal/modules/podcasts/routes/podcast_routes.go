@@ -1,38 +1,60 @@
 package podcasts
 
 import (
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/config"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/jwtkeys"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/ratelimit"
 	"github.com/Al-Khaimah/khaimah-golang-backend/internal/middlewares"
 	podcastHandler "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/podcasts/handlers"
 	podcastRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/podcasts/repositories"
 	podcastService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/podcasts/services"
+	referralRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/referrals/repositories"
+	referralService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/referrals/services"
+	roles "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/enums"
+	subscriptionRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/repositories"
+	subscriptionService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/services"
 	userRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/repositories"
 	userService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/services"
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
 )
 
-func RegisterRoutes(e *echo.Echo, db *gorm.DB) {
+func RegisterRoutes(e *echo.Echo, db *gorm.DB, cfg *config.Config) {
+	keys := jwtkeys.Default()
+
 	authRepo := userRepository.NewAuthRepository(db)
 	userRepo := userRepository.NewUserRepository(db)
 	bookmarksRepo := userRepository.NewBookmarkRepository(db)
 
-	userService := userService.NewUserService(userRepo, authRepo, bookmarksRepo)
+	subscriptionRepo := subscriptionRepository.NewSubscriptionRepository(db)
+	newSubscriptionService := subscriptionService.NewSubscriptionService(subscriptionRepo, userRepo)
+	newReferralService := referralService.NewReferralService(referralRepository.NewReferralRepository(db), userRepo, newSubscriptionService, cfg.Referrals)
+
+	userService := userService.NewUserService(userRepo, authRepo, bookmarksRepo, newReferralService, cfg)
 
 	podcastRepo := podcastRepository.NewPodcastRepository(db)
-	podcastService := podcastService.NewPodcastService(podcastRepo)
+	entitlementService := subscriptionService.NewEntitlementService(subscriptionRepo)
+	podcastService := podcastService.NewPodcastService(podcastRepo, entitlementService, newReferralService)
 	podcastHandler := podcastHandler.NewPodcastHandler(podcastService, userService)
 
+	likeLimit := middlewares.RateLimit(cfg.RateLimit, ratelimit.Policy{
+		Name: "like", Limit: 30, Window: time.Minute, Algorithm: ratelimit.TokenBucket, Key: ratelimit.ByUserID,
+	})
+
 	e.GET("/podcasts/trending", podcastHandler.GetTrendingPodcasts)
-	podcastGroup := e.Group("/podcasts", middlewares.AuthMiddleware(authRepo))
+	podcastGroup := e.Group("/podcasts", middlewares.AuthMiddleware(authRepo, keys))
 	podcastGroup.GET("/", podcastHandler.GetAllPodcasts)
 	podcastGroup.GET("/recommended", podcastHandler.GetRecommendedPodcasts)
 	podcastGroup.GET("/:id", podcastHandler.GetPodcastDetails)
-	podcastGroup.POST("/:podcast_id/like", podcastHandler.LikePodcast)
+	podcastGroup.POST("/:podcast_id/like", podcastHandler.LikePodcast, likeLimit)
 	podcastGroup.GET("/category/:category_id", podcastHandler.GetPodcastsByCategory)
 	podcastGroup.POST("/:podcast_id/download", podcastHandler.DownloadPodcast)
+	podcastGroup.GET("/:podcast_id/stream", podcastHandler.StreamPodcast)
 	podcastGroup.POST("/:podcast_id/track", podcastHandler.TrackUserPodcast)
 	podcastGroup.GET("/history", podcastHandler.UserWatchHistory)
 
-	/*adminGroup := e.Group("/admin", middlewares.AdminMiddleware())
-	adminGroup.DELETE()*/ //TODO: delete podcast by podcast_id for admin
+	adminGroup := e.Group("/admin/podcasts", middlewares.RequirePermission(keys, roles.PermissionPodcastsWrite))
+	adminGroup.PATCH("/:podcast_id/premium", podcastHandler.SetPodcastPremium)
 }
@@ -1,24 +1,32 @@
 package podcasts
 
 import (
+	"context"
 	"fmt"
 	"github.com/Al-Khaimah/khaimah-golang-backend/config"
 	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/tracing"
 	categoryRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/categories/repositories"
 	podcastsDto "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/podcasts/dtos"
 	podcastsModels "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/podcasts/models"
 	podcasts "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/podcasts/repositories"
+	referralService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/referrals/services"
+	subscriptionService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/services"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"net/http"
 	"sort"
 	"strings"
 )
 
 type PodcastService struct {
 	PodcastRepository *podcasts.PodcastRepository
+	Entitlements      *subscriptionService.EntitlementService
+	Referrals         *referralService.ReferralService
 }
 
-func NewPodcastService(podcastRepository *podcasts.PodcastRepository) *PodcastService {
-	return &PodcastService{PodcastRepository: podcastRepository}
+func NewPodcastService(podcastRepository *podcasts.PodcastRepository, entitlements *subscriptionService.EntitlementService, referrals *referralService.ReferralService) *PodcastService {
+	return &PodcastService{PodcastRepository: podcastRepository, Entitlements: entitlements, Referrals: referrals}
 }
 
 func (s *PodcastService) GetAllPodcasts(getAllPodcastsRequestDto podcastsDto.GetAllPodcastsRequestDto, userID string) base.Response {
@@ -37,9 +45,10 @@ func (s *PodcastService) GetAllPodcasts(getAllPodcastsRequestDto podcastsDto.Get
 	if err != nil {
 		return base.SetErrorMessage("Failed to get podcasts", err)
 	}
+	canAccessPremium := s.canAccessPremium(userUUID)
 	podcastDtos := make([]interface{}, len(podcasts))
 	for i, podcast := range podcasts {
-		podcastDtos[i] = podcastsDto.MapToPodcastDTO(podcast, userUUID)
+		podcastDtos[i] = podcastsDto.MapToPodcastDTO(podcast, userUUID, canAccessPremium)
 	}
 
 	return base.SetPaginatedResponse(podcastDtos, page, perPage, totalCount)
@@ -54,7 +63,10 @@ func generateRecommendedPodcastsCacheKey(userID string, categoriesIDs []string)
 	return fmt.Sprintf("recommended_podcasts:%s:%s", userID, strings.Join(sortedCategories, ","))
 }
 
-func (s *PodcastService) GetRecommendedPodcasts(userID string, userCategoriesIDs []string) base.Response {
+func (s *PodcastService) GetRecommendedPodcasts(ctx context.Context, userID string, userCategoriesIDs []string) base.Response {
+	ctx, span := tracing.Start(ctx, "PodcastService.GetRecommendedPodcasts", attribute.Int("categories.count", len(userCategoriesIDs)))
+	defer span.End()
+
 	userUUID, err := uuid.Parse(userID)
 	if err != nil {
 		return base.SetErrorMessage("Invalid user ID format", err)
@@ -79,21 +91,33 @@ func (s *PodcastService) GetRecommendedPodcasts(userID string, userCategoriesIDs
 		categoriesUUID = append(categoriesUUID, catID)
 	}
 
-	completedIDs, err := s.PodcastRepository.GetCompletedPodcastsIDs(userUUID)
+	// Following a category includes its subcategories
+	categoryRepo := categoryRepository.NewCategoryRepository(config.GetDB()).WithContext(ctx)
+	categoriesUUID, err = categoryRepo.FindActiveCategoryIDsWithChildren(categoriesUUID)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return base.SetErrorMessage("Failed to get user categories", err)
+	}
+
+	podcastRepo := s.PodcastRepository.WithContext(ctx)
+	completedIDs, err := podcastRepo.GetCompletedPodcastsIDs(userUUID)
 	if err != nil {
+		tracing.RecordError(span, err)
 		return base.SetErrorMessage("Failed to get completed podcast IDs", err)
 	}
 
-	podcasts, err := s.PodcastRepository.GetRecommendedPodcasts(categoriesUUID, completedIDs)
+	podcasts, err := podcastRepo.GetRecommendedPodcasts(categoriesUUID, completedIDs)
 	if err != nil {
+		tracing.RecordError(span, err)
 		return base.SetErrorMessage("Failed to get recommended podcasts", err)
 	}
+	span.SetAttributes(attribute.Int("podcasts.count", len(podcasts)))
 
-	categoryRepo := categoryRepository.NewCategoryRepository(config.GetDB())
 	grouped := make(map[string]podcastsDto.GetRecommendedPodcastsResponseDto)
+	canAccessPremium := s.canAccessPremium(userUUID)
 
 	for _, podcast := range podcasts {
-		dto := podcastsDto.MapToPodcastDTO(podcast, userUUID)
+		dto := podcastsDto.MapToPodcastDTO(podcast, userUUID, canAccessPremium)
 		categoryID := podcast.CategoryID.String()
 
 		if _, exists := grouped[categoryID]; !exists {
@@ -138,9 +162,10 @@ func (s *PodcastService) GetTrendingPodcasts(userID string) base.Response {
 		return base.SetErrorMessage("Failed to fetch trending podcasts", err)
 	}
 
+	canAccessPremium := s.canAccessPremium(userUUID)
 	response := make([]interface{}, len(podcasts))
 	for i, podcast := range podcasts {
-		response[i] = podcastsDto.MapToPodcastDTO(podcast, userUUID)
+		response[i] = podcastsDto.MapToPodcastDTO(podcast, userUUID, canAccessPremium)
 	}
 
 	return base.SetData(response)
@@ -161,13 +186,16 @@ func (s *PodcastService) GetPodcastDetails(podcastID string, userID string) base
 	if err != nil {
 		return base.SetErrorMessage("Failed to get podcast details", err)
 	}
+	if podcast == nil {
+		return base.SetErrorMessage("Podcast not found", "No podcast exists with this ID")
+	}
 
-	podcastDetailsDto := podcastsDto.MapToPodcastDTO(*podcast, userUUID)
+	podcastDetailsDto := podcastsDto.MapToPodcastDTO(*podcast, userUUID, s.canAccessPremium(userUUID))
 
 	return base.SetData(podcastDetailsDto)
 }
 
-func (s *PodcastService) LikePodcast(podcastID string, addLikes int) base.Response {
+func (s *PodcastService) LikePodcast(userID, podcastID string, addLikes int) base.Response {
 	podcastUUID, err := uuid.Parse(podcastID)
 	if err != nil {
 		return base.SetErrorMessage("Invalid podcast ID format", err)
@@ -182,6 +210,18 @@ func (s *PodcastService) LikePodcast(podcastID string, addLikes int) base.Respon
 		return base.SetErrorMessage("Failed to like podcast", err)
 	}
 
+	if userUUID, err := uuid.Parse(userID); err == nil {
+		podcast, _ := s.PodcastRepository.FindPodcastByID(podcastUUID)
+		if podcast != nil {
+			_ = s.PodcastRepository.CreatePodcastLike(&podcastsModels.PodcastLike{
+				UserID:     userUUID,
+				PodcastID:  podcast.ID,
+				CategoryID: podcast.CategoryID,
+				Count:      addLikes,
+			})
+		}
+	}
+
 	return base.SetData(podcastsDto.LikePodcastResponseDto{
 		PodcastID:         podcastUUID.String(),
 		PodcastTotalLikes: likeCount,
@@ -206,14 +246,21 @@ func (s *PodcastService) GetPodcastsByCategory(getPodcastsByCategoryRequestDto p
 		return base.SetErrorMessage("Invalid category ID format", err)
 	}
 
-	podcasts, totalCount, err := s.PodcastRepository.FindPodcastsByCategoryID(categoryUUID, offset, limit)
+	// A category's listing includes the podcasts of its subcategories
+	categoryIDs, err := categoryRepository.NewCategoryRepository(config.GetDB()).FindActiveCategoryIDsWithChildren([]uuid.UUID{categoryUUID})
+	if err != nil {
+		return base.SetErrorMessage("Failed to get category", err)
+	}
+
+	podcasts, totalCount, err := s.PodcastRepository.FindPodcastsByCategoryIDs(categoryIDs, offset, limit)
 	if err != nil {
 		return base.SetErrorMessage("Failed to get podcasts by category ID", err)
 	}
 
+	canAccessPremium := s.canAccessPremium(userUUID)
 	podcastDtos := make([]interface{}, len(podcasts))
 	for i, podcast := range podcasts {
-		podcastDtos[i] = podcastsDto.MapToPodcastDTO(podcast, userUUID)
+		podcastDtos[i] = podcastsDto.MapToPodcastDTO(podcast, userUUID, canAccessPremium)
 	}
 
 	return base.SetPaginatedResponse(podcastDtos, page, perPage, totalCount)
@@ -235,6 +282,12 @@ func (s *PodcastService) ToggleDownloadPodcast(userID, podcastID string) base.Re
 		return base.SetErrorMessage("Failed to check download status", err)
 	}
 
+	if !exists {
+		if _, response, ok := s.checkPodcastAccess(uid, pid); !ok {
+			return response
+		}
+	}
+
 	var action string
 	if exists {
 		err = s.PodcastRepository.RemoveDownload(uid, pid)
@@ -250,6 +303,79 @@ func (s *PodcastService) ToggleDownloadPodcast(userID, podcastID string) base.Re
 	return base.SetSuccessMessage("Download " + action + " successfully")
 }
 
+// StreamPodcast returns the audio URL of a podcast when the user is entitled to play it
+func (s *PodcastService) StreamPodcast(userID, podcastID string) (string, base.Response) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return "", base.SetErrorMessage("Invalid User ID", err)
+	}
+
+	pid, err := uuid.Parse(podcastID)
+	if err != nil {
+		return "", base.SetErrorMessage("Invalid Podcast ID", err)
+	}
+
+	podcast, response, ok := s.checkPodcastAccess(uid, pid)
+	if !ok {
+		return "", response
+	}
+	if podcast.AudioURL == "" {
+		return "", base.SetErrorMessage("Audio not available", "This podcast has no audio yet")
+	}
+	return podcast.AudioURL, response
+}
+
+// canAccessPremium resolves once per request whether the user may play premium podcasts. A failed
+// lookup counts as no access, so premium audio is never exposed by mistake.
+func (s *PodcastService) canAccessPremium(userID uuid.UUID) bool {
+	canAccess, _ := s.Entitlements.CanAccessPodcast(userID, true)
+	return canAccess
+}
+
+// checkPodcastAccess loads the podcast and rejects users without the entitlement a premium podcast needs
+func (s *PodcastService) checkPodcastAccess(userID, podcastID uuid.UUID) (*podcastsModels.Podcast, base.Response, bool) {
+	podcast, err := s.PodcastRepository.FindPodcastByID(podcastID)
+	if err != nil {
+		return nil, base.SetErrorMessage("Failed to get podcast details", err), false
+	}
+	if podcast == nil {
+		return nil, base.SetErrorMessage("Podcast not found", "No podcast exists with this ID"), false
+	}
+
+	canAccess, err := s.Entitlements.CanAccessPodcast(userID, podcast.IsPremium)
+	if err != nil {
+		return nil, base.SetErrorMessage("Failed to check subscription", err), false
+	}
+	if !canAccess {
+		response := base.SetErrorMessage("Premium content", "A premium subscription is required for this podcast")
+		response.HTTPStatus = http.StatusForbidden
+		return nil, response, false
+	}
+
+	return podcast, base.Response{}, true
+}
+
+func (s *PodcastService) SetPodcastPremium(podcastID string, isPremium bool) base.Response {
+	pid, err := uuid.Parse(podcastID)
+	if err != nil {
+		return base.SetErrorMessage("Invalid Podcast ID", err)
+	}
+
+	podcast, err := s.PodcastRepository.FindPodcastByID(pid)
+	if err != nil {
+		return base.SetErrorMessage("Failed to get podcast details", err)
+	}
+	if podcast == nil {
+		return base.SetErrorMessage("Podcast not found", "No podcast exists with this ID")
+	}
+
+	if err := s.PodcastRepository.UpdatePodcastPremium(pid, isPremium); err != nil {
+		return base.SetErrorMessage("Failed to update podcast", err)
+	}
+
+	return base.SetSuccessMessage("Podcast updated successfully")
+}
+
 func (s *PodcastService) TrackUserPodcast(userID, podcastID string, trackUserPodcastRequestDto podcastsDto.TrackUserPodcastRequestDto) base.Response {
 	uid, err := uuid.Parse(userID)
 	if err != nil {
@@ -289,6 +415,10 @@ func (s *PodcastService) TrackUserPodcast(userID, podcastID string, trackUserPod
 		}
 	}
 
+	if trackUserPodcast.IsCompleted {
+		s.Referrals.QueueReward(uid)
+	}
+
 	TrackUserPodcastDto := podcastsDto.TrackUserPodcastResponseDto{
 		ResumePosition: trackUserPodcast.ResumePosition,
 		IsCompleted:    trackUserPodcast.IsCompleted,
@@ -312,9 +442,10 @@ func (s *PodcastService) UserWatchHistory(userID string, getUserWatchHistoryRequ
 		return base.SetErrorMessage("Failed to get all podcasts by IDs", err)
 	}
 
+	canAccessPremium := s.canAccessPremium(uid)
 	podcastDtos := make([]interface{}, len(*userCompletedPodcasts))
 	for i, podcast := range *userCompletedPodcasts {
-		podcastDtos[i] = podcastsDto.MapToPodcastDTO(podcast, uid)
+		podcastDtos[i] = podcastsDto.MapToPodcastDTO(podcast, uid, canAccessPremium)
 	}
 
 	return base.SetPaginatedResponse(podcastDtos, page, perPage, totalCount)
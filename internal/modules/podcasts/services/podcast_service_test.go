@@ -1,6 +1,7 @@
 package podcasts
 
 import (
+	"context"
 	"testing"
 
 	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
@@ -29,7 +30,7 @@ func TestGetRecommendedPodcasts_InvalidUserID(t *testing.T) {
 		PodcastRepository: nil,
 	}
 
-	response := service.GetRecommendedPodcasts("invalid-uuid", []string{"valid-uuid"})
+	response := service.GetRecommendedPodcasts(context.Background(), "invalid-uuid", []string{"valid-uuid"})
 
 	assert.Equal(t, "Invalid user ID format", response.MessageTitle)
 }
@@ -40,7 +41,7 @@ func TestGetRecommendedPodcasts_InvalidCategoryID(t *testing.T) {
 	}
 
 	userID := uuid.New().String()
-	response := service.GetRecommendedPodcasts(userID, []string{"invalid-category-id"})
+	response := service.GetRecommendedPodcasts(context.Background(), userID, []string{"invalid-category-id"})
 
 	assert.Equal(t, "Invalid category ID format", response.MessageTitle)
 }
@@ -82,7 +83,7 @@ func TestLikePodcast_InvalidPodcastID(t *testing.T) {
 		PodcastRepository: nil,
 	}
 
-	response := service.LikePodcast("invalid-podcast-id", 1)
+	response := service.LikePodcast(uuid.New().String(), "invalid-podcast-id", 1)
 
 	assert.Equal(t, "Invalid podcast ID format", response.MessageTitle)
 }
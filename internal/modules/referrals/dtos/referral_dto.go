@@ -0,0 +1,56 @@
+package referrals
+
+import (
+	"time"
+
+	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/referrals/models"
+)
+
+type ReferralSummaryDTO struct {
+	Code       string `json:"code"`
+	Pending    int64  `json:"pending"`
+	Rewarded   int64  `json:"rewarded"`
+	RewardDays int    `json:"reward_days"`
+}
+
+type PromoCodeDTO struct {
+	ID               string     `json:"id"`
+	Code             string     `json:"code"`
+	PlanCode         string     `json:"plan_code"`
+	Days             int        `json:"days"`
+	MaxRedemptions   int        `json:"max_redemptions"`
+	RedemptionsCount int        `json:"redemptions_count"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	IsActive         bool       `json:"is_active"`
+}
+
+type CreatePromoCodeRequestDTO struct {
+	Code           string     `json:"code" validate:"required,alphanum,max=50"`
+	PlanCode       string     `json:"plan_code" validate:"required"`
+	Days           int        `json:"days" validate:"required,min=1"`
+	MaxRedemptions int        `json:"max_redemptions" validate:"min=0"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+}
+
+type RedeemPromoCodeRequestDTO struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type RedeemPromoCodeResponseDTO struct {
+	Code             string    `json:"code"`
+	Days             int       `json:"days"`
+	CurrentPeriodEnd time.Time `json:"current_period_end"`
+}
+
+func MapToPromoCodeDTO(promoCode models.PromoCode) PromoCodeDTO {
+	return PromoCodeDTO{
+		ID:               promoCode.ID.String(),
+		Code:             promoCode.Code,
+		PlanCode:         promoCode.PlanCode,
+		Days:             promoCode.Days,
+		MaxRedemptions:   promoCode.MaxRedemptions,
+		RedemptionsCount: promoCode.RedemptionsCount,
+		ExpiresAt:        promoCode.ExpiresAt,
+		IsActive:         promoCode.IsActive,
+	}
+}
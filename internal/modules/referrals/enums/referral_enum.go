@@ -0,0 +1,9 @@
+package referrals
+
+type ReferralStatus string
+
+const (
+	ReferralStatusPending  ReferralStatus = "pending"
+	ReferralStatusRewarded ReferralStatus = "rewarded"
+	ReferralStatusRejected ReferralStatus = "rejected"
+)
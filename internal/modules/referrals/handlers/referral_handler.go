@@ -0,0 +1,64 @@
+package referrals
+
+import (
+	"net/http"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	referralDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/referrals/dtos"
+	referralService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/referrals/services"
+	"github.com/labstack/echo/v4"
+)
+
+type ReferralHandler struct {
+	ReferralService  *referralService.ReferralService
+	PromoCodeService *referralService.PromoCodeService
+}
+
+func NewReferralHandler(referralService *referralService.ReferralService, promoCodeService *referralService.PromoCodeService) *ReferralHandler {
+	return &ReferralHandler{ReferralService: referralService, PromoCodeService: promoCodeService}
+}
+
+func (h *ReferralHandler) GetMyReferral(c echo.Context) error {
+	userID, ok := c.Get("user_id").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, base.SetErrorMessage("Unauthorized", "Invalid or missing user ID"))
+	}
+
+	response := h.ReferralService.GetMyReferral(userID)
+	return c.JSON(response.HTTPStatus, response)
+}
+
+func (h *ReferralHandler) RedeemPromoCode(c echo.Context) error {
+	userID, ok := c.Get("user_id").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, base.SetErrorMessage("Unauthorized", "Invalid or missing user ID"))
+	}
+
+	var req referralDTO.RedeemPromoCodeRequestDTO
+	if res, ok := base.BindAndValidate(c, &req); !ok {
+		return c.JSON(res.HTTPStatus, res)
+	}
+
+	response := h.PromoCodeService.RedeemPromoCode(userID, req)
+	return c.JSON(response.HTTPStatus, response)
+}
+
+func (h *ReferralHandler) GetAllPromoCodes(c echo.Context) error {
+	response := h.PromoCodeService.GetAllPromoCodes()
+	return c.JSON(response.HTTPStatus, response)
+}
+
+func (h *ReferralHandler) CreatePromoCode(c echo.Context) error {
+	var req referralDTO.CreatePromoCodeRequestDTO
+	if res, ok := base.BindAndValidate(c, &req); !ok {
+		return c.JSON(res.HTTPStatus, res)
+	}
+
+	response := h.PromoCodeService.CreatePromoCode(req)
+	return c.JSON(response.HTTPStatus, response)
+}
+
+func (h *ReferralHandler) DeactivatePromoCode(c echo.Context) error {
+	response := h.PromoCodeService.DeactivatePromoCode(c.Param("id"))
+	return c.JSON(response.HTTPStatus, response)
+}
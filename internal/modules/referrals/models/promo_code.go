@@ -0,0 +1,26 @@
+package referrals
+
+import (
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	"github.com/google/uuid"
+)
+
+// PromoCode grants premium days on a plan to whoever redeems it
+type PromoCode struct {
+	base.Model
+	Code             string     `gorm:"type:varchar(50);uniqueIndex" json:"code"`
+	PlanCode         string     `gorm:"type:varchar(50)" json:"plan_code"`
+	Days             int        `gorm:"default:0" json:"days"`
+	MaxRedemptions   int        `gorm:"default:0" json:"max_redemptions"` // 0 means unlimited
+	RedemptionsCount int        `gorm:"default:0" json:"redemptions_count"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	IsActive         bool       `gorm:"default:true" json:"is_active"`
+}
+
+type PromoRedemption struct {
+	base.Model
+	PromoCodeID uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_promo_redemption" json:"promo_code_id"`
+	UserID      uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_promo_redemption" json:"user_id"`
+}
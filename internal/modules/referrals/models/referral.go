@@ -0,0 +1,28 @@
+package referrals
+
+import (
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	referrals "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/referrals/enums"
+	"github.com/google/uuid"
+)
+
+// ReferralCode is the code a user shares to invite others
+type ReferralCode struct {
+	base.Model
+	UserID uuid.UUID `gorm:"type:uuid;uniqueIndex" json:"user_id"`
+	Code   string    `gorm:"type:varchar(16);uniqueIndex" json:"code"`
+}
+
+// Referral links an invited user to the user whose code they signed up with
+type Referral struct {
+	base.Model
+	ReferrerID     uuid.UUID                `gorm:"type:uuid;index" json:"referrer_id"`
+	RefereeID      uuid.UUID                `gorm:"type:uuid;uniqueIndex" json:"referee_id"`
+	Code           string                   `gorm:"type:varchar(16)" json:"code"`
+	Status         referrals.ReferralStatus `gorm:"type:varchar(20);index;default:'pending'" json:"status"`
+	RewardDays     int                      `gorm:"default:0" json:"reward_days"`
+	RewardedAt     *time.Time               `json:"rewarded_at,omitempty"`
+	RejectedReason string                   `gorm:"type:text" json:"rejected_reason,omitempty"`
+}
@@ -0,0 +1,262 @@
+package referrals
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	podcastModels "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/podcasts/models"
+	referrals "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/referrals/enums"
+	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/referrals/models"
+)
+
+var ErrPromoCodeExhausted = errors.New("promo code has reached its redemption limit")
+
+type ReferralRepository struct {
+	DB *gorm.DB
+}
+
+func NewReferralRepository(db *gorm.DB) *ReferralRepository {
+	return &ReferralRepository{
+		DB: db,
+	}
+}
+
+func (r *ReferralRepository) FindReferralCodeByUserID(userID uuid.UUID) (*models.ReferralCode, error) {
+	var code models.ReferralCode
+	result := r.DB.Where("user_id = ?", userID).First(&code)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to find referral code: %w", result.Error)
+	}
+	return &code, nil
+}
+
+func (r *ReferralRepository) FindReferralCodeByCode(code string) (*models.ReferralCode, error) {
+	var referralCode models.ReferralCode
+	result := r.DB.Where("code = ?", code).First(&referralCode)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to find referral code: %w", result.Error)
+	}
+	return &referralCode, nil
+}
+
+func (r *ReferralRepository) CreateReferralCode(code *models.ReferralCode) error {
+	if err := r.DB.Create(code).Error; err != nil {
+		return fmt.Errorf("failed to create referral code: %w", err)
+	}
+	return nil
+}
+
+func (r *ReferralRepository) FindReferralByRefereeID(refereeID uuid.UUID) (*models.Referral, error) {
+	var referral models.Referral
+	result := r.DB.Where("referee_id = ?", refereeID).First(&referral)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to find referral: %w", result.Error)
+	}
+	return &referral, nil
+}
+
+func (r *ReferralRepository) CreateReferral(referral *models.Referral) error {
+	if err := r.DB.Create(referral).Error; err != nil {
+		return fmt.Errorf("failed to create referral: %w", err)
+	}
+	return nil
+}
+
+// ClaimReferralReward moves a pending referral to rewarded and reports whether this call won the claim,
+// so a referral completed twice in parallel is only rewarded once
+func (r *ReferralRepository) ClaimReferralReward(referralID uuid.UUID, rewardDays int, at time.Time) (bool, error) {
+	result := r.DB.Model(&models.Referral{}).
+		Where("id = ? AND status = ?", referralID, referrals.ReferralStatusPending).
+		Updates(map[string]interface{}{
+			"status":      referrals.ReferralStatusRewarded,
+			"reward_days": rewardDays,
+			"rewarded_at": at,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to claim referral reward: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *ReferralRepository) ReleaseReferralReward(referralID uuid.UUID) error {
+	result := r.DB.Model(&models.Referral{}).
+		Where("id = ?", referralID).
+		Updates(map[string]interface{}{
+			"status":      referrals.ReferralStatusPending,
+			"reward_days": 0,
+			"rewarded_at": nil,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to release referral reward: %w", result.Error)
+	}
+	return nil
+}
+
+func (r *ReferralRepository) RejectReferral(referralID uuid.UUID, reason string) error {
+	result := r.DB.Model(&models.Referral{}).
+		Where("id = ? AND status = ?", referralID, referrals.ReferralStatusPending).
+		Updates(map[string]interface{}{
+			"status":          referrals.ReferralStatusRejected,
+			"rejected_reason": reason,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to reject referral: %w", result.Error)
+	}
+	return nil
+}
+
+func (r *ReferralRepository) CountReferralsByStatus(referrerID uuid.UUID) (map[referrals.ReferralStatus]int64, error) {
+	var rows []struct {
+		Status referrals.ReferralStatus
+		Count  int64
+	}
+	result := r.DB.Model(&models.Referral{}).
+		Select("status, COUNT(*) AS count").
+		Where("referrer_id = ?", referrerID).
+		Group("status").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to count referrals: %w", result.Error)
+	}
+
+	counts := make(map[referrals.ReferralStatus]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *ReferralRepository) CountRewardedReferralsSince(referrerID uuid.UUID, since time.Time) (int64, error) {
+	var count int64
+	result := r.DB.Model(&models.Referral{}).
+		Where("referrer_id = ? AND status = ? AND rewarded_at >= ?", referrerID, referrals.ReferralStatusRewarded, since).
+		Count(&count)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to count rewarded referrals: %w", result.Error)
+	}
+	return count, nil
+}
+
+func (r *ReferralRepository) HasCompletedPodcast(userID uuid.UUID) (bool, error) {
+	var count int64
+	result := r.DB.Model(&podcastModels.UserPodcast{}).
+		Where("user_id = ? AND is_completed = ?", userID, true).
+		Count(&count)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to check completed podcasts: %w", result.Error)
+	}
+	return count > 0, nil
+}
+
+func (r *ReferralRepository) FindAllPromoCodes() ([]models.PromoCode, error) {
+	var promoCodes []models.PromoCode
+	result := r.DB.Order("created_at DESC").Find(&promoCodes)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to fetch promo codes: %w", result.Error)
+	}
+	return promoCodes, nil
+}
+
+func (r *ReferralRepository) FindPromoCodeByID(promoCodeID uuid.UUID) (*models.PromoCode, error) {
+	var promoCode models.PromoCode
+	result := r.DB.Where("id = ?", promoCodeID).First(&promoCode)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to find promo code: %w", result.Error)
+	}
+	return &promoCode, nil
+}
+
+func (r *ReferralRepository) FindPromoCodeByCode(code string) (*models.PromoCode, error) {
+	var promoCode models.PromoCode
+	result := r.DB.Where("code = ?", code).First(&promoCode)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to find promo code: %w", result.Error)
+	}
+	return &promoCode, nil
+}
+
+func (r *ReferralRepository) CreatePromoCode(promoCode *models.PromoCode) error {
+	if err := r.DB.Create(promoCode).Error; err != nil {
+		return fmt.Errorf("failed to create promo code: %w", err)
+	}
+	return nil
+}
+
+func (r *ReferralRepository) UpdatePromoCode(promoCode *models.PromoCode) error {
+	if err := r.DB.Save(promoCode).Error; err != nil {
+		return fmt.Errorf("failed to update promo code: %w", err)
+	}
+	return nil
+}
+
+func (r *ReferralRepository) HasRedeemedPromoCode(promoCodeID, userID uuid.UUID) (bool, error) {
+	var count int64
+	result := r.DB.Model(&models.PromoRedemption{}).
+		Where("promo_code_id = ? AND user_id = ?", promoCodeID, userID).
+		Count(&count)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to check promo redemption: %w", result.Error)
+	}
+	return count > 0, nil
+}
+
+// RedeemPromoCode records the redemption and takes one use off the code's cap in a single transaction
+func (r *ReferralRepository) RedeemPromoCode(promoCodeID, userID uuid.UUID) (*models.PromoRedemption, error) {
+	redemption := &models.PromoRedemption{PromoCodeID: promoCodeID, UserID: userID}
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.PromoCode{}).
+			Where("id = ? AND (max_redemptions = 0 OR redemptions_count < max_redemptions)", promoCodeID).
+			Update("redemptions_count", gorm.Expr("redemptions_count + 1"))
+		if result.Error != nil {
+			return fmt.Errorf("failed to update promo code: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrPromoCodeExhausted
+		}
+
+		if err := tx.Create(redemption).Error; err != nil {
+			return fmt.Errorf("failed to record promo redemption: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return redemption, nil
+}
+
+// ReleasePromoRedemption undoes a redemption whose grant could not be applied
+func (r *ReferralRepository) ReleasePromoRedemption(redemption *models.PromoRedemption) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(redemption).Error; err != nil {
+			return fmt.Errorf("failed to delete promo redemption: %w", err)
+		}
+		result := tx.Model(&models.PromoCode{}).
+			Where("id = ?", redemption.PromoCodeID).
+			Update("redemptions_count", gorm.Expr("redemptions_count - 1"))
+		if result.Error != nil {
+			return fmt.Errorf("failed to update promo code: %w", result.Error)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,44 @@
+package referrals
+
+import (
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/config"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/jobs"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/jwtkeys"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/middlewares"
+	referralHandler "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/referrals/handlers"
+	referralRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/referrals/repositories"
+	referralService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/referrals/services"
+	roles "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/enums"
+	subscriptionRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/repositories"
+	subscriptionService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/services"
+	userRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/repositories"
+)
+
+func RegisterRoutes(e *echo.Echo, db *gorm.DB, cfg *config.Config) {
+	keys := jwtkeys.Default()
+
+	authRepo := userRepository.NewAuthRepository(db)
+	userRepo := userRepository.NewUserRepository(db)
+	referralRepo := referralRepository.NewReferralRepository(db)
+	subscriptionRepo := subscriptionRepository.NewSubscriptionRepository(db)
+	newSubscriptionService := subscriptionService.NewSubscriptionService(subscriptionRepo, userRepo)
+	newReferralService := referralService.NewReferralService(referralRepo, userRepo, newSubscriptionService, cfg.Referrals)
+	newPromoCodeService := referralService.NewPromoCodeService(referralRepo, subscriptionRepo, newSubscriptionService)
+	newReferralHandler := referralHandler.NewReferralHandler(newReferralService, newPromoCodeService)
+
+	jobs.Register(referralService.ReferralRewardJobName, newReferralService.RewardReferral)
+
+	referralGroup := e.Group("/referrals", middlewares.AuthMiddleware(authRepo, keys))
+	referralGroup.GET("/me", newReferralHandler.GetMyReferral)
+
+	promoGroup := e.Group("/promo-codes", middlewares.AuthMiddleware(authRepo, keys))
+	promoGroup.POST("/redeem", newReferralHandler.RedeemPromoCode)
+
+	adminPromoGroup := e.Group("/admin/promo-codes", middlewares.RequirePermission(keys, roles.PermissionSubscriptionsWrite))
+	adminPromoGroup.GET("/", newReferralHandler.GetAllPromoCodes)
+	adminPromoGroup.POST("/", newReferralHandler.CreatePromoCode)
+	adminPromoGroup.DELETE("/:id", newReferralHandler.DeactivatePromoCode)
+}
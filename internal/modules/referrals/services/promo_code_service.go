@@ -0,0 +1,158 @@
+package referrals
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	referralDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/referrals/dtos"
+	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/referrals/models"
+	referralRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/referrals/repositories"
+	subscriptions "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/enums"
+	subscriptionRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/repositories"
+	subscriptionService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/services"
+	"github.com/google/uuid"
+)
+
+type PromoCodeService struct {
+	ReferralRepo     *referralRepository.ReferralRepository
+	SubscriptionRepo *subscriptionRepository.SubscriptionRepository
+	StateManager     subscriptionService.StateManager
+}
+
+func NewPromoCodeService(referralRepo *referralRepository.ReferralRepository, subscriptionRepo *subscriptionRepository.SubscriptionRepository, stateManager subscriptionService.StateManager) *PromoCodeService {
+	return &PromoCodeService{
+		ReferralRepo:     referralRepo,
+		SubscriptionRepo: subscriptionRepo,
+		StateManager:     stateManager,
+	}
+}
+
+func (s *PromoCodeService) GetAllPromoCodes() base.Response {
+	promoCodes, err := s.ReferralRepo.FindAllPromoCodes()
+	if err != nil {
+		return base.SetErrorMessage("Failed to fetch promo codes", err)
+	}
+
+	response := make([]referralDTO.PromoCodeDTO, len(promoCodes))
+	for i, promoCode := range promoCodes {
+		response[i] = referralDTO.MapToPromoCodeDTO(promoCode)
+	}
+	return base.SetData(response)
+}
+
+func (s *PromoCodeService) CreatePromoCode(req referralDTO.CreatePromoCodeRequestDTO) base.Response {
+	code := strings.ToUpper(strings.TrimSpace(req.Code))
+
+	existing, err := s.ReferralRepo.FindPromoCodeByCode(code)
+	if err != nil {
+		return base.SetErrorMessage("Failed to fetch promo code", err)
+	}
+	if existing != nil {
+		return base.SetErrorMessage("Promo code already exists", "A promo code with this code already exists")
+	}
+
+	plan, err := s.SubscriptionRepo.FindPlanByCode(req.PlanCode)
+	if err != nil {
+		return base.SetErrorMessage("Failed to fetch plan", err)
+	}
+	if plan == nil {
+		return base.SetErrorMessage("Plan not found", "No plan exists with this code")
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return base.SetErrorMessage("Invalid expiry", "Expiry must be in the future")
+	}
+
+	promoCode := &models.PromoCode{
+		Code:           code,
+		PlanCode:       plan.Code,
+		Days:           req.Days,
+		MaxRedemptions: req.MaxRedemptions,
+		ExpiresAt:      req.ExpiresAt,
+		IsActive:       true,
+	}
+	if err := s.ReferralRepo.CreatePromoCode(promoCode); err != nil {
+		return base.SetErrorMessage("Failed to create promo code", err)
+	}
+
+	return base.SetData(referralDTO.MapToPromoCodeDTO(*promoCode), "Promo code created successfully")
+}
+
+func (s *PromoCodeService) DeactivatePromoCode(promoCodeID string) base.Response {
+	pid, err := uuid.Parse(promoCodeID)
+	if err != nil {
+		return base.SetErrorMessage("Invalid Promo Code ID", err)
+	}
+
+	promoCode, err := s.ReferralRepo.FindPromoCodeByID(pid)
+	if err != nil {
+		return base.SetErrorMessage("Failed to fetch promo code", err)
+	}
+	if promoCode == nil {
+		return base.SetErrorMessage("Promo code not found", "No promo code exists with this ID")
+	}
+
+	promoCode.IsActive = false
+	if err := s.ReferralRepo.UpdatePromoCode(promoCode); err != nil {
+		return base.SetErrorMessage("Failed to update promo code", err)
+	}
+
+	return base.SetSuccessMessage("Promo code deactivated successfully")
+}
+
+func (s *PromoCodeService) RedeemPromoCode(userID string, req referralDTO.RedeemPromoCodeRequestDTO) base.Response {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return base.SetErrorMessage("Invalid User ID", err)
+	}
+
+	promoCode, err := s.ReferralRepo.FindPromoCodeByCode(strings.ToUpper(strings.TrimSpace(req.Code)))
+	if err != nil {
+		return base.SetErrorMessage("Failed to fetch promo code", err)
+	}
+	if promoCode == nil || !promoCode.IsActive {
+		return base.SetErrorMessage("Invalid promo code", "This promo code does not exist")
+	}
+	if promoCode.ExpiresAt != nil && !promoCode.ExpiresAt.After(time.Now()) {
+		return base.SetErrorMessage("Promo code expired", "This promo code has expired")
+	}
+
+	redeemed, err := s.ReferralRepo.HasRedeemedPromoCode(promoCode.ID, uid)
+	if err != nil {
+		return base.SetErrorMessage("Failed to check promo code", err)
+	}
+	if redeemed {
+		return base.SetErrorMessage("Promo code already redeemed", "You have already redeemed this promo code")
+	}
+
+	redemption, err := s.ReferralRepo.RedeemPromoCode(promoCode.ID, uid)
+	if errors.Is(err, referralRepository.ErrPromoCodeExhausted) {
+		return base.SetErrorMessage("Promo code exhausted", "This promo code has reached its redemption limit")
+	}
+	if err != nil {
+		return base.SetErrorMessage("Failed to redeem promo code", err)
+	}
+
+	subscription, err := s.StateManager.Apply(subscriptionService.StateChange{
+		Event:    subscriptions.EventGranted,
+		Provider: subscriptions.ProviderPromo,
+		UserID:   uid,
+		PlanCode: promoCode.PlanCode,
+		Days:     promoCode.Days,
+	})
+	if err != nil {
+		if releaseErr := s.ReferralRepo.ReleasePromoRedemption(redemption); releaseErr != nil {
+			slog.Error("Failed to release promo redemption", "redemption_id", redemption.ID, "error", releaseErr)
+		}
+		return base.SetErrorMessage("Failed to redeem promo code", err)
+	}
+
+	return base.SetData(referralDTO.RedeemPromoCodeResponseDTO{
+		Code:             promoCode.Code,
+		Days:             promoCode.Days,
+		CurrentPeriodEnd: subscription.CurrentPeriodEnd,
+	}, "Promo code redeemed successfully")
+}
@@ -0,0 +1,291 @@
+package referrals
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/config"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/jobs"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/logger"
+	referralDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/referrals/dtos"
+	referrals "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/referrals/enums"
+	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/referrals/models"
+	referralRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/referrals/repositories"
+	subscriptions "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/enums"
+	subscriptionService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/services"
+	userModels "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/models"
+	userRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/repositories"
+	"github.com/google/uuid"
+)
+
+const (
+	ReferralRewardJobName = "referrals.reward"
+
+	// referral codes avoid characters that are easy to confuse when read aloud or typed
+	referralCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	referralCodeLength   = 8
+
+	// maxReferralRewardsPerMonth caps how many invites can earn premium days in a rolling 30 days
+	maxReferralRewardsPerMonth = 10
+)
+
+var ErrSelfReferral = errors.New("users cannot refer themselves")
+
+type ReferralService struct {
+	ReferralRepo *referralRepository.ReferralRepository
+	UserRepo     *userRepository.UserRepository
+	StateManager subscriptionService.StateManager
+	RewardDays   int
+	RewardPlan   string
+}
+
+func NewReferralService(referralRepo *referralRepository.ReferralRepository, userRepo *userRepository.UserRepository, stateManager subscriptionService.StateManager, cfg config.ReferralsConfig) *ReferralService {
+	return &ReferralService{
+		ReferralRepo: referralRepo,
+		UserRepo:     userRepo,
+		StateManager: stateManager,
+		RewardDays:   cfg.RewardDays,
+		RewardPlan:   cfg.RewardPlan,
+	}
+}
+
+func (s *ReferralService) GetMyReferral(userID string) base.Response {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return base.SetErrorMessage("Invalid User ID", err)
+	}
+
+	code, err := s.getOrCreateReferralCode(uid)
+	if err != nil {
+		return base.SetErrorMessage("Failed to fetch referral code", err)
+	}
+
+	counts, err := s.ReferralRepo.CountReferralsByStatus(uid)
+	if err != nil {
+		return base.SetErrorMessage("Failed to fetch referrals", err)
+	}
+
+	return base.SetData(referralDTO.ReferralSummaryDTO{
+		Code:       code.Code,
+		Pending:    counts[referrals.ReferralStatusPending],
+		Rewarded:   counts[referrals.ReferralStatusRewarded],
+		RewardDays: s.RewardDays,
+	})
+}
+
+// getOrCreateReferralCode returns the user's referral code, generating one on first use
+func (s *ReferralService) getOrCreateReferralCode(userID uuid.UUID) (*models.ReferralCode, error) {
+	code, err := s.ReferralRepo.FindReferralCodeByUserID(userID)
+	if err != nil || code != nil {
+		return code, err
+	}
+
+	for attempt := 0; attempt < 5; attempt++ {
+		generated, err := generateReferralCode()
+		if err != nil {
+			return nil, err
+		}
+		existing, err := s.ReferralRepo.FindReferralCodeByCode(generated)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			continue
+		}
+
+		code = &models.ReferralCode{UserID: userID, Code: generated}
+		if err := s.ReferralRepo.CreateReferralCode(code); err != nil {
+			return nil, err
+		}
+		return code, nil
+	}
+	return nil, errors.New("failed to generate a unique referral code")
+}
+
+func generateReferralCode() (string, error) {
+	var builder strings.Builder
+	max := big.NewInt(int64(len(referralCodeAlphabet)))
+	for i := 0; i < referralCodeLength; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate referral code: %w", err)
+		}
+		builder.WriteByte(referralCodeAlphabet[n.Int64()])
+	}
+	return builder.String(), nil
+}
+
+// LinkReferral records that a newly created account signed up with a referral code.
+// An empty code is a no-op; codes that would amount to a self-referral are rejected.
+func (s *ReferralService) LinkReferral(refereeID uuid.UUID, code string) error {
+	if s == nil {
+		return nil
+	}
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return nil
+	}
+
+	referralCode, err := s.ReferralRepo.FindReferralCodeByCode(code)
+	if err != nil {
+		return err
+	}
+	if referralCode == nil {
+		return fmt.Errorf("unknown referral code %q", code)
+	}
+
+	existing, err := s.ReferralRepo.FindReferralByRefereeID(refereeID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return nil
+	}
+
+	referrer, err := s.UserRepo.FindOneByID(referralCode.UserID)
+	if err != nil {
+		return err
+	}
+	referee, err := s.UserRepo.FindOneByID(refereeID)
+	if err != nil {
+		return err
+	}
+	if referrer == nil || referee == nil {
+		return errors.New("referral user not found")
+	}
+
+	if isSelfReferral(referrer, referee) {
+		return ErrSelfReferral
+	}
+
+	// the referrer must not have been invited by the account now using their code
+	referrerReferral, err := s.ReferralRepo.FindReferralByRefereeID(referrer.ID)
+	if err != nil {
+		return err
+	}
+	if referrerReferral != nil && referrerReferral.ReferrerID == referee.ID {
+		return ErrSelfReferral
+	}
+
+	return s.ReferralRepo.CreateReferral(&models.Referral{
+		ReferrerID: referrer.ID,
+		RefereeID:  referee.ID,
+		Code:       code,
+		Status:     referrals.ReferralStatusPending,
+	})
+}
+
+// isSelfReferral reports whether two accounts look like the same person
+func isSelfReferral(referrer, referee *userModels.User) bool {
+	if referrer.ID == referee.ID {
+		return true
+	}
+	if referrer.Mobile != "" && referrer.Mobile == referee.Mobile {
+		return true
+	}
+	if referrer.Email != "" && normalizeEmail(referrer.Email) == normalizeEmail(referee.Email) {
+		return true
+	}
+	return false
+}
+
+// normalizeEmail folds the aliases mail providers deliver to the same inbox,
+// such as plus-addressing and Gmail's ignored dots
+func normalizeEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	local, domain, found := strings.Cut(email, "@")
+	if !found {
+		return email
+	}
+	if plus := strings.Index(local, "+"); plus >= 0 {
+		local = local[:plus]
+	}
+	if domain == "googlemail.com" {
+		domain = "gmail.com"
+	}
+	if domain == "gmail.com" {
+		local = strings.ReplaceAll(local, ".", "")
+	}
+	return local + "@" + domain
+}
+
+// QueueReward schedules a check of the user's pending referral after they complete a podcast
+func (s *ReferralService) QueueReward(refereeID uuid.UUID) {
+	if s == nil {
+		return
+	}
+	if err := jobs.Enqueue(ReferralRewardJobName, refereeID.String()); err != nil {
+		slog.Error("Failed to queue referral reward", "referee_id", refereeID, "error", err)
+	}
+}
+
+// RewardReferral is the job handler that grants the referrer premium days once the referee
+// has completed their first podcast
+func (s *ReferralService) RewardReferral(ctx context.Context, payload string) error {
+	refereeID, err := uuid.Parse(payload)
+	if err != nil {
+		return fmt.Errorf("invalid referee ID %q: %w", payload, err)
+	}
+
+	referral, err := s.ReferralRepo.FindReferralByRefereeID(refereeID)
+	if err != nil {
+		return err
+	}
+	if referral == nil || referral.Status != referrals.ReferralStatusPending {
+		return nil
+	}
+
+	completed, err := s.ReferralRepo.HasCompletedPodcast(refereeID)
+	if err != nil {
+		return err
+	}
+	if !completed {
+		return nil
+	}
+
+	referrer, err := s.UserRepo.FindOneByID(referral.ReferrerID)
+	if err != nil {
+		return err
+	}
+	if referrer == nil || referrer.DeletionRequestedAt != nil {
+		return s.ReferralRepo.RejectReferral(referral.ID, "referrer account is no longer active")
+	}
+
+	now := time.Now()
+	rewarded, err := s.ReferralRepo.CountRewardedReferralsSince(referrer.ID, now.AddDate(0, 0, -30))
+	if err != nil {
+		return err
+	}
+	if rewarded >= maxReferralRewardsPerMonth {
+		return s.ReferralRepo.RejectReferral(referral.ID, "referrer reached the monthly reward limit")
+	}
+
+	claimed, err := s.ReferralRepo.ClaimReferralReward(referral.ID, s.RewardDays, now)
+	if err != nil || !claimed {
+		return err
+	}
+
+	_, err = s.StateManager.Apply(subscriptionService.StateChange{
+		Event:    subscriptions.EventGranted,
+		Provider: subscriptions.ProviderReferral,
+		UserID:   referrer.ID,
+		PlanCode: s.RewardPlan,
+		Days:     s.RewardDays,
+	})
+	if err != nil {
+		if releaseErr := s.ReferralRepo.ReleaseReferralReward(referral.ID); releaseErr != nil {
+			logger.FromContext(ctx).Error("Failed to release referral", "referral_id", referral.ID, "error", releaseErr)
+		}
+		return fmt.Errorf("failed to grant referral reward: %w", err)
+	}
+
+	logger.FromContext(ctx).Info("Granted referral reward", "referral_id", referral.ID, "referrer_id", referrer.ID, "days", s.RewardDays)
+	return nil
+}
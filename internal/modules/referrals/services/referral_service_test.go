@@ -0,0 +1,69 @@
+package referrals
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	referralDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/referrals/dtos"
+	userModels "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	assert.Equal(t, "johndoe@gmail.com", normalizeEmail("John.Doe+invite@GoogleMail.com"))
+	assert.Equal(t, "john.doe@example.com", normalizeEmail(" john.doe+1@example.com "))
+}
+
+func TestIsSelfReferral(t *testing.T) {
+	referrer := &userModels.User{Email: "john.doe@gmail.com", Mobile: "+966500000001"}
+	referrer.ID = uuid.New()
+
+	alias := &userModels.User{Email: "johndoe+2@gmail.com"}
+	alias.ID = uuid.New()
+	sameMobile := &userModels.User{Email: "mobile_966500000001@placeholder.com", Mobile: "+966500000001"}
+	sameMobile.ID = uuid.New()
+	friend := &userModels.User{Email: "jane@example.com", Mobile: "+966500000002"}
+	friend.ID = uuid.New()
+
+	assert.True(t, isSelfReferral(referrer, referrer))
+	assert.True(t, isSelfReferral(referrer, alias))
+	assert.True(t, isSelfReferral(referrer, sameMobile))
+	assert.False(t, isSelfReferral(referrer, friend))
+}
+
+func TestGenerateReferralCode(t *testing.T) {
+	code, err := generateReferralCode()
+
+	require.NoError(t, err)
+	assert.Len(t, code, referralCodeLength)
+	for _, c := range code {
+		assert.True(t, strings.ContainsRune(referralCodeAlphabet, c))
+	}
+}
+
+func TestLinkReferral_EmptyCode(t *testing.T) {
+	service := ReferralService{}
+
+	assert.NoError(t, service.LinkReferral(uuid.New(), "  "))
+}
+
+func TestGetMyReferral_InvalidUserID(t *testing.T) {
+	service := ReferralService{}
+
+	response := service.GetMyReferral("invalid-uuid")
+
+	assert.Equal(t, http.StatusBadRequest, response.HTTPStatus)
+	assert.Equal(t, "Invalid User ID", response.MessageTitle)
+}
+
+func TestRedeemPromoCode_InvalidUserID(t *testing.T) {
+	service := PromoCodeService{}
+
+	response := service.RedeemPromoCode("invalid-uuid", referralDTO.RedeemPromoCodeRequestDTO{Code: "WELCOME"})
+
+	assert.Equal(t, http.StatusBadRequest, response.HTTPStatus)
+	assert.Equal(t, "Invalid User ID", response.MessageTitle)
+}
@@ -0,0 +1,24 @@
+package roles
+
+// RoleDTO describes a role and the permissions it grants.
+// swagger:model RoleDTO
+type RoleDTO struct {
+	ID          string   `json:"id" example:"abcd1234"`
+	Name        string   `json:"name" example:"editor"`
+	Description string   `json:"description" example:"Content editors"`
+	Permissions []string `json:"permissions" example:"[\"podcasts:write\",\"categories:write\"]"`
+}
+
+// AssignRoleRequestDTO defines the body for assigning a role to a user.
+// swagger:model AssignRoleRequestDTO
+type AssignRoleRequestDTO struct {
+	Role string `json:"role" validate:"required" example:"editor"`
+}
+
+// UserRolesResponseDTO lists the roles and effective permissions of a user.
+// swagger:model UserRolesResponseDTO
+type UserRolesResponseDTO struct {
+	UserID      string   `json:"user_id" example:"abcd1234"`
+	Roles       []string `json:"roles" example:"[\"editor\"]"`
+	Permissions []string `json:"permissions" example:"[\"podcasts:write\"]"`
+}
@@ -0,0 +1,47 @@
+package roles
+
+const (
+	PermissionAll                = "*"
+	PermissionPodcastsWrite      = "podcasts:write"
+	PermissionCategoriesWrite    = "categories:write"
+	PermissionUsersRead          = "users:read"
+	PermissionUsersWrite         = "users:write"
+	PermissionUsersDelete        = "users:delete"
+	PermissionRolesManage        = "roles:manage"
+	PermissionCampaignsSend      = "campaigns:send"
+	PermissionSubscriptionsWrite = "subscriptions:write"
+	PermissionAuditRead          = "audit:read"
+	PermissionUsersImpersonate   = "users:impersonate"
+	PermissionAnalyticsRead      = "analytics:read"
+)
+
+const (
+	RoleSuperAdmin = "superadmin"
+	RoleEditor     = "editor"
+	RoleModerator  = "moderator"
+	RoleSupport    = "support"
+)
+
+// Permissions lists every permission key together with its description
+var Permissions = map[string]string{
+	PermissionAll:                "Every permission",
+	PermissionPodcastsWrite:      "Create, update and delete podcasts",
+	PermissionCategoriesWrite:    "Create, update and delete categories",
+	PermissionUsersRead:          "View users",
+	PermissionUsersWrite:         "Update users",
+	PermissionUsersDelete:        "Delete users",
+	PermissionRolesManage:        "Assign and revoke roles",
+	PermissionCampaignsSend:      "Send notification campaigns",
+	PermissionSubscriptionsWrite: "Manage plans and grant subscriptions",
+	PermissionAuditRead:          "View and export the audit log",
+	PermissionUsersImpersonate:   "Act as a user to see what they see, read-only unless also allowed to update users",
+	PermissionAnalyticsRead:      "View category analytics",
+}
+
+// DefaultRoles is the seeded role set and the permissions each role grants
+var DefaultRoles = map[string][]string{
+	RoleSuperAdmin: {PermissionAll},
+	RoleEditor:     {PermissionPodcastsWrite, PermissionCategoriesWrite, PermissionCampaignsSend, PermissionAnalyticsRead},
+	RoleModerator:  {PermissionUsersRead, PermissionUsersWrite, PermissionUsersDelete, PermissionPodcastsWrite},
+	RoleSupport:    {PermissionUsersRead, PermissionUsersImpersonate},
+}
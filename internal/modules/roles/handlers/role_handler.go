@@ -0,0 +1,75 @@
+package roles
+
+import (
+	base "github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	roleDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/dtos"
+	roleService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/services"
+	"github.com/labstack/echo/v4"
+)
+
+type RoleHandler struct {
+	RoleService *roleService.RoleService
+}
+
+func NewRoleHandler(roleService *roleService.RoleService) *RoleHandler {
+	return &RoleHandler{RoleService: roleService}
+}
+
+// @Summary     List roles
+// @Description Returns every role together with the permissions it grants
+// @Tags        roles
+// @Produce     json
+// @Success     200  {array}   roleDTO.RoleDTO
+// @Failure     400  {object}  echo.HTTPError
+// @Router      /admin/roles [get]
+func (h *RoleHandler) GetRoles(c echo.Context) error {
+	response := h.RoleService.GetAllRoles()
+	return c.JSON(response.HTTPStatus, response)
+}
+
+// @Summary     Get a user's roles
+// @Description Returns the roles and effective permissions of a user
+// @Tags        roles
+// @Produce     json
+// @Param       user_id  path      string  true  "User ID"
+// @Success     200      {object}  roleDTO.UserRolesResponseDTO
+// @Failure     400      {object}  echo.HTTPError
+// @Router      /admin/users/{user_id}/roles [get]
+func (h *RoleHandler) GetUserRoles(c echo.Context) error {
+	response := h.RoleService.GetUserRoles(c.Param("user_id"))
+	return c.JSON(response.HTTPStatus, response)
+}
+
+// @Summary     Assign a role to a user
+// @Description Grants the named role to a user
+// @Tags        roles
+// @Accept      json
+// @Produce     json
+// @Param       user_id  path      string                        true  "User ID"
+// @Param       request  body      roleDTO.AssignRoleRequestDTO  true  "Role to assign"
+// @Success     200      {object}  roleDTO.UserRolesResponseDTO
+// @Failure     400      {object}  echo.HTTPError
+// @Router      /admin/users/{user_id}/roles [post]
+func (h *RoleHandler) AssignRole(c echo.Context) error {
+	var req roleDTO.AssignRoleRequestDTO
+	if res, ok := base.BindAndValidate(c, &req); !ok {
+		return c.JSON(res.HTTPStatus, res)
+	}
+
+	response := h.RoleService.AssignRole(c.Request().Context(), c.Param("user_id"), req.Role)
+	return c.JSON(response.HTTPStatus, response)
+}
+
+// @Summary     Revoke a role from a user
+// @Description Removes the named role from a user
+// @Tags        roles
+// @Produce     json
+// @Param       user_id  path      string  true  "User ID"
+// @Param       role     path      string  true  "Role name"
+// @Success     200      {object}  roleDTO.UserRolesResponseDTO
+// @Failure     400      {object}  echo.HTTPError
+// @Router      /admin/users/{user_id}/roles/{role} [delete]
+func (h *RoleHandler) RevokeRole(c echo.Context) error {
+	response := h.RoleService.RevokeRole(c.Request().Context(), c.Param("user_id"), c.Param("role"))
+	return c.JSON(response.HTTPStatus, response)
+}
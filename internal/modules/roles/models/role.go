@@ -0,0 +1,28 @@
+package roles
+
+import (
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+)
+
+type Role struct {
+	base.Model
+	Name        string       `gorm:"type:varchar(50);uniqueIndex" json:"name"`
+	Description string       `gorm:"type:varchar(255)" json:"description"`
+	Permissions []Permission `gorm:"many2many:role_permissions" json:"permissions"`
+}
+
+type Permission struct {
+	base.Model
+	Key         string `gorm:"type:varchar(100);uniqueIndex" json:"key"`
+	Description string `gorm:"type:varchar(255)" json:"description"`
+}
+
+// HasPermission reports whether the role grants the permission, either directly or through the wildcard
+func (r Role) HasPermission(key string) bool {
+	for _, permission := range r.Permissions {
+		if permission.Key == key || permission.Key == "*" {
+			return true
+		}
+	}
+	return false
+}
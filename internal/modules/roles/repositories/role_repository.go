@@ -0,0 +1,96 @@
+package roles
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/models"
+)
+
+type RoleRepository struct {
+	DB *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) *RoleRepository {
+	return &RoleRepository{
+		DB: db,
+	}
+}
+
+func (r *RoleRepository) FindAllRoles() ([]models.Role, error) {
+	var roles []models.Role
+	result := r.DB.Preload("Permissions").Order("name").Find(&roles)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to fetch roles: %w", result.Error)
+	}
+	return roles, nil
+}
+
+func (r *RoleRepository) FindRoleByName(name string) (*models.Role, error) {
+	var role models.Role
+	result := r.DB.Preload("Permissions").Where("name = ?", name).First(&role)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find role: %w", result.Error)
+	}
+	return &role, nil
+}
+
+func (r *RoleRepository) FindUserRoles(userID uuid.UUID) ([]models.Role, error) {
+	var roles []models.Role
+	result := r.DB.Preload("Permissions").
+		Joins("INNER JOIN user_roles ON user_roles.role_id = roles.id").
+		Where("user_roles.user_id = ?", userID).
+		Find(&roles)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to fetch user roles: %w", result.Error)
+	}
+	return roles, nil
+}
+
+func (r *RoleRepository) AssignRole(userID, roleID uuid.UUID) error {
+	result := r.DB.Exec("INSERT INTO user_roles (user_id, role_id) VALUES (?, ?) ON CONFLICT DO NOTHING", userID, roleID)
+	if result.Error != nil {
+		return fmt.Errorf("failed to assign role: %w", result.Error)
+	}
+	return nil
+}
+
+func (r *RoleRepository) RevokeRole(userID, roleID uuid.UUID) error {
+	result := r.DB.Exec("DELETE FROM user_roles WHERE user_id = ? AND role_id = ?", userID, roleID)
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke role: %w", result.Error)
+	}
+	return nil
+}
+
+// UpsertRole creates the role if it does not exist and replaces its permissions
+func (r *RoleRepository) UpsertRole(name string, permissionKeys []string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		var permissions []models.Permission
+		if err := tx.Where("key IN ?", permissionKeys).Find(&permissions).Error; err != nil {
+			return err
+		}
+
+		var role models.Role
+		if err := tx.Where(models.Role{Name: name}).FirstOrCreate(&role).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&role).Association("Permissions").Replace(permissions)
+	})
+}
+
+func (r *RoleRepository) UpsertPermission(key, description string) error {
+	permission := models.Permission{Key: key}
+	result := r.DB.Where(models.Permission{Key: key}).Assign(models.Permission{Description: description}).FirstOrCreate(&permission)
+	if result.Error != nil {
+		return fmt.Errorf("failed to upsert permission: %w", result.Error)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package roles
+
+import (
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/config"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/jwtkeys"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/middlewares"
+	roleHandler "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/handlers"
+	roleRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/repositories"
+	roleService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/services"
+	userRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/repositories"
+
+	roles "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/enums"
+)
+
+func RegisterRoutes(e *echo.Echo, db *gorm.DB, cfg *config.Config) {
+	keys := jwtkeys.Default()
+
+	roleRepo := roleRepository.NewRoleRepository(db)
+	userRepo := userRepository.NewUserRepository(db)
+	newRoleService := roleService.NewRoleService(roleRepo, userRepo)
+	newRoleHandler := roleHandler.NewRoleHandler(newRoleService)
+
+	adminRoleGroup := e.Group("/admin", middlewares.RequirePermission(keys, roles.PermissionRolesManage))
+	adminRoleGroup.GET("/roles", newRoleHandler.GetRoles)
+	adminRoleGroup.GET("/users/:user_id/roles", newRoleHandler.GetUserRoles)
+	adminRoleGroup.POST("/users/:user_id/roles", newRoleHandler.AssignRole)
+	adminRoleGroup.DELETE("/users/:user_id/roles/:role", newRoleHandler.RevokeRole)
+}
@@ -0,0 +1,212 @@
+package roles
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	audit "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/audit/enums"
+	auditService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/audit/services"
+	roleDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/dtos"
+	roles "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/enums"
+	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/models"
+	roleRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/repositories"
+	userRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/repositories"
+	"github.com/google/uuid"
+)
+
+type RoleService struct {
+	RoleRepo *roleRepository.RoleRepository
+	UserRepo *userRepository.UserRepository
+}
+
+func NewRoleService(roleRepo *roleRepository.RoleRepository, userRepo *userRepository.UserRepository) *RoleService {
+	return &RoleService{
+		RoleRepo: roleRepo,
+		UserRepo: userRepo,
+	}
+}
+
+func (s *RoleService) GetAllRoles() base.Response {
+	allRoles, err := s.RoleRepo.FindAllRoles()
+	if err != nil {
+		return base.SetErrorMessage("Failed to fetch roles", err)
+	}
+
+	response := make([]roleDTO.RoleDTO, len(allRoles))
+	for i, role := range allRoles {
+		response[i] = mapRoleDTO(role)
+	}
+
+	return base.SetData(response)
+}
+
+func (s *RoleService) GetUserRoles(userID string) base.Response {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return base.SetErrorMessage("Invalid User ID", err)
+	}
+
+	userRoles, err := s.RoleRepo.FindUserRoles(uid)
+	if err != nil {
+		return base.SetErrorMessage("Failed to fetch user roles", err)
+	}
+
+	return base.SetData(mapUserRolesDTO(uid, userRoles))
+}
+
+func (s *RoleService) AssignRole(ctx context.Context, userID string, roleName string) base.Response {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return base.SetErrorMessage("Invalid User ID", err)
+	}
+
+	user, err := s.UserRepo.FindOneByID(uid)
+	if err != nil {
+		return base.SetErrorMessage("Failed to fetch user", err)
+	}
+	if user == nil {
+		return base.SetErrorMessage("User not found", "No user exists with this ID")
+	}
+
+	role, err := s.RoleRepo.FindRoleByName(roleName)
+	if err != nil {
+		return base.SetErrorMessage("Failed to fetch role", err)
+	}
+	if role == nil {
+		return base.SetErrorMessage("Role not found", "No role exists with this name")
+	}
+
+	before, err := s.RoleRepo.FindUserRoles(uid)
+	if err != nil {
+		return base.SetErrorMessage("Failed to fetch user roles", err)
+	}
+
+	if err := s.RoleRepo.AssignRole(uid, role.ID); err != nil {
+		return base.SetErrorMessage("Failed to assign role", err)
+	}
+	s.recordRoleChange(ctx, audit.ActionRoleAssigned, uid, role.Name, before)
+
+	return s.GetUserRoles(userID)
+}
+
+func (s *RoleService) RevokeRole(ctx context.Context, userID string, roleName string) base.Response {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return base.SetErrorMessage("Invalid User ID", err)
+	}
+
+	role, err := s.RoleRepo.FindRoleByName(roleName)
+	if err != nil {
+		return base.SetErrorMessage("Failed to fetch role", err)
+	}
+	if role == nil {
+		return base.SetErrorMessage("Role not found", "No role exists with this name")
+	}
+
+	before, err := s.RoleRepo.FindUserRoles(uid)
+	if err != nil {
+		return base.SetErrorMessage("Failed to fetch user roles", err)
+	}
+
+	if err := s.RoleRepo.RevokeRole(uid, role.ID); err != nil {
+		return base.SetErrorMessage("Failed to revoke role", err)
+	}
+	s.recordRoleChange(ctx, audit.ActionRoleRevoked, uid, role.Name, before)
+
+	return s.GetUserRoles(userID)
+}
+
+// recordRoleChange audits an assignment or revocation with the user's role names before and after it
+func (s *RoleService) recordRoleChange(ctx context.Context, action string, userID uuid.UUID, roleName string, before []models.Role) {
+	entry := auditService.Entry{
+		Action:     action,
+		TargetType: audit.TargetUser,
+		TargetID:   userID.String(),
+		Before:     map[string]interface{}{"roles": roleNames(before)},
+		Metadata:   map[string]interface{}{"role": roleName},
+	}
+	if after, err := s.RoleRepo.FindUserRoles(userID); err == nil {
+		entry.After = map[string]interface{}{"roles": roleNames(after)}
+	}
+	auditService.Record(ctx, entry)
+}
+
+func roleNames(userRoles []models.Role) []string {
+	names := make([]string, len(userRoles))
+	for i, role := range userRoles {
+		names[i] = role.Name
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HasPermissions reports whether the roles grant every one of the permissions
+func HasPermissions(userRoles []models.Role, permissions ...string) bool {
+	for _, permission := range permissions {
+		granted := false
+		for _, role := range userRoles {
+			if role.HasPermission(permission) {
+				granted = true
+				break
+			}
+		}
+		if !granted {
+			return false
+		}
+	}
+	return true
+}
+
+// SeedDefaultRoles creates the default permissions and roles and keeps their grants up to date
+func SeedDefaultRoles(roleRepo *roleRepository.RoleRepository) error {
+	for key, description := range roles.Permissions {
+		if err := roleRepo.UpsertPermission(key, description); err != nil {
+			return err
+		}
+	}
+
+	for name, permissions := range roles.DefaultRoles {
+		if err := roleRepo.UpsertRole(name, permissions); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func mapRoleDTO(role models.Role) roleDTO.RoleDTO {
+	permissions := make([]string, len(role.Permissions))
+	for i, permission := range role.Permissions {
+		permissions[i] = permission.Key
+	}
+	sort.Strings(permissions)
+
+	return roleDTO.RoleDTO{
+		ID:          role.ID.String(),
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: permissions,
+	}
+}
+
+func mapUserRolesDTO(userID uuid.UUID, userRoles []models.Role) roleDTO.UserRolesResponseDTO {
+	permissionSet := make(map[string]struct{})
+	for _, role := range userRoles {
+		for _, permission := range role.Permissions {
+			permissionSet[permission.Key] = struct{}{}
+		}
+	}
+
+	permissions := make([]string, 0, len(permissionSet))
+	for permission := range permissionSet {
+		permissions = append(permissions, permission)
+	}
+	sort.Strings(permissions)
+
+	return roleDTO.UserRolesResponseDTO{
+		UserID:      userID.String(),
+		Roles:       roleNames(userRoles),
+		Permissions: permissions,
+	}
+}
@@ -0,0 +1,44 @@
+package roles
+
+import (
+	"context"
+	"testing"
+
+	roles "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/enums"
+	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func roleWith(name string, permissions ...string) models.Role {
+	role := models.Role{Name: name}
+	for _, permission := range permissions {
+		role.Permissions = append(role.Permissions, models.Permission{Key: permission})
+	}
+	return role
+}
+
+func TestHasPermissions_GrantedAcrossRoles(t *testing.T) {
+	userRoles := []models.Role{
+		roleWith(roles.RoleSupport, roles.PermissionUsersRead),
+		roleWith(roles.RoleEditor, roles.PermissionPodcastsWrite),
+	}
+
+	assert.True(t, HasPermissions(userRoles, roles.PermissionUsersRead, roles.PermissionPodcastsWrite))
+	assert.False(t, HasPermissions(userRoles, roles.PermissionUsersDelete))
+}
+
+func TestHasPermissions_SuperAdminWildcard(t *testing.T) {
+	userRoles := []models.Role{roleWith(roles.RoleSuperAdmin, roles.PermissionAll)}
+
+	assert.True(t, HasPermissions(userRoles, roles.PermissionUsersDelete, roles.PermissionCampaignsSend))
+}
+
+func TestHasPermissions_NoPermissionsRequired(t *testing.T) {
+	assert.True(t, HasPermissions([]models.Role{roleWith(roles.RoleSupport)}))
+}
+
+func TestAssignRole_InvalidUserID(t *testing.T) {
+	service := RoleService{}
+	response := service.AssignRole(context.Background(), "invalid-user-id", roles.RoleEditor)
+	assert.Equal(t, "Invalid User ID", response.MessageTitle)
+}
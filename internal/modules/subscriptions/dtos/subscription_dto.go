@@ -0,0 +1,103 @@
+package subscriptions
+
+import (
+	"time"
+
+	subscriptions "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/enums"
+	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/models"
+)
+
+type PlanDTO struct {
+	ID                  string                   `json:"id"`
+	Code                string                   `json:"code"`
+	Name                string                   `json:"name"`
+	Period              subscriptions.PlanPeriod `json:"period"`
+	PriceCents          int                      `json:"price_cents"`
+	Currency            string                   `json:"currency"`
+	TrialDays           int                      `json:"trial_days"`
+	GraceDays           int                      `json:"grace_days"`
+	IsActive            bool                     `json:"is_active"`
+	AppStoreProductID   string                   `json:"app_store_product_id,omitempty"`
+	GooglePlayProductID string                   `json:"google_play_product_id,omitempty"`
+}
+
+type CreatePlanRequestDTO struct {
+	Code                string                   `json:"code" validate:"required"`
+	Name                string                   `json:"name" validate:"required"`
+	Period              subscriptions.PlanPeriod `json:"period" validate:"required,oneof=monthly yearly"`
+	PriceCents          int                      `json:"price_cents" validate:"min=0"`
+	Currency            string                   `json:"currency" validate:"required,len=3"`
+	TrialDays           int                      `json:"trial_days" validate:"min=0"`
+	GraceDays           int                      `json:"grace_days" validate:"min=0"`
+	AppStoreProductID   string                   `json:"app_store_product_id"`
+	GooglePlayProductID string                   `json:"google_play_product_id"`
+}
+
+type UpdatePlanRequestDTO struct {
+	Name                string  `json:"name" validate:"omitempty"`
+	PriceCents          *int    `json:"price_cents" validate:"omitempty,min=0"`
+	TrialDays           *int    `json:"trial_days" validate:"omitempty,min=0"`
+	GraceDays           *int    `json:"grace_days" validate:"omitempty,min=0"`
+	IsActive            *bool   `json:"is_active"`
+	AppStoreProductID   *string `json:"app_store_product_id"`
+	GooglePlayProductID *string `json:"google_play_product_id"`
+}
+
+type VerifyPurchaseRequestDTO struct {
+	Provider          string `json:"provider" validate:"required,oneof=app_store google_play"`
+	SignedTransaction string `json:"signed_transaction" validate:"required_if=Provider app_store"`
+	PurchaseToken     string `json:"purchase_token" validate:"required_if=Provider google_play"`
+	ProductID         string `json:"product_id" validate:"required_if=Provider google_play"`
+}
+
+type GrantSubscriptionRequestDTO struct {
+	UserID   string `json:"user_id" validate:"required,uuid"`
+	PlanCode string `json:"plan_code" validate:"required"`
+	Days     int    `json:"days" validate:"required,min=1"`
+}
+
+type SubscriptionDTO struct {
+	ID                 string                           `json:"id"`
+	Plan               *PlanDTO                         `json:"plan,omitempty"`
+	Status             subscriptions.SubscriptionStatus `json:"status"`
+	Provider           string                           `json:"provider"`
+	AutoRenew          bool                             `json:"auto_renew"`
+	CurrentPeriodStart time.Time                        `json:"current_period_start"`
+	CurrentPeriodEnd   time.Time                        `json:"current_period_end"`
+	GraceEndsAt        *time.Time                       `json:"grace_ends_at,omitempty"`
+	CanceledAt         *time.Time                       `json:"canceled_at,omitempty"`
+}
+
+func MapToPlanDTO(plan models.Plan) PlanDTO {
+	return PlanDTO{
+		ID:                  plan.ID.String(),
+		Code:                plan.Code,
+		Name:                plan.Name,
+		Period:              plan.Period,
+		PriceCents:          plan.PriceCents,
+		Currency:            plan.Currency,
+		TrialDays:           plan.TrialDays,
+		GraceDays:           plan.GraceDays,
+		IsActive:            plan.IsActive,
+		AppStoreProductID:   plan.AppStoreProductID,
+		GooglePlayProductID: plan.GooglePlayProductID,
+	}
+}
+
+func MapToSubscriptionDTO(subscription models.Subscription) SubscriptionDTO {
+	dto := SubscriptionDTO{
+		ID:                 subscription.ID.String(),
+		Status:             subscription.Status,
+		Provider:           subscription.Provider,
+		AutoRenew:          subscription.AutoRenew,
+		CurrentPeriodStart: subscription.CurrentPeriodStart,
+		CurrentPeriodEnd:   subscription.CurrentPeriodEnd,
+		GraceEndsAt:        subscription.GraceEndsAt,
+		CanceledAt:         subscription.CanceledAt,
+	}
+	if subscription.Plan != nil {
+		plan := MapToPlanDTO(*subscription.Plan)
+		dto.Plan = &plan
+	}
+	return dto
+}
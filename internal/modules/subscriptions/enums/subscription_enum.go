@@ -0,0 +1,53 @@
+package subscriptions
+
+type SubscriptionStatus string
+
+const (
+	SubscriptionStatusTrialing SubscriptionStatus = "trialing"
+	SubscriptionStatusActive   SubscriptionStatus = "active"
+	SubscriptionStatusGrace    SubscriptionStatus = "grace"
+	SubscriptionStatusExpired  SubscriptionStatus = "expired"
+)
+
+// EntitledStatuses are the statuses that grant premium access
+var EntitledStatuses = []SubscriptionStatus{
+	SubscriptionStatusTrialing,
+	SubscriptionStatusActive,
+	SubscriptionStatusGrace,
+}
+
+type SubscriptionEvent string
+
+const (
+	EventTrialStarted SubscriptionEvent = "trial_started"
+	EventPurchased    SubscriptionEvent = "purchased"
+	EventRenewed      SubscriptionEvent = "renewed"
+	EventGraceStarted SubscriptionEvent = "grace_started"
+	EventCanceled     SubscriptionEvent = "canceled"
+	EventExpired      SubscriptionEvent = "expired"
+	EventRefunded     SubscriptionEvent = "refunded"
+	EventGranted      SubscriptionEvent = "granted"
+)
+
+type PlanPeriod string
+
+const (
+	PlanPeriodMonthly PlanPeriod = "monthly"
+	PlanPeriodYearly  PlanPeriod = "yearly"
+)
+
+// Billing providers that can drive subscription state
+const (
+	ProviderManual     = "manual"
+	ProviderPromo      = "promo"
+	ProviderReferral   = "referral"
+	ProviderAppStore   = "app_store"
+	ProviderGooglePlay = "google_play"
+)
+
+// GrantProviders are the providers whose subscriptions are granted days rather than billed
+var GrantProviders = []string{ProviderManual, ProviderPromo, ProviderReferral}
+
+const (
+	EntitlementPremiumContent = "premium_content"
+)
@@ -0,0 +1,102 @@
+package subscriptions
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	subscriptionDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/dtos"
+	subscriptions "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/enums"
+	subscriptionService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/services"
+	"github.com/labstack/echo/v4"
+)
+
+// maxNotificationBytes caps the size of store webhook bodies
+const maxNotificationBytes = 1 << 20
+
+type SubscriptionHandler struct {
+	SubscriptionService *subscriptionService.SubscriptionService
+	StoreService        *subscriptionService.StoreService
+}
+
+func NewSubscriptionHandler(subscriptionService *subscriptionService.SubscriptionService, storeService *subscriptionService.StoreService) *SubscriptionHandler {
+	return &SubscriptionHandler{SubscriptionService: subscriptionService, StoreService: storeService}
+}
+
+func (h *SubscriptionHandler) GetPlans(c echo.Context) error {
+	response := h.SubscriptionService.GetPlans()
+	return c.JSON(response.HTTPStatus, response)
+}
+
+func (h *SubscriptionHandler) GetMySubscription(c echo.Context) error {
+	userID, ok := c.Get("user_id").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, base.SetErrorMessage("Unauthorized", "Invalid or missing user ID"))
+	}
+
+	response := h.SubscriptionService.GetMySubscription(userID)
+	return c.JSON(response.HTTPStatus, response)
+}
+
+func (h *SubscriptionHandler) CreatePlan(c echo.Context) error {
+	var req subscriptionDTO.CreatePlanRequestDTO
+	if res, ok := base.BindAndValidate(c, &req); !ok {
+		return c.JSON(res.HTTPStatus, res)
+	}
+
+	response := h.SubscriptionService.CreatePlan(req)
+	return c.JSON(response.HTTPStatus, response)
+}
+
+func (h *SubscriptionHandler) UpdatePlan(c echo.Context) error {
+	var req subscriptionDTO.UpdatePlanRequestDTO
+	if res, ok := base.BindAndValidate(c, &req); !ok {
+		return c.JSON(res.HTTPStatus, res)
+	}
+
+	response := h.SubscriptionService.UpdatePlan(c.Param("id"), req)
+	return c.JSON(response.HTTPStatus, response)
+}
+
+func (h *SubscriptionHandler) GrantSubscription(c echo.Context) error {
+	var req subscriptionDTO.GrantSubscriptionRequestDTO
+	if res, ok := base.BindAndValidate(c, &req); !ok {
+		return c.JSON(res.HTTPStatus, res)
+	}
+
+	response := h.SubscriptionService.GrantSubscription(req)
+	return c.JSON(response.HTTPStatus, response)
+}
+
+func (h *SubscriptionHandler) VerifyPurchase(c echo.Context) error {
+	userID, ok := c.Get("user_id").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, base.SetErrorMessage("Unauthorized", "Invalid or missing user ID"))
+	}
+
+	var req subscriptionDTO.VerifyPurchaseRequestDTO
+	if res, ok := base.BindAndValidate(c, &req); !ok {
+		return c.JSON(res.HTTPStatus, res)
+	}
+
+	response := h.StoreService.VerifyPurchase(c.Request().Context(), userID, req)
+	return c.JSON(response.HTTPStatus, response)
+}
+
+func (h *SubscriptionHandler) AppStoreNotification(c echo.Context) error {
+	return h.handleStoreNotification(c, subscriptions.ProviderAppStore)
+}
+
+func (h *SubscriptionHandler) GooglePlayNotification(c echo.Context) error {
+	return h.handleStoreNotification(c, subscriptions.ProviderGooglePlay)
+}
+
+func (h *SubscriptionHandler) handleStoreNotification(c echo.Context, provider string) error {
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxNotificationBytes))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, base.SetErrorMessage("Invalid notification", err))
+	}
+
+	response := h.StoreService.HandleNotification(c.Request().Context(), provider, body, c.Request().Header)
+	return c.JSON(response.HTTPStatus, response)
+}
@@ -0,0 +1,67 @@
+package subscriptions
+
+import (
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	subscriptions "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/enums"
+	"github.com/google/uuid"
+)
+
+type Plan struct {
+	base.Model
+	Code       string                   `gorm:"type:varchar(50);uniqueIndex" json:"code"`
+	Name       string                   `gorm:"type:varchar(100)" json:"name"`
+	Period     subscriptions.PlanPeriod `gorm:"type:varchar(20)" json:"period"`
+	PriceCents int                      `gorm:"default:0" json:"price_cents"`
+	Currency   string                   `gorm:"type:varchar(3);default:'SAR'" json:"currency"`
+	TrialDays  int                      `gorm:"default:0" json:"trial_days"`
+	GraceDays  int                      `gorm:"default:0" json:"grace_days"`
+	IsActive   bool                     `gorm:"default:true" json:"is_active"`
+	// store product identifiers used to match in-app purchases to this plan
+	AppStoreProductID   string `gorm:"type:varchar(255);index" json:"app_store_product_id"`
+	GooglePlayProductID string `gorm:"type:varchar(255);index" json:"google_play_product_id"`
+}
+
+// PeriodEnd returns the end of a billing period starting at start
+func (p Plan) PeriodEnd(start time.Time) time.Time {
+	if p.Period == subscriptions.PlanPeriodYearly {
+		return start.AddDate(1, 0, 0)
+	}
+	return start.AddDate(0, 1, 0)
+}
+
+type Subscription struct {
+	base.Model
+	UserID             uuid.UUID                        `gorm:"type:uuid;index" json:"user_id"`
+	PlanID             uuid.UUID                        `gorm:"type:uuid;index" json:"plan_id"`
+	Plan               *Plan                            `gorm:"foreignKey:PlanID" json:"plan,omitempty"`
+	Status             subscriptions.SubscriptionStatus `gorm:"type:varchar(20);index" json:"status"`
+	Provider           string                           `gorm:"type:varchar(20)" json:"provider"`
+	ExternalID         string                           `gorm:"type:varchar(255);index" json:"external_id"`
+	AutoRenew          bool                             `gorm:"default:true" json:"auto_renew"`
+	CurrentPeriodStart time.Time                        `json:"current_period_start"`
+	CurrentPeriodEnd   time.Time                        `gorm:"index" json:"current_period_end"`
+	GraceEndsAt        *time.Time                       `json:"grace_ends_at,omitempty"`
+	CanceledAt         *time.Time                       `json:"canceled_at,omitempty"`
+	ExpiredAt          *time.Time                       `json:"expired_at,omitempty"`
+}
+
+// SubscriptionEventLog keeps the history of every state change applied to a subscription
+type SubscriptionEventLog struct {
+	base.Model
+	SubscriptionID uuid.UUID                        `gorm:"type:uuid;index" json:"subscription_id"`
+	Event          subscriptions.SubscriptionEvent  `gorm:"type:varchar(30)" json:"event"`
+	FromStatus     subscriptions.SubscriptionStatus `gorm:"type:varchar(20)" json:"from_status"`
+	ToStatus       subscriptions.SubscriptionStatus `gorm:"type:varchar(20)" json:"to_status"`
+	Provider       string                           `gorm:"type:varchar(20)" json:"provider"`
+	OccurredAt     time.Time                        `json:"occurred_at"`
+}
+
+// StoreNotification records a processed store server notification so redelivered webhooks are applied once
+type StoreNotification struct {
+	base.Model
+	Provider       string `gorm:"type:varchar(20);uniqueIndex:idx_store_notification" json:"provider"`
+	NotificationID string `gorm:"type:varchar(255);uniqueIndex:idx_store_notification" json:"notification_id"`
+	Type           string `gorm:"type:varchar(100)" json:"type"`
+}
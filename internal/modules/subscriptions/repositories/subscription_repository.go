@@ -0,0 +1,220 @@
+package subscriptions
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	subscriptions "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/enums"
+	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/models"
+)
+
+type SubscriptionRepository struct {
+	DB *gorm.DB
+}
+
+func NewSubscriptionRepository(db *gorm.DB) *SubscriptionRepository {
+	return &SubscriptionRepository{
+		DB: db,
+	}
+}
+
+func (r *SubscriptionRepository) FindAllPlans(onlyActive bool) ([]models.Plan, error) {
+	var plans []models.Plan
+	query := r.DB.Order("price_cents")
+	if onlyActive {
+		query = query.Where("is_active = ?", true)
+	}
+	if err := query.Find(&plans).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch plans: %w", err)
+	}
+	return plans, nil
+}
+
+func (r *SubscriptionRepository) FindPlanByID(planID uuid.UUID) (*models.Plan, error) {
+	var plan models.Plan
+	result := r.DB.Where("id = ?", planID).First(&plan)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find plan: %w", result.Error)
+	}
+	return &plan, nil
+}
+
+func (r *SubscriptionRepository) FindPlanByCode(code string) (*models.Plan, error) {
+	var plan models.Plan
+	result := r.DB.Where("code = ?", code).First(&plan)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find plan: %w", result.Error)
+	}
+	return &plan, nil
+}
+
+// FindPlanByProductID finds the plan a store product identifier belongs to
+func (r *SubscriptionRepository) FindPlanByProductID(provider, productID string) (*models.Plan, error) {
+	column := "app_store_product_id"
+	if provider == subscriptions.ProviderGooglePlay {
+		column = "google_play_product_id"
+	}
+
+	var plan models.Plan
+	result := r.DB.Where(column+" = ?", productID).First(&plan)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to find plan: %w", result.Error)
+	}
+	return &plan, nil
+}
+
+func (r *SubscriptionRepository) CreatePlan(plan *models.Plan) error {
+	if err := r.DB.Create(plan).Error; err != nil {
+		return fmt.Errorf("failed to create plan: %w", err)
+	}
+	return nil
+}
+
+func (r *SubscriptionRepository) UpdatePlan(plan *models.Plan) error {
+	if err := r.DB.Save(plan).Error; err != nil {
+		return fmt.Errorf("failed to update plan: %w", err)
+	}
+	return nil
+}
+
+// FindCurrentSubscription returns the user's subscription that grants access at now, if any. The
+// period or grace end is checked too, so a lapsed subscription stops granting access even before
+// the expiry job moves it out of an entitled status.
+func (r *SubscriptionRepository) FindCurrentSubscription(userID uuid.UUID, now time.Time) (*models.Subscription, error) {
+	var subscription models.Subscription
+	result := r.DB.Preload("Plan").
+		Where("user_id = ? AND status IN ?", userID, subscriptions.EntitledStatuses).
+		Where("current_period_end > ? OR grace_ends_at > ?", now, now).
+		Order("current_period_end DESC").
+		First(&subscription)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find subscription: %w", result.Error)
+	}
+	return &subscription, nil
+}
+
+// FindCurrentGrantedSubscription returns the user's entitled subscription that was granted rather than billed, if any
+func (r *SubscriptionRepository) FindCurrentGrantedSubscription(userID uuid.UUID) (*models.Subscription, error) {
+	var subscription models.Subscription
+	result := r.DB.Preload("Plan").
+		Where("user_id = ? AND status IN ? AND provider IN ?", userID, subscriptions.EntitledStatuses, subscriptions.GrantProviders).
+		Order("current_period_end DESC").
+		First(&subscription)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find subscription: %w", result.Error)
+	}
+	return &subscription, nil
+}
+
+func (r *SubscriptionRepository) FindLatestSubscription(userID uuid.UUID) (*models.Subscription, error) {
+	var subscription models.Subscription
+	result := r.DB.Preload("Plan").Where("user_id = ?", userID).Order("current_period_end DESC").First(&subscription)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find subscription: %w", result.Error)
+	}
+	return &subscription, nil
+}
+
+func (r *SubscriptionRepository) FindSubscriptionByID(subscriptionID uuid.UUID) (*models.Subscription, error) {
+	var subscription models.Subscription
+	result := r.DB.Preload("Plan").Where("id = ?", subscriptionID).First(&subscription)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find subscription: %w", result.Error)
+	}
+	return &subscription, nil
+}
+
+func (r *SubscriptionRepository) FindSubscriptionByExternalID(provider, externalID string) (*models.Subscription, error) {
+	var subscription models.Subscription
+	result := r.DB.Preload("Plan").Where("provider = ? AND external_id = ?", provider, externalID).First(&subscription)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find subscription: %w", result.Error)
+	}
+	return &subscription, nil
+}
+
+// FindSubscriptionsDueForTransition returns subscriptions whose period or grace window has ended
+func (r *SubscriptionRepository) FindSubscriptionsDueForTransition(now time.Time) ([]models.Subscription, error) {
+	var due []models.Subscription
+	result := r.DB.Preload("Plan").
+		Where("(status IN ? AND current_period_end <= ?) OR (status = ? AND grace_ends_at <= ?)",
+			[]subscriptions.SubscriptionStatus{subscriptions.SubscriptionStatusTrialing, subscriptions.SubscriptionStatusActive}, now,
+			subscriptions.SubscriptionStatusGrace, now).
+		Find(&due)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to fetch subscriptions due for transition: %w", result.Error)
+	}
+	return due, nil
+}
+
+func (r *SubscriptionRepository) CountEntitledSubscriptions(userID uuid.UUID) (int64, error) {
+	var count int64
+	result := r.DB.Model(&models.Subscription{}).
+		Where("user_id = ? AND status IN ?", userID, subscriptions.EntitledStatuses).
+		Count(&count)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to count subscriptions: %w", result.Error)
+	}
+	return count, nil
+}
+
+// SaveSubscription persists the subscription together with the event that changed it
+func (r *SubscriptionRepository) SaveSubscription(subscription *models.Subscription, event *models.SubscriptionEventLog) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Plan").Save(subscription).Error; err != nil {
+			return fmt.Errorf("failed to save subscription: %w", err)
+		}
+		event.SubscriptionID = subscription.ID
+		if err := tx.Create(event).Error; err != nil {
+			return fmt.Errorf("failed to record subscription event: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *SubscriptionRepository) IsStoreNotificationProcessed(provider, notificationID string) (bool, error) {
+	var count int64
+	result := r.DB.Model(&models.StoreNotification{}).
+		Where("provider = ? AND notification_id = ?", provider, notificationID).
+		Count(&count)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to check store notification: %w", result.Error)
+	}
+	return count > 0, nil
+}
+
+func (r *SubscriptionRepository) CreateStoreNotification(notification *models.StoreNotification) error {
+	result := r.DB.Create(notification)
+	if result.Error != nil {
+		return fmt.Errorf("failed to record store notification: %w", result.Error)
+	}
+	return nil
+}
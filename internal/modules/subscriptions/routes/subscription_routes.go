@@ -0,0 +1,50 @@
+package subscriptions
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/config"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/jobs"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/jwtkeys"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/middlewares"
+	roles "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/enums"
+	subscriptionHandler "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/handlers"
+	subscriptionRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/repositories"
+	subscriptionService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/services"
+	userRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/repositories"
+)
+
+func RegisterRoutes(e *echo.Echo, db *gorm.DB, cfg *config.Config) {
+	keys := jwtkeys.Default()
+
+	authRepo := userRepository.NewAuthRepository(db)
+	userRepo := userRepository.NewUserRepository(db)
+	subscriptionRepo := subscriptionRepository.NewSubscriptionRepository(db)
+	newSubscriptionService := subscriptionService.NewSubscriptionService(subscriptionRepo, userRepo)
+	newStoreService := subscriptionService.NewStoreService(
+		subscriptionRepo,
+		newSubscriptionService,
+		subscriptionService.NewAppStoreVerifierFromConfig(cfg.Stores, !cfg.IsProduction()),
+		subscriptionService.NewGooglePlayVerifierFromConfig(cfg.Stores),
+	)
+	newSubscriptionHandler := subscriptionHandler.NewSubscriptionHandler(newSubscriptionService, newStoreService)
+
+	jobs.Register(subscriptionService.SubscriptionExpiryJobName, newSubscriptionService.ExpireSubscriptions)
+	jobs.Every(15*time.Minute, subscriptionService.SubscriptionExpiryJobName, "")
+
+	e.GET("/subscriptions/plans", newSubscriptionHandler.GetPlans)
+	e.POST("/subscriptions/webhooks/app-store", newSubscriptionHandler.AppStoreNotification)
+	e.POST("/subscriptions/webhooks/google-play", newSubscriptionHandler.GooglePlayNotification)
+
+	subscriptionGroup := e.Group("/subscriptions", middlewares.AuthMiddleware(authRepo, keys))
+	subscriptionGroup.GET("/me", newSubscriptionHandler.GetMySubscription)
+	subscriptionGroup.POST("/verify", newSubscriptionHandler.VerifyPurchase)
+
+	adminSubscriptionGroup := e.Group("/admin/subscriptions", middlewares.RequirePermission(keys, roles.PermissionSubscriptionsWrite))
+	adminSubscriptionGroup.POST("/plans", newSubscriptionHandler.CreatePlan)
+	adminSubscriptionGroup.PUT("/plans/:id", newSubscriptionHandler.UpdatePlan)
+	adminSubscriptionGroup.POST("/grant", newSubscriptionHandler.GrantSubscription)
+}
@@ -0,0 +1,313 @@
+package subscriptions
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/config"
+	subscriptions "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/enums"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	// Apple marks the certificates it signs App Store payloads with using these extensions
+	appleLeafCertificateOID         = asn1.ObjectIdentifier{1, 2, 840, 113635, 100, 6, 11, 1}
+	appleIntermediateCertificateOID = asn1.ObjectIdentifier{1, 2, 840, 113635, 100, 6, 2, 1}
+
+	ErrNoTrustedRoots = errors.New("no trusted App Store root certificates configured")
+)
+
+const (
+	// App Store offer type for introductory offers such as free trials
+	appStoreOfferTypeIntroductory = 1
+	// appStoreEnvironmentProduction marks real purchases; sandbox, Xcode and TestFlight ones carry another environment
+	appStoreEnvironmentProduction = "Production"
+)
+
+// AppStoreVerifier verifies StoreKit 2 signed transactions and App Store Server Notifications v2.
+// Payloads are JWS signed with the leaf of the x5c chain in the header, which must chain up to Roots.
+type AppStoreVerifier struct {
+	BundleID string
+	Roots    *x509.CertPool
+	// AllowSandbox accepts transactions from the sandbox, which any tester gets for free. It is
+	// only set outside production.
+	AllowSandbox bool
+	Now          func() time.Time
+}
+
+var _ StoreVerifier = (*AppStoreVerifier)(nil)
+
+func NewAppStoreVerifier(bundleID string, roots *x509.CertPool) *AppStoreVerifier {
+	return &AppStoreVerifier{BundleID: bundleID, Roots: roots, Now: time.Now}
+}
+
+// NewAppStoreVerifierFromConfig builds the verifier from the bundle ID and the PEM bundle of Apple root
+// certificates. Sandbox transactions are accepted when allowSandbox is set.
+func NewAppStoreVerifierFromConfig(cfg config.StoresConfig, allowSandbox bool) *AppStoreVerifier {
+	var roots *x509.CertPool
+	if path := cfg.AppleRootCerts; path != "" {
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			slog.Error("Failed to read App Store root certificates", "error", err)
+		} else {
+			roots = x509.NewCertPool()
+			if !roots.AppendCertsFromPEM(pem) {
+				slog.Error("No certificates found in App Store root certificates file", "path", path)
+				roots = nil
+			}
+		}
+	}
+	verifier := NewAppStoreVerifier(cfg.AppleBundleID, roots)
+	verifier.AllowSandbox = allowSandbox
+	return verifier
+}
+
+func (v *AppStoreVerifier) Provider() string {
+	return subscriptions.ProviderAppStore
+}
+
+type appStoreTransaction struct {
+	jwt.RegisteredClaims
+	TransactionID         string `json:"transactionId"`
+	OriginalTransactionID string `json:"originalTransactionId"`
+	BundleID              string `json:"bundleId"`
+	ProductID             string `json:"productId"`
+	PurchaseDate          int64  `json:"purchaseDate"`
+	ExpiresDate           int64  `json:"expiresDate"`
+	Type                  string `json:"type"`
+	AppAccountToken       string `json:"appAccountToken"`
+	OfferType             int    `json:"offerType"`
+	RevocationDate        int64  `json:"revocationDate"`
+	Environment           string `json:"environment"`
+}
+
+type appStoreRenewalInfo struct {
+	jwt.RegisteredClaims
+	OriginalTransactionID  string `json:"originalTransactionId"`
+	AutoRenewStatus        int    `json:"autoRenewStatus"`
+	GracePeriodExpiresDate int64  `json:"gracePeriodExpiresDate"`
+}
+
+type appStoreNotification struct {
+	jwt.RegisteredClaims
+	NotificationType string `json:"notificationType"`
+	Subtype          string `json:"subtype"`
+	NotificationUUID string `json:"notificationUUID"`
+	SignedDate       int64  `json:"signedDate"`
+	Data             struct {
+		BundleID              string `json:"bundleId"`
+		Environment           string `json:"environment"`
+		SignedTransactionInfo string `json:"signedTransactionInfo"`
+		SignedRenewalInfo     string `json:"signedRenewalInfo"`
+	} `json:"data"`
+}
+
+func (v *AppStoreVerifier) VerifyPurchase(_ context.Context, purchase StorePurchase) (*StoreTransaction, error) {
+	if purchase.SignedTransaction == "" {
+		return nil, errors.New("missing signed transaction")
+	}
+
+	var transaction appStoreTransaction
+	if err := v.parseSigned(purchase.SignedTransaction, &transaction); err != nil {
+		return nil, err
+	}
+	if transaction.BundleID != v.BundleID {
+		return nil, fmt.Errorf("transaction is for bundle %q", transaction.BundleID)
+	}
+	if err := v.checkEnvironment(transaction.Environment); err != nil {
+		return nil, err
+	}
+
+	return transaction.toStoreTransaction(), nil
+}
+
+func (v *AppStoreVerifier) ParseNotification(_ context.Context, body []byte, _ http.Header) (*StoreNotification, error) {
+	var request struct {
+		SignedPayload string `json:"signedPayload"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil || request.SignedPayload == "" {
+		return nil, errors.New("missing signed payload")
+	}
+
+	var payload appStoreNotification
+	if err := v.parseSigned(request.SignedPayload, &payload); err != nil {
+		return nil, err
+	}
+	if payload.Data.BundleID != v.BundleID {
+		return nil, fmt.Errorf("notification is for bundle %q", payload.Data.BundleID)
+	}
+	if err := v.checkEnvironment(payload.Data.Environment); err != nil {
+		return nil, err
+	}
+
+	notification := &StoreNotification{
+		ID:         payload.NotificationUUID,
+		Type:       payload.NotificationType,
+		OccurredAt: time.UnixMilli(payload.SignedDate),
+	}
+	if payload.Subtype != "" {
+		notification.Type += "." + payload.Subtype
+	}
+
+	if payload.Data.SignedTransactionInfo != "" {
+		var transaction appStoreTransaction
+		if err := v.parseSigned(payload.Data.SignedTransactionInfo, &transaction); err != nil {
+			return nil, fmt.Errorf("invalid transaction info: %w", err)
+		}
+		if err := v.checkEnvironment(transaction.Environment); err != nil {
+			return nil, err
+		}
+		notification.Transaction = transaction.toStoreTransaction()
+	}
+
+	if payload.Data.SignedRenewalInfo != "" {
+		var renewal appStoreRenewalInfo
+		if err := v.parseSigned(payload.Data.SignedRenewalInfo, &renewal); err != nil {
+			return nil, fmt.Errorf("invalid renewal info: %w", err)
+		}
+		if renewal.GracePeriodExpiresDate > 0 {
+			notification.GraceEndsAt = time.UnixMilli(renewal.GracePeriodExpiresDate)
+		}
+	}
+
+	notification.Event = appStoreEvent(payload.NotificationType, payload.Subtype, notification.Transaction)
+	return notification, nil
+}
+
+// checkEnvironment refuses payloads from outside the production App Store unless sandbox ones are allowed
+func (v *AppStoreVerifier) checkEnvironment(environment string) error {
+	if environment != appStoreEnvironmentProduction && !v.AllowSandbox {
+		return fmt.Errorf("payload is from the %q environment", environment)
+	}
+	return nil
+}
+
+// appStoreEvent maps an App Store notification type and subtype to a subscription event
+func appStoreEvent(notificationType, subtype string, transaction *StoreTransaction) subscriptions.SubscriptionEvent {
+	switch notificationType {
+	case "SUBSCRIBED":
+		if transaction != nil && transaction.IsTrial {
+			return subscriptions.EventTrialStarted
+		}
+		return subscriptions.EventPurchased
+	case "DID_RENEW":
+		return subscriptions.EventRenewed
+	case "DID_FAIL_TO_RENEW":
+		if subtype == "GRACE_PERIOD" {
+			return subscriptions.EventGraceStarted
+		}
+	case "DID_CHANGE_RENEWAL_STATUS":
+		if subtype == "AUTO_RENEW_DISABLED" {
+			return subscriptions.EventCanceled
+		}
+	case "EXPIRED", "GRACE_PERIOD_EXPIRED":
+		return subscriptions.EventExpired
+	case "REFUND", "REVOKE":
+		return subscriptions.EventRefunded
+	}
+	return ""
+}
+
+func (t appStoreTransaction) toStoreTransaction() *StoreTransaction {
+	transaction := &StoreTransaction{
+		ExternalID:  t.OriginalTransactionID,
+		ProductID:   t.ProductID,
+		AccountID:   t.AppAccountToken,
+		PurchasedAt: time.UnixMilli(t.PurchaseDate),
+		ExpiresAt:   time.UnixMilli(t.ExpiresDate),
+		IsTrial:     t.OfferType == appStoreOfferTypeIntroductory,
+	}
+	if t.RevocationDate > 0 {
+		revokedAt := time.UnixMilli(t.RevocationDate)
+		transaction.RevokedAt = &revokedAt
+	}
+	return transaction
+}
+
+// parseSigned verifies a JWS against the x5c chain in its header and decodes the payload into claims
+func (v *AppStoreVerifier) parseSigned(signed string, claims jwt.Claims) error {
+	_, err := jwt.ParseWithClaims(signed, claims, func(token *jwt.Token) (interface{}, error) {
+		return v.verifyCertificateChain(token.Header["x5c"])
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodES256.Alg()}), jwt.WithoutClaimsValidation())
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+	return nil
+}
+
+// verifyCertificateChain checks that the x5c chain leads to a trusted root and returns the leaf key
+func (v *AppStoreVerifier) verifyCertificateChain(header interface{}) (*ecdsa.PublicKey, error) {
+	if v.Roots == nil {
+		return nil, ErrNoTrustedRoots
+	}
+
+	chain, ok := header.([]interface{})
+	if !ok || len(chain) < 2 {
+		return nil, errors.New("missing x5c certificate chain")
+	}
+
+	certificates := make([]*x509.Certificate, len(chain))
+	for i, entry := range chain {
+		encoded, ok := entry.(string)
+		if !ok {
+			return nil, errors.New("malformed x5c certificate")
+		}
+		der, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("malformed x5c certificate: %w", err)
+		}
+		certificates[i], err = x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("malformed x5c certificate: %w", err)
+		}
+	}
+
+	leaf, intermediate := certificates[0], certificates[1]
+	if !hasExtension(leaf, appleLeafCertificateOID) || !hasExtension(intermediate, appleIntermediateCertificateOID) {
+		return nil, errors.New("certificate chain is not an App Store signing chain")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, certificate := range certificates[1:] {
+		intermediates.AddCert(certificate)
+	}
+
+	now := time.Now
+	if v.Now != nil {
+		now = v.Now
+	}
+	_, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         v.Roots,
+		Intermediates: intermediates,
+		CurrentTime:   now(),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("untrusted certificate chain: %w", err)
+	}
+
+	key, ok := leaf.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errors.New("leaf certificate does not hold an ECDSA key")
+	}
+	return key, nil
+}
+
+func hasExtension(certificate *x509.Certificate, oid asn1.ObjectIdentifier) bool {
+	for _, extension := range certificate.Extensions {
+		if extension.Id.Equal(oid) {
+			return true
+		}
+	}
+	return false
+}
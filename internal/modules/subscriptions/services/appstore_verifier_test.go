@@ -0,0 +1,265 @@
+package subscriptions
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	subscriptions "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/enums"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testBundleID = "com.example.khaimah"
+
+// appStoreFixture is a locally generated root -> intermediate -> leaf chain shaped like Apple's
+type appStoreFixture struct {
+	roots   *x509.CertPool
+	leafKey *ecdsa.PrivateKey
+	x5c     []string
+}
+
+func newAppStoreFixture(t *testing.T) appStoreFixture {
+	t.Helper()
+
+	rootKey, root := newTestCertificate(t, "Test Root CA", nil, nil, true, nil)
+	intermediateKey, intermediate := newTestCertificate(t, "Test Intermediate", root, rootKey, true, appleIntermediateCertificateOID)
+	leafKey, leaf := newTestCertificate(t, "Test Leaf", intermediate, intermediateKey, false, appleLeafCertificateOID)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(root)
+
+	return appStoreFixture{
+		roots:   roots,
+		leafKey: leafKey,
+		x5c: []string{
+			base64.StdEncoding.EncodeToString(leaf.Raw),
+			base64.StdEncoding.EncodeToString(intermediate.Raw),
+			base64.StdEncoding.EncodeToString(root.Raw),
+		},
+	}
+}
+
+func newTestCertificate(t *testing.T, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey, isCA bool, marker asn1.ObjectIdentifier) (*ecdsa.PrivateKey, *x509.Certificate) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	if marker != nil {
+		template.ExtraExtensions = []pkix.Extension{{Id: marker, Value: []byte{0x05, 0x00}}}
+	}
+
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return key, certificate
+}
+
+func (f appStoreFixture) sign(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["x5c"] = f.x5c
+	signed, err := token.SignedString(f.leafKey)
+	require.NoError(t, err)
+	return signed
+}
+
+func testTransactionClaims(expiresAt time.Time) jwt.MapClaims {
+	return jwt.MapClaims{
+		"transactionId":         "2000000000000002",
+		"originalTransactionId": "2000000000000001",
+		"bundleId":              testBundleID,
+		"productId":             "premium.monthly",
+		"purchaseDate":          time.Now().Add(-time.Hour).UnixMilli(),
+		"expiresDate":           expiresAt.UnixMilli(),
+		"appAccountToken":       "6f1c9a53-1d0b-4c55-9b8a-52f0e1c7d2aa",
+		"type":                  "Auto-Renewable Subscription",
+		"environment":           "Production",
+	}
+}
+
+func TestAppStoreVerifyPurchase_ValidTransaction(t *testing.T) {
+	fixture := newAppStoreFixture(t)
+	verifier := NewAppStoreVerifier(testBundleID, fixture.roots)
+	expiresAt := time.Now().Add(30 * 24 * time.Hour).Truncate(time.Millisecond)
+
+	transaction, err := verifier.VerifyPurchase(context.Background(), StorePurchase{
+		SignedTransaction: fixture.sign(t, testTransactionClaims(expiresAt)),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "2000000000000001", transaction.ExternalID)
+	assert.Equal(t, "premium.monthly", transaction.ProductID)
+	assert.Equal(t, "6f1c9a53-1d0b-4c55-9b8a-52f0e1c7d2aa", transaction.AccountID)
+	assert.True(t, expiresAt.Equal(transaction.ExpiresAt))
+	assert.False(t, transaction.IsTrial)
+}
+
+func TestAppStoreVerifyPurchase_UntrustedRoot(t *testing.T) {
+	fixture := newAppStoreFixture(t)
+	verifier := NewAppStoreVerifier(testBundleID, newAppStoreFixture(t).roots)
+
+	_, err := verifier.VerifyPurchase(context.Background(), StorePurchase{
+		SignedTransaction: fixture.sign(t, testTransactionClaims(time.Now().Add(time.Hour))),
+	})
+
+	assert.ErrorContains(t, err, "untrusted certificate chain")
+}
+
+func TestAppStoreVerifyPurchase_NoRootsConfigured(t *testing.T) {
+	fixture := newAppStoreFixture(t)
+	verifier := NewAppStoreVerifier(testBundleID, nil)
+
+	_, err := verifier.VerifyPurchase(context.Background(), StorePurchase{
+		SignedTransaction: fixture.sign(t, testTransactionClaims(time.Now().Add(time.Hour))),
+	})
+
+	assert.ErrorIs(t, err, ErrNoTrustedRoots)
+}
+
+func TestAppStoreVerifyPurchase_TamperedPayload(t *testing.T) {
+	fixture := newAppStoreFixture(t)
+	verifier := NewAppStoreVerifier(testBundleID, fixture.roots)
+	signed := fixture.sign(t, testTransactionClaims(time.Now().Add(time.Hour)))
+
+	other := fixture.sign(t, testTransactionClaims(time.Now().Add(365*24*time.Hour)))
+	parts, otherParts := strings.Split(signed, "."), strings.Split(other, ".")
+	tampered := parts[0] + "." + otherParts[1] + "." + parts[2]
+
+	_, err := verifier.VerifyPurchase(context.Background(), StorePurchase{SignedTransaction: tampered})
+
+	assert.Error(t, err)
+}
+
+func TestAppStoreVerifyPurchase_WrongBundle(t *testing.T) {
+	fixture := newAppStoreFixture(t)
+	verifier := NewAppStoreVerifier("com.example.other", fixture.roots)
+
+	_, err := verifier.VerifyPurchase(context.Background(), StorePurchase{
+		SignedTransaction: fixture.sign(t, testTransactionClaims(time.Now().Add(time.Hour))),
+	})
+
+	assert.ErrorContains(t, err, "bundle")
+}
+
+func TestAppStoreVerifyPurchase_SandboxTransaction(t *testing.T) {
+	fixture := newAppStoreFixture(t)
+	claims := testTransactionClaims(time.Now().Add(30 * 24 * time.Hour))
+	claims["environment"] = "Sandbox"
+	purchase := StorePurchase{SignedTransaction: fixture.sign(t, claims)}
+
+	verifier := NewAppStoreVerifier(testBundleID, fixture.roots)
+	_, err := verifier.VerifyPurchase(context.Background(), purchase)
+	assert.Error(t, err)
+
+	verifier.AllowSandbox = true
+	_, err = verifier.VerifyPurchase(context.Background(), purchase)
+	assert.NoError(t, err)
+}
+
+func TestAppStoreParseNotification(t *testing.T) {
+	fixture := newAppStoreFixture(t)
+	verifier := NewAppStoreVerifier(testBundleID, fixture.roots)
+	expiresAt := time.Now().Add(30 * 24 * time.Hour).Truncate(time.Millisecond)
+	graceEndsAt := time.Now().Add(16 * 24 * time.Hour).Truncate(time.Millisecond)
+
+	tests := []struct {
+		notificationType string
+		subtype          string
+		event            subscriptions.SubscriptionEvent
+	}{
+		{"SUBSCRIBED", "INITIAL_BUY", subscriptions.EventPurchased},
+		{"DID_RENEW", "", subscriptions.EventRenewed},
+		{"DID_FAIL_TO_RENEW", "GRACE_PERIOD", subscriptions.EventGraceStarted},
+		{"DID_CHANGE_RENEWAL_STATUS", "AUTO_RENEW_DISABLED", subscriptions.EventCanceled},
+		{"DID_CHANGE_RENEWAL_STATUS", "AUTO_RENEW_ENABLED", ""},
+		{"EXPIRED", "VOLUNTARY", subscriptions.EventExpired},
+		{"REFUND", "", subscriptions.EventRefunded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.notificationType+"/"+tt.subtype, func(t *testing.T) {
+			payload := fixture.sign(t, jwt.MapClaims{
+				"notificationType": tt.notificationType,
+				"subtype":          tt.subtype,
+				"notificationUUID": "9b3b8f4e-5d3c-4f0e-8a55-0b6f1c2d3e4f",
+				"signedDate":       time.Now().UnixMilli(),
+				"data": map[string]interface{}{
+					"bundleId":              testBundleID,
+					"environment":           "Production",
+					"signedTransactionInfo": fixture.sign(t, testTransactionClaims(expiresAt)),
+					"signedRenewalInfo": fixture.sign(t, jwt.MapClaims{
+						"originalTransactionId":  "2000000000000001",
+						"gracePeriodExpiresDate": graceEndsAt.UnixMilli(),
+					}),
+				},
+			})
+			body, _ := json.Marshal(map[string]string{"signedPayload": payload})
+
+			notification, err := verifier.ParseNotification(context.Background(), body, nil)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.event, notification.Event)
+			assert.Equal(t, "9b3b8f4e-5d3c-4f0e-8a55-0b6f1c2d3e4f", notification.ID)
+			assert.Equal(t, "2000000000000001", notification.Transaction.ExternalID)
+			assert.True(t, expiresAt.Equal(notification.Transaction.ExpiresAt))
+			assert.True(t, graceEndsAt.Equal(notification.GraceEndsAt))
+		})
+	}
+}
+
+func TestAppStoreParseNotification_SandboxNotification(t *testing.T) {
+	fixture := newAppStoreFixture(t)
+	verifier := NewAppStoreVerifier(testBundleID, fixture.roots)
+	payload := fixture.sign(t, jwt.MapClaims{
+		"notificationType": "SUBSCRIBED",
+		"subtype":          "INITIAL_BUY",
+		"notificationUUID": "9b3b8f4e-5d3c-4f0e-8a55-0b6f1c2d3e4f",
+		"signedDate":       time.Now().UnixMilli(),
+		"data": map[string]interface{}{
+			"bundleId":              testBundleID,
+			"environment":           "Sandbox",
+			"signedTransactionInfo": fixture.sign(t, testTransactionClaims(time.Now().Add(30*24*time.Hour))),
+		},
+	})
+	body, _ := json.Marshal(map[string]string{"signedPayload": payload})
+
+	_, err := verifier.ParseNotification(context.Background(), body, nil)
+
+	assert.Error(t, err)
+}
+
+func TestAppStoreParseNotification_MissingPayload(t *testing.T) {
+	verifier := NewAppStoreVerifier(testBundleID, newAppStoreFixture(t).roots)
+
+	_, err := verifier.ParseNotification(context.Background(), []byte(`{}`), nil)
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,55 @@
+package subscriptions
+
+import (
+	"time"
+
+	subscriptions "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/enums"
+	subscriptionRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/repositories"
+	"github.com/google/uuid"
+)
+
+// EntitlementService answers what a user is allowed to access based on their subscription
+type EntitlementService struct {
+	SubscriptionRepo *subscriptionRepository.SubscriptionRepository
+}
+
+func NewEntitlementService(subscriptionRepo *subscriptionRepository.SubscriptionRepository) *EntitlementService {
+	return &EntitlementService{SubscriptionRepo: subscriptionRepo}
+}
+
+// GetEntitlements returns the entitlements the user currently holds
+func (s *EntitlementService) GetEntitlements(userID uuid.UUID) ([]string, error) {
+	subscription, err := s.SubscriptionRepo.FindCurrentSubscription(userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if subscription == nil {
+		return []string{}, nil
+	}
+	return []string{subscriptions.EntitlementPremiumContent}, nil
+}
+
+// HasEntitlement reports whether the user holds the given entitlement
+func (s *EntitlementService) HasEntitlement(userID uuid.UUID, entitlement string) (bool, error) {
+	entitlements, err := s.GetEntitlements(userID)
+	if err != nil {
+		return false, err
+	}
+	for _, e := range entitlements {
+		if e == entitlement {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CanAccessPodcast reports whether the user may play or download a podcast
+func (s *EntitlementService) CanAccessPodcast(userID uuid.UUID, isPremium bool) (bool, error) {
+	if !isPremium {
+		return true, nil
+	}
+	if s == nil || userID == uuid.Nil {
+		return false, nil
+	}
+	return s.HasEntitlement(userID, subscriptions.EntitlementPremiumContent)
+}
@@ -0,0 +1,76 @@
+package subscriptions
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	subscriptions "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/enums"
+	subscriptionRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/repositories"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// subscriptionRow stands in for a database holding one subscription still in an entitled status.
+// Like Postgres, it only returns the row when the query's conditions on the period hold.
+type subscriptionRow struct {
+	userID    uuid.UUID
+	periodEnd time.Time
+	graceEnds *time.Time
+}
+
+func (s *subscriptionRow) query(query string, args []driver.NamedValue) ([]string, [][]driver.Value) {
+	if !strings.HasPrefix(query, "SELECT * FROM \"subscriptions\"") {
+		return []string{"id"}, nil
+	}
+	if strings.Contains(query, "current_period_end > $") {
+		var now time.Time
+		for _, arg := range args {
+			if value, ok := arg.Value.(time.Time); ok {
+				now = value
+			}
+		}
+		inGrace := s.graceEnds != nil && s.graceEnds.After(now)
+		if !s.periodEnd.After(now) && !inGrace {
+			return []string{"id"}, nil
+		}
+	}
+
+	var graceEnds driver.Value
+	if s.graceEnds != nil {
+		graceEnds = *s.graceEnds
+	}
+	return []string{"id", "user_id", "status", "current_period_end", "grace_ends_at"},
+		[][]driver.Value{{uuid.NewString(), s.userID.String(), string(subscriptions.SubscriptionStatusActive), s.periodEnd, graceEnds}}
+}
+
+func (s *subscriptionRow) exec(string, []driver.NamedValue) {}
+
+func TestHasEntitlement_IgnoresLapsedPeriod(t *testing.T) {
+	now := time.Now()
+	graceEnds := now.Add(24 * time.Hour)
+	tests := []struct {
+		name     string
+		row      subscriptionRow
+		entitled bool
+	}{
+		{"period running", subscriptionRow{periodEnd: now.Add(time.Hour)}, true},
+		{"period ended", subscriptionRow{periodEnd: now.Add(-time.Hour)}, false},
+		{"period ended, in grace", subscriptionRow{periodEnd: now.Add(-time.Hour), graceEnds: &graceEnds}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := tt.row
+			row.userID = uuid.New()
+			service := NewEntitlementService(subscriptionRepository.NewSubscriptionRepository(openFakeDB(t, &row)))
+
+			entitled, err := service.HasEntitlement(row.userID, subscriptions.EntitlementPremiumContent)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.entitled, entitled)
+		})
+	}
+}
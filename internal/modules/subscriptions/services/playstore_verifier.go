@@ -0,0 +1,503 @@
+package subscriptions
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/config"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/tracing"
+	subscriptions "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/enums"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	googleCertsURL          = "https://www.googleapis.com/oauth2/v3/certs"
+	androidPublisherBaseURL = "https://androidpublisher.googleapis.com/androidpublisher/v3/applications"
+	androidPublisherScope   = "https://www.googleapis.com/auth/androidpublisher"
+)
+
+// Play subscription states that still grant access
+const (
+	playStateActive      = "SUBSCRIPTION_STATE_ACTIVE"
+	playStateGracePeriod = "SUBSCRIPTION_STATE_IN_GRACE_PERIOD"
+	playStateCanceled    = "SUBSCRIPTION_STATE_CANCELED"
+)
+
+// PlaySubscriptionPurchase is the subset of the Play Developer API subscriptionsv2 resource we use
+type PlaySubscriptionPurchase struct {
+	SubscriptionState          string    `json:"subscriptionState"`
+	StartTime                  time.Time `json:"startTime"`
+	LatestOrderID              string    `json:"latestOrderId"`
+	ExternalAccountIdentifiers *struct {
+		ObfuscatedExternalAccountID string `json:"obfuscatedExternalAccountId"`
+	} `json:"externalAccountIdentifiers"`
+	LineItems []PlayLineItem `json:"lineItems"`
+}
+
+type PlayLineItem struct {
+	ProductID    string    `json:"productId"`
+	ExpiryTime   time.Time `json:"expiryTime"`
+	OfferDetails struct {
+		BasePlanID string `json:"basePlanId"`
+		OfferID    string `json:"offerId"`
+	} `json:"offerDetails"`
+}
+
+// PlaySubscriptionsClient looks up subscription purchases by token
+type PlaySubscriptionsClient interface {
+	GetSubscription(ctx context.Context, packageName, purchaseToken string) (*PlaySubscriptionPurchase, error)
+}
+
+// KeySource resolves the RSA public key that signed a token
+type KeySource interface {
+	Key(ctx context.Context, kid string) (*rsa.PublicKey, error)
+}
+
+// GooglePlayVerifier verifies purchase tokens with the Play Developer API and Real-time Developer
+// Notifications delivered by Pub/Sub push, whose OIDC bearer token must be signed by Google for Audience.
+type GooglePlayVerifier struct {
+	PackageName         string
+	Audience            string
+	ServiceAccountEmail string
+	Client              PlaySubscriptionsClient
+	Keys                KeySource
+	Now                 func() time.Time
+}
+
+var _ StoreVerifier = (*GooglePlayVerifier)(nil)
+
+func NewGooglePlayVerifier(packageName string, client PlaySubscriptionsClient, keys KeySource, audience, serviceAccountEmail string) *GooglePlayVerifier {
+	return &GooglePlayVerifier{
+		PackageName:         packageName,
+		Audience:            audience,
+		ServiceAccountEmail: serviceAccountEmail,
+		Client:              client,
+		Keys:                keys,
+		Now:                 time.Now,
+	}
+}
+
+// NewGooglePlayVerifierFromConfig builds the verifier from the Google Play and Pub/Sub store settings
+func NewGooglePlayVerifierFromConfig(cfg config.StoresConfig) *GooglePlayVerifier {
+	var client PlaySubscriptionsClient
+	if path := cfg.GooglePlayServiceAccountFile; path != "" {
+		publisher, err := NewAndroidPublisherClient(path)
+		if err != nil {
+			slog.Error("Failed to load Google Play service account", "error", err)
+		} else {
+			client = publisher
+		}
+	}
+
+	return NewGooglePlayVerifier(
+		cfg.GooglePlayPackageName,
+		client,
+		NewJWKSKeySource(googleCertsURL),
+		cfg.GooglePubSubAudience,
+		cfg.GooglePubSubServiceAccount,
+	)
+}
+
+func (v *GooglePlayVerifier) Provider() string {
+	return subscriptions.ProviderGooglePlay
+}
+
+func (v *GooglePlayVerifier) VerifyPurchase(ctx context.Context, purchase StorePurchase) (*StoreTransaction, error) {
+	if purchase.PurchaseToken == "" {
+		return nil, errors.New("missing purchase token")
+	}
+
+	transaction, state, err := v.lookupSubscription(ctx, purchase.PurchaseToken)
+	if err != nil {
+		return nil, err
+	}
+	if transaction.ProductID != purchase.ProductID {
+		return nil, fmt.Errorf("purchase token is for product %q", transaction.ProductID)
+	}
+	if state != playStateActive && state != playStateGracePeriod && state != playStateCanceled {
+		return nil, fmt.Errorf("subscription is %s", state)
+	}
+
+	return transaction, nil
+}
+
+type playDeveloperNotification struct {
+	Version                  string `json:"version"`
+	PackageName              string `json:"packageName"`
+	EventTimeMillis          string `json:"eventTimeMillis"`
+	SubscriptionNotification *struct {
+		NotificationType int    `json:"notificationType"`
+		PurchaseToken    string `json:"purchaseToken"`
+		SubscriptionID   string `json:"subscriptionId"`
+	} `json:"subscriptionNotification"`
+}
+
+type pubSubPush struct {
+	Message struct {
+		Data      string `json:"data"`
+		MessageID string `json:"messageId"`
+	} `json:"message"`
+	Subscription string `json:"subscription"`
+}
+
+type pubSubClaims struct {
+	jwt.RegisteredClaims
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+}
+
+func (v *GooglePlayVerifier) ParseNotification(ctx context.Context, body []byte, header http.Header) (*StoreNotification, error) {
+	if err := v.verifyPushToken(ctx, header.Get("Authorization")); err != nil {
+		return nil, err
+	}
+
+	var push pubSubPush
+	if err := json.Unmarshal(body, &push); err != nil {
+		return nil, fmt.Errorf("malformed push message: %w", err)
+	}
+	data, err := base64.StdEncoding.DecodeString(push.Message.Data)
+	if err != nil {
+		return nil, fmt.Errorf("malformed push message data: %w", err)
+	}
+
+	var payload playDeveloperNotification
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("malformed developer notification: %w", err)
+	}
+	if payload.PackageName != v.PackageName {
+		return nil, fmt.Errorf("notification is for package %q", payload.PackageName)
+	}
+
+	notification := &StoreNotification{ID: push.Message.MessageID, Type: "TEST"}
+	if millis, err := strconv.ParseInt(payload.EventTimeMillis, 10, 64); err == nil {
+		notification.OccurredAt = time.UnixMilli(millis)
+	}
+
+	// test notifications and one-time product notifications carry no subscription
+	if payload.SubscriptionNotification == nil {
+		return notification, nil
+	}
+
+	subscriptionNotification := payload.SubscriptionNotification
+	notification.Type = strconv.Itoa(subscriptionNotification.NotificationType)
+	notification.Event = playEvent(subscriptionNotification.NotificationType)
+	if notification.Event == "" {
+		return notification, nil
+	}
+
+	notification.Transaction = &StoreTransaction{
+		ExternalID: subscriptionNotification.PurchaseToken,
+		ProductID:  subscriptionNotification.SubscriptionID,
+	}
+
+	// the notification only names the token, the current period comes from the Play Developer API
+	if v.Client != nil {
+		transaction, _, err := v.lookupSubscription(ctx, subscriptionNotification.PurchaseToken)
+		if err != nil {
+			return nil, err
+		}
+		notification.Transaction = transaction
+	}
+
+	return notification, nil
+}
+
+// playEvent maps a Play subscription notification type to a subscription event
+func playEvent(notificationType int) subscriptions.SubscriptionEvent {
+	switch notificationType {
+	case 1, 2: // SUBSCRIPTION_RECOVERED, SUBSCRIPTION_RENEWED
+		return subscriptions.EventRenewed
+	case 3: // SUBSCRIPTION_CANCELED
+		return subscriptions.EventCanceled
+	case 4: // SUBSCRIPTION_PURCHASED
+		return subscriptions.EventPurchased
+	case 5, 13: // SUBSCRIPTION_ON_HOLD, SUBSCRIPTION_EXPIRED
+		return subscriptions.EventExpired
+	case 6: // SUBSCRIPTION_IN_GRACE_PERIOD
+		return subscriptions.EventGraceStarted
+	case 12: // SUBSCRIPTION_REVOKED
+		return subscriptions.EventRefunded
+	}
+	return ""
+}
+
+func (v *GooglePlayVerifier) lookupSubscription(ctx context.Context, purchaseToken string) (*StoreTransaction, string, error) {
+	if v.Client == nil {
+		return nil, "", errors.New("google play client is not configured")
+	}
+
+	purchase, err := v.Client.GetSubscription(ctx, v.PackageName, purchaseToken)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to look up purchase: %w", err)
+	}
+	if len(purchase.LineItems) == 0 {
+		return nil, "", errors.New("purchase has no line items")
+	}
+
+	item := purchase.LineItems[0]
+	transaction := &StoreTransaction{
+		ExternalID:  purchaseToken,
+		ProductID:   item.ProductID,
+		PurchasedAt: purchase.StartTime,
+		ExpiresAt:   item.ExpiryTime,
+	}
+	if purchase.ExternalAccountIdentifiers != nil {
+		transaction.AccountID = purchase.ExternalAccountIdentifiers.ObfuscatedExternalAccountID
+	}
+	return transaction, purchase.SubscriptionState, nil
+}
+
+// verifyPushToken checks the OIDC token Pub/Sub attaches to push requests
+func (v *GooglePlayVerifier) verifyPushToken(ctx context.Context, authorization string) error {
+	raw, found := strings.CutPrefix(authorization, "Bearer ")
+	if !found || raw == "" {
+		return errors.New("missing bearer token")
+	}
+	if v.Audience == "" {
+		return errors.New("push audience is not configured")
+	}
+
+	now := time.Now
+	if v.Now != nil {
+		now = v.Now
+	}
+
+	var claims pubSubClaims
+	_, err := jwt.ParseWithClaims(raw, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return v.Keys.Key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithAudience(v.Audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(now),
+	)
+	if err != nil {
+		return fmt.Errorf("invalid bearer token: %w", err)
+	}
+
+	if claims.Issuer != "accounts.google.com" && claims.Issuer != "https://accounts.google.com" {
+		return fmt.Errorf("unexpected token issuer %q", claims.Issuer)
+	}
+	if v.ServiceAccountEmail != "" && (claims.Email != v.ServiceAccountEmail || !claims.EmailVerified) {
+		return fmt.Errorf("unexpected token email %q", claims.Email)
+	}
+	return nil
+}
+
+// JWKSKeySource fetches and caches RSA keys published as a JSON Web Key Set
+type JWKSKeySource struct {
+	URL        string
+	HTTPClient *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+func NewJWKSKeySource(url string) *JWKSKeySource {
+	return &JWKSKeySource{URL: url, HTTPClient: tracing.NewHTTPClient(10 * time.Second)}
+}
+
+func (s *JWKSKeySource) Key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key, found := s.keys[kid]; found && time.Since(s.fetchedAt) < time.Hour {
+		return key, nil
+	}
+
+	keys, err := s.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.keys = keys
+	s.fetchedAt = time.Now()
+
+	key, found := keys[kid]
+	if !found {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+func (s *JWKSKeySource) fetch(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch signing keys: status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode signing keys: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, key := range set.Keys {
+		if key.Kty != "RSA" {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(key.N)
+		e, errE := base64.RawURLEncoding.DecodeString(key.E)
+		if errN != nil || errE != nil {
+			continue
+		}
+		keys[key.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	return keys, nil
+}
+
+// AndroidPublisherClient calls the Play Developer API with a service account
+type AndroidPublisherClient struct {
+	ClientEmail string
+	PrivateKey  *rsa.PrivateKey
+	TokenURI    string
+	HTTPClient  *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+var _ PlaySubscriptionsClient = (*AndroidPublisherClient)(nil)
+
+// NewAndroidPublisherClient loads a service account JSON key file
+func NewAndroidPublisherClient(path string) (*AndroidPublisherClient, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var account struct {
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+		TokenURI    string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(content, &account); err != nil {
+		return nil, fmt.Errorf("malformed service account file: %w", err)
+	}
+
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(account.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("malformed service account key: %w", err)
+	}
+
+	return &AndroidPublisherClient{
+		ClientEmail: account.ClientEmail,
+		PrivateKey:  key,
+		TokenURI:    account.TokenURI,
+		HTTPClient:  tracing.NewHTTPClient(10 * time.Second),
+	}, nil
+}
+
+func (c *AndroidPublisherClient) GetSubscription(ctx context.Context, packageName, purchaseToken string) (*PlaySubscriptionPurchase, error) {
+	token, err := c.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("%s/%s/purchases/subscriptionsv2/tokens/%s", androidPublisherBaseURL, url.PathEscape(packageName), url.PathEscape(purchaseToken))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("play developer api returned %d: %s", resp.StatusCode, body)
+	}
+
+	var purchase PlaySubscriptionPurchase
+	if err := json.NewDecoder(resp.Body).Decode(&purchase); err != nil {
+		return nil, fmt.Errorf("failed to decode purchase: %w", err)
+	}
+	return &purchase, nil
+}
+
+// token exchanges a signed service account assertion for an OAuth access token
+func (c *AndroidPublisherClient) token(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.accessToken != "" && time.Now().Before(c.expiresAt) {
+		return c.accessToken, nil
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   c.ClientEmail,
+		"scope": androidPublisherScope,
+		"aud":   c.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(c.PrivateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign service account assertion: %w", err)
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint returned %d", resp.StatusCode)
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode access token: %w", err)
+	}
+
+	c.accessToken = result.AccessToken
+	c.expiresAt = now.Add(time.Duration(result.ExpiresIn)*time.Second - time.Minute)
+	return c.accessToken, nil
+}
@@ -0,0 +1,204 @@
+package subscriptions
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	subscriptions "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/enums"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testPackageName    = "com.example.khaimah"
+	testPushAudience   = "https://api.example.com/subscriptions/webhooks/google-play"
+	testPushAccount    = "play-rtdn@example.iam.gserviceaccount.com"
+	testPurchaseToken  = "purchase-token-1"
+	testPlayProductID  = "premium_monthly"
+	testPushSigningKey = "test-key-1"
+)
+
+type staticKeySource map[string]*rsa.PublicKey
+
+func (s staticKeySource) Key(_ context.Context, kid string) (*rsa.PublicKey, error) {
+	key, found := s[kid]
+	if !found {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+type fakePlayClient struct {
+	purchase *PlaySubscriptionPurchase
+}
+
+func (c fakePlayClient) GetSubscription(_ context.Context, packageName, purchaseToken string) (*PlaySubscriptionPurchase, error) {
+	if packageName != testPackageName || purchaseToken != testPurchaseToken {
+		return nil, fmt.Errorf("purchase not found")
+	}
+	return c.purchase, nil
+}
+
+func newTestPlayPurchase(state string, expiresAt time.Time) *PlaySubscriptionPurchase {
+	purchase := &PlaySubscriptionPurchase{SubscriptionState: state, StartTime: time.Now().Add(-time.Hour)}
+	purchase.LineItems = []PlayLineItem{{ProductID: testPlayProductID, ExpiryTime: expiresAt}}
+	return purchase
+}
+
+func newTestPlayVerifier(t *testing.T, purchase *PlaySubscriptionPurchase) (*GooglePlayVerifier, *rsa.PrivateKey) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	verifier := NewGooglePlayVerifier(
+		testPackageName,
+		fakePlayClient{purchase: purchase},
+		staticKeySource{testPushSigningKey: &key.PublicKey},
+		testPushAudience,
+		testPushAccount,
+	)
+	return verifier, key
+}
+
+func signPushToken(t *testing.T, key *rsa.PrivateKey, audience string) http.Header {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            "https://accounts.google.com",
+		"aud":            audience,
+		"email":          testPushAccount,
+		"email_verified": true,
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Hour).Unix(),
+	})
+	token.Header["kid"] = testPushSigningKey
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+signed)
+	return header
+}
+
+func testPushBody(t *testing.T, notificationType int) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]interface{}{
+		"version":         "1.0",
+		"packageName":     testPackageName,
+		"eventTimeMillis": fmt.Sprint(time.Now().UnixMilli()),
+		"subscriptionNotification": map[string]interface{}{
+			"version":          "1.0",
+			"notificationType": notificationType,
+			"purchaseToken":    testPurchaseToken,
+			"subscriptionId":   testPlayProductID,
+		},
+	})
+	require.NoError(t, err)
+
+	body, err := json.Marshal(map[string]interface{}{
+		"message": map[string]string{
+			"data":      base64.StdEncoding.EncodeToString(data),
+			"messageId": "136969346945",
+		},
+		"subscription": "projects/khaimah/subscriptions/play-rtdn",
+	})
+	require.NoError(t, err)
+	return body
+}
+
+func TestGooglePlayParseNotification_Renewed(t *testing.T) {
+	expiresAt := time.Now().Add(30 * 24 * time.Hour).UTC().Truncate(time.Second)
+	verifier, key := newTestPlayVerifier(t, newTestPlayPurchase(playStateActive, expiresAt))
+
+	notification, err := verifier.ParseNotification(context.Background(), testPushBody(t, 2), signPushToken(t, key, testPushAudience))
+
+	require.NoError(t, err)
+	assert.Equal(t, "136969346945", notification.ID)
+	assert.Equal(t, subscriptions.EventRenewed, notification.Event)
+	assert.Equal(t, testPurchaseToken, notification.Transaction.ExternalID)
+	assert.Equal(t, testPlayProductID, notification.Transaction.ProductID)
+	assert.True(t, expiresAt.Equal(notification.Transaction.ExpiresAt))
+}
+
+func TestGooglePlayParseNotification_EventMapping(t *testing.T) {
+	verifier, key := newTestPlayVerifier(t, newTestPlayPurchase(playStateActive, time.Now().Add(time.Hour)))
+
+	tests := map[int]subscriptions.SubscriptionEvent{
+		3:  subscriptions.EventCanceled,
+		4:  subscriptions.EventPurchased,
+		6:  subscriptions.EventGraceStarted,
+		12: subscriptions.EventRefunded,
+		13: subscriptions.EventExpired,
+		8:  "",
+	}
+
+	for notificationType, event := range tests {
+		notification, err := verifier.ParseNotification(context.Background(), testPushBody(t, notificationType), signPushToken(t, key, testPushAudience))
+
+		require.NoError(t, err)
+		assert.Equal(t, event, notification.Event, "notification type %d", notificationType)
+	}
+}
+
+func TestGooglePlayParseNotification_WrongAudience(t *testing.T) {
+	verifier, key := newTestPlayVerifier(t, newTestPlayPurchase(playStateActive, time.Now().Add(time.Hour)))
+
+	_, err := verifier.ParseNotification(context.Background(), testPushBody(t, 2), signPushToken(t, key, "https://attacker.example.com"))
+
+	assert.ErrorContains(t, err, "invalid bearer token")
+}
+
+func TestGooglePlayParseNotification_UnknownSigner(t *testing.T) {
+	verifier, _ := newTestPlayVerifier(t, newTestPlayPurchase(playStateActive, time.Now().Add(time.Hour)))
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	_, err = verifier.ParseNotification(context.Background(), testPushBody(t, 2), signPushToken(t, otherKey, testPushAudience))
+
+	assert.ErrorContains(t, err, "invalid bearer token")
+}
+
+func TestGooglePlayParseNotification_MissingToken(t *testing.T) {
+	verifier, _ := newTestPlayVerifier(t, newTestPlayPurchase(playStateActive, time.Now().Add(time.Hour)))
+
+	_, err := verifier.ParseNotification(context.Background(), testPushBody(t, 2), http.Header{})
+
+	assert.ErrorContains(t, err, "missing bearer token")
+}
+
+func TestGooglePlayVerifyPurchase(t *testing.T) {
+	expiresAt := time.Now().Add(30 * 24 * time.Hour).UTC().Truncate(time.Second)
+	verifier, _ := newTestPlayVerifier(t, newTestPlayPurchase(playStateActive, expiresAt))
+
+	transaction, err := verifier.VerifyPurchase(context.Background(), StorePurchase{PurchaseToken: testPurchaseToken, ProductID: testPlayProductID})
+
+	require.NoError(t, err)
+	assert.Equal(t, testPurchaseToken, transaction.ExternalID)
+	assert.True(t, expiresAt.Equal(transaction.ExpiresAt))
+}
+
+func TestGooglePlayVerifyPurchase_ProductMismatch(t *testing.T) {
+	verifier, _ := newTestPlayVerifier(t, newTestPlayPurchase(playStateActive, time.Now().Add(time.Hour)))
+
+	_, err := verifier.VerifyPurchase(context.Background(), StorePurchase{PurchaseToken: testPurchaseToken, ProductID: "premium_yearly"})
+
+	assert.ErrorContains(t, err, "product")
+}
+
+func TestGooglePlayVerifyPurchase_Expired(t *testing.T) {
+	verifier, _ := newTestPlayVerifier(t, newTestPlayPurchase("SUBSCRIPTION_STATE_EXPIRED", time.Now().Add(-time.Hour)))
+
+	_, err := verifier.VerifyPurchase(context.Background(), StorePurchase{PurchaseToken: testPurchaseToken, ProductID: testPlayProductID})
+
+	assert.Error(t, err)
+}
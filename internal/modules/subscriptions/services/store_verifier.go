@@ -0,0 +1,220 @@
+package subscriptions
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	subscriptionDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/dtos"
+	subscriptions "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/enums"
+	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/models"
+	subscriptionRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/repositories"
+	"github.com/google/uuid"
+)
+
+// StorePurchase is what the app submits after a purchase completes
+type StorePurchase struct {
+	SignedTransaction string
+	PurchaseToken     string
+	ProductID         string
+}
+
+// StoreTransaction is a purchase whose signature or token has been verified with the store
+type StoreTransaction struct {
+	ExternalID  string
+	ProductID   string
+	AccountID   string
+	PurchasedAt time.Time
+	ExpiresAt   time.Time
+	IsTrial     bool
+	RevokedAt   *time.Time
+}
+
+// StoreNotification is a verified server notification translated into a subscription event.
+// Event is empty for notification types that do not change subscription state.
+type StoreNotification struct {
+	ID          string
+	Type        string
+	Event       subscriptions.SubscriptionEvent
+	Transaction *StoreTransaction
+	GraceEndsAt time.Time
+	OccurredAt  time.Time
+}
+
+// StoreVerifier verifies purchases and server notifications for a single store
+type StoreVerifier interface {
+	Provider() string
+	VerifyPurchase(ctx context.Context, purchase StorePurchase) (*StoreTransaction, error)
+	ParseNotification(ctx context.Context, body []byte, header http.Header) (*StoreNotification, error)
+}
+
+type StoreService struct {
+	SubscriptionRepo *subscriptionRepository.SubscriptionRepository
+	StateManager     StateManager
+	Verifiers        map[string]StoreVerifier
+}
+
+func NewStoreService(subscriptionRepo *subscriptionRepository.SubscriptionRepository, stateManager StateManager, verifiers ...StoreVerifier) *StoreService {
+	service := &StoreService{
+		SubscriptionRepo: subscriptionRepo,
+		StateManager:     stateManager,
+		Verifiers:        make(map[string]StoreVerifier),
+	}
+	for _, verifier := range verifiers {
+		service.Verifiers[verifier.Provider()] = verifier
+	}
+	return service
+}
+
+// VerifyPurchase verifies a purchase submitted by the app and activates the matching subscription
+func (s *StoreService) VerifyPurchase(ctx context.Context, userID string, req subscriptionDTO.VerifyPurchaseRequestDTO) base.Response {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return base.SetErrorMessage("Invalid User ID", err)
+	}
+
+	verifier, found := s.Verifiers[req.Provider]
+	if !found {
+		return base.SetErrorMessage("Unsupported provider", "Purchases from this store are not supported")
+	}
+
+	transaction, err := verifier.VerifyPurchase(ctx, StorePurchase{
+		SignedTransaction: req.SignedTransaction,
+		PurchaseToken:     req.PurchaseToken,
+		ProductID:         req.ProductID,
+	})
+	if err != nil {
+		return base.SetErrorMessage("Invalid purchase", err)
+	}
+
+	if transaction.AccountID != "" && transaction.AccountID != uid.String() {
+		return base.SetErrorMessage("Invalid purchase", "This purchase belongs to another account")
+	}
+
+	existing, err := s.SubscriptionRepo.FindSubscriptionByExternalID(req.Provider, transaction.ExternalID)
+	if err != nil {
+		return base.SetErrorMessage("Failed to fetch subscription", err)
+	}
+	if existing != nil && existing.UserID != uid {
+		return base.SetErrorMessage("Invalid purchase", "This purchase belongs to another account")
+	}
+	// a transaction without an account token could have been submitted by anyone holding it, so it
+	// is only accepted again from the account it is already bound to
+	if transaction.AccountID == "" && existing == nil {
+		return base.SetErrorMessage("Invalid purchase", "This purchase is not linked to an account")
+	}
+
+	if transaction.RevokedAt != nil || !transaction.ExpiresAt.After(time.Now()) {
+		return base.SetErrorMessage("Subscription expired", "This purchase is no longer active")
+	}
+
+	plan, err := s.SubscriptionRepo.FindPlanByProductID(req.Provider, transaction.ProductID)
+	if err != nil {
+		return base.SetErrorMessage("Failed to fetch plan", err)
+	}
+	if plan == nil {
+		return base.SetErrorMessage("Plan not found", "No plan exists for this product")
+	}
+
+	event := subscriptions.EventPurchased
+	if transaction.IsTrial {
+		event = subscriptions.EventTrialStarted
+	}
+
+	subscription, err := s.StateManager.Apply(StateChange{
+		Event:      event,
+		Provider:   req.Provider,
+		ExternalID: transaction.ExternalID,
+		UserID:     uid,
+		PlanCode:   plan.Code,
+		PeriodEnd:  transaction.ExpiresAt,
+	})
+	if err != nil {
+		return base.SetErrorMessage("Failed to activate subscription", err)
+	}
+
+	return base.SetData(subscriptionDTO.MapToSubscriptionDTO(*subscription), "Subscription activated successfully")
+}
+
+// HandleNotification verifies a store server notification and applies the state change it carries
+func (s *StoreService) HandleNotification(ctx context.Context, provider string, body []byte, header http.Header) base.Response {
+	verifier, found := s.Verifiers[provider]
+	if !found {
+		return base.SetErrorMessage("Unsupported provider", "Notifications from this store are not supported")
+	}
+
+	notification, err := verifier.ParseNotification(ctx, body, header)
+	if err != nil {
+		response := base.SetErrorMessage("Invalid notification", err)
+		response.HTTPStatus = http.StatusUnauthorized
+		return response
+	}
+
+	if notification.ID != "" {
+		processed, err := s.SubscriptionRepo.IsStoreNotificationProcessed(provider, notification.ID)
+		if err != nil {
+			return base.SetErrorMessage("Failed to check notification", err)
+		}
+		if processed {
+			return base.SetSuccessMessage("Notification already processed")
+		}
+	}
+
+	if notification.Event != "" && notification.Transaction != nil {
+		if err := s.applyNotification(provider, notification); err != nil {
+			return base.SetErrorMessage("Failed to apply notification", err)
+		}
+	}
+
+	if notification.ID != "" {
+		err := s.SubscriptionRepo.CreateStoreNotification(&models.StoreNotification{
+			Provider:       provider,
+			NotificationID: notification.ID,
+			Type:           notification.Type,
+		})
+		if err != nil {
+			slog.Error("Failed to record store notification", "provider", provider, "notification_id", notification.ID, "error", err)
+		}
+	}
+
+	return base.SetSuccessMessage("Notification processed")
+}
+
+func (s *StoreService) applyNotification(provider string, notification *StoreNotification) error {
+	transaction := notification.Transaction
+
+	change := StateChange{
+		Event:      notification.Event,
+		Provider:   provider,
+		ExternalID: transaction.ExternalID,
+		PeriodEnd:  transaction.ExpiresAt,
+		OccurredAt: notification.OccurredAt,
+	}
+	if notification.Event == subscriptions.EventGraceStarted {
+		change.PeriodEnd = notification.GraceEndsAt
+	}
+	if uid, err := uuid.Parse(transaction.AccountID); err == nil {
+		change.UserID = uid
+	}
+
+	if transaction.ProductID != "" {
+		plan, err := s.SubscriptionRepo.FindPlanByProductID(provider, transaction.ProductID)
+		if err != nil {
+			return err
+		}
+		if plan != nil {
+			change.PlanCode = plan.Code
+		}
+	}
+
+	_, err := s.StateManager.Apply(change)
+	if errors.Is(err, ErrSubscriptionNotFound) || errors.Is(err, ErrPlanNotFound) {
+		// the purchase was never linked to an account here, nothing to update
+		slog.Warn("Ignoring store notification for unknown subscription", "provider", provider, "type", notification.Type, "external_id", transaction.ExternalID)
+		return nil
+	}
+	return err
+}
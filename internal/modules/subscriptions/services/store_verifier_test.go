@@ -0,0 +1,72 @@
+package subscriptions
+
+import (
+	"context"
+	"database/sql/driver"
+	"net/http"
+	"testing"
+	"time"
+
+	subscriptionDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/dtos"
+	subscriptions "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/enums"
+	subscriptionRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/repositories"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubVerifier accepts any purchase as the configured transaction
+type stubVerifier struct{ transaction StoreTransaction }
+
+func (v stubVerifier) Provider() string { return subscriptions.ProviderAppStore }
+
+func (v stubVerifier) VerifyPurchase(context.Context, StorePurchase) (*StoreTransaction, error) {
+	transaction := v.transaction
+	return &transaction, nil
+}
+
+func (v stubVerifier) ParseNotification(context.Context, []byte, http.Header) (*StoreNotification, error) {
+	return nil, nil
+}
+
+// emptyStore stands in for a database without subscriptions or plans
+type emptyStore struct{}
+
+func (emptyStore) query(string, []driver.NamedValue) ([]string, [][]driver.Value) {
+	return []string{"id"}, nil
+}
+
+func (emptyStore) exec(string, []driver.NamedValue) {}
+
+func TestVerifyPurchase_RequiresAccountToken(t *testing.T) {
+	userID := uuid.New()
+	tests := []struct {
+		name      string
+		accountID string
+		title     string
+	}{
+		{"no account token", "", "Invalid purchase"},
+		{"caller's account token", userID.String(), "Plan not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewStoreService(
+				subscriptionRepository.NewSubscriptionRepository(openFakeDB(t, emptyStore{})),
+				nil,
+				stubVerifier{StoreTransaction{
+					ExternalID: "2000000000000001",
+					ProductID:  "premium.monthly",
+					AccountID:  tt.accountID,
+					ExpiresAt:  time.Now().Add(30 * 24 * time.Hour),
+				}},
+			)
+
+			response := service.VerifyPurchase(context.Background(), userID.String(), subscriptionDTO.VerifyPurchaseRequestDTO{
+				Provider:          subscriptions.ProviderAppStore,
+				SignedTransaction: "signed",
+			})
+
+			assert.Equal(t, tt.title, response.MessageTitle)
+		})
+	}
+}
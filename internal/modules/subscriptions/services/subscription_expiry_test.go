@@ -0,0 +1,152 @@
+package subscriptions
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	subscriptions "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/enums"
+	subscriptionRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/repositories"
+	userEnums "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/enums"
+	userRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/repositories"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// grantStore stands in for the database holding a single manual grant. It answers the queries of
+// the expiry job and records the statements that change data.
+type grantStore struct {
+	mu             sync.Mutex
+	subscriptionID uuid.UUID
+	userID         uuid.UUID
+	planID         uuid.UUID
+	periodEnd      time.Time
+	status         subscriptions.SubscriptionStatus
+	userType       string
+	events         int
+}
+
+func (s *grantStore) query(query string, args []driver.NamedValue) ([]string, [][]driver.Value) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case strings.HasPrefix(query, "SELECT count(*) FROM \"subscriptions\""):
+		var count int64
+		for _, status := range subscriptions.EntitledStatuses {
+			if s.status == status {
+				count = 1
+			}
+		}
+		return []string{"count"}, [][]driver.Value{{count}}
+	case strings.HasPrefix(query, "SELECT * FROM \"subscriptions\""):
+		// the row is only found by its own ID: a manual grant has no external ID to match
+		if strings.Contains(query, "external_id") {
+			return []string{"id"}, nil
+		}
+		return []string{"id", "user_id", "plan_id", "status", "provider", "external_id", "auto_renew", "current_period_start", "current_period_end"},
+			[][]driver.Value{{s.subscriptionID.String(), s.userID.String(), s.planID.String(), string(s.status), subscriptions.ProviderManual, "", false, s.periodEnd.AddDate(0, 0, -30), s.periodEnd}}
+	case strings.HasPrefix(query, "SELECT * FROM \"plans\""):
+		return []string{"id", "code", "period", "grace_days"}, [][]driver.Value{{s.planID.String(), "monthly", "monthly", int64(3)}}
+	case strings.HasPrefix(query, "INSERT INTO \"subscription_event_logs\""):
+		s.events++
+		return []string{"id"}, [][]driver.Value{{uuid.NewString()}}
+	}
+	return []string{"id"}, nil
+}
+
+func (s *grantStore) exec(query string, args []driver.NamedValue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case strings.HasPrefix(query, "UPDATE \"subscriptions\""):
+		for _, arg := range args {
+			if value, ok := arg.Value.(string); ok && value == string(subscriptions.SubscriptionStatusExpired) {
+				s.status = subscriptions.SubscriptionStatusExpired
+			}
+		}
+	case strings.HasPrefix(query, "UPDATE \"users\" SET \"user_type\""):
+		s.userType = args[0].Value.(string)
+	}
+}
+
+// fakeStore stands in for the database behind a fakeConn, answering queries and statements
+type fakeStore interface {
+	query(query string, args []driver.NamedValue) ([]string, [][]driver.Value)
+	exec(query string, args []driver.NamedValue)
+}
+
+type fakeConn struct{ store fakeStore }
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c fakeConn) Close() error                        { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)           { return c, nil }
+func (c fakeConn) Commit() error                       { return nil }
+func (c fakeConn) Rollback() error                     { return nil }
+
+func (c fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.store.exec(query, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (c fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	columns, rows := c.store.query(query, args)
+	return &fakeRows{columns: columns, rows: rows}, nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+type fakeConnector struct{ store fakeStore }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return fakeConn(c), nil }
+func (c fakeConnector) Driver() driver.Driver                        { return nil }
+
+func openFakeDB(t *testing.T, store fakeStore) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(fakeConnector{store})}), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	return db
+}
+
+func TestExpireSubscriptions_ExpiresManualGrant(t *testing.T) {
+	store := &grantStore{
+		subscriptionID: uuid.New(),
+		userID:         uuid.New(),
+		planID:         uuid.New(),
+		periodEnd:      time.Now().Add(-time.Hour),
+		status:         subscriptions.SubscriptionStatusActive,
+		userType:       string(userEnums.UserTypeSubscribed),
+	}
+	db := openFakeDB(t, store)
+	service := NewSubscriptionService(subscriptionRepository.NewSubscriptionRepository(db), userRepository.NewUserRepository(db))
+
+	require.NoError(t, service.ExpireSubscriptions(context.Background(), ""))
+
+	assert.Equal(t, subscriptions.SubscriptionStatusExpired, store.status)
+	assert.Equal(t, 1, store.events)
+	assert.Equal(t, string(userEnums.UserTypeFree), store.userType)
+}
@@ -0,0 +1,375 @@
+package subscriptions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	subscriptionDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/dtos"
+	subscriptions "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/enums"
+	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/models"
+	subscriptionRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/repositories"
+	userEnums "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/enums"
+	userRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/repositories"
+	"github.com/google/uuid"
+)
+
+const SubscriptionExpiryJobName = "subscriptions.expiry"
+
+var (
+	ErrPlanNotFound         = errors.New("plan not found")
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+)
+
+// StateChange is a subscription lifecycle event reported by a billing backend
+type StateChange struct {
+	Event subscriptions.SubscriptionEvent
+	// SubscriptionID names the subscription directly, for changes the app makes itself such as
+	// expiry; store events are matched by Provider and ExternalID instead
+	SubscriptionID uuid.UUID
+	Provider       string
+	ExternalID     string
+	UserID         uuid.UUID
+	PlanCode       string
+	PeriodEnd      time.Time
+	Days           int
+	OccurredAt     time.Time
+}
+
+// StateManager applies subscription state changes. Billing backends never touch subscriptions
+// directly; they translate their own notifications into StateChange values and hand them over.
+type StateManager interface {
+	Apply(change StateChange) (*models.Subscription, error)
+}
+
+type SubscriptionService struct {
+	SubscriptionRepo *subscriptionRepository.SubscriptionRepository
+	UserRepo         *userRepository.UserRepository
+}
+
+var _ StateManager = (*SubscriptionService)(nil)
+
+func NewSubscriptionService(subscriptionRepo *subscriptionRepository.SubscriptionRepository, userRepo *userRepository.UserRepository) *SubscriptionService {
+	return &SubscriptionService{
+		SubscriptionRepo: subscriptionRepo,
+		UserRepo:         userRepo,
+	}
+}
+
+// Apply moves a subscription through its lifecycle and keeps the user's tier in sync
+func (s *SubscriptionService) Apply(change StateChange) (*models.Subscription, error) {
+	if change.OccurredAt.IsZero() {
+		change.OccurredAt = time.Now()
+	}
+
+	subscription, err := s.findSubscriptionForChange(change)
+	if err != nil {
+		return nil, err
+	}
+
+	fromStatus := subscription.Status
+	now := change.OccurredAt
+
+	switch change.Event {
+	case subscriptions.EventTrialStarted, subscriptions.EventPurchased, subscriptions.EventRenewed:
+		status := subscriptions.SubscriptionStatusActive
+		if change.Event == subscriptions.EventTrialStarted {
+			status = subscriptions.SubscriptionStatusTrialing
+		}
+		periodEnd := change.PeriodEnd
+		if periodEnd.IsZero() {
+			if status == subscriptions.SubscriptionStatusTrialing && subscription.Plan.TrialDays > 0 {
+				periodEnd = now.AddDate(0, 0, subscription.Plan.TrialDays)
+			} else {
+				periodEnd = subscription.Plan.PeriodEnd(now)
+			}
+		}
+		subscription.Status = status
+		subscription.CurrentPeriodStart = now
+		subscription.CurrentPeriodEnd = periodEnd
+		subscription.AutoRenew = true
+		subscription.GraceEndsAt = nil
+		subscription.CanceledAt = nil
+		subscription.ExpiredAt = nil
+
+	case subscriptions.EventGranted:
+		start := now
+		if subscription.CurrentPeriodEnd.After(now) && fromStatus != subscriptions.SubscriptionStatusExpired {
+			start = subscription.CurrentPeriodEnd
+		} else {
+			subscription.CurrentPeriodStart = now
+		}
+		subscription.Status = subscriptions.SubscriptionStatusActive
+		subscription.CurrentPeriodEnd = start.AddDate(0, 0, change.Days)
+		subscription.AutoRenew = false
+		subscription.ExpiredAt = nil
+
+	case subscriptions.EventGraceStarted:
+		graceEndsAt := change.PeriodEnd
+		if graceEndsAt.IsZero() {
+			graceEndsAt = now.AddDate(0, 0, subscription.Plan.GraceDays)
+		}
+		subscription.Status = subscriptions.SubscriptionStatusGrace
+		subscription.GraceEndsAt = &graceEndsAt
+
+	case subscriptions.EventCanceled:
+		subscription.AutoRenew = false
+		subscription.CanceledAt = &now
+
+	case subscriptions.EventExpired, subscriptions.EventRefunded:
+		subscription.Status = subscriptions.SubscriptionStatusExpired
+		subscription.AutoRenew = false
+		subscription.ExpiredAt = &now
+		if change.Event == subscriptions.EventRefunded {
+			subscription.CurrentPeriodEnd = now
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported subscription event: %s", change.Event)
+	}
+
+	event := &models.SubscriptionEventLog{
+		Event:      change.Event,
+		FromStatus: fromStatus,
+		ToStatus:   subscription.Status,
+		Provider:   change.Provider,
+		OccurredAt: now,
+	}
+	if err := s.SubscriptionRepo.SaveSubscription(subscription, event); err != nil {
+		return nil, err
+	}
+
+	if err := s.syncUserTier(subscription.UserID); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// findSubscriptionForChange loads the subscription the change refers to, or prepares a new one
+// when the change starts a subscription
+func (s *SubscriptionService) findSubscriptionForChange(change StateChange) (*models.Subscription, error) {
+	var subscription *models.Subscription
+	var err error
+
+	if change.SubscriptionID != uuid.Nil {
+		subscription, err = s.SubscriptionRepo.FindSubscriptionByID(change.SubscriptionID)
+	} else if change.ExternalID != "" {
+		subscription, err = s.SubscriptionRepo.FindSubscriptionByExternalID(change.Provider, change.ExternalID)
+	} else if change.UserID != uuid.Nil && change.Event == subscriptions.EventGranted {
+		// granted days stack onto an existing grant but never onto a store-billed subscription
+		subscription, err = s.SubscriptionRepo.FindCurrentGrantedSubscription(change.UserID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if subscription != nil {
+		return subscription, nil
+	}
+
+	switch change.Event {
+	case subscriptions.EventTrialStarted, subscriptions.EventPurchased, subscriptions.EventRenewed, subscriptions.EventGranted:
+	default:
+		return nil, ErrSubscriptionNotFound
+	}
+	if change.UserID == uuid.Nil {
+		return nil, ErrSubscriptionNotFound
+	}
+
+	plan, err := s.SubscriptionRepo.FindPlanByCode(change.PlanCode)
+	if err != nil {
+		return nil, err
+	}
+	if plan == nil {
+		return nil, ErrPlanNotFound
+	}
+
+	return &models.Subscription{
+		UserID:     change.UserID,
+		PlanID:     plan.ID,
+		Plan:       plan,
+		Provider:   change.Provider,
+		ExternalID: change.ExternalID,
+	}, nil
+}
+
+// syncUserTier keeps users.user_type in line with whether the user currently holds an entitled subscription
+func (s *SubscriptionService) syncUserTier(userID uuid.UUID) error {
+	count, err := s.SubscriptionRepo.CountEntitledSubscriptions(userID)
+	if err != nil {
+		return err
+	}
+
+	userType := userEnums.UserTypeFree
+	if count > 0 {
+		userType = userEnums.UserTypeSubscribed
+	}
+	return s.UserRepo.UpdateUserType(userID, userType)
+}
+
+// ExpireSubscriptions is the job handler that moves lapsed subscriptions into grace or expiry
+func (s *SubscriptionService) ExpireSubscriptions(_ context.Context, _ string) error {
+	now := time.Now()
+	due, err := s.SubscriptionRepo.FindSubscriptionsDueForTransition(now)
+	if err != nil {
+		return err
+	}
+
+	for _, subscription := range due {
+		event := subscriptions.EventExpired
+		if subscription.Status != subscriptions.SubscriptionStatusGrace && subscription.AutoRenew &&
+			subscription.Plan != nil && subscription.Plan.GraceDays > 0 {
+			event = subscriptions.EventGraceStarted
+		}
+
+		// Granted subscriptions have no external ID, so the row is named by its own ID
+		_, err := s.Apply(StateChange{
+			Event:          event,
+			SubscriptionID: subscription.ID,
+			Provider:       subscription.Provider,
+			UserID:         subscription.UserID,
+			OccurredAt:     now,
+		})
+		if err != nil {
+			slog.Error("Failed to transition subscription", "subscription_id", subscription.ID, "event", event, "error", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *SubscriptionService) GetPlans() base.Response {
+	plans, err := s.SubscriptionRepo.FindAllPlans(true)
+	if err != nil {
+		return base.SetErrorMessage("Failed to fetch plans", err)
+	}
+
+	response := make([]subscriptionDTO.PlanDTO, len(plans))
+	for i, plan := range plans {
+		response[i] = subscriptionDTO.MapToPlanDTO(plan)
+	}
+	return base.SetData(response)
+}
+
+func (s *SubscriptionService) CreatePlan(req subscriptionDTO.CreatePlanRequestDTO) base.Response {
+	existing, err := s.SubscriptionRepo.FindPlanByCode(req.Code)
+	if err != nil {
+		return base.SetErrorMessage("Failed to fetch plan", err)
+	}
+	if existing != nil {
+		return base.SetErrorMessage("Plan already exists", "A plan with this code already exists")
+	}
+
+	plan := &models.Plan{
+		Code:                req.Code,
+		Name:                req.Name,
+		Period:              req.Period,
+		PriceCents:          req.PriceCents,
+		Currency:            req.Currency,
+		TrialDays:           req.TrialDays,
+		GraceDays:           req.GraceDays,
+		IsActive:            true,
+		AppStoreProductID:   req.AppStoreProductID,
+		GooglePlayProductID: req.GooglePlayProductID,
+	}
+	if err := s.SubscriptionRepo.CreatePlan(plan); err != nil {
+		return base.SetErrorMessage("Failed to create plan", err)
+	}
+
+	return base.SetData(subscriptionDTO.MapToPlanDTO(*plan), "Plan created successfully")
+}
+
+func (s *SubscriptionService) UpdatePlan(planID string, req subscriptionDTO.UpdatePlanRequestDTO) base.Response {
+	uid, err := uuid.Parse(planID)
+	if err != nil {
+		return base.SetErrorMessage("Invalid Plan ID", err)
+	}
+
+	plan, err := s.SubscriptionRepo.FindPlanByID(uid)
+	if err != nil {
+		return base.SetErrorMessage("Failed to fetch plan", err)
+	}
+	if plan == nil {
+		return base.SetErrorMessage("Plan not found", "No plan exists with this ID")
+	}
+
+	if req.Name != "" {
+		plan.Name = req.Name
+	}
+	if req.PriceCents != nil {
+		plan.PriceCents = *req.PriceCents
+	}
+	if req.TrialDays != nil {
+		plan.TrialDays = *req.TrialDays
+	}
+	if req.GraceDays != nil {
+		plan.GraceDays = *req.GraceDays
+	}
+	if req.IsActive != nil {
+		plan.IsActive = *req.IsActive
+	}
+	if req.AppStoreProductID != nil {
+		plan.AppStoreProductID = *req.AppStoreProductID
+	}
+	if req.GooglePlayProductID != nil {
+		plan.GooglePlayProductID = *req.GooglePlayProductID
+	}
+
+	if err := s.SubscriptionRepo.UpdatePlan(plan); err != nil {
+		return base.SetErrorMessage("Failed to update plan", err)
+	}
+
+	return base.SetData(subscriptionDTO.MapToPlanDTO(*plan), "Plan updated successfully")
+}
+
+func (s *SubscriptionService) GetMySubscription(userID string) base.Response {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return base.SetErrorMessage("Invalid User ID", err)
+	}
+
+	subscription, err := s.SubscriptionRepo.FindLatestSubscription(uid)
+	if err != nil {
+		return base.SetErrorMessage("Failed to fetch subscription", err)
+	}
+	if subscription == nil {
+		return base.SetData(nil, "No subscription found")
+	}
+
+	return base.SetData(subscriptionDTO.MapToSubscriptionDTO(*subscription))
+}
+
+// GrantSubscription lets an admin give a user premium days on a plan without going through a store
+func (s *SubscriptionService) GrantSubscription(req subscriptionDTO.GrantSubscriptionRequestDTO) base.Response {
+	uid, err := uuid.Parse(req.UserID)
+	if err != nil {
+		return base.SetErrorMessage("Invalid User ID", err)
+	}
+
+	user, err := s.UserRepo.FindOneByID(uid)
+	if err != nil {
+		return base.SetErrorMessage("Failed to fetch user", err)
+	}
+	if user == nil {
+		return base.SetErrorMessage("User not found", "No user exists with this ID")
+	}
+
+	subscription, err := s.Apply(StateChange{
+		Event:    subscriptions.EventGranted,
+		Provider: subscriptions.ProviderManual,
+		UserID:   uid,
+		PlanCode: req.PlanCode,
+		Days:     req.Days,
+	})
+	if errors.Is(err, ErrPlanNotFound) {
+		return base.SetErrorMessage("Plan not found", "No plan exists with this code")
+	}
+	if err != nil {
+		return base.SetErrorMessage("Failed to grant subscription", err)
+	}
+
+	return base.SetData(subscriptionDTO.MapToSubscriptionDTO(*subscription), "Subscription granted successfully")
+}
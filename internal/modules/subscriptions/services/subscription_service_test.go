@@ -0,0 +1,67 @@
+package subscriptions
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	subscriptionDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/dtos"
+	subscriptions "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/enums"
+	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanPeriodEnd(t *testing.T) {
+	start := time.Date(2025, 1, 31, 10, 0, 0, 0, time.UTC)
+
+	monthly := models.Plan{Period: subscriptions.PlanPeriodMonthly}
+	yearly := models.Plan{Period: subscriptions.PlanPeriodYearly}
+
+	assert.Equal(t, start.AddDate(0, 1, 0), monthly.PeriodEnd(start))
+	assert.Equal(t, time.Date(2026, 1, 31, 10, 0, 0, 0, time.UTC), yearly.PeriodEnd(start))
+}
+
+func TestApply_UnknownSubscription(t *testing.T) {
+	service := SubscriptionService{}
+
+	_, err := service.Apply(StateChange{Event: subscriptions.EventExpired, UserID: uuid.New()})
+
+	assert.ErrorIs(t, err, ErrSubscriptionNotFound)
+}
+
+func TestGetMySubscription_InvalidUserID(t *testing.T) {
+	service := SubscriptionService{}
+
+	response := service.GetMySubscription("invalid-uuid")
+
+	assert.Equal(t, http.StatusBadRequest, response.HTTPStatus)
+	assert.Equal(t, "Invalid User ID", response.MessageTitle)
+}
+
+func TestGrantSubscription_InvalidUserID(t *testing.T) {
+	service := SubscriptionService{}
+
+	response := service.GrantSubscription(subscriptionDTO.GrantSubscriptionRequestDTO{UserID: "invalid-uuid", PlanCode: "monthly", Days: 30})
+
+	assert.Equal(t, http.StatusBadRequest, response.HTTPStatus)
+	assert.Equal(t, "Invalid User ID", response.MessageTitle)
+}
+
+func TestCanAccessPodcast_FreeContent(t *testing.T) {
+	var service *EntitlementService
+
+	canAccess, err := service.CanAccessPodcast(uuid.Nil, false)
+
+	assert.NoError(t, err)
+	assert.True(t, canAccess)
+}
+
+func TestCanAccessPodcast_PremiumWithoutUser(t *testing.T) {
+	service := EntitlementService{}
+
+	canAccess, err := service.CanAccessPodcast(uuid.Nil, true)
+
+	assert.NoError(t, err)
+	assert.False(t, canAccess)
+}
@@ -0,0 +1,97 @@
+package users
+
+import (
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	categories "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/categories/models"
+	subscriptionDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/dtos"
+	users "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/enums"
+)
+
+// ListUsersRequestDTO searches and filters users for the admin panel; created_from and
+// created_to are RFC 3339 times.
+// swagger:model ListUsersRequestDTO
+type ListUsersRequestDTO struct {
+	base.PaginationRequest
+	Search       string `query:"q" validate:"omitempty,max=100" example:"john"`
+	UserType     string `query:"user_type" validate:"omitempty,oneof=free subscribed admin" message:"user_type must be free, subscribed or admin" example:"subscribed"`
+	SignupMethod string `query:"signup_method" validate:"omitempty,oneof=password email_otp mobile_otp google apple import" message:"signup_method must be password, email_otp, mobile_otp, google, apple or import" example:"google"`
+	CategoryID   string `query:"category_id" validate:"omitempty,uuid" message:"category_id must be a valid ID"`
+	CreatedFrom  string `query:"created_from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00" message:"created_from must be an RFC 3339 time" example:"2025-01-01T00:00:00Z"`
+	CreatedTo    string `query:"created_to" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00" message:"created_to must be an RFC 3339 time" example:"2025-02-01T00:00:00Z"`
+	Sort         string `query:"sort" validate:"omitempty,oneof=created_at first_name last_name email" message:"sort must be created_at, first_name, last_name or email" example:"created_at"`
+	Order        string `query:"order" validate:"omitempty,oneof=asc desc" message:"order must be asc or desc" example:"desc"`
+}
+
+// AdminUserDTO is one row of the admin user list.
+// swagger:model AdminUserDTO
+type AdminUserDTO struct {
+	ID           string             `json:"id" example:"abcd1234"`
+	FirstName    string             `json:"first_name" example:"John"`
+	LastName     string             `json:"last_name" example:"Doe"`
+	Email        string             `json:"email" example:"john.doe@example.com"`
+	Mobile       string             `json:"mobile,omitempty" example:"+9665XXXXXXX"`
+	UserType     users.UserType     `json:"user_type" example:"subscribed"`
+	SignupMethod users.SignupMethod `json:"signup_method,omitempty" example:"google"`
+	Roles        []string           `json:"roles"`
+	CreatedAt    time.Time          `json:"created_at" example:"2025-06-05T15:04:05Z"`
+	// DeletionRequestedAt is set while the account waits out its deletion grace period
+	DeletionRequestedAt *time.Time `json:"deletion_requested_at,omitempty"`
+	SuspendedUntil      *time.Time `json:"suspended_until,omitempty"`
+	BannedAt            *time.Time `json:"banned_at,omitempty"`
+	RestrictionReason   string     `json:"restriction_reason,omitempty"`
+}
+
+// AdminUserDetailDTO is everything the admin panel shows about one user.
+// swagger:model AdminUserDetailDTO
+type AdminUserDetailDTO struct {
+	AdminUserDTO
+	Categories   []categories.Category            `json:"categories"`
+	Session      UserSessionDTO                   `json:"session"`
+	Listening    ListeningStatsDTO                `json:"listening"`
+	Subscription *subscriptionDTO.SubscriptionDTO `json:"subscription"`
+}
+
+// UserSessionDTO describes how the user signs in and their most recent logins
+type UserSessionDTO struct {
+	IsActive            bool           `json:"is_active"`
+	TwoFactorEnabled    bool           `json:"two_factor_enabled"`
+	FailedLoginAttempts int            `json:"failed_login_attempts"`
+	LastFailedLoginAt   *time.Time     `json:"last_failed_login_at,omitempty"`
+	LockedUntil         *time.Time     `json:"locked_until,omitempty"`
+	RecentLogins        []UserLoginDTO `json:"recent_logins"`
+}
+
+type UserLoginDTO struct {
+	At        string `json:"at" example:"2025-06-05T15:04:05Z"`
+	Method    string `json:"method,omitempty" example:"password"`
+	IP        string `json:"ip,omitempty" example:"203.0.113.7"`
+	UserAgent string `json:"user_agent,omitempty"`
+}
+
+type ListeningStatsDTO struct {
+	PodcastsStarted   int64      `json:"podcasts_started" example:"42"`
+	PodcastsCompleted int64      `json:"podcasts_completed" example:"30"`
+	LastListenedAt    *time.Time `json:"last_listened_at,omitempty" example:"2025-06-05T15:04:05Z"`
+	Likes             int64      `json:"likes" example:"12"`
+	Bookmarks         int64      `json:"bookmarks" example:"5"`
+	Downloads         int64      `json:"downloads" example:"3"`
+}
+
+// ImpersonateRequestDTO defines the body for impersonating a user. Tokens are read-only
+// unless allow_writes is set by an admin who may also update users.
+// swagger:model ImpersonateRequestDTO
+type ImpersonateRequestDTO struct {
+	Reason      string `json:"reason" validate:"required,max=500" example:"Ticket #1234: feed shows no podcasts"`
+	AllowWrites bool   `json:"allow_writes" example:"false"`
+}
+
+// ImpersonationTokenDTO is a short-lived token that acts as the impersonated user.
+// swagger:model ImpersonationTokenDTO
+type ImpersonationTokenDTO struct {
+	Token     string    `json:"token" example:"eyJhbGciOiJFUzI1Ni..."`
+	UserID    string    `json:"user_id" example:"abcd1234"`
+	ReadOnly  bool      `json:"read_only" example:"true"`
+	ExpiresAt time.Time `json:"expires_at" example:"2025-06-05T15:19:05Z"`
+}
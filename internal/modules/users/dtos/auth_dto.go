@@ -1,11 +1,13 @@
 package users
 
 type OAuthRequestDTO struct {
-	Provider string `json:"provider"`
+	Provider     string `json:"provider"`
+	ReferralCode string `json:"referral_code"`
 }
 
 type SSOLoginResponse struct {
 	Token      string       `json:"token"`
+	ExpiresAt  string       `json:"expires_at"`
 	UserExists bool         `json:"user_exists"`
 	User       *UserBaseDTO `json:"user"`
 }
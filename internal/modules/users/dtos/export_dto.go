@@ -0,0 +1,36 @@
+package users
+
+import (
+	"time"
+
+	users "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/enums"
+)
+
+// DataExportDTO describes the state of a personal data export.
+// swagger:model DataExportDTO
+type DataExportDTO struct {
+	ID          string             `json:"id" example:"abcd1234"`
+	Status      users.ExportStatus `json:"status" example:"ready"`
+	RequestedAt time.Time          `json:"requested_at"`
+	CompletedAt *time.Time         `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time         `json:"expires_at,omitempty"`
+	DownloadURL string             `json:"download_url,omitempty" example:"https://api.example.com/exports/5f2c..."`
+}
+
+type ExportProfileDTO struct {
+	ID        string         `json:"id"`
+	FirstName string         `json:"first_name"`
+	LastName  string         `json:"last_name"`
+	Email     string         `json:"email"`
+	Mobile    string         `json:"mobile"`
+	UserType  users.UserType `json:"user_type"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+type ExportSessionDTO struct {
+	ID        string    `json:"id"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,46 @@
+package users
+
+import (
+	"time"
+
+	users "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/enums"
+)
+
+// ImportRowStatus says why a row of an import was not created
+type ImportRowStatus string
+
+const (
+	// ImportRowInvalid rows have a bad mobile number, name or category, or repeat an earlier row
+	ImportRowInvalid ImportRowStatus = "invalid"
+	// ImportRowSkipped rows are for a mobile number that already has an account
+	ImportRowSkipped ImportRowStatus = "skipped"
+	// ImportRowFailed rows were valid but could not be saved
+	ImportRowFailed ImportRowStatus = "failed"
+)
+
+// ImportRowDTO reports a row of an import file that was not created; row is its line in the file.
+// swagger:model ImportRowDTO
+type ImportRowDTO struct {
+	Row    int             `json:"row" example:"7"`
+	Mobile string          `json:"mobile,omitempty" example:"966501234567"`
+	Status ImportRowStatus `json:"status" example:"invalid"`
+	Errors []string        `json:"errors"`
+}
+
+// UserImportDTO reports on a CSV import. A dry run is not saved, so it has no ID or status, and
+// created_count is how many users the import would create.
+// swagger:model UserImportDTO
+type UserImportDTO struct {
+	ID           string             `json:"id,omitempty" example:"abcd1234"`
+	FileName     string             `json:"file_name" example:"partner-launch.csv"`
+	DryRun       bool               `json:"dry_run" example:"false"`
+	Status       users.ImportStatus `json:"status,omitempty" example:"completed"`
+	TotalRows    int                `json:"total_rows" example:"250"`
+	CreatedCount int                `json:"created_count" example:"240"`
+	SkippedCount int                `json:"skipped_count" example:"6"`
+	FailedCount  int                `json:"failed_count" example:"4"`
+	Rows         []ImportRowDTO     `json:"rows"`
+	RequestedAt  *time.Time         `json:"requested_at,omitempty"`
+	CompletedAt  *time.Time         `json:"completed_at,omitempty"`
+	Error        string             `json:"error,omitempty"`
+}
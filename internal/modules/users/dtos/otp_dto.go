@@ -7,10 +7,11 @@ import (
 // SendOTPRequestDTO defines the body for sending an OTP.
 // swagger:model SendOTPRequestDTO
 type SendOTPRequestDTO struct {
-	Mobile     string   `json:"mobile" validate:"omitempty" example:"+9665XXXXXXX"`
-	Email      string   `json:"email" validate:"omitempty,email" example:"user@example.com"`
-	FirstName  string   `json:"first_name" validate:"omitempty" example:"Ziyad"`
-	Categories []string `json:"categories" validate:"omitempty" example:"[\"9d671bac-17b0-42cf-b68a-aa908f30b134\"]"`
+	Mobile       string   `json:"mobile" validate:"omitempty" example:"+9665XXXXXXX"`
+	Email        string   `json:"email" validate:"omitempty,email" example:"user@example.com"`
+	FirstName    string   `json:"first_name" validate:"omitempty" example:"Ziyad"`
+	Categories   []string `json:"categories" validate:"omitempty" example:"[\"9d671bac-17b0-42cf-b68a-aa908f30b134\"]"`
+	ReferralCode string   `json:"referral_code" validate:"omitempty" example:"K7M2QX9P"`
 }
 
 // SendOTPResponseDTO is returned after successfully sending an OTP.
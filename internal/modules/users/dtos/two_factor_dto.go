@@ -0,0 +1,63 @@
+package users
+
+import (
+	categories "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/categories/models"
+)
+
+// TwoFactorEnrollmentDTO is returned when enrollment starts. The secret is shown once, for
+// authenticator apps that cannot scan the QR code.
+// swagger:model TwoFactorEnrollmentDTO
+type TwoFactorEnrollmentDTO struct {
+	Secret     string `json:"secret" example:"JBSWY3DPEHPK3PXP"`
+	OTPAuthURI string `json:"otpauth_uri" example:"otpauth://totp/Khaimah:john.doe@example.com?secret=JBSWY3DPEHPK3PXP&issuer=Khaimah"`
+	QRCode     string `json:"qr_code" example:"data:image/png;base64,iVBORw0KGgo..."`
+}
+
+// TwoFactorCodeDTO carries a TOTP code or, where accepted, a recovery code.
+// swagger:model TwoFactorCodeDTO
+type TwoFactorCodeDTO struct {
+	Code string `json:"code" validate:"required" example:"123456"`
+}
+
+// TwoFactorRecoveryCodesDTO lists freshly generated recovery codes. They are shown only once.
+// swagger:model TwoFactorRecoveryCodesDTO
+type TwoFactorRecoveryCodesDTO struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"[\"k7m2q-x9pw4\"]"`
+}
+
+// TwoFactorStatusDTO describes the current user's two-factor setup.
+// swagger:model TwoFactorStatusDTO
+type TwoFactorStatusDTO struct {
+	Enabled                bool  `json:"enabled" example:"true"`
+	Required               bool  `json:"required" example:"false"`
+	RecoveryCodesRemaining int64 `json:"recovery_codes_remaining" example:"10"`
+}
+
+// TwoFactorChallengeDTO is returned instead of a token when the account has two-factor
+// authentication enabled. The challenge token is exchanged at /auth/2fa/challenge.
+// swagger:model TwoFactorChallengeDTO
+type TwoFactorChallengeDTO struct {
+	TwoFactorRequired bool   `json:"two_factor_required" example:"true"`
+	ChallengeToken    string `json:"challenge_token" example:"4f1c2a..."`
+	ExpiresIn         int    `json:"expires_in" example:"300"`
+}
+
+// TwoFactorChallengeRequestDTO completes a two-factor challenge with a TOTP or recovery code.
+// swagger:model TwoFactorChallengeRequestDTO
+type TwoFactorChallengeRequestDTO struct {
+	ChallengeToken string `json:"challenge_token" validate:"required" example:"4f1c2a..."`
+	Code           string `json:"code" validate:"required" example:"123456"`
+}
+
+// TwoFactorLoginResponseDTO is returned once a two-factor challenge is completed.
+// swagger:model TwoFactorLoginResponseDTO
+type TwoFactorLoginResponseDTO struct {
+	ID         string                `json:"id" example:"abcd1234"`
+	FirstName  string                `json:"first_name" example:"John"`
+	LastName   string                `json:"last_name" example:"Doe"`
+	Email      string                `json:"email" example:"john.doe@example.com"`
+	Mobile     string                `json:"mobile,omitempty" example:"+9665XXXXXXX"`
+	Categories []categories.Category `json:"categories"`
+	Token      string                `json:"token" example:"eyJhbGciOiJIUzI1Ni..."`
+	ExpiresAt  string                `json:"expires_at" example:"2025-06-05T15:04:05Z"`
+}
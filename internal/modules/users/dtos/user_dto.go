@@ -1,6 +1,8 @@
 package users
 
 import (
+	"time"
+
 	categoryDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/categories/dtos"
 	categories "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/categories/models"
 	podcastDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/podcasts/dtos"
@@ -10,11 +12,12 @@ import (
 // SignupRequestDTO defines the body for creating a new user.
 // swagger:model SignupRequestDTO
 type SignupRequestDTO struct {
-	FirstName  string   `json:"first_name" validate:"required" example:"John"`
-	LastName   string   `json:"last_name" validate:"omitempty" example:"Doe"`
-	Email      string   `json:"email" validate:"required,email" example:"john.doe@example.com"`
-	Categories []string `json:"categories" validate:"required"  example:"[\"f6a75a87-d695-4ee9-a095-5a79edce4eb8\",\"1db71f60-f276-431d-934c-fd84f8014566\"]"`
-	Password   string   `json:"password" validate:"required,passwordvalidator" message:"Password must be at least 6 characters and contain both letters and numbers" example:"Pa55word"`
+	FirstName    string   `json:"first_name" validate:"required" example:"John"`
+	LastName     string   `json:"last_name" validate:"omitempty" example:"Doe"`
+	Email        string   `json:"email" validate:"required,email" example:"john.doe@example.com"`
+	Categories   []string `json:"categories" validate:"required"  example:"[\"f6a75a87-d695-4ee9-a095-5a79edce4eb8\",\"1db71f60-f276-431d-934c-fd84f8014566\"]"`
+	Password     string   `json:"password" validate:"required,passwordvalidator" message:"Password must be at least 6 characters and contain both letters and numbers" example:"Pa55word"`
+	ReferralCode string   `json:"referral_code" validate:"omitempty" example:"K7M2QX9P"`
 }
 
 // SignupResponseDTO is returned after a successful signup.
@@ -48,6 +51,37 @@ type LoginResponseDTO struct {
 	ExpiresAt  string                `json:"expires_at" example:"2025-06-05T15:04:05Z"`
 }
 
+// RefreshTokenResponseDTO is returned when a token is renewed.
+// swagger:model RefreshTokenResponseDTO
+type RefreshTokenResponseDTO struct {
+	Token     string `json:"token" example:"eyJhbGciOiJIUzI1Ni..."`
+	ExpiresAt string `json:"expires_at" example:"2025-06-05T15:04:05Z"`
+}
+
+// LockedAccountDTO describes an account whose password login is locked.
+// swagger:model LockedAccountDTO
+type LockedAccountDTO struct {
+	UserID              string     `json:"user_id" example:"abcd1234"`
+	Email               string     `json:"email" example:"john.doe@example.com"`
+	Mobile              string     `json:"mobile,omitempty" example:"+9665XXXXXXX"`
+	FailedLoginAttempts int        `json:"failed_login_attempts" example:"10"`
+	LastFailedLoginAt   *time.Time `json:"last_failed_login_at" example:"2025-06-05T15:04:05Z"`
+	LockedUntil         *time.Time `json:"locked_until" example:"2025-06-05T15:19:05Z"`
+}
+
+// SuspendUserRequestDTO defines the body for suspending a user until a given RFC 3339 time.
+// swagger:model SuspendUserRequestDTO
+type SuspendUserRequestDTO struct {
+	Until  string `json:"until" validate:"required,datetime=2006-01-02T15:04:05Z07:00" message:"until must be an RFC 3339 time" example:"2025-07-01T00:00:00Z"`
+	Reason string `json:"reason" validate:"required,max=500" example:"Spamming comments"`
+}
+
+// BanUserRequestDTO defines the body for banning a user.
+// swagger:model BanUserRequestDTO
+type BanUserRequestDTO struct {
+	Reason string `json:"reason" validate:"required,max=500" example:"Repeated abuse after suspension"`
+}
+
 // UserProfileDTO represents a user's profile data.
 // swagger:model UserProfileDTO
 type UserProfileDTO struct {
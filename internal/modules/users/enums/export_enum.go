@@ -0,0 +1,12 @@
+package users
+
+type ExportStatus string
+
+const (
+	ExportStatusPending    ExportStatus = "pending"
+	ExportStatusProcessing ExportStatus = "processing"
+	ExportStatusReady      ExportStatus = "ready"
+	ExportStatusFailed     ExportStatus = "failed"
+	// ExportStatusExpired exports had their archive deleted once the download link expired
+	ExportStatusExpired ExportStatus = "expired"
+)
@@ -5,5 +5,20 @@ type UserType string
 const (
 	UserTypeFree       UserType = "free"
 	UserTypeSubscribed UserType = "subscribed"
-	UserTypeAdmin      UserType = "admin"
+	// UserTypeAdmin is kept for accounts created before roles existed; the seeder moves them to the superadmin role
+	UserTypeAdmin UserType = "admin"
+)
+
+// SignupMethod is how an account was first created. Accounts created before it was recorded
+// have none, except the password and mobile OTP ones the migration could recognise.
+type SignupMethod string
+
+const (
+	SignupMethodPassword  SignupMethod = "password"
+	SignupMethodEmailOTP  SignupMethod = "email_otp"
+	SignupMethodMobileOTP SignupMethod = "mobile_otp"
+	SignupMethodGoogle    SignupMethod = "google"
+	SignupMethodApple     SignupMethod = "apple"
+	// SignupMethodImport marks accounts an admin pre-provisioned from a CSV; they sign in with a mobile OTP
+	SignupMethodImport SignupMethod = "import"
 )
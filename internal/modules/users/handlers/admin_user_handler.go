@@ -0,0 +1,130 @@
+package users
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	base "github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/logger"
+	roles "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/enums"
+	roleModels "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/models"
+	roleService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/services"
+	userDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/dtos"
+	userService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/services"
+	"github.com/labstack/echo/v4"
+)
+
+type AdminUserHandler struct {
+	AdminUserService *userService.AdminUserService
+}
+
+func NewAdminUserHandler(adminUserService *userService.AdminUserService) *AdminUserHandler {
+	return &AdminUserHandler{
+		AdminUserService: adminUserService,
+	}
+}
+
+// @Summary     Search users (admin only)
+// @Description Returns a page of users matching a search on name, email or mobile, and the given filters
+// @Tags        users
+// @Produce     json
+// @Param       q              query     string  false  "Part of the name, email or mobile"
+// @Param       user_type      query     string  false  "free, subscribed or admin"
+// @Param       signup_method  query     string  false  "password, email_otp, mobile_otp, google, apple or import"
+// @Param       category_id    query     string  false  "Only users following this category"
+// @Param       created_from   query     string  false  "Earliest signup time, RFC 3339"
+// @Param       created_to     query     string  false  "Latest signup time (exclusive), RFC 3339"
+// @Param       sort           query     string  false  "created_at, first_name, last_name or email"
+// @Param       order          query     string  false  "asc or desc; newest first by default"
+// @Param       page           query     int     false  "Page number"
+// @Param       per_page       query     int     false  "Users per page, at most 100"
+// @Success     200            {array}   userDTO.AdminUserDTO
+// @Failure     400            {object}  echo.HTTPError
+// @Router      /admin/users [get]
+func (h *AdminUserHandler) ListUsers(c echo.Context) error {
+	var req userDTO.ListUsersRequestDTO
+	if res, ok := base.BindAndValidate(c, &req); !ok {
+		return c.JSON(res.HTTPStatus, res)
+	}
+	req.BindPaginationParams(c)
+
+	response := h.AdminUserService.ListUsers(req)
+	return c.JSON(response.HTTPStatus, response)
+}
+
+// @Summary     Export users as CSV (admin only)
+// @Description Downloads every user matching the same search and filters as the list endpoint, newest first. The mobile, first_name, last_name and categories columns come first, so the file can be imported again.
+// @Tags        users
+// @Produce     text/csv
+// @Param       q              query     string  false  "Part of the name, email or mobile"
+// @Param       user_type      query     string  false  "free, subscribed or admin"
+// @Param       signup_method  query     string  false  "password, email_otp, mobile_otp, google, apple or import"
+// @Param       category_id    query     string  false  "Only users following this category"
+// @Param       created_from   query     string  false  "Earliest signup time, RFC 3339"
+// @Param       created_to     query     string  false  "Latest signup time (exclusive), RFC 3339"
+// @Success     200            {file}    file
+// @Failure     400            {object}  echo.HTTPError
+// @Router      /admin/users/export [get]
+func (h *AdminUserHandler) ExportUsers(c echo.Context) error {
+	var req userDTO.ListUsersRequestDTO
+	if res, ok := base.BindAndValidate(c, &req); !ok {
+		return c.JSON(res.HTTPStatus, res)
+	}
+	filter, err := userService.NewUserFilter(req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, base.SetErrorMessage("معايير البحث غير صالحة", err.Error()))
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", "users-"+time.Now().Format("2006-01-02")+".csv"))
+	c.Response().WriteHeader(http.StatusOK)
+
+	// The status is already sent, so a failure part way through can only cut the file short
+	if err := h.AdminUserService.ExportUsers(filter, c.Response()); err != nil {
+		logger.FromEcho(c).Error("Failed to export users", "error", err)
+	}
+	return nil
+}
+
+// @Summary     Get a user's details (admin only)
+// @Description Returns a user's profile, sign-in state and recent logins, listening stats and subscription
+// @Tags        users
+// @Produce     json
+// @Param       user_id  path      string  true  "User ID"
+// @Success     200      {object}  userDTO.AdminUserDetailDTO
+// @Failure     400      {object}  echo.HTTPError
+// @Failure     404      {object}  echo.HTTPError
+// @Router      /admin/users/{user_id} [get]
+func (h *AdminUserHandler) GetUserDetails(c echo.Context) error {
+	response := h.AdminUserService.GetUserDetails(c.Param("user_id"))
+	return c.JSON(response.HTTPStatus, response)
+}
+
+// @Summary     Impersonate a user (admin only)
+// @Description Issues a short-lived token that acts as the user, to see what they see. It is read-only unless allow_writes is set by an admin who may also update users. Every request made with it is audited and answered with an X-Impersonated-By header.
+// @Tags        users
+// @Accept      json
+// @Produce     json
+// @Param       user_id  path      string                         true  "ID of the user to impersonate"
+// @Param       request  body      userDTO.ImpersonateRequestDTO  true  "Reason, and whether to allow writes"
+// @Success     200      {object}  userDTO.ImpersonationTokenDTO
+// @Failure     400      {object}  echo.HTTPError
+// @Failure     403      {object}  echo.HTTPError
+// @Router      /admin/users/{user_id}/impersonate [post]
+func (h *AdminUserHandler) Impersonate(c echo.Context) error {
+	var req userDTO.ImpersonateRequestDTO
+	if res, ok := base.BindAndValidate(c, &req); !ok {
+		return c.JSON(res.HTTPStatus, res)
+	}
+
+	adminID, ok := c.Get("user_id").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, base.SetErrorMessage("غير مصرح به"))
+	}
+	adminRoles, _ := c.Get("roles").([]roleModels.Role)
+	canWrite := roleService.HasPermissions(adminRoles, roles.PermissionUsersWrite)
+
+	response := h.AdminUserService.Impersonate(c.Request().Context(), adminID, c.Param("user_id"), req, canWrite)
+	return c.JSON(response.HTTPStatus, response)
+}
@@ -30,6 +30,6 @@ func (h *AuthHandler) OAuthLogin(c echo.Context) error {
 		return c.JSON(res.HTTPStatus, res)
 	}
 
-	response := h.AuthService.SSOLogin(&oAuthRequestDTO, token)
+	response := h.AuthService.SSOLogin(c.Request().Context(), &oAuthRequestDTO, token)
 	return c.JSON(response.HTTPStatus, response)
 }
@@ -0,0 +1,71 @@
+package users
+
+import (
+	"fmt"
+	"net/http"
+
+	base "github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	userService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/services"
+	"github.com/labstack/echo/v4"
+)
+
+type ExportHandler struct {
+	ExportService *userService.ExportService
+}
+
+func NewExportHandler(exportService *userService.ExportService) *ExportHandler {
+	return &ExportHandler{
+		ExportService: exportService,
+	}
+}
+
+// @Summary     Request a personal data export
+// @Description Enqueues a job that assembles the user's data into a ZIP archive and notifies the user when it is ready
+// @Tags        users
+// @Produce     json
+// @Success     200  {object}  userDTO.DataExportDTO
+// @Failure     400  {object}  echo.HTTPError
+// @Router      /user/export [post]
+func (h *ExportHandler) RequestExport(c echo.Context) error {
+	userID, ok := c.Get("user_id").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, base.SetErrorMessage("غير مصرح به"))
+	}
+
+	response := h.ExportService.RequestExport(userID)
+	return c.JSON(response.HTTPStatus, response)
+}
+
+// @Summary     Get the latest personal data export
+// @Description Returns the status of the user's most recent data export and its download link once ready
+// @Tags        users
+// @Produce     json
+// @Success     200  {object}  userDTO.DataExportDTO
+// @Failure     400  {object}  echo.HTTPError
+// @Router      /user/export [get]
+func (h *ExportHandler) GetExportStatus(c echo.Context) error {
+	userID, ok := c.Get("user_id").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, base.SetErrorMessage("غير مصرح به"))
+	}
+
+	response := h.ExportService.GetLatestExport(userID)
+	return c.JSON(response.HTTPStatus, response)
+}
+
+// @Summary     Download a personal data export
+// @Description Downloads the export archive through its expiring link
+// @Tags        users
+// @Produce     application/zip
+// @Param       token  path  string  true  "Download token"
+// @Success     200
+// @Failure     400  {object}  echo.HTTPError
+// @Router      /exports/{token} [get]
+func (h *ExportHandler) DownloadExport(c echo.Context) error {
+	export, response := h.ExportService.FindDownloadableExport(c.Param("token"))
+	if export == nil {
+		return c.JSON(response.HTTPStatus, response)
+	}
+
+	return c.Attachment(export.FilePath, fmt.Sprintf("khaimah-data-%s.zip", export.CreatedAt.Format("2006-01-02")))
+}
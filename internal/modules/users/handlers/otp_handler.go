@@ -33,7 +33,7 @@ func (h *OTPHandler) SendOTP(c echo.Context) error {
 		return c.JSON(res.HTTPStatus, res)
 	}
 
-	response := h.otpService.SendOTP(&req)
+	response := h.otpService.SendOTP(c.Request().Context(), &req)
 	return c.JSON(response.HTTPStatus, response)
 }
 
@@ -53,6 +53,6 @@ func (h *OTPHandler) VerifyOTP(c echo.Context) error {
 		return c.JSON(res.HTTPStatus, res)
 	}
 
-	response := h.otpService.VerifyOTP(&req)
+	response := h.otpService.VerifyOTP(c.Request().Context(), &req)
 	return c.JSON(response.HTTPStatus, response)
 }
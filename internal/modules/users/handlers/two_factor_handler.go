@@ -0,0 +1,151 @@
+package users
+
+import (
+	"net/http"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	userDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/dtos"
+	userService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/services"
+	"github.com/labstack/echo/v4"
+)
+
+type TwoFactorHandler struct {
+	twoFactorService *userService.TwoFactorService
+}
+
+func NewTwoFactorHandler(twoFactorService *userService.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorService: twoFactorService,
+	}
+}
+
+// GetStatus godoc
+// @Summary Get two-factor status
+// @Description Reports whether two-factor authentication is enabled, whether it is required and how many recovery codes remain
+// @Tags users
+// @Produce json
+// @Success 200 {object} userDTO.TwoFactorStatusDTO
+// @Failure 400 {object} base.Response
+// @Router /user/2fa [get]
+func (h *TwoFactorHandler) GetStatus(c echo.Context) error {
+	userID, ok := c.Get("user_id").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, base.SetErrorMessage("غير مصرح به"))
+	}
+
+	response := h.twoFactorService.GetStatus(userID)
+	return c.JSON(response.HTTPStatus, response)
+}
+
+// Enroll godoc
+// @Summary Start two-factor enrollment
+// @Description Creates a TOTP secret and returns it with an otpauth URI and QR code for an authenticator app
+// @Tags users
+// @Produce json
+// @Success 200 {object} userDTO.TwoFactorEnrollmentDTO
+// @Failure 400 {object} base.Response
+// @Router /user/2fa/enroll [post]
+func (h *TwoFactorHandler) Enroll(c echo.Context) error {
+	userID, ok := c.Get("user_id").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, base.SetErrorMessage("غير مصرح به"))
+	}
+
+	response := h.twoFactorService.Enroll(userID)
+	return c.JSON(response.HTTPStatus, response)
+}
+
+// ConfirmEnrollment godoc
+// @Summary Confirm two-factor enrollment
+// @Description Enables two-factor authentication with the first code from the authenticator app and returns the recovery codes
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body userDTO.TwoFactorCodeDTO true "TOTP code"
+// @Success 200 {object} userDTO.TwoFactorRecoveryCodesDTO
+// @Failure 400 {object} base.Response
+// @Router /user/2fa/verify [post]
+func (h *TwoFactorHandler) ConfirmEnrollment(c echo.Context) error {
+	userID, ok := c.Get("user_id").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, base.SetErrorMessage("غير مصرح به"))
+	}
+
+	var req userDTO.TwoFactorCodeDTO
+	if res, ok := base.BindAndValidate(c, &req); !ok {
+		return c.JSON(res.HTTPStatus, res)
+	}
+
+	response := h.twoFactorService.ConfirmEnrollment(userID, req)
+	return c.JSON(response.HTTPStatus, response)
+}
+
+// Disable godoc
+// @Summary Disable two-factor authentication
+// @Description Turns two-factor authentication off after checking a TOTP or recovery code. Not allowed for staff accounts.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body userDTO.TwoFactorCodeDTO true "TOTP or recovery code"
+// @Success 200 {object} base.Response
+// @Failure 400 {object} base.Response
+// @Router /user/2fa/disable [post]
+func (h *TwoFactorHandler) Disable(c echo.Context) error {
+	userID, ok := c.Get("user_id").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, base.SetErrorMessage("غير مصرح به"))
+	}
+
+	var req userDTO.TwoFactorCodeDTO
+	if res, ok := base.BindAndValidate(c, &req); !ok {
+		return c.JSON(res.HTTPStatus, res)
+	}
+
+	response := h.twoFactorService.Disable(userID, req)
+	return c.JSON(response.HTTPStatus, response)
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate recovery codes
+// @Description Replaces every recovery code after checking a TOTP or recovery code
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body userDTO.TwoFactorCodeDTO true "TOTP or recovery code"
+// @Success 200 {object} userDTO.TwoFactorRecoveryCodesDTO
+// @Failure 400 {object} base.Response
+// @Router /user/2fa/recovery-codes [post]
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c echo.Context) error {
+	userID, ok := c.Get("user_id").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, base.SetErrorMessage("غير مصرح به"))
+	}
+
+	var req userDTO.TwoFactorCodeDTO
+	if res, ok := base.BindAndValidate(c, &req); !ok {
+		return c.JSON(res.HTTPStatus, res)
+	}
+
+	response := h.twoFactorService.RegenerateRecoveryCodes(userID, req)
+	return c.JSON(response.HTTPStatus, response)
+}
+
+// CompleteChallenge godoc
+// @Summary Complete a two-factor login
+// @Description Exchanges the challenge token returned by a login for a JWT, given a TOTP or recovery code
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body userDTO.TwoFactorChallengeRequestDTO true "Challenge token and code"
+// @Success 200 {object} userDTO.TwoFactorLoginResponseDTO
+// @Failure 401 {object} base.Response
+// @Router /auth/2fa/challenge [post]
+func (h *TwoFactorHandler) CompleteChallenge(c echo.Context) error {
+	var req userDTO.TwoFactorChallengeRequestDTO
+	if res, ok := base.BindAndValidate(c, &req); !ok {
+		return c.JSON(res.HTTPStatus, res)
+	}
+
+	response := h.twoFactorService.CompleteChallenge(c.Request().Context(), req)
+	return c.JSON(response.HTTPStatus, response)
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	base "github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	roleModels "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/models"
 	userDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/dtos"
 	userService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/services"
 	"github.com/labstack/echo/v4"
@@ -33,7 +34,7 @@ func (h *UserHandler) CreateUser(c echo.Context) error {
 		return c.JSON(res.HTTPStatus, res)
 	}
 
-	response := h.UserService.CreateUser(&signupDTO)
+	response := h.UserService.CreateUser(c.Request().Context(), &signupDTO)
 	return c.JSON(response.HTTPStatus, response)
 }
 
@@ -52,7 +53,19 @@ func (h *UserHandler) LoginUser(c echo.Context) error {
 		return c.JSON(res.HTTPStatus, res)
 	}
 
-	response := h.UserService.LoginUser(&loginDTO)
+	response := h.UserService.LoginUser(c.Request().Context(), &loginDTO, c.RealIP())
+	return c.JSON(response.HTTPStatus, response)
+}
+
+// @Summary     Refresh the current token
+// @Description Issues a new JWT for the signed-in user. Tokens expire, so clients renew them before expires_at.
+// @Tags        users
+// @Produce     json
+// @Success     200  {object}  userDTO.RefreshTokenResponseDTO
+// @Failure     401  {object}  echo.HTTPError
+// @Router      /auth/refresh [post]
+func (h *UserHandler) RefreshToken(c echo.Context) error {
+	response := h.UserService.RefreshToken(c)
 	return c.JSON(response.HTTPStatus, response)
 }
 
@@ -154,7 +167,7 @@ func (h *UserHandler) ChangePassword(c echo.Context) error {
 		return c.JSON(res.HTTPStatus, res)
 	}
 
-	response := h.UserService.ChangePassword(userID, passwordDTO)
+	response := h.UserService.ChangePassword(c.Request().Context(), userID, passwordDTO)
 	return c.JSON(response.HTTPStatus, response)
 }
 
@@ -171,49 +184,104 @@ func (h *UserHandler) DeleteMyAccount(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, base.SetErrorMessage("غير مصرح به"))
 	}
 
-	response := h.UserService.DeleteUser(userID)
+	response := h.UserService.DeleteUser(c.Request().Context(), userID)
+	return c.JSON(response.HTTPStatus, response)
+}
+
+// @Summary     Delete a user (admin only)
+// @Description Deletes any user by ID (admin only)
+// @Tags        users
+// @Produce     json
+// @Param       id   path      string  true  "ID of the user to delete"
+// @Success     200  {string}  string  "User deleted successfully"
+// @Failure     400  {object}  echo.HTTPError
+// @Router      /admin/user/:id [delete]
+func (h *UserHandler) DeleteUser(c echo.Context) error {
+	userID := c.Param("id")
+
+	response := h.UserService.DeleteUser(c.Request().Context(), userID)
+	return c.JSON(response.HTTPStatus, response)
+}
+
+// @Summary     List locked accounts (admin only)
+// @Description Returns the accounts whose password login is locked after repeated failed attempts
+// @Tags        users
+// @Produce     json
+// @Success     200  {array}   userDTO.LockedAccountDTO
+// @Failure     400  {object}  echo.HTTPError
+// @Router      /admin/locked-users [get]
+func (h *UserHandler) GetLockedAccounts(c echo.Context) error {
+	response := h.UserService.GetLockedAccounts()
 	return c.JSON(response.HTTPStatus, response)
 }
 
-// @Summary     Get all users (admin only)
-// @Description Returns a list of all users (only accessible by admins)
+// @Summary     Unlock an account (admin only)
+// @Description Lifts a password login lockout and clears the failed attempts
 // @Tags        users
 // @Produce     json
-// @Success     200  {array}   userDTO.UserProfileDTO
+// @Param       id   path      string  true  "ID of the user to unlock"
+// @Success     200  {string}  string  "تم إلغاء قفل الحساب بنجاح"
 // @Failure     400  {object}  echo.HTTPError
-// @Router      /admin/all-users [get]
-func (h *UserHandler) GetAllUsers(c echo.Context) error {
-	response := h.UserService.GetAllUsers(c)
+// @Router      /admin/user/:id/unlock [post]
+func (h *UserHandler) UnlockAccount(c echo.Context) error {
+	response := h.UserService.UnlockAccount(c.Request().Context(), c.Param("id"))
 	return c.JSON(response.HTTPStatus, response)
 }
 
-// @Summary     Mark a user as admin
-// @Description Grants admin privileges to a specific user (admin only)
+// @Summary     Suspend an account (admin only)
+// @Description Blocks the user from signing in and using the app until the given time
 // @Tags        users
+// @Accept      json
 // @Produce     json
-// @Param       user_id  path      string  true  "ID of the user to promote"
-// @Success     200      {string}  string  "User marked as admin"
+// @Param       id       path      string                         true  "ID of the user to suspend"
+// @Param       request  body      userDTO.SuspendUserRequestDTO  true  "End of the suspension and its reason"
+// @Success     200      {string}  string  "تم إيقاف الحساب بنجاح"
 // @Failure     400      {object}  echo.HTTPError
-// @Router      /admin/mark-user-admin/:user_id [post]
-func (h *UserHandler) MarkUserAsAdmin(c echo.Context) error {
-	userID := c.Param("user_id")
+// @Failure     403      {object}  echo.HTTPError
+// @Router      /admin/user/:id/suspend [post]
+func (h *UserHandler) SuspendUser(c echo.Context) error {
+	var req userDTO.SuspendUserRequestDTO
+	if res, ok := base.BindAndValidate(c, &req); !ok {
+		return c.JSON(res.HTTPStatus, res)
+	}
 
-	response := h.UserService.MarkUserAdmin(userID)
+	adminRoles, _ := c.Get("roles").([]roleModels.Role)
+	response := h.UserService.SuspendUser(c.Request().Context(), c.Param("id"), req, adminRoles)
 	return c.JSON(response.HTTPStatus, response)
 }
 
-// @Summary     Delete a user (admin only)
-// @Description Deletes any user by ID (admin only)
+// @Summary     Ban an account (admin only)
+// @Description Blocks the user from signing in and using the app until reinstated
 // @Tags        users
+// @Accept      json
 // @Produce     json
-// @Param       id   path      string  true  "ID of the user to delete"
-// @Success     200  {string}  string  "User deleted successfully"
-// @Failure     400  {object}  echo.HTTPError
-// @Router      /admin/user/:id [delete]
-func (h *UserHandler) DeleteUser(c echo.Context) error {
-	userID := c.Param("id")
+// @Param       id       path      string                     true  "ID of the user to ban"
+// @Param       request  body      userDTO.BanUserRequestDTO  true  "Reason for the ban"
+// @Success     200      {string}  string  "تم حظر الحساب بنجاح"
+// @Failure     400      {object}  echo.HTTPError
+// @Failure     403      {object}  echo.HTTPError
+// @Router      /admin/user/:id/ban [post]
+func (h *UserHandler) BanUser(c echo.Context) error {
+	var req userDTO.BanUserRequestDTO
+	if res, ok := base.BindAndValidate(c, &req); !ok {
+		return c.JSON(res.HTTPStatus, res)
+	}
 
-	response := h.UserService.DeleteUser(userID)
+	adminRoles, _ := c.Get("roles").([]roleModels.Role)
+	response := h.UserService.BanUser(c.Request().Context(), c.Param("id"), req, adminRoles)
+	return c.JSON(response.HTTPStatus, response)
+}
+
+// @Summary     Reinstate an account (admin only)
+// @Description Lifts a suspension or ban
+// @Tags        users
+// @Produce     json
+// @Param       id   path      string  true  "ID of the user to reinstate"
+// @Success     200  {string}  string  "تم إعادة تفعيل الحساب بنجاح"
+// @Failure     400  {object}  echo.HTTPError
+// @Router      /admin/user/:id/reinstate [post]
+func (h *UserHandler) ReinstateUser(c echo.Context) error {
+	response := h.UserService.ReinstateUser(c.Request().Context(), c.Param("id"))
 	return c.JSON(response.HTTPStatus, response)
 }
 
@@ -0,0 +1,74 @@
+package users
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	base "github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	userService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/services"
+	"github.com/labstack/echo/v4"
+)
+
+type UserImportHandler struct {
+	UserImportService *userService.UserImportService
+}
+
+func NewUserImportHandler(userImportService *userService.UserImportService) *UserImportHandler {
+	return &UserImportHandler{
+		UserImportService: userImportService,
+	}
+}
+
+// @Summary     Import users from CSV (admin only)
+// @Description Pre-provisions users from a CSV with a header row and the columns mobile, categories (category IDs separated by ";"), and optionally first_name and last_name. Mobile numbers are normalized to 966XXXXXXXXX; numbers that already have an account are skipped. With dry_run=true the file is only checked and the report returned; otherwise the users are created in the background and the import can be followed at /admin/users/imports/{id}.
+// @Tags        users
+// @Accept      multipart/form-data
+// @Produce     json
+// @Param       file     formData  file  true   "CSV file, at most 2 MB and 5000 rows"
+// @Param       dry_run  query     bool  false  "Only check the file"
+// @Success     200      {object}  userDTO.UserImportDTO
+// @Failure     400      {object}  echo.HTTPError
+// @Router      /admin/users/import [post]
+func (h *UserImportHandler) ImportUsers(c echo.Context) error {
+	adminID, ok := c.Get("user_id").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, base.SetErrorMessage("غير مصرح به"))
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, base.SetErrorMessage("ملف الاستيراد مطلوب"))
+	}
+	if file.Size > userService.MaxImportFileSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, base.SetErrorMessage("حجم ملف الاستيراد يتجاوز الحد المسموح"))
+	}
+	src, err := file.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, base.SetErrorMessage("تعذر قراءة ملف الاستيراد"))
+	}
+	defer src.Close()
+	content, err := io.ReadAll(io.LimitReader(src, userService.MaxImportFileSize+1))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, base.SetErrorMessage("تعذر قراءة ملف الاستيراد"))
+	}
+
+	dryRun, _ := strconv.ParseBool(c.FormValue("dry_run"))
+
+	response := h.UserImportService.ImportUsers(adminID, file.Filename, content, dryRun)
+	return c.JSON(response.HTTPStatus, response)
+}
+
+// @Summary     Get a user import (admin only)
+// @Description Returns the progress of a CSV import and, once processed, every row that was not created and why
+// @Tags        users
+// @Produce     json
+// @Param       id   path      string  true  "Import ID"
+// @Success     200  {object}  userDTO.UserImportDTO
+// @Failure     400  {object}  echo.HTTPError
+// @Failure     404  {object}  echo.HTTPError
+// @Router      /admin/users/imports/{id} [get]
+func (h *UserImportHandler) GetImport(c echo.Context) error {
+	response := h.UserImportService.GetImport(c.Param("id"))
+	return c.JSON(response.HTTPStatus, response)
+}
@@ -0,0 +1,17 @@
+package users
+
+import (
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	"github.com/google/uuid"
+)
+
+// AccountPurge records every account that was permanently removed by the purge job
+type AccountPurge struct {
+	base.Model
+	UserID              uuid.UUID `gorm:"type:uuid;index" json:"user_id"`
+	DeletionRequestedAt time.Time `json:"deletion_requested_at"`
+	PurgedAt            time.Time `json:"purged_at"`
+	RowsDeleted         int64     `json:"rows_deleted"`
+}
@@ -0,0 +1,20 @@
+package users
+
+import (
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	users "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/enums"
+	"github.com/google/uuid"
+)
+
+type DataExport struct {
+	base.Model
+	UserID      uuid.UUID          `gorm:"type:uuid;index" json:"user_id"`
+	Status      users.ExportStatus `gorm:"type:varchar(20);default:'pending'" json:"status"`
+	Token       string             `gorm:"type:varchar(64);uniqueIndex" json:"-"`
+	FilePath    string             `gorm:"type:text" json:"-"`
+	CompletedAt *time.Time         `json:"completed_at"`
+	ExpiresAt   *time.Time         `json:"expires_at"`
+	Error       string             `gorm:"type:text" json:"error,omitempty"`
+}
@@ -1,6 +1,8 @@
 package users
 
 import (
+	"time"
+
 	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
 	"github.com/google/uuid"
 )
@@ -11,4 +13,13 @@ type IamAuth struct {
 	User     *User     `gorm:"foreignKey:UserID" json:"user"`
 	Password string    `gorm:"type:varchar(255)" json:"password"`
 	IsActive bool      `json:"is_active"`
+
+	FailedLoginAttempts int        `gorm:"default:0" json:"failed_login_attempts"`
+	LastFailedLoginAt   *time.Time `json:"last_failed_login_at,omitempty"`
+	LockedUntil         *time.Time `gorm:"index" json:"locked_until,omitempty"`
+}
+
+// IsLocked reports whether password login is blocked at now
+func (a *IamAuth) IsLocked(now time.Time) bool {
+	return a.LockedUntil != nil && a.LockedUntil.After(now)
 }
@@ -0,0 +1,32 @@
+package users
+
+import (
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	"github.com/google/uuid"
+)
+
+// TwoFactor is a user's TOTP enrollment. It stays pending until the first code is verified.
+type TwoFactor struct {
+	base.Model
+	UserID uuid.UUID `gorm:"type:uuid;uniqueIndex" json:"user_id"`
+	// Secret is the base32 TOTP secret, encrypted with the two-factor key
+	Secret    string     `gorm:"type:text" json:"-"`
+	EnabledAt *time.Time `json:"enabled_at"`
+	// LastUsedStep is the time step of the last accepted code, so codes cannot be replayed
+	LastUsedStep int64 `json:"-"`
+}
+
+func (t *TwoFactor) IsEnabled() bool {
+	return t != nil && t.EnabledAt != nil
+}
+
+// TwoFactorRecoveryCode is a single-use code that stands in for a TOTP code when the
+// authenticator is lost. Only the SHA-256 hash of the code is stored.
+type TwoFactorRecoveryCode struct {
+	base.Model
+	UserID   uuid.UUID  `gorm:"type:uuid;index" json:"user_id"`
+	CodeHash string     `gorm:"type:varchar(64);uniqueIndex" json:"-"`
+	UsedAt   *time.Time `json:"used_at"`
+}
@@ -1,9 +1,12 @@
 package users
 
 import (
+	"time"
+
 	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
 	categories "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/categories/models"
 	podcasts "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/podcasts/models"
+	roles "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/models"
 	users "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/enums"
 )
 
@@ -15,8 +18,28 @@ type User struct {
 	Email     string         `gorm:"type:varchar(255);index" json:"email"`
 	Mobile    string         `gorm:"type:varchar(20);index" json:"mobile"`
 
+	SignupMethod users.SignupMethod `gorm:"type:varchar(20);index" json:"signup_method,omitempty"`
+
+	DeletionRequestedAt *time.Time `gorm:"index" json:"deletion_requested_at,omitempty"`
+
+	// A suspended account cannot sign in until SuspendedUntil; a banned one until an admin reinstates it
+	SuspendedUntil    *time.Time `gorm:"index" json:"suspended_until,omitempty"`
+	BannedAt          *time.Time `json:"banned_at,omitempty"`
+	RestrictionReason string     `gorm:"type:text" json:"restriction_reason,omitempty"`
+
 	Categories []categories.Category `gorm:"many2many:user_categories" json:"categories"`
 	Bookmarks  []podcasts.Podcast    `gorm:"many2many:user_bookmarks" json:"bookmarks,omitempty"`
 	Downloads  []podcasts.Podcast    `gorm:"many2many:user_downloads" json:"downloads,omitempty"`
+	Roles      []roles.Role          `gorm:"many2many:user_roles" json:"roles,omitempty"`
 	Auth       IamAuth               `gorm:"foreignKey:UserID" json:"auth"`
 }
+
+// IsBanned reports whether an admin has banned the account
+func (u *User) IsBanned() bool {
+	return u.BannedAt != nil
+}
+
+// IsSuspended reports whether the account is suspended at now
+func (u *User) IsSuspended(now time.Time) bool {
+	return u.SuspendedUntil != nil && u.SuspendedUntil.After(now)
+}
@@ -0,0 +1,27 @@
+package users
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	users "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/enums"
+	"github.com/google/uuid"
+)
+
+// UserImport is an admin's CSV of users to pre-provision. The file is kept until it is processed.
+type UserImport struct {
+	base.Model
+	CreatedBy    uuid.UUID          `gorm:"type:uuid;index" json:"created_by"`
+	FileName     string             `gorm:"type:varchar(255)" json:"file_name"`
+	Status       users.ImportStatus `gorm:"type:varchar(20);default:'pending';index" json:"status"`
+	Content      string             `gorm:"type:text" json:"-"`
+	TotalRows    int                `json:"total_rows"`
+	CreatedCount int                `json:"created_count"`
+	SkippedCount int                `json:"skipped_count"`
+	FailedCount  int                `json:"failed_count"`
+	// RowReport lists every row that was not created, and why
+	RowReport   json.RawMessage `gorm:"type:jsonb" json:"rows"`
+	CompletedAt *time.Time      `json:"completed_at"`
+	Error       string          `gorm:"type:text" json:"error,omitempty"`
+}
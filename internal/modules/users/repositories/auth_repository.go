@@ -2,10 +2,12 @@ package users
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/models"
 )
@@ -44,3 +46,52 @@ func (r *AuthRepository) UpdateAuth(auth *models.IamAuth) error {
 	}
 	return nil
 }
+
+// RecordFailedLogin counts a failed password login and returns the new total. The count starts
+// again from one when the previous failure was before since, so an expired lockout is not renewed
+// by the next failure. The increment happens in SQL so concurrent attempts cannot overwrite each other.
+func (r *AuthRepository) RecordFailedLogin(userID uuid.UUID, at, since time.Time) (int, error) {
+	var auth models.IamAuth
+	result := r.DB.Model(&auth).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "failed_login_attempts"}}}).
+		Where("user_id = ?", userID).
+		Updates(map[string]interface{}{
+			"failed_login_attempts": gorm.Expr("CASE WHEN last_failed_login_at > ? THEN failed_login_attempts + 1 ELSE 1 END", since),
+			"last_failed_login_at":  at,
+		})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to record failed login: %w", result.Error)
+	}
+	return auth.FailedLoginAttempts, nil
+}
+
+func (r *AuthRepository) LockUntil(userID uuid.UUID, until time.Time) error {
+	result := r.DB.Model(&models.IamAuth{}).Where("user_id = ?", userID).Update("locked_until", until)
+	if result.Error != nil {
+		return fmt.Errorf("failed to lock authentication record: %w", result.Error)
+	}
+	return nil
+}
+
+// ClearFailedLogins resets the failure count and lifts any lockout
+func (r *AuthRepository) ClearFailedLogins(userID uuid.UUID) error {
+	result := r.DB.Model(&models.IamAuth{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+		"failed_login_attempts": 0,
+		"last_failed_login_at":  nil,
+		"locked_until":          nil,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to clear failed logins: %w", result.Error)
+	}
+	return nil
+}
+
+// FindLockedAuths returns the authentication records locked at now, with their users
+func (r *AuthRepository) FindLockedAuths(now time.Time) ([]models.IamAuth, error) {
+	var auths []models.IamAuth
+	result := r.DB.Preload("User").Where("locked_until > ?", now).Order("locked_until DESC").Find(&auths)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to find locked accounts: %w", result.Error)
+	}
+	return auths, nil
+}
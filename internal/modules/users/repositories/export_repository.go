@@ -0,0 +1,103 @@
+package users
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	users "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/enums"
+	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/models"
+)
+
+type ExportRepository struct {
+	DB *gorm.DB
+}
+
+func NewExportRepository(db *gorm.DB) *ExportRepository {
+	return &ExportRepository{
+		DB: db,
+	}
+}
+
+func (r *ExportRepository) CreateExport(export *models.DataExport) error {
+	result := r.DB.Create(export)
+	if result.Error != nil {
+		return fmt.Errorf("failed to create data export: %w", result.Error)
+	}
+	return nil
+}
+
+func (r *ExportRepository) UpdateExport(export *models.DataExport) error {
+	result := r.DB.Save(export)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update data export: %w", result.Error)
+	}
+	return nil
+}
+
+func (r *ExportRepository) FindExportByID(exportID uuid.UUID) (*models.DataExport, error) {
+	var export models.DataExport
+	result := r.DB.Where("id = ?", exportID).First(&export)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find data export: %w", result.Error)
+	}
+	return &export, nil
+}
+
+func (r *ExportRepository) FindExportByToken(token string) (*models.DataExport, error) {
+	var export models.DataExport
+	result := r.DB.Where("token = ?", token).First(&export)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find data export: %w", result.Error)
+	}
+	return &export, nil
+}
+
+func (r *ExportRepository) FindLatestExportByUserID(userID uuid.UUID) (*models.DataExport, error) {
+	var export models.DataExport
+	result := r.DB.Where("user_id = ?", userID).Order("created_at DESC").First(&export)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find data export: %w", result.Error)
+	}
+	return &export, nil
+}
+
+func (r *ExportRepository) FindExportsByStatus(statuses ...users.ExportStatus) ([]models.DataExport, error) {
+	var exports []models.DataExport
+	result := r.DB.Where("status IN ?", statuses).Find(&exports)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to fetch data exports: %w", result.Error)
+	}
+	return exports, nil
+}
+
+func (r *ExportRepository) FindExportsByUserID(userID uuid.UUID) ([]models.DataExport, error) {
+	var exports []models.DataExport
+	result := r.DB.Where("user_id = ?", userID).Find(&exports)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to fetch data exports: %w", result.Error)
+	}
+	return exports, nil
+}
+
+// FindExpiredExports returns the ready exports whose download link expired before now
+func (r *ExportRepository) FindExpiredExports(now time.Time) ([]models.DataExport, error) {
+	var exports []models.DataExport
+	result := r.DB.Where("status = ? AND expires_at < ?", users.ExportStatusReady, now).Find(&exports)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to fetch expired data exports: %w", result.Error)
+	}
+	return exports, nil
+}
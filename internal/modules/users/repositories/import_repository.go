@@ -0,0 +1,60 @@
+package users
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	users "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/enums"
+	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/models"
+)
+
+type ImportRepository struct {
+	DB *gorm.DB
+}
+
+func NewImportRepository(db *gorm.DB) *ImportRepository {
+	return &ImportRepository{
+		DB: db,
+	}
+}
+
+func (r *ImportRepository) CreateImport(userImport *models.UserImport) error {
+	result := r.DB.Create(userImport)
+	if result.Error != nil {
+		return fmt.Errorf("failed to create user import: %w", result.Error)
+	}
+	return nil
+}
+
+func (r *ImportRepository) UpdateImport(userImport *models.UserImport) error {
+	result := r.DB.Save(userImport)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update user import: %w", result.Error)
+	}
+	return nil
+}
+
+func (r *ImportRepository) FindImportByID(importID uuid.UUID) (*models.UserImport, error) {
+	var userImport models.UserImport
+	result := r.DB.Where("id = ?", importID).First(&userImport)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find user import: %w", result.Error)
+	}
+	return &userImport, nil
+}
+
+// FindImportIDsByStatus returns only the IDs, so resuming imports does not load their files
+func (r *ImportRepository) FindImportIDsByStatus(statuses ...users.ImportStatus) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	result := r.DB.Model(&models.UserImport{}).Where("status IN ?", statuses).Pluck("id", &ids)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to fetch user imports: %w", result.Error)
+	}
+	return ids, nil
+}
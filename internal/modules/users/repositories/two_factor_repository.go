@@ -0,0 +1,154 @@
+package users
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/models"
+)
+
+type TwoFactorRepository struct {
+	DB *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{
+		DB: db,
+	}
+}
+
+func (r *TwoFactorRepository) FindByUserID(userID uuid.UUID) (*models.TwoFactor, error) {
+	var twoFactor models.TwoFactor
+	result := r.DB.Where("user_id = ?", userID).First(&twoFactor)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find two-factor enrollment: %w", result.Error)
+	}
+	return &twoFactor, nil
+}
+
+func (r *TwoFactorRepository) IsEnabled(userID uuid.UUID) (bool, error) {
+	var count int64
+	result := r.DB.Model(&models.TwoFactor{}).Where("user_id = ? AND enabled_at IS NOT NULL", userID).Count(&count)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to check two-factor enrollment: %w", result.Error)
+	}
+	return count > 0, nil
+}
+
+// StartEnrollment replaces any pending enrollment with a new secret
+func (r *TwoFactorRepository) StartEnrollment(userID uuid.UUID, encryptedSecret string) (*models.TwoFactor, error) {
+	twoFactor := &models.TwoFactor{UserID: userID, Secret: encryptedSecret}
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.TwoFactor{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(twoFactor).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start two-factor enrollment: %w", err)
+	}
+	return twoFactor, nil
+}
+
+// Enable activates a pending enrollment and replaces the recovery codes
+func (r *TwoFactorRepository) Enable(twoFactor *models.TwoFactor, step int64, codeHashes []string) error {
+	now := time.Now()
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(twoFactor).Updates(map[string]interface{}{
+			"enabled_at":     now,
+			"last_used_step": step,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		return replaceRecoveryCodes(tx, twoFactor.UserID, codeHashes)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to enable two-factor authentication: %w", err)
+	}
+
+	twoFactor.EnabledAt = &now
+	twoFactor.LastUsedStep = step
+	return nil
+}
+
+// UseStep records step as the last accepted code. It reports false when a code at or after
+// step was already used, which means the code is being replayed.
+func (r *TwoFactorRepository) UseStep(twoFactorID uuid.UUID, step int64) (bool, error) {
+	result := r.DB.Model(&models.TwoFactor{}).
+		Where("id = ? AND last_used_step < ?", twoFactorID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to record two-factor code: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(userID uuid.UUID, codeHashes []string) error {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codeHashes)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to replace recovery codes: %w", err)
+	}
+	return nil
+}
+
+// UseRecoveryCode spends the recovery code with the given hash, reporting false when the
+// user has no unused code with that hash
+func (r *TwoFactorRepository) UseRecoveryCode(userID uuid.UUID, codeHash string) (bool, error) {
+	result := r.DB.Model(&models.TwoFactorRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *TwoFactorRepository) CountUnusedRecoveryCodes(userID uuid.UUID) (int64, error) {
+	var count int64
+	result := r.DB.Model(&models.TwoFactorRecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&count)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to count recovery codes: %w", result.Error)
+	}
+	return count, nil
+}
+
+// Delete removes the enrollment and its recovery codes
+func (r *TwoFactorRepository) Delete(userID uuid.UUID) error {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.TwoFactorRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("user_id = ?", userID).Delete(&models.TwoFactor{}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete two-factor enrollment: %w", err)
+	}
+	return nil
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID uuid.UUID, codeHashes []string) error {
+	if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.TwoFactorRecoveryCode{}).Error; err != nil {
+		return err
+	}
+
+	codes := make([]models.TwoFactorRecoveryCode, len(codeHashes))
+	for i, hash := range codeHashes {
+		codes[i] = models.TwoFactorRecoveryCode{UserID: userID, CodeHash: hash}
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	return tx.Create(&codes).Error
+}
@@ -1,17 +1,22 @@
 package users
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
-	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/utils"
+	"slices"
+	"strings"
+	"time"
 
 	podcastModel "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/podcasts/models"
 
 	"github.com/google/uuid"
 
 	categoryModel "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/categories/models"
+	enums "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/enums"
 	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository struct {
@@ -71,6 +76,7 @@ func (r *UserRepository) FindOneByID(userID uuid.UUID) (*models.User, error) {
 		Preload("Categories").
 		Preload("Bookmarks").
 		Preload("Downloads").
+		Preload("Roles").
 		Where("id = ?", userID).First(&user)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -88,18 +94,54 @@ func (r *UserRepository) CreateUser(user *models.User) (*models.User, error) {
 		return nil, fmt.Errorf("failed to create user: %w", result.Error)
 	}
 
-	identity := ""
-	if user.Email != "" {
-		identity = user.Email
-	}
-	if user.Mobile != "" {
-		identity = user.Mobile
+	return user, nil
+}
+
+// CreateImportedUser creates a pre-provisioned user with an inactive sign-in record and their
+// categories, unless an account already uses the mobile number. It reports whether it created one.
+func (r *UserRepository) CreateImportedUser(user *models.User) (bool, error) {
+	created := false
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&models.User{}).Where("mobile = ?", user.Mobile).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return nil
+		}
+
+		// The categories already exist, so only the links to them are written
+		if err := tx.Omit("Categories.*").Create(user).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.IamAuth{UserID: user.ID, IsActive: false}).Error; err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to create imported user: %w", err)
 	}
+	return created, nil
+}
 
-	slackMessage := fmt.Sprintf("🚀 New user account created:\n%s (%s)", user.FirstName, identity)
-	_ = utils.SendSlackNotification(slackMessage)
+// FindExistingMobiles returns which of the mobile numbers already belong to an account
+func (r *UserRepository) FindExistingMobiles(mobiles []string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	if len(mobiles) == 0 {
+		return existing, nil
+	}
 
-	return user, nil
+	var found []string
+	result := r.DB.Model(&models.User{}).Where("mobile IN ?", mobiles).Distinct().Pluck("mobile", &found)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to fetch existing mobiles: %w", result.Error)
+	}
+	for _, mobile := range found {
+		existing[mobile] = true
+	}
+	return existing, nil
 }
 
 func (r *UserRepository) UpdateUser(user *models.User) error {
@@ -110,23 +152,284 @@ func (r *UserRepository) UpdateUser(user *models.User) error {
 	return nil
 }
 
-func (r *UserRepository) FindAllUsers() ([]models.User, error) {
+func (r *UserRepository) UpdateUserType(userID uuid.UUID, userType enums.UserType) error {
+	result := r.DB.Model(&models.User{}).Where("id = ?", userID).Update("user_type", userType)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update user type: %w", result.Error)
+	}
+	return nil
+}
+
+// UserFilter narrows an admin user search; zero fields match everything
+type UserFilter struct {
+	// Search matches part of the first name, last name, full name, email or mobile
+	Search       string
+	UserType     enums.UserType
+	SignupMethod enums.SignupMethod
+	CategoryID   *uuid.UUID
+	CreatedFrom  *time.Time
+	CreatedTo    *time.Time
+	// Sort is one of UserSortColumns, created_at when empty
+	Sort       string
+	Descending bool
+}
+
+// UserSortColumns are the columns a user search can be ordered by
+var UserSortColumns = []string{"created_at", "first_name", "last_name", "email"}
+
+// SearchUsers returns a page of matching users with their roles, and the total number of matches
+func (r *UserRepository) SearchUsers(filter UserFilter, offset, limit int) ([]models.User, int64, error) {
+	var total int64
+	if err := r.searchQuery(filter).Model(&models.User{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	column := "created_at"
+	if slices.Contains(UserSortColumns, filter.Sort) {
+		column = filter.Sort
+	}
+
 	var users []models.User
-	result := r.DB.Find(&users)
+	result := r.searchQuery(filter).
+		Preload("Roles").
+		Order(clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: filter.Descending}).
+		Order("id").
+		Offset(offset).
+		Limit(limit).
+		Find(&users)
 	if result.Error != nil {
-		return nil, fmt.Errorf("failed to fetch users: %w", result.Error)
+		return nil, 0, fmt.Errorf("failed to fetch users: %w", result.Error)
 	}
-	return users, nil
+	return users, total, nil
+}
+
+// EachUserBatch passes the users matching filter to fn in batches, newest first, with their
+// categories and roles. Batches continue from the last user seen, so none is repeated or skipped.
+func (r *UserRepository) EachUserBatch(filter UserFilter, batchSize int, fn func([]models.User) error) error {
+	var last *models.User
+	for {
+		query := r.searchQuery(filter)
+		if last != nil {
+			query = query.Where("(created_at, id) < (?, ?)", last.CreatedAt, last.ID)
+		}
+
+		var users []models.User
+		result := query.
+			Preload("Categories").
+			Preload("Roles").
+			Order("created_at DESC, id DESC").
+			Limit(batchSize).
+			Find(&users)
+		if result.Error != nil {
+			return fmt.Errorf("failed to fetch users: %w", result.Error)
+		}
+		if len(users) == 0 {
+			return nil
+		}
+		if err := fn(users); err != nil {
+			return err
+		}
+		if len(users) < batchSize {
+			return nil
+		}
+		last = &users[len(users)-1]
+	}
+}
+
+func (r *UserRepository) searchQuery(filter UserFilter) *gorm.DB {
+	query := r.DB.Model(&models.User{})
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		pattern := "%" + escapeLike(search) + "%"
+		query = query.Where(
+			"(first_name ILIKE @pattern OR last_name ILIKE @pattern OR (first_name || ' ' || last_name) ILIKE @pattern OR email ILIKE @pattern OR mobile ILIKE @pattern)",
+			sql.Named("pattern", pattern),
+		)
+	}
+	if filter.UserType != "" {
+		query = query.Where("user_type = ?", filter.UserType)
+	}
+	if filter.SignupMethod != "" {
+		query = query.Where("signup_method = ?", filter.SignupMethod)
+	}
+	if filter.CategoryID != nil {
+		query = query.Where("EXISTS (SELECT 1 FROM user_categories WHERE user_categories.user_id = users.id AND user_categories.category_id = ?)", *filter.CategoryID)
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("created_at < ?", *filter.CreatedTo)
+	}
+	return query
+}
+
+// escapeLike makes the LIKE wildcards in s match literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// ListeningStats summarises what a user has listened to, liked, bookmarked and downloaded
+type ListeningStats struct {
+	PodcastsStarted   int64
+	PodcastsCompleted int64
+	LastListenedAt    *time.Time
+	Likes             int64
+	Bookmarks         int64
+	Downloads         int64
+}
+
+func (r *UserRepository) FindListeningStats(userID uuid.UUID) (*ListeningStats, error) {
+	var stats ListeningStats
+	result := r.DB.Raw(`
+		SELECT
+			(SELECT COUNT(*) FROM user_podcasts WHERE user_id = @user AND deleted_at IS NULL) AS podcasts_started,
+			(SELECT COUNT(*) FROM user_podcasts WHERE user_id = @user AND deleted_at IS NULL AND is_completed) AS podcasts_completed,
+			(SELECT MAX(updated_at) FROM user_podcasts WHERE user_id = @user AND deleted_at IS NULL) AS last_listened_at,
+			(SELECT COUNT(*) FROM podcast_likes WHERE user_id = @user AND deleted_at IS NULL) AS likes,
+			(SELECT COUNT(*) FROM user_bookmarks WHERE user_id = @user) AS bookmarks,
+			(SELECT COUNT(*) FROM user_downloads WHERE user_id = @user) AS downloads`,
+		sql.Named("user", userID),
+	).Scan(&stats)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to fetch listening stats: %w", result.Error)
+	}
+	return &stats, nil
+}
+
+func (r *UserRepository) MarkPendingDeletion(userID uuid.UUID, requestedAt time.Time) error {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Update("deletion_requested_at", requestedAt).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.IamAuth{}).Where("user_id = ?", userID).Update("is_active", false).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark user for deletion: %w", err)
+	}
+	return nil
+}
+
+func (r *UserRepository) CancelPendingDeletion(user *models.User) error {
+	if user.DeletionRequestedAt == nil {
+		return nil
+	}
+
+	result := r.DB.Model(&models.User{}).Where("id = ?", user.ID).Update("deletion_requested_at", nil)
+	if result.Error != nil {
+		return fmt.Errorf("failed to cancel account deletion: %w", result.Error)
+	}
+	user.DeletionRequestedAt = nil
+	return nil
 }
 
-func (r *UserRepository) DeleteUser(userID uuid.UUID) error {
-	result := r.DB.Where("id = ?", userID).Delete(&models.User{})
+// UpdateRestriction saves the user's suspension, ban and their reason
+func (r *UserRepository) UpdateRestriction(user *models.User) error {
+	result := r.DB.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+		"suspended_until":    user.SuspendedUntil,
+		"banned_at":          user.BannedAt,
+		"restriction_reason": user.RestrictionReason,
+	})
 	if result.Error != nil {
-		return fmt.Errorf("failed to delete user: %w", result.Error)
+		return fmt.Errorf("failed to update user restriction: %w", result.Error)
 	}
 	return nil
 }
 
+// EndExpiredSuspensions clears the suspensions that ended by now and returns the users as they
+// were, so the caller knows which suspension ended
+func (r *UserRepository) EndExpiredSuspensions(now time.Time, limit int) ([]models.User, error) {
+	var users []models.User
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("suspended_until <= ?", now).Limit(limit).Find(&users).Error; err != nil {
+			return err
+		}
+		if len(users) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, len(users))
+		for i, user := range users {
+			ids[i] = user.ID
+		}
+		return tx.Model(&models.User{}).
+			Where("id IN ? AND suspended_until <= ?", ids, now).
+			Updates(map[string]interface{}{"suspended_until": nil, "restriction_reason": ""}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to end expired suspensions: %w", err)
+	}
+	return users, nil
+}
+
+// FindUsersDueForPurge returns accounts whose deletion was requested before the cutoff,
+// including accounts soft-deleted before the grace period existed
+func (r *UserRepository) FindUsersDueForPurge(cutoff time.Time, limit int) ([]models.User, error) {
+	var users []models.User
+	result := r.DB.Unscoped().
+		Where("deletion_requested_at <= ? OR (deletion_requested_at IS NULL AND deleted_at <= ?)", cutoff, cutoff).
+		Limit(limit).
+		Find(&users)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to fetch users due for purge: %w", result.Error)
+	}
+	return users, nil
+}
+
+// purgeStatements delete every row keyed by the user, with the users row last so that no foreign
+// key still points at it. A table that stores user data must be added here.
+var purgeStatements = []string{
+	"DELETE FROM user_categories WHERE user_id = @user",
+	"DELETE FROM user_bookmarks WHERE user_id = @user",
+	"DELETE FROM user_downloads WHERE user_id = @user",
+	"DELETE FROM user_podcasts WHERE user_id = @user",
+	"DELETE FROM podcast_likes WHERE user_id = @user",
+	"DELETE FROM notifications WHERE user_id = @user",
+	"DELETE FROM data_exports WHERE user_id = @user",
+	"DELETE FROM two_factor_recovery_codes WHERE user_id = @user",
+	"DELETE FROM two_factors WHERE user_id = @user",
+	"DELETE FROM subscription_event_logs WHERE subscription_id IN (SELECT id FROM subscriptions WHERE user_id = @user)",
+	"DELETE FROM subscriptions WHERE user_id = @user",
+	"DELETE FROM referrals WHERE referrer_id = @user OR referee_id = @user",
+	"DELETE FROM referral_codes WHERE user_id = @user",
+	"DELETE FROM promo_redemptions WHERE user_id = @user",
+	"DELETE FROM user_roles WHERE user_id = @user",
+	"DELETE FROM iam_auths WHERE user_id = @user",
+	"DELETE FROM users WHERE id = @user",
+}
+
+// PurgeUser permanently deletes the user and every row that depends on it in one transaction,
+// and records the purge in the account_purges table
+func (r *UserRepository) PurgeUser(user *models.User) (int64, error) {
+	var rowsDeleted int64
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		for _, statement := range purgeStatements {
+			result := tx.Exec(statement, sql.Named("user", user.ID))
+			if result.Error != nil {
+				return result.Error
+			}
+			rowsDeleted += result.RowsAffected
+		}
+
+		requestedAt := user.DeletedAt.Time
+		if user.DeletionRequestedAt != nil {
+			requestedAt = *user.DeletionRequestedAt
+		}
+
+		return tx.Create(&models.AccountPurge{
+			UserID:              user.ID,
+			DeletionRequestedAt: requestedAt,
+			PurgedAt:            time.Now(),
+			RowsDeleted:         rowsDeleted,
+		}).Error
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge user: %w", err)
+	}
+
+	return rowsDeleted, nil
+}
+
 func (r *UserRepository) FindUserCategories(userID uuid.UUID) ([]categoryModel.Category, error) {
 	var user models.User
 
@@ -0,0 +1,51 @@
+package users
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var purgeTablePattern = regexp.MustCompile(`^DELETE FROM (\w+) `)
+
+func purgedTables(t *testing.T) []string {
+	tables := make([]string, 0, len(purgeStatements))
+	for _, statement := range purgeStatements {
+		match := purgeTablePattern.FindStringSubmatch(statement)
+		require.NotNil(t, match, statement)
+		assert.Contains(t, statement, "@user")
+		tables = append(tables, match[1])
+	}
+	return tables
+}
+
+// TestPurgeStatements_CoverUserTables tests that a purge deletes every table holding user data,
+// and the user itself last so no foreign key is left pointing at it
+func TestPurgeStatements_CoverUserTables(t *testing.T) {
+	tables := purgedTables(t)
+
+	require.NotEmpty(t, tables)
+	assert.Equal(t, "users", tables[len(tables)-1])
+	for _, table := range []string{
+		"user_categories",
+		"user_bookmarks",
+		"user_downloads",
+		"user_podcasts",
+		"podcast_likes",
+		"notifications",
+		"data_exports",
+		"two_factor_recovery_codes",
+		"two_factors",
+		"subscription_event_logs",
+		"subscriptions",
+		"referrals",
+		"referral_codes",
+		"promo_redemptions",
+		"user_roles",
+		"iam_auths",
+	} {
+		assert.Contains(t, tables[:len(tables)-1], table)
+	}
+}
@@ -1,12 +1,25 @@
 package users
 
 import (
-	"fmt"
+	"log/slog"
 	"net/http"
-	"os"
+	"time"
 
+	"github.com/Al-Khaimah/khaimah-golang-backend/config"
 	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/cache"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/jobs"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/jwtkeys"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/ratelimit"
 	"github.com/Al-Khaimah/khaimah-golang-backend/internal/middlewares"
+	auditRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/audit/repositories"
+	categoryRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/categories/repositories"
+	notificationRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/notifications/repositories"
+	podcastRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/podcasts/repositories"
+	referralRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/referrals/repositories"
+	referralService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/referrals/services"
+	roles "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/enums"
+	subscriptionRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/repositories"
+	subscriptionService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/services"
 	userHandler "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/handlers"
 	userRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/repositories"
 	userService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/services"
@@ -14,31 +27,80 @@ import (
 	"gorm.io/gorm"
 )
 
-func RegisterRoutes(e *echo.Echo, db *gorm.DB) {
+func RegisterRoutes(e *echo.Echo, db *gorm.DB, cfg *config.Config) {
+	keys := jwtkeys.Default()
+
 	if err := cache.InitCache(); err != nil {
-		fmt.Printf("❌ Warning: Failed to initialize cache: %v\n", err)
+		slog.Warn("Failed to initialize cache", "error", err)
 	}
 
 	userRepo := userRepository.NewUserRepository(db)
 	authRepo := userRepository.NewAuthRepository(db)
 	bookmarksRepo := userRepository.NewBookmarkRepository(db)
-	newAuthService := userService.NewAuthService(userRepo, authRepo)
-	newUserService := userService.NewUserService(userRepo, authRepo, bookmarksRepo)
-	newOTPService := userService.NewOTPService(userRepo, authRepo, []byte(os.Getenv("JWT_SECRET")))
+	exportRepo := userRepository.NewExportRepository(db)
+	podcastRepo := podcastRepository.NewPodcastRepository(db)
+	notificationRepo := notificationRepository.NewNotificationRepository(db)
+	newSubscriptionService := subscriptionService.NewSubscriptionService(subscriptionRepository.NewSubscriptionRepository(db), userRepo)
+	newReferralService := referralService.NewReferralService(referralRepository.NewReferralRepository(db), userRepo, newSubscriptionService, cfg.Referrals)
+	newAuthService := userService.NewAuthService(userRepo, authRepo, newReferralService, cfg)
+	newUserService := userService.NewUserService(userRepo, authRepo, bookmarksRepo, newReferralService, cfg)
+	newOTPService := userService.NewOTPService(userRepo, authRepo, newReferralService, cfg)
 	newUserHandler := userHandler.NewUserHandler(newUserService)
 	newAuthHandler := userHandler.NewAuthHandler(newAuthService)
+	newExportService := userService.NewExportService(userRepo, authRepo, exportRepo, podcastRepo, notificationRepo, cfg)
 	newOTPHandler := userHandler.NewOTPHandler(newOTPService)
+	newExportHandler := userHandler.NewExportHandler(newExportService)
+	newTwoFactorService := userService.NewTwoFactorService(userRepo, authRepo, userRepository.NewTwoFactorRepository(db), cfg)
+	newTwoFactorHandler := userHandler.NewTwoFactorHandler(newTwoFactorService)
+	newAdminUserService := userService.NewAdminUserService(userRepo, authRepo, subscriptionRepository.NewSubscriptionRepository(db), auditRepository.NewAuditRepository(db))
+	newAdminUserHandler := userHandler.NewAdminUserHandler(newAdminUserService)
+	newUserImportService := userService.NewUserImportService(userRepo, userRepository.NewImportRepository(db), categoryRepository.NewCategoryRepository(db))
+	newUserImportHandler := userHandler.NewUserImportHandler(newUserImportService)
+
+	newAccountDeletionService := userService.NewAccountDeletionService(userRepo, exportRepo)
+
+	jobs.Register(userService.ExportJobName, newExportService.ProcessExport)
+	jobs.Register(userService.ExportExpiryJobName, newExportService.ExpireExports)
+	jobs.Every(time.Hour, userService.ExportExpiryJobName, "")
+	jobs.Register(userService.AccountPurgeJobName, newAccountDeletionService.PurgeDueAccounts)
+	jobs.Every(6*time.Hour, userService.AccountPurgeJobName, "")
+	jobs.Register(userService.SuspensionExpiryJobName, newUserService.ExpireSuspensions)
+	jobs.Every(userService.SuspensionExpiryInterval, userService.SuspensionExpiryJobName, "")
+	jobs.Register(userService.UserImportJobName, newUserImportService.ProcessImport)
+	newExportService.ResumePendingExports()
+	newUserImportService.ResumePendingImports()
+
+	authLimit := middlewares.RateLimit(cfg.RateLimit, ratelimit.Policy{
+		Name: "auth", Limit: 60, Window: time.Minute, Algorithm: ratelimit.SlidingWindow, Key: ratelimit.ByIP,
+	})
+	signupLimit := middlewares.RateLimit(cfg.RateLimit, ratelimit.Policy{
+		Name: "signup", Limit: 5, Window: time.Hour, Algorithm: ratelimit.SlidingWindow, Key: ratelimit.ByIP,
+	})
+	loginLimit := middlewares.RateLimit(cfg.RateLimit, ratelimit.Policy{
+		Name: "login", Limit: 10, Window: time.Minute, Algorithm: ratelimit.SlidingWindow, Key: ratelimit.ByIP,
+	})
+	otpLimit := middlewares.RateLimit(cfg.RateLimit, ratelimit.Policy{
+		Name: "send-otp", Limit: 5, Window: 10 * time.Minute, Algorithm: ratelimit.SlidingWindow, Key: ratelimit.ByIP,
+	})
+	verifyOTPLimit := middlewares.RateLimit(cfg.RateLimit, ratelimit.Policy{
+		Name: "verify-otp", Limit: 10, Window: 10 * time.Minute, Algorithm: ratelimit.SlidingWindow, Key: ratelimit.ByIP,
+	})
+	twoFactorLimit := middlewares.RateLimit(cfg.RateLimit, ratelimit.Policy{
+		Name: "2fa", Limit: 10, Window: 10 * time.Minute, Algorithm: ratelimit.SlidingWindow, Key: ratelimit.ByUserID,
+	})
 
-	authGroup := e.Group("/auth")
-	authGroup.POST("/signup", newUserHandler.CreateUser)
-	authGroup.POST("/login", newUserHandler.LoginUser)
-	authGroup.POST("/logout", newUserHandler.LogoutUser, middlewares.AuthMiddleware(authRepo))
+	authGroup := e.Group("/auth", authLimit)
+	authGroup.POST("/signup", newUserHandler.CreateUser, signupLimit)
+	authGroup.POST("/login", newUserHandler.LoginUser, loginLimit)
+	authGroup.POST("/logout", newUserHandler.LogoutUser, middlewares.AuthMiddleware(authRepo, keys))
+	authGroup.POST("/refresh", newUserHandler.RefreshToken, middlewares.AuthMiddleware(authRepo, keys))
 	authGroup.POST("/oauth", newAuthHandler.OAuthLogin)
-	authGroup.POST("/oauth/user", newUserHandler.CreateSSOUser, middlewares.AuthMiddleware(authRepo))
-	authGroup.POST("/send-otp", newOTPHandler.SendOTP)
-	authGroup.POST("/verify-otp", newOTPHandler.VerifyOTP)
+	authGroup.POST("/oauth/user", newUserHandler.CreateSSOUser, middlewares.AuthMiddleware(authRepo, keys))
+	authGroup.POST("/send-otp", newOTPHandler.SendOTP, otpLimit)
+	authGroup.POST("/verify-otp", newOTPHandler.VerifyOTP, verifyOTPLimit)
+	authGroup.POST("/2fa/challenge", newTwoFactorHandler.CompleteChallenge, twoFactorLimit)
 
-	userGroup := e.Group("/user", middlewares.AuthMiddleware(authRepo))
+	userGroup := e.Group("/user", middlewares.AuthMiddleware(authRepo, keys))
 	userGroup.GET("/profile", newUserHandler.GetUserProfile)
 	userGroup.PUT("/profile", newUserHandler.UpdateUserProfile)
 	userGroup.DELETE("/profile/delete-my-account", newUserHandler.DeleteMyAccount)
@@ -47,11 +109,37 @@ func RegisterRoutes(e *echo.Echo, db *gorm.DB) {
 	userGroup.GET("/bookmarks", newUserHandler.GetUserBookmarks)
 	userGroup.POST("/bookmarks/:podcast_id", newUserHandler.ToggleBookmarkPodcast)
 	userGroup.GET("/downloads", newUserHandler.GetDownloadedPodcasts)
+	userGroup.POST("/export", newExportHandler.RequestExport)
+	userGroup.GET("/export", newExportHandler.GetExportStatus)
+	userGroup.GET("/2fa", newTwoFactorHandler.GetStatus)
+	userGroup.POST("/2fa/enroll", newTwoFactorHandler.Enroll)
+	userGroup.POST("/2fa/verify", newTwoFactorHandler.ConfirmEnrollment, twoFactorLimit)
+	userGroup.POST("/2fa/disable", newTwoFactorHandler.Disable, twoFactorLimit)
+	userGroup.POST("/2fa/recovery-codes", newTwoFactorHandler.RegenerateRecoveryCodes, twoFactorLimit)
+
+	e.GET("/exports/:token", newExportHandler.DownloadExport)
+
+	adminGroup := e.Group("/admin", middlewares.RequirePermission(keys, roles.PermissionUsersRead))
+	adminGroup.GET("/users", newAdminUserHandler.ListUsers)
+	adminGroup.GET("/users/export", newAdminUserHandler.ExportUsers)
+	adminGroup.GET("/users/imports/:id", newUserImportHandler.GetImport)
+	adminGroup.GET("/users/:user_id", newAdminUserHandler.GetUserDetails)
+	// kept for admin panel builds that still call the old listing
+	adminGroup.GET("/all-users", newAdminUserHandler.ListUsers)
+	adminGroup.GET("/locked-users", newUserHandler.GetLockedAccounts)
+
+	adminWriteGroup := e.Group("/admin", middlewares.RequirePermission(keys, roles.PermissionUsersWrite))
+	adminWriteGroup.POST("/users/import", newUserImportHandler.ImportUsers)
+	adminWriteGroup.POST("/user/:id/unlock", newUserHandler.UnlockAccount)
+	adminWriteGroup.POST("/user/:id/suspend", newUserHandler.SuspendUser)
+	adminWriteGroup.POST("/user/:id/ban", newUserHandler.BanUser)
+	adminWriteGroup.POST("/user/:id/reinstate", newUserHandler.ReinstateUser)
+
+	impersonateGroup := e.Group("/admin", middlewares.RequirePermission(keys, roles.PermissionUsersImpersonate))
+	impersonateGroup.POST("/users/:user_id/impersonate", newAdminUserHandler.Impersonate)
 
-	adminGroup := e.Group("/admin", middlewares.AdminMiddleware())
-	adminGroup.POST("/mark-user-admin/:user_id", newUserHandler.MarkUserAsAdmin)
-	adminGroup.GET("/all-users", newUserHandler.GetAllUsers)
-	adminGroup.DELETE("/user/:id", newUserHandler.DeleteUser)
+	adminDeleteGroup := e.Group("/admin", middlewares.RequirePermission(keys, roles.PermissionUsersDelete))
+	adminDeleteGroup.DELETE("/user/:id", newUserHandler.DeleteUser)
 
 	monitor := func(c echo.Context) error {
 		return c.String(http.StatusOK, "khaimah is live")
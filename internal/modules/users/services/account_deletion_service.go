@@ -0,0 +1,98 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/logger"
+	audit "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/audit/enums"
+	auditService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/audit/services"
+	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/models"
+	repos "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/repositories"
+	"github.com/google/uuid"
+)
+
+const (
+	AccountPurgeJobName        = "users.account_purge"
+	AccountDeletionGracePeriod = 30 * 24 * time.Hour
+	accountPurgeBatchSize      = 100
+)
+
+// AccountDeletionService permanently removes accounts once their deletion grace period is over
+type AccountDeletionService struct {
+	UserRepo   *repos.UserRepository
+	ExportRepo *repos.ExportRepository
+}
+
+func NewAccountDeletionService(userRepo *repos.UserRepository, exportRepo *repos.ExportRepository) *AccountDeletionService {
+	return &AccountDeletionService{
+		UserRepo:   userRepo,
+		ExportRepo: exportRepo,
+	}
+}
+
+// PurgeDueAccounts is the job handler that hard-deletes every account past its grace period
+func (s *AccountDeletionService) PurgeDueAccounts(ctx context.Context, _ string) error {
+	cutoff := time.Now().Add(-AccountDeletionGracePeriod)
+
+	for {
+		users, err := s.UserRepo.FindUsersDueForPurge(cutoff, accountPurgeBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for i := range users {
+			if err := s.purge(ctx, &users[i]); err != nil {
+				logger.FromContext(ctx).Error("Failed to purge user", "purged_user_id", users[i].ID, "error", err)
+			}
+		}
+
+		if len(users) < accountPurgeBatchSize {
+			return nil
+		}
+	}
+}
+
+// PurgeAccount permanently removes a single account right away, without waiting for the grace period
+func (s *AccountDeletionService) PurgeAccount(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.UserRepo.FindOneByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.New("user not found")
+	}
+	if user.DeletionRequestedAt == nil {
+		now := time.Now()
+		user.DeletionRequestedAt = &now
+	}
+	return s.purge(ctx, user)
+}
+
+func (s *AccountDeletionService) purge(ctx context.Context, user *models.User) error {
+	exports, _ := s.ExportRepo.FindExportsByUserID(user.ID)
+
+	rowsDeleted, err := s.UserRepo.PurgeUser(user)
+	if err != nil {
+		return err
+	}
+
+	for _, export := range exports {
+		if export.FilePath != "" {
+			_ = os.Remove(export.FilePath)
+		}
+	}
+
+	// Only the ID is kept: the purge exists to remove the user's personal data
+	auditService.Record(ctx, auditService.Entry{
+		Action:     audit.ActionUserPurged,
+		TargetType: audit.TargetUser,
+		TargetID:   user.ID.String(),
+		Metadata:   map[string]interface{}{"rows_deleted": rowsDeleted},
+	})
+	slog.Info("Purged user", "purged_user_id", user.ID, "rows_deleted", rowsDeleted)
+	return nil
+}
@@ -0,0 +1,221 @@
+package users
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/logger"
+	audit "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/audit/enums"
+	auditService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/audit/services"
+	roleModels "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/models"
+	roleService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/services"
+	userDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/dtos"
+	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/models"
+	"github.com/google/uuid"
+)
+
+const (
+	SuspensionExpiryJobName = "suspension-expiry"
+	// SuspensionExpiryInterval is how often ended suspensions are cleared. Sign-in checks compare
+	// against the end time, so a suspension stops applying on time even before the job runs.
+	SuspensionExpiryInterval = 10 * time.Minute
+
+	suspensionExpiryBatchSize = 100
+)
+
+// AccountRestricted returns the answer to give a banned or suspended user trying to use the app
+func AccountRestricted(user *models.User, now time.Time) (base.Response, bool) {
+	var response base.Response
+	switch {
+	case user.IsBanned():
+		response = base.SetErrorMessage("تم حظر حسابك", restrictionReason(user))
+	case user.IsSuspended(now):
+		until := user.SuspendedUntil.UTC().Format("2006-01-02 15:04 UTC")
+		response = base.SetErrorMessage(fmt.Sprintf("تم إيقاف حسابك مؤقتاً حتى %s", until), restrictionReason(user))
+	default:
+		return base.Response{}, false
+	}
+	response.HTTPStatus = http.StatusForbidden
+	return response, true
+}
+
+func restrictionReason(user *models.User) string {
+	if user.RestrictionReason == "" {
+		return "تواصل مع الدعم لمزيد من المعلومات"
+	}
+	return "السبب: " + user.RestrictionReason
+}
+
+// SuspendUser blocks the user from signing in until the given time. adminRoles are the roles of
+// the admin asking, who cannot suspend staff granted permissions they do not hold themselves.
+func (s *UserService) SuspendUser(ctx context.Context, userID string, req userDTO.SuspendUserRequestDTO, adminRoles []roleModels.Role) base.Response {
+	until, err := time.Parse(time.RFC3339, req.Until)
+	if err != nil || !until.After(time.Now()) {
+		return base.SetErrorMessage("يجب أن يكون تاريخ انتهاء الإيقاف في المستقبل")
+	}
+
+	user, response, ok := s.findRestrictionTarget(userID)
+	if !ok {
+		return response
+	}
+	if response, ok := s.checkRestrictable(user, adminRoles); !ok {
+		return response
+	}
+	if user.IsBanned() {
+		return base.SetErrorMessage("الحساب محظور بالفعل")
+	}
+
+	before := restrictionState(user)
+	user.SuspendedUntil = &until
+	user.RestrictionReason = req.Reason
+	if err := s.UserRepo.UpdateRestriction(user); err != nil {
+		return base.SetErrorMessage("فشل في إيقاف الحساب")
+	}
+	recordRestriction(ctx, audit.ActionUserSuspended, user, before)
+
+	return base.SetSuccessMessage("تم إيقاف الحساب بنجاح")
+}
+
+// BanUser blocks the user from signing in until an admin reinstates them. Like SuspendUser, it
+// refuses staff granted permissions the admin does not hold.
+func (s *UserService) BanUser(ctx context.Context, userID string, req userDTO.BanUserRequestDTO, adminRoles []roleModels.Role) base.Response {
+	user, response, ok := s.findRestrictionTarget(userID)
+	if !ok {
+		return response
+	}
+	if response, ok := s.checkRestrictable(user, adminRoles); !ok {
+		return response
+	}
+
+	before := restrictionState(user)
+	now := time.Now()
+	user.BannedAt = &now
+	user.SuspendedUntil = nil
+	user.RestrictionReason = req.Reason
+	if err := s.UserRepo.UpdateRestriction(user); err != nil {
+		return base.SetErrorMessage("فشل في حظر الحساب")
+	}
+	recordRestriction(ctx, audit.ActionUserBanned, user, before)
+
+	return base.SetSuccessMessage("تم حظر الحساب بنجاح")
+}
+
+// ReinstateUser lifts a suspension or ban
+func (s *UserService) ReinstateUser(ctx context.Context, userID string) base.Response {
+	user, response, ok := s.findRestrictionTarget(userID)
+	if !ok {
+		return response
+	}
+	if !user.IsBanned() && user.SuspendedUntil == nil {
+		return base.SetErrorMessage("الحساب غير موقوف أو محظور")
+	}
+
+	before := restrictionState(user)
+	user.BannedAt = nil
+	user.SuspendedUntil = nil
+	user.RestrictionReason = ""
+	if err := s.UserRepo.UpdateRestriction(user); err != nil {
+		return base.SetErrorMessage("فشل في إعادة تفعيل الحساب")
+	}
+	recordRestriction(ctx, audit.ActionUserReinstated, user, before)
+
+	return base.SetSuccessMessage("تم إعادة تفعيل الحساب بنجاح")
+}
+
+// ExpireSuspensions is the job handler that clears suspensions whose end has passed
+func (s *UserService) ExpireSuspensions(ctx context.Context, _ string) error {
+	for {
+		ended, err := s.UserRepo.EndExpiredSuspensions(time.Now(), suspensionExpiryBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for i := range ended {
+			after := ended[i]
+			after.SuspendedUntil = nil
+			after.RestrictionReason = ""
+			auditService.Record(ctx, auditService.Entry{
+				Action:     audit.ActionSuspensionEnded,
+				TargetType: audit.TargetUser,
+				TargetID:   ended[i].ID.String(),
+				Before:     restrictionState(&ended[i]),
+				After:      restrictionState(&after),
+			})
+		}
+		if len(ended) > 0 {
+			logger.FromContext(ctx).Info("Ended expired suspensions", "count", len(ended))
+		}
+
+		if len(ended) < suspensionExpiryBatchSize {
+			return nil
+		}
+	}
+}
+
+func (s *UserService) findRestrictionTarget(userID string) (*models.User, base.Response, bool) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, base.SetErrorMessage("الرقم التعريفي للمستخدم غير صالح"), false
+	}
+
+	user, err := s.UserRepo.FindOneByID(uid)
+	if err != nil {
+		return nil, base.SetErrorMessage("خطأ في قاعدة البيانات"), false
+	}
+	if user == nil {
+		return nil, base.SetErrorMessage("لم يتم العثور على مستخدم بهذا الرقم التعريفي"), false
+	}
+	return user, base.Response{}, true
+}
+
+// checkRestrictable refuses to restrict a user whose roles grant a permission missing from
+// adminRoles, so that an admin cannot lock out a superadmin or anyone else above them
+func (s *UserService) checkRestrictable(user *models.User, adminRoles []roleModels.Role) (base.Response, bool) {
+	if len(user.Roles) == 0 {
+		return base.Response{}, true
+	}
+
+	userRoles, err := s.RoleRepo.FindUserRoles(user.ID)
+	if err != nil {
+		return base.SetErrorMessage("فشل في استرجاع أدوار المستخدم"), false
+	}
+	if grantsBeyond(userRoles, adminRoles) {
+		response := base.SetErrorMessage("لا يمكنك تقييد حساب يملك صلاحيات لا تملكها")
+		response.HTTPStatus = http.StatusForbidden
+		return response, false
+	}
+	return base.Response{}, true
+}
+
+// grantsBeyond reports whether userRoles grant any permission that adminRoles do not
+func grantsBeyond(userRoles, adminRoles []roleModels.Role) bool {
+	for _, role := range userRoles {
+		for _, permission := range role.Permissions {
+			if !roleService.HasPermissions(adminRoles, permission.Key) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func restrictionState(user *models.User) map[string]interface{} {
+	return map[string]interface{}{
+		"suspended_until":    user.SuspendedUntil,
+		"banned_at":          user.BannedAt,
+		"restriction_reason": user.RestrictionReason,
+	}
+}
+
+func recordRestriction(ctx context.Context, action string, user *models.User, before map[string]interface{}) {
+	auditService.Record(ctx, auditService.Entry{
+		Action:     action,
+		TargetType: audit.TargetUser,
+		TargetID:   user.ID.String(),
+		Before:     before,
+		After:      restrictionState(user),
+	})
+}
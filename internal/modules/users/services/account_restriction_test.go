@@ -0,0 +1,72 @@
+package users
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	roles "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/enums"
+	roleModels "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/models"
+	userDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/dtos"
+	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountRestricted(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(time.Hour)
+
+	_, restricted := AccountRestricted(&models.User{}, now)
+	assert.False(t, restricted)
+
+	_, restricted = AccountRestricted(&models.User{SuspendedUntil: &past, RestrictionReason: "spam"}, now)
+	assert.False(t, restricted, "an ended suspension applies no longer, even before the expiry job clears it")
+
+	response, restricted := AccountRestricted(&models.User{SuspendedUntil: &future, RestrictionReason: "spam"}, now)
+	assert.True(t, restricted)
+	assert.Equal(t, http.StatusForbidden, response.HTTPStatus)
+	assert.Contains(t, response.MessageTitle, future.UTC().Format("2006-01-02 15:04"))
+
+	response, restricted = AccountRestricted(&models.User{BannedAt: &past}, now)
+	assert.True(t, restricted)
+	assert.Equal(t, http.StatusForbidden, response.HTTPStatus)
+	assert.Equal(t, "تم حظر حسابك", response.MessageTitle)
+}
+
+func TestSuspendUser_RejectsPastEnd(t *testing.T) {
+	service := UserService{}
+	response := service.SuspendUser(context.Background(), "invalid-user-id", userDTO.SuspendUserRequestDTO{
+		Until:  time.Now().Add(-time.Hour).Format(time.RFC3339),
+		Reason: "spam",
+	}, nil)
+	assert.Equal(t, http.StatusBadRequest, response.HTTPStatus)
+	assert.Equal(t, "يجب أن يكون تاريخ انتهاء الإيقاف في المستقبل", response.MessageTitle)
+}
+
+func TestReinstateUser_InvalidUserID(t *testing.T) {
+	service := UserService{}
+	response := service.ReinstateUser(context.Background(), "invalid-user-id")
+	assert.Equal(t, "الرقم التعريفي للمستخدم غير صالح", response.MessageTitle)
+}
+
+func defaultRole(name string) roleModels.Role {
+	role := roleModels.Role{Name: name}
+	for _, key := range roles.DefaultRoles[name] {
+		role.Permissions = append(role.Permissions, roleModels.Permission{Key: key})
+	}
+	return role
+}
+
+func TestGrantsBeyond_ProtectsStaffAboveTheAdmin(t *testing.T) {
+	superAdmin := []roleModels.Role{defaultRole(roles.RoleSuperAdmin)}
+	moderator := []roleModels.Role{defaultRole(roles.RoleModerator)}
+	support := []roleModels.Role{defaultRole(roles.RoleSupport)}
+
+	assert.True(t, grantsBeyond(superAdmin, moderator), "a moderator cannot restrict a superadmin")
+	assert.True(t, grantsBeyond(support, moderator), "a moderator cannot restrict staff who may impersonate")
+	assert.False(t, grantsBeyond(moderator, moderator))
+	assert.False(t, grantsBeyond(nil, moderator))
+	assert.False(t, grantsBeyond(support, superAdmin))
+	assert.False(t, grantsBeyond(superAdmin, superAdmin))
+}
@@ -0,0 +1,288 @@
+package users
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/utils"
+	audit "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/audit/enums"
+	auditRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/audit/repositories"
+	subscriptionDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/dtos"
+	subscriptionRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/repositories"
+	userDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/dtos"
+	users "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/enums"
+	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/models"
+	repos "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/repositories"
+	"github.com/google/uuid"
+)
+
+const (
+	// recentLoginsShown is how many of a user's latest logins the admin detail view lists
+	recentLoginsShown = 10
+	// exportUsersBatchSize is how many users the CSV export reads from the database at a time
+	exportUsersBatchSize = 500
+)
+
+// exportUsersHeader starts with the columns of an import file, so an export can be imported again
+var exportUsersHeader = []string{"mobile", "first_name", "last_name", "categories", "id", "email", "user_type", "signup_method", "roles", "created_at", "suspended_until", "banned_at"}
+
+// AdminUserService backs the admin panel's user search and user detail pages
+type AdminUserService struct {
+	UserRepo         *repos.UserRepository
+	AuthRepo         *repos.AuthRepository
+	TwoFactorRepo    *repos.TwoFactorRepository
+	SubscriptionRepo *subscriptionRepository.SubscriptionRepository
+	AuditRepo        *auditRepository.AuditRepository
+}
+
+func NewAdminUserService(userRepo *repos.UserRepository, authRepo *repos.AuthRepository, subscriptionRepo *subscriptionRepository.SubscriptionRepository, auditRepo *auditRepository.AuditRepository) *AdminUserService {
+	return &AdminUserService{
+		UserRepo:         userRepo,
+		AuthRepo:         authRepo,
+		TwoFactorRepo:    repos.NewTwoFactorRepository(userRepo.DB),
+		SubscriptionRepo: subscriptionRepo,
+		AuditRepo:        auditRepo,
+	}
+}
+
+func (s *AdminUserService) ListUsers(req userDTO.ListUsersRequestDTO) base.Response {
+	filter, err := NewUserFilter(req)
+	if err != nil {
+		return base.SetErrorMessage("معايير البحث غير صالحة", err.Error())
+	}
+
+	found, total, err := s.UserRepo.SearchUsers(filter, (req.Page-1)*req.PerPage, req.PerPage)
+	if err != nil {
+		response := base.SetErrorMessage("فشل في استرجاع المستخدمين", err)
+		response.HTTPStatus = http.StatusInternalServerError
+		return response
+	}
+
+	items := make([]interface{}, len(found))
+	for i, user := range found {
+		items[i] = mapAdminUserDTO(user)
+	}
+	return base.SetPaginatedResponse(items, req.Page, req.PerPage, int(total))
+}
+
+// ExportUsers writes every user matching filter to w as CSV, newest first
+func (s *AdminUserService) ExportUsers(filter repos.UserFilter, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(exportUsersHeader); err != nil {
+		return fmt.Errorf("failed to write user export: %w", err)
+	}
+
+	err := s.UserRepo.EachUserBatch(filter, exportUsersBatchSize, func(found []models.User) error {
+		for _, user := range found {
+			if err := writer.Write(exportUserRow(user)); err != nil {
+				return fmt.Errorf("failed to write user export: %w", err)
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// GetUserDetails returns a user's profile with their sign-in state, recent logins, listening
+// stats and current subscription, or their latest one when none is active
+func (s *AdminUserService) GetUserDetails(userID string) base.Response {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return base.SetErrorMessage("الرقم التعريفي للمستخدم غير صالح")
+	}
+
+	user, err := s.UserRepo.FindOneByID(uid)
+	if err != nil {
+		return base.SetErrorMessage("فشل في استرجاع المستخدم")
+	}
+	if user == nil {
+		response := base.SetErrorMessage("لم يتم العثور على مستخدم بهذا الرقم التعريفي")
+		response.HTTPStatus = http.StatusNotFound
+		return response
+	}
+
+	session, err := s.findSession(uid)
+	if err != nil {
+		return base.SetErrorMessage("فشل في استرجاع جلسات المستخدم")
+	}
+
+	stats, err := s.UserRepo.FindListeningStats(uid)
+	if err != nil {
+		return base.SetErrorMessage("فشل في استرجاع إحصائيات الاستماع")
+	}
+
+	subscription, err := s.SubscriptionRepo.FindCurrentSubscription(uid, time.Now())
+	if err == nil && subscription == nil {
+		subscription, err = s.SubscriptionRepo.FindLatestSubscription(uid)
+	}
+	if err != nil {
+		return base.SetErrorMessage("فشل في استرجاع اشتراك المستخدم")
+	}
+
+	details := userDTO.AdminUserDetailDTO{
+		AdminUserDTO: mapAdminUserDTO(*user),
+		Categories:   user.Categories,
+		Session:      *session,
+		Listening: userDTO.ListeningStatsDTO{
+			PodcastsStarted:   stats.PodcastsStarted,
+			PodcastsCompleted: stats.PodcastsCompleted,
+			LastListenedAt:    stats.LastListenedAt,
+			Likes:             stats.Likes,
+			Bookmarks:         stats.Bookmarks,
+			Downloads:         stats.Downloads,
+		},
+	}
+	if subscription != nil {
+		mapped := subscriptionDTO.MapToSubscriptionDTO(*subscription)
+		details.Subscription = &mapped
+	}
+
+	return base.SetData(details)
+}
+
+func (s *AdminUserService) findSession(userID uuid.UUID) (*userDTO.UserSessionDTO, error) {
+	session := &userDTO.UserSessionDTO{RecentLogins: []userDTO.UserLoginDTO{}}
+
+	// Accounts that never signed in with a password or OTP have no authentication record
+	if auth, err := s.AuthRepo.FindAuthByUserID(userID); err == nil {
+		session.IsActive = auth.IsActive
+		session.FailedLoginAttempts = auth.FailedLoginAttempts
+		session.LastFailedLoginAt = auth.LastFailedLoginAt
+		session.LockedUntil = auth.LockedUntil
+	}
+
+	var err error
+	if session.TwoFactorEnabled, err = s.TwoFactorRepo.IsEnabled(userID); err != nil {
+		return nil, err
+	}
+
+	logins, _, err := s.AuditRepo.FindEvents(auditRepository.EventFilter{
+		Action:     audit.ActionLogin,
+		TargetType: audit.TargetUser,
+		TargetID:   userID.String(),
+	}, 0, recentLoginsShown)
+	if err != nil {
+		return nil, err
+	}
+	for _, login := range logins {
+		var metadata struct {
+			Method string `json:"method"`
+		}
+		_ = json.Unmarshal(login.Metadata, &metadata)
+		session.RecentLogins = append(session.RecentLogins, userDTO.UserLoginDTO{
+			At:        login.CreatedAt.UTC().Format(time.RFC3339),
+			Method:    metadata.Method,
+			IP:        login.IP,
+			UserAgent: login.UserAgent,
+		})
+	}
+	return session, nil
+}
+
+// NewUserFilter turns the query parameters of an admin user search into a filter
+func NewUserFilter(req userDTO.ListUsersRequestDTO) (repos.UserFilter, error) {
+	filter := repos.UserFilter{
+		Search:       req.Search,
+		UserType:     users.UserType(req.UserType),
+		SignupMethod: users.SignupMethod(req.SignupMethod),
+		Sort:         req.Sort,
+		// Newest accounts first unless asked otherwise
+		Descending: req.Order == "desc" || (req.Order == "" && (req.Sort == "" || req.Sort == "created_at")),
+	}
+
+	if req.CategoryID != "" {
+		categoryID, err := uuid.Parse(req.CategoryID)
+		if err != nil {
+			return filter, fmt.Errorf("invalid category_id: %w", err)
+		}
+		filter.CategoryID = &categoryID
+	}
+	if req.CreatedFrom != "" {
+		from, err := time.Parse(time.RFC3339, req.CreatedFrom)
+		if err != nil {
+			return filter, fmt.Errorf("invalid created_from: %w", err)
+		}
+		filter.CreatedFrom = &from
+	}
+	if req.CreatedTo != "" {
+		to, err := time.Parse(time.RFC3339, req.CreatedTo)
+		if err != nil {
+			return filter, fmt.Errorf("invalid created_to: %w", err)
+		}
+		filter.CreatedTo = &to
+	}
+	return filter, nil
+}
+
+func exportUserRow(user models.User) []string {
+	roleNames := make([]string, len(user.Roles))
+	for i, role := range user.Roles {
+		roleNames[i] = role.Name
+	}
+	email := user.Email
+	// Accounts created with a mobile number get a placeholder address nobody can receive mail at
+	if strings.HasSuffix(email, "@placeholder.com") {
+		email = ""
+	}
+
+	row := []string{
+		user.Mobile,
+		user.FirstName,
+		user.LastName,
+		strings.Join(utils.ConvertCategoriesToStringIDs(user.Categories), importCategorySeparator),
+		user.ID.String(),
+		email,
+		string(user.UserType),
+		string(user.SignupMethod),
+		strings.Join(roleNames, importCategorySeparator),
+		user.CreatedAt.UTC().Format(time.RFC3339),
+		formatExportTime(user.SuspendedUntil),
+		formatExportTime(user.BannedAt),
+	}
+	for i, value := range row {
+		row[i] = utils.SpreadsheetSafe(value)
+	}
+	return row
+}
+
+func formatExportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func mapAdminUserDTO(user models.User) userDTO.AdminUserDTO {
+	roleNames := make([]string, len(user.Roles))
+	for i, role := range user.Roles {
+		roleNames[i] = role.Name
+	}
+
+	return userDTO.AdminUserDTO{
+		ID:                  user.ID.String(),
+		FirstName:           user.FirstName,
+		LastName:            user.LastName,
+		Email:               user.Email,
+		Mobile:              user.Mobile,
+		UserType:            user.UserType,
+		SignupMethod:        user.SignupMethod,
+		Roles:               roleNames,
+		CreatedAt:           user.CreatedAt,
+		DeletionRequestedAt: user.DeletionRequestedAt,
+		SuspendedUntil:      user.SuspendedUntil,
+		BannedAt:            user.BannedAt,
+		RestrictionReason:   user.RestrictionReason,
+	}
+}
@@ -0,0 +1,58 @@
+package users
+
+import (
+	"testing"
+	"time"
+
+	userDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/dtos"
+	users "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/enums"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewUserFilter_ParsesFilters(t *testing.T) {
+	categoryID := uuid.New()
+
+	filter, err := NewUserFilter(userDTO.ListUsersRequestDTO{
+		Search:       "john",
+		UserType:     "subscribed",
+		SignupMethod: "google",
+		CategoryID:   categoryID.String(),
+		CreatedFrom:  "2025-01-01T00:00:00Z",
+		CreatedTo:    "2025-02-01T00:00:00+03:00",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "john", filter.Search)
+	assert.Equal(t, users.UserTypeSubscribed, filter.UserType)
+	assert.Equal(t, users.SignupMethodGoogle, filter.SignupMethod)
+	assert.Equal(t, categoryID, *filter.CategoryID)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), filter.CreatedFrom.UTC())
+	assert.Equal(t, time.Date(2025, 1, 31, 21, 0, 0, 0, time.UTC), filter.CreatedTo.UTC())
+}
+
+func TestNewUserFilter_RejectsBadValues(t *testing.T) {
+	_, err := NewUserFilter(userDTO.ListUsersRequestDTO{CategoryID: "news"})
+	assert.Error(t, err)
+
+	_, err = NewUserFilter(userDTO.ListUsersRequestDTO{CreatedFrom: "2025-01-01"})
+	assert.Error(t, err)
+}
+
+func TestNewUserFilter_SortOrder(t *testing.T) {
+	for _, tc := range []struct {
+		sort, order string
+		descending  bool
+	}{
+		{"", "", true},
+		{"created_at", "", true},
+		{"created_at", "asc", false},
+		{"first_name", "", false},
+		{"email", "desc", true},
+	} {
+		filter, err := NewUserFilter(userDTO.ListUsersRequestDTO{Sort: tc.sort, Order: tc.order})
+		require.NoError(t, err)
+		assert.Equal(t, tc.descending, filter.Descending, "sort=%q order=%q", tc.sort, tc.order)
+	}
+}
@@ -5,12 +5,16 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
-	"os"
 	"time"
 
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/logger"
 	DTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/dtos"
 
+	"github.com/Al-Khaimah/khaimah-golang-backend/config"
 	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/tracing"
+	referralService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/referrals/services"
+	users "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/enums"
 	userModel "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/models"
 	userRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/repositories"
 	"github.com/golang-jwt/jwt/v5"
@@ -19,7 +23,7 @@ import (
 )
 
 type Provider interface {
-	Exchange(idToken string) (string, error)
+	Exchange(ctx context.Context, idToken string) (string, error)
 	GetClientSecret() (string, error)
 }
 
@@ -38,38 +42,42 @@ type AppleProvider struct {
 type AuthService struct {
 	userRepo  *userRepository.UserRepository
 	authRepo  *userRepository.AuthRepository
+	twoFactor *userRepository.TwoFactorRepository
 	providers map[string]Provider
-	jwtSecret []byte
+	slackURL  string
+	referrals *referralService.ReferralService
 }
 
-func NewAuthService(userRepo *userRepository.UserRepository, authRepo *userRepository.AuthRepository) *AuthService {
+func NewAuthService(userRepo *userRepository.UserRepository, authRepo *userRepository.AuthRepository, referrals *referralService.ReferralService, cfg *config.Config) *AuthService {
 	return &AuthService{
-		userRepo: userRepo,
-		authRepo: authRepo,
+		userRepo:  userRepo,
+		authRepo:  authRepo,
+		twoFactor: userRepository.NewTwoFactorRepository(userRepo.DB),
+		referrals: referrals,
 		providers: map[string]Provider{
-			"google": NewGoogleProvider(),
-			"apple":  NewAppleProvider(),
+			"google": NewGoogleProvider(cfg.Auth),
+			"apple":  NewAppleProvider(cfg.Auth),
 		},
-		jwtSecret: []byte(os.Getenv("JWT_SECRET")),
+		slackURL: cfg.Messaging.SlackWebhookURL,
 	}
 }
 
-func NewGoogleProvider() *GoogleProvider {
-	return &GoogleProvider{ClientID: os.Getenv("GOOGLE_CLIENT_ID")}
+func NewGoogleProvider(cfg config.AuthConfig) *GoogleProvider {
+	return &GoogleProvider{ClientID: cfg.GoogleClientID}
 }
 
-func NewAppleProvider() *AppleProvider {
+func NewAppleProvider(cfg config.AuthConfig) *AppleProvider {
 	return &AppleProvider{
 		JWKSUrl:    "https://appleid.apple.com/auth/keys",
-		ClientID:   os.Getenv("APPLE_CLIENT_ID"),
-		TeamID:     os.Getenv("APPLE_TEAM_ID"),
-		KeyID:      os.Getenv("APPLE_KEY_ID"),
-		PrivateKey: os.Getenv("APPLE_PRIVATE_KEY"),
+		ClientID:   cfg.AppleClientID,
+		TeamID:     cfg.AppleTeamID,
+		KeyID:      cfg.AppleKeyID,
+		PrivateKey: cfg.ApplePrivateKey,
 	}
 }
 
-func (g *GoogleProvider) Exchange(token string) (string, error) {
-	p, err := idtoken.Validate(context.Background(), token, g.ClientID)
+func (g *GoogleProvider) Exchange(ctx context.Context, token string) (string, error) {
+	p, err := idtoken.Validate(ctx, token, g.ClientID)
 	if err != nil {
 		return "", fmt.Errorf("google token invalid: %w", err)
 	}
@@ -77,8 +85,8 @@ func (g *GoogleProvider) Exchange(token string) (string, error) {
 	return email, nil
 }
 
-func (a *AppleProvider) Exchange(token string) (string, error) {
-	set, err := jwk.Fetch(context.Background(), a.JWKSUrl)
+func (a *AppleProvider) Exchange(ctx context.Context, token string) (string, error) {
+	set, err := jwk.Fetch(ctx, a.JWKSUrl, jwk.WithHTTPClient(tracing.NewHTTPClient(10*time.Second)))
 	if err != nil {
 		return "", fmt.Errorf("apple jwk fetch failed: %w", err)
 	}
@@ -164,13 +172,13 @@ func (a *AppleProvider) GetClientSecret() (string, error) {
 	return clientSecret, nil
 }
 
-func (s *AuthService) SSOLogin(dto *DTO.OAuthRequestDTO, token string) base.Response {
+func (s *AuthService) SSOLogin(ctx context.Context, dto *DTO.OAuthRequestDTO, token string) base.Response {
 	provider, ok := s.providers[dto.Provider]
 	if !ok {
 		return base.SetErrorMessage("unsupported provider")
 	}
 
-	email, err := provider.Exchange(token)
+	email, err := provider.Exchange(ctx, token)
 	if err != nil {
 		return base.SetErrorMessage("invalid token: " + err.Error())
 	}
@@ -183,22 +191,43 @@ func (s *AuthService) SSOLogin(dto *DTO.OAuthRequestDTO, token string) base.Resp
 	userExists := (user != nil)
 	if !userExists {
 		newUser := &userModel.User{
-			Email: email,
+			Email:        email,
+			SignupMethod: users.SignupMethod(dto.Provider),
 		}
 		user, err = s.userRepo.CreateUser(newUser)
 		if err != nil {
 			return base.SetErrorMessage("failed to create user")
 		}
+
+		if err := s.referrals.LinkReferral(user.ID, dto.ReferralCode); err != nil {
+			logger.FromContext(ctx).Error("Failed to link referral", "new_user_id", user.ID, "error", err)
+		}
+
+		notifyNewUser(ctx, s.slackURL, user)
 	}
 
-	claims := jwt.MapClaims{
-		"user_id": user.ID,
+	if userExists {
+		if response, restricted := AccountRestricted(user, time.Now()); restricted {
+			return response
+		}
+		if response, challenged := startTwoFactorChallenge(ctx, s.twoFactor, user.ID); challenged {
+			return response
+		}
+	}
+
+	if err := s.userRepo.CancelPendingDeletion(user); err != nil {
+		return base.SetErrorMessage("failed to cancel account deletion")
 	}
-	tok := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signed, err := tok.SignedString(s.jwtSecret)
+	if userAuth, err := s.authRepo.FindAuthByUserID(user.ID); err == nil && !userAuth.IsActive {
+		userAuth.IsActive = true
+		_ = s.authRepo.UpdateAuth(userAuth)
+	}
+
+	signed, expiresAt, err := GenerateJWT(user)
 	if err != nil {
 		return base.SetErrorMessage("sign token error")
 	}
+	recordLogin(ctx, user.ID, dto.Provider)
 
 	var userDetails *DTO.UserBaseDTO
 	if userExists {
@@ -215,6 +244,7 @@ func (s *AuthService) SSOLogin(dto *DTO.OAuthRequestDTO, token string) base.Resp
 
 	SSOLoginResponse := DTO.SSOLoginResponse{
 		Token:      signed,
+		ExpiresAt:  tokenExpiry(expiresAt),
 		UserExists: userExists,
 		User:       userDetails,
 	}
@@ -240,6 +270,6 @@ func (s *AuthService) ValidateWithProvider(providerKey, token string) (bool, err
 		//ToDO :: Google Auth
 	}
 
-	_, err := provider.Exchange(token)
+	_, err := provider.Exchange(context.Background(), token)
 	return err == nil, err
 }
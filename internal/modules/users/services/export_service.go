@@ -0,0 +1,454 @@
+package users
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/config"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/jobs"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/logger"
+	notificationEnums "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/notifications/enums"
+	notificationModels "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/notifications/models"
+	notificationRepos "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/notifications/repositories"
+	podcastRepos "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/podcasts/repositories"
+	userDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/dtos"
+	users "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/enums"
+	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/models"
+	repos "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/repositories"
+	"github.com/google/uuid"
+)
+
+const (
+	ExportJobName = "users.data_export"
+	// ExportExpiryJobName deletes the archives of exports whose download link has expired
+	ExportExpiryJobName = "users.data_export_expiry"
+	exportLinkTTL       = 48 * time.Hour
+	exportFileMode      = 0o600
+)
+
+type ExportService struct {
+	UserRepo         *repos.UserRepository
+	AuthRepo         *repos.AuthRepository
+	ExportRepo       *repos.ExportRepository
+	PodcastRepo      *podcastRepos.PodcastRepository
+	NotificationRepo *notificationRepos.NotificationRepository
+	ExportsDir       string
+	PublicBaseURL    string
+}
+
+func NewExportService(userRepo *repos.UserRepository, authRepo *repos.AuthRepository, exportRepo *repos.ExportRepository, podcastRepo *podcastRepos.PodcastRepository, notificationRepo *notificationRepos.NotificationRepository, cfg *config.Config) *ExportService {
+	return &ExportService{
+		UserRepo:         userRepo,
+		AuthRepo:         authRepo,
+		ExportRepo:       exportRepo,
+		PodcastRepo:      podcastRepo,
+		NotificationRepo: notificationRepo,
+		ExportsDir:       cfg.Exports.Dir,
+		PublicBaseURL:    cfg.Server.PublicBaseURL,
+	}
+}
+
+// RequestExport creates an export record for the user and enqueues the job that builds the archive
+func (s *ExportService) RequestExport(userID string) base.Response {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return base.SetErrorMessage("الرقم التعريفي للمستخدم غير صالح")
+	}
+
+	latest, err := s.ExportRepo.FindLatestExportByUserID(uid)
+	if err != nil {
+		return base.SetErrorMessage("خطأ في قاعدة البيانات")
+	}
+	if latest != nil && (latest.Status == users.ExportStatusPending || latest.Status == users.ExportStatusProcessing) {
+		return base.SetWarningMessage("طلب تصدير البيانات قيد المعالجة بالفعل")
+	}
+
+	token, err := generateExportToken()
+	if err != nil {
+		return base.SetErrorMessage("فشل في إنشاء طلب التصدير")
+	}
+
+	export := &models.DataExport{
+		UserID: uid,
+		Status: users.ExportStatusPending,
+		Token:  token,
+	}
+	if err := s.ExportRepo.CreateExport(export); err != nil {
+		return base.SetErrorMessage("فشل في إنشاء طلب التصدير")
+	}
+
+	if err := jobs.Enqueue(ExportJobName, export.ID.String()); err != nil {
+		export.Status = users.ExportStatusFailed
+		export.Error = err.Error()
+		_ = s.ExportRepo.UpdateExport(export)
+		return base.SetErrorMessage("فشل في جدولة طلب التصدير")
+	}
+
+	return base.SetData(s.mapExportDTO(export), "تم استلام طلب تصدير البيانات، سيتم إشعارك عند جاهزيته")
+}
+
+// GetLatestExport returns the status of the user's most recent export
+func (s *ExportService) GetLatestExport(userID string) base.Response {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return base.SetErrorMessage("الرقم التعريفي للمستخدم غير صالح")
+	}
+
+	export, err := s.ExportRepo.FindLatestExportByUserID(uid)
+	if err != nil {
+		return base.SetErrorMessage("خطأ في قاعدة البيانات")
+	}
+	if export == nil {
+		return base.SetErrorMessage("لا يوجد طلب تصدير بيانات")
+	}
+
+	return base.SetData(s.mapExportDTO(export))
+}
+
+// FindDownloadableExport resolves a download token to a ready, unexpired export archive
+func (s *ExportService) FindDownloadableExport(token string) (*models.DataExport, base.Response) {
+	export, err := s.ExportRepo.FindExportByToken(token)
+	if err != nil {
+		return nil, base.SetErrorMessage("خطأ في قاعدة البيانات")
+	}
+	if export == nil || export.Status != users.ExportStatusReady {
+		return nil, base.SetErrorMessage("رابط التحميل غير صالح")
+	}
+	if export.ExpiresAt == nil || time.Now().After(*export.ExpiresAt) {
+		return nil, base.SetErrorMessage("انتهت صلاحية رابط التحميل")
+	}
+
+	return export, base.Response{}
+}
+
+// ResumePendingExports re-enqueues exports that were interrupted by a restart
+func (s *ExportService) ResumePendingExports() {
+	exports, err := s.ExportRepo.FindExportsByStatus(users.ExportStatusPending, users.ExportStatusProcessing)
+	if err != nil {
+		return
+	}
+	for _, export := range exports {
+		_ = jobs.Enqueue(ExportJobName, export.ID.String())
+	}
+}
+
+// ExpireExports deletes the archives of exports whose download link has expired and marks them
+// expired. An archive that cannot be deleted is left ready, so the next run tries again.
+func (s *ExportService) ExpireExports(ctx context.Context, _ string) error {
+	exports, err := s.ExportRepo.FindExpiredExports(time.Now())
+	if err != nil {
+		return err
+	}
+
+	for i := range exports {
+		export := &exports[i]
+		if err := removeExportFile(export.FilePath); err != nil {
+			logger.FromContext(ctx).Error("Failed to delete expired export archive", "export_id", export.ID, "error", err)
+			continue
+		}
+
+		export.Status = users.ExportStatusExpired
+		export.FilePath = ""
+		if err := s.ExportRepo.UpdateExport(export); err != nil {
+			return err
+		}
+	}
+	if len(exports) > 0 {
+		logger.FromContext(ctx).Info("Expired data exports", "count", len(exports))
+	}
+	return nil
+}
+
+// removeExportFile deletes an export archive; one that is already gone counts as deleted
+func removeExportFile(path string) error {
+	if path == "" {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// ProcessExport is the job handler that assembles the archive and notifies the user
+func (s *ExportService) ProcessExport(_ context.Context, payload string) error {
+	exportID, err := uuid.Parse(payload)
+	if err != nil {
+		return fmt.Errorf("invalid export id: %w", err)
+	}
+
+	export, err := s.ExportRepo.FindExportByID(exportID)
+	if err != nil {
+		return err
+	}
+	if export == nil {
+		return fmt.Errorf("data export %s not found", exportID)
+	}
+
+	export.Status = users.ExportStatusProcessing
+	if err := s.ExportRepo.UpdateExport(export); err != nil {
+		return err
+	}
+
+	path, err := s.buildArchive(export)
+	if err != nil {
+		export.Status = users.ExportStatusFailed
+		export.Error = err.Error()
+		_ = s.ExportRepo.UpdateExport(export)
+		return err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(exportLinkTTL)
+	export.Status = users.ExportStatusReady
+	export.FilePath = path
+	export.CompletedAt = &now
+	export.ExpiresAt = &expiresAt
+	if err := s.ExportRepo.UpdateExport(export); err != nil {
+		return err
+	}
+
+	return s.NotificationRepo.CreateNotification(&notificationModels.Notification{
+		UserID:      export.UserID,
+		Title:       "نسخة بياناتك جاهزة",
+		Description: fmt.Sprintf("يمكنك تحميل نسخة بياناتك من الرابط التالي خلال ٤٨ ساعة: %s", s.exportDownloadURL(export.Token)),
+		Type:        notificationEnums.NotificationTypeDataExport,
+	})
+}
+
+func (s *ExportService) buildArchive(export *models.DataExport) (string, error) {
+	user, err := s.UserRepo.FindOneByID(export.UserID)
+	if err != nil {
+		return "", err
+	}
+	if user == nil {
+		return "", fmt.Errorf("user %s not found", export.UserID)
+	}
+
+	history, err := s.PodcastRepo.FindUserPodcasts(user.ID)
+	if err != nil {
+		return "", err
+	}
+	likes, err := s.PodcastRepo.FindUserLikes(user.ID)
+	if err != nil {
+		return "", err
+	}
+	notifications, err := s.NotificationRepo.FindUserNotifications(user.ID)
+	if err != nil {
+		return "", err
+	}
+
+	var sessions []userDTO.ExportSessionDTO
+	if auth, err := s.AuthRepo.FindAuthByUserID(user.ID); err == nil {
+		sessions = append(sessions, userDTO.ExportSessionDTO{
+			ID:        auth.ID.String(),
+			IsActive:  auth.IsActive,
+			CreatedAt: auth.CreatedAt,
+			UpdatedAt: auth.UpdatedAt,
+		})
+	}
+
+	if err := os.MkdirAll(s.ExportsDir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create exports directory: %w", err)
+	}
+	path := filepath.Join(s.ExportsDir, fmt.Sprintf("%s.zip", export.ID))
+	err = writeArchive(path, func(zw *zip.Writer) error {
+		profile := userDTO.ExportProfileDTO{
+			ID:        user.ID.String(),
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+			Email:     user.Email,
+			Mobile:    user.Mobile,
+			UserType:  user.UserType,
+			CreatedAt: user.CreatedAt,
+			UpdatedAt: user.UpdatedAt,
+		}
+		if err := writeZipJSON(zw, "profile.json", profile); err != nil {
+			return err
+		}
+
+		categoryRows := make([][]string, len(user.Categories))
+		for i, category := range user.Categories {
+			categoryRows[i] = []string{category.ID.String(), category.Name, category.Description}
+		}
+		if err := writeZipJSON(zw, "categories.json", user.Categories); err != nil {
+			return err
+		}
+		if err := writeZipCSV(zw, "categories.csv", []string{"id", "name", "description"}, categoryRows); err != nil {
+			return err
+		}
+
+		bookmarkRows := make([][]string, len(user.Bookmarks))
+		for i, podcast := range user.Bookmarks {
+			bookmarkRows[i] = []string{podcast.ID.String(), podcast.Title, podcast.CategoryID.String()}
+		}
+		if err := writeZipJSON(zw, "bookmarks.json", user.Bookmarks); err != nil {
+			return err
+		}
+		if err := writeZipCSV(zw, "bookmarks.csv", []string{"podcast_id", "title", "category_id"}, bookmarkRows); err != nil {
+			return err
+		}
+
+		downloadRows := make([][]string, len(user.Downloads))
+		for i, podcast := range user.Downloads {
+			downloadRows[i] = []string{podcast.ID.String(), podcast.Title, podcast.CategoryID.String()}
+		}
+		if err := writeZipJSON(zw, "downloads.json", user.Downloads); err != nil {
+			return err
+		}
+		if err := writeZipCSV(zw, "downloads.csv", []string{"podcast_id", "title", "category_id"}, downloadRows); err != nil {
+			return err
+		}
+
+		historyRows := make([][]string, len(history))
+		for i, entry := range history {
+			historyRows[i] = []string{
+				entry.PodcastID.String(),
+				entry.CategoryID.String(),
+				strconv.Itoa(entry.ResumePosition),
+				strconv.FormatBool(entry.IsCompleted),
+				entry.CreatedAt.Format(time.RFC3339),
+				entry.UpdatedAt.Format(time.RFC3339),
+			}
+		}
+		if err := writeZipJSON(zw, "listening_history.json", history); err != nil {
+			return err
+		}
+		if err := writeZipCSV(zw, "listening_history.csv", []string{"podcast_id", "category_id", "resume_position", "is_completed", "started_at", "updated_at"}, historyRows); err != nil {
+			return err
+		}
+
+		likeRows := make([][]string, len(likes))
+		for i, like := range likes {
+			likeRows[i] = []string{like.PodcastID.String(), strconv.Itoa(like.Count), like.CreatedAt.Format(time.RFC3339)}
+		}
+		if err := writeZipJSON(zw, "likes.json", likes); err != nil {
+			return err
+		}
+		if err := writeZipCSV(zw, "likes.csv", []string{"podcast_id", "count", "liked_at"}, likeRows); err != nil {
+			return err
+		}
+
+		notificationRows := make([][]string, len(notifications))
+		for i, notification := range notifications {
+			notificationRows[i] = []string{
+				notification.ID.String(),
+				notification.Type,
+				notification.Title,
+				notification.Description,
+				strconv.FormatBool(notification.IsRead),
+				notification.CreatedAt.Format(time.RFC3339),
+			}
+		}
+		if err := writeZipJSON(zw, "notifications.json", notifications); err != nil {
+			return err
+		}
+		if err := writeZipCSV(zw, "notifications.csv", []string{"id", "type", "title", "description", "is_read", "created_at"}, notificationRows); err != nil {
+			return err
+		}
+
+		if err := writeZipJSON(zw, "sessions.json", sessions); err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// writeArchive creates the zip archive at path and fills it with write. The archive holds personal
+// data, so it is removed again if anything fails rather than left half written.
+func writeArchive(path string, write func(zw *zip.Writer) error) (err error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, exportFileMode)
+	if err != nil {
+		return fmt.Errorf("failed to create export archive: %w", err)
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("failed to close export archive: %w", closeErr)
+		}
+		if err != nil {
+			_ = removeExportFile(path)
+		}
+	}()
+
+	zw := zip.NewWriter(file)
+	if err := write(zw); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to finalize export archive: %w", err)
+	}
+	return nil
+}
+
+func (s *ExportService) mapExportDTO(export *models.DataExport) userDTO.DataExportDTO {
+	dto := userDTO.DataExportDTO{
+		ID:          export.ID.String(),
+		Status:      export.Status,
+		RequestedAt: export.CreatedAt,
+		CompletedAt: export.CompletedAt,
+		ExpiresAt:   export.ExpiresAt,
+	}
+	if export.Status == users.ExportStatusReady && export.ExpiresAt != nil && time.Now().Before(*export.ExpiresAt) {
+		dto.DownloadURL = s.exportDownloadURL(export.Token)
+	}
+	return dto
+}
+
+func writeZipJSON(zw *zip.Writer, name string, data interface{}) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to export: %w", name, err)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+func writeZipCSV(zw *zip.Writer, name string, header []string, rows [][]string) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to export: %w", name, err)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+func generateExportToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (s *ExportService) exportDownloadURL(token string) string {
+	return fmt.Sprintf("%s/exports/%s", s.PublicBaseURL, token)
+}
@@ -0,0 +1,87 @@
+package users
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRequestExport_InvalidUserID tests validation of user ID
+func TestRequestExport_InvalidUserID(t *testing.T) {
+	service := ExportService{}
+	response := service.RequestExport("invalid-user-id")
+	assert.Equal(t, "الرقم التعريفي للمستخدم غير صالح", response.MessageTitle)
+}
+
+// TestWriteZipCSV tests that CSV sections are written with their header row
+func TestWriteZipCSV(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	err := writeZipCSV(zw, "likes.csv", []string{"podcast_id", "count"}, [][]string{{"abc", "2"}})
+	assert.NoError(t, err)
+	assert.NoError(t, zw.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+	assert.Len(t, zr.File, 1)
+	assert.Equal(t, "likes.csv", zr.File[0].Name)
+
+	f, err := zr.File[0].Open()
+	assert.NoError(t, err)
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"podcast_id", "count"}, {"abc", "2"}}, records)
+}
+
+// TestRemoveExportFile tests that expired archives are deleted and missing ones are not an error
+func TestRemoveExportFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.zip")
+	assert.NoError(t, os.WriteFile(path, []byte("archive"), 0o600))
+
+	assert.NoError(t, removeExportFile(path))
+	_, err := os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	assert.NoError(t, removeExportFile(path))
+	assert.NoError(t, removeExportFile(""))
+}
+
+// TestWriteArchive_RemovesFileOnFailure tests that a half-written archive is not left on disk
+func TestWriteArchive_RemovesFileOnFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.zip")
+
+	err := writeArchive(path, func(zw *zip.Writer) error {
+		if err := writeZipJSON(zw, "profile.json", map[string]string{"email": "mohammed@example.com"}); err != nil {
+			return err
+		}
+		return errors.New("failed to fetch likes")
+	})
+
+	assert.EqualError(t, err, "failed to fetch likes")
+	_, statErr := os.Stat(path)
+	assert.True(t, os.IsNotExist(statErr))
+}
+
+// TestWriteArchive_KeepsCompleteArchive tests that a finished archive is kept and readable
+func TestWriteArchive_KeepsCompleteArchive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.zip")
+
+	err := writeArchive(path, func(zw *zip.Writer) error {
+		return writeZipJSON(zw, "profile.json", map[string]string{"email": "mohammed@example.com"})
+	})
+
+	assert.NoError(t, err)
+	zr, err := zip.OpenReader(path)
+	assert.NoError(t, err)
+	defer zr.Close()
+	assert.Len(t, zr.File, 1)
+}
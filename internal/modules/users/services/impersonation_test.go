@@ -0,0 +1,27 @@
+package users
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	userDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/dtos"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImpersonate_RefusesBeforeLookingUpTheUser(t *testing.T) {
+	service := AdminUserService{}
+	adminID := uuid.NewString()
+	req := userDTO.ImpersonateRequestDTO{Reason: "ticket"}
+
+	response := service.Impersonate(context.Background(), adminID, "invalid-user-id", req, false)
+	assert.Equal(t, "الرقم التعريفي للمستخدم غير صالح", response.MessageTitle)
+
+	response = service.Impersonate(context.Background(), adminID, adminID, req, false)
+	assert.Equal(t, "لا يمكنك انتحال هوية حسابك", response.MessageTitle)
+
+	req.AllowWrites = true
+	response = service.Impersonate(context.Background(), adminID, uuid.NewString(), req, false)
+	assert.Equal(t, http.StatusForbidden, response.HTTPStatus)
+}
@@ -0,0 +1,198 @@
+package users
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/logger"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/ratelimit"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/utils"
+	audit "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/audit/enums"
+	auditService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/audit/services"
+	userDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/dtos"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// LoginDelayAfter is the number of failed logins after which each failure is answered
+	// progressively slower: one second, then doubling up to LoginMaxDelay
+	LoginDelayAfter = 3
+	LoginMaxDelay   = 8 * time.Second
+	// LoginLockoutAfter failed logins, each within LoginLockoutPeriod of the one before, lock
+	// password login for LoginLockoutPeriod. Signing in with an OTP, or an admin, unlocks the
+	// account earlier. Once the lockout runs out the count starts again.
+	LoginLockoutAfter  = 10
+	LoginLockoutPeriod = 15 * time.Minute
+	// LoginIPFailureLimit failed logins from one IP within LoginIPFailureWindow block
+	// password login from that IP, whichever accounts were tried
+	LoginIPFailureLimit  = 30
+	LoginIPFailureWindow = 15 * time.Minute
+)
+
+var (
+	loginIPFailures = ratelimit.Counter{Name: "login-failures-ip", Window: LoginIPFailureWindow}
+	// Unknown emails are counted too so that lockouts and delays do not reveal which emails have accounts
+	loginUnknownEmailFailures = ratelimit.Counter{Name: "login-failures-email", Window: LoginLockoutPeriod}
+	loginUnknownEmailLocks    = ratelimit.Counter{Name: "login-locks-email", Window: LoginLockoutPeriod}
+)
+
+// dummyPasswordHash is compared against when the email has no account, so both cases take as long
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("khaimah-login-timing"), bcrypt.DefaultCost)
+	return hash
+})
+
+// loginDelay returns how long to hold back the answer to the failures-th failed login
+func loginDelay(failures int) time.Duration {
+	if failures < LoginDelayAfter {
+		return 0
+	}
+	shift := failures - LoginDelayAfter
+	if shift > 5 {
+		return LoginMaxDelay
+	}
+	return min(time.Second<<shift, LoginMaxDelay)
+}
+
+// waitLoginDelay holds back a failed login, returning early if the client goes away
+func waitLoginDelay(ctx context.Context, failures int) {
+	delay := loginDelay(failures)
+	if delay == 0 {
+		return
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+// invalidCredentials is the single answer to a wrong email or password, so neither is revealed
+func invalidCredentials() base.Response {
+	response := base.SetErrorMessage("البريد الإلكتروني أو كلمة المرور غير صحيحة")
+	response.HTTPStatus = http.StatusUnauthorized
+	return response
+}
+
+func accountLocked() base.Response {
+	response := base.SetErrorMessage("تم قفل تسجيل الدخول بكلمة المرور مؤقتاً بسبب محاولات فاشلة متعددة", "سجّل الدخول برمز التحقق لإلغاء القفل، أو حاول مجدداً لاحقاً")
+	response.HTTPStatus = http.StatusLocked
+	return response
+}
+
+func tooManyLoginAttempts() base.Response {
+	response := base.SetErrorMessage("محاولات تسجيل دخول كثيرة، يرجى المحاولة لاحقاً")
+	response.HTTPStatus = http.StatusTooManyRequests
+	return response
+}
+
+// failUnknownEmail answers a login for an email without an account exactly like a wrong password
+func (s *UserService) failUnknownEmail(ctx context.Context, email, password, ip string) base.Response {
+	limiter := ratelimit.Default()
+	email = utils.FormatEmail(email)
+	if limiter.Count(ctx, loginUnknownEmailLocks, email) > 0 {
+		return accountLocked()
+	}
+
+	_ = bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(password))
+
+	failures := limiter.Increment(ctx, loginUnknownEmailFailures, email)
+	limiter.Increment(ctx, loginIPFailures, ip)
+	if failures >= LoginLockoutAfter {
+		// As for an account, the lock runs for a whole period from this failure and the count
+		// starts again after it
+		limiter.Increment(ctx, loginUnknownEmailLocks, email)
+		limiter.Reset(ctx, loginUnknownEmailFailures, email)
+	}
+	waitLoginDelay(ctx, int(failures))
+	return invalidCredentials()
+}
+
+// failPassword records a wrong password against the account, locking it once the limit is reached
+func (s *UserService) failPassword(ctx context.Context, userID uuid.UUID, ip string) base.Response {
+	now := time.Now()
+	ratelimit.Default().Increment(ctx, loginIPFailures, ip)
+
+	failures, err := s.AuthRepo.RecordFailedLogin(userID, now, now.Add(-LoginLockoutPeriod))
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to record failed login", "user_id", userID, "error", err)
+		return invalidCredentials()
+	}
+
+	auditService.Record(ctx, auditService.Entry{
+		Action:     audit.ActionLoginFailed,
+		TargetType: audit.TargetUser,
+		TargetID:   userID.String(),
+		Metadata:   map[string]interface{}{"method": "password", "failed_attempts": failures},
+	})
+
+	if failures >= LoginLockoutAfter {
+		if err := s.AuthRepo.LockUntil(userID, now.Add(LoginLockoutPeriod)); err != nil {
+			logger.FromContext(ctx).Error("Failed to lock account", "user_id", userID, "error", err)
+		} else {
+			logger.FromContext(ctx).Warn("Locked password login after repeated failures", "user_id", userID, "failed_attempts", failures)
+		}
+	}
+
+	waitLoginDelay(ctx, failures)
+	return invalidCredentials()
+}
+
+// GetLockedAccounts lists the accounts whose password login is currently locked
+func (s *UserService) GetLockedAccounts() base.Response {
+	auths, err := s.AuthRepo.FindLockedAuths(time.Now())
+	if err != nil {
+		return base.SetErrorMessage("فشل في استرجاع الحسابات المقفلة")
+	}
+
+	accounts := make([]userDTO.LockedAccountDTO, 0, len(auths))
+	for _, auth := range auths {
+		account := userDTO.LockedAccountDTO{
+			UserID:              auth.UserID.String(),
+			FailedLoginAttempts: auth.FailedLoginAttempts,
+			LastFailedLoginAt:   auth.LastFailedLoginAt,
+			LockedUntil:         auth.LockedUntil,
+		}
+		if auth.User != nil {
+			account.Email = auth.User.Email
+			account.Mobile = auth.User.Mobile
+		}
+		accounts = append(accounts, account)
+	}
+
+	return base.SetData(accounts)
+}
+
+// UnlockAccount lifts a password login lockout and forgets the failed attempts
+func (s *UserService) UnlockAccount(ctx context.Context, userID string) base.Response {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return base.SetErrorMessage("الرقم التعريفي للمستخدم غير صالح")
+	}
+
+	user, err := s.UserRepo.FindOneByID(uid)
+	if err != nil {
+		return base.SetErrorMessage("خطأ في قاعدة البيانات")
+	}
+	if user == nil {
+		return base.SetErrorMessage("لم يتم العثور على مستخدم بهذا الرقم التعريفي")
+	}
+
+	if err := s.AuthRepo.ClearFailedLogins(uid); err != nil {
+		return base.SetErrorMessage("فشل في إلغاء قفل الحساب")
+	}
+
+	auditService.Record(ctx, auditService.Entry{
+		Action:     audit.ActionUserUnlocked,
+		TargetType: audit.TargetUser,
+		TargetID:   uid.String(),
+	})
+
+	return base.SetSuccessMessage("تم إلغاء قفل الحساب بنجاح")
+}
@@ -0,0 +1,272 @@
+package users
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/ratelimit"
+	userDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/dtos"
+	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/models"
+	repos "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/repositories"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestLoginDelay_GrowsAndCaps(t *testing.T) {
+	assert.Equal(t, time.Duration(0), loginDelay(1))
+	assert.Equal(t, time.Duration(0), loginDelay(LoginDelayAfter-1))
+	assert.Equal(t, time.Second, loginDelay(LoginDelayAfter))
+	assert.Equal(t, 2*time.Second, loginDelay(LoginDelayAfter+1))
+	assert.Equal(t, LoginMaxDelay, loginDelay(LoginDelayAfter+10))
+	assert.Equal(t, LoginMaxDelay, loginDelay(1000))
+}
+
+func TestIamAuth_IsLocked(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(time.Minute)
+
+	assert.False(t, (&models.IamAuth{}).IsLocked(now))
+	assert.False(t, (&models.IamAuth{LockedUntil: &past}).IsLocked(now))
+	assert.True(t, (&models.IamAuth{LockedUntil: &future}).IsLocked(now))
+}
+
+// TestFailUnknownEmail_MatchesWrongPassword checks that an unknown email cannot be told apart
+// from a wrong password, and that unknown emails are locked out like real accounts
+func TestFailUnknownEmail_MatchesWrongPassword(t *testing.T) {
+	service := UserService{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // skip the progressive delays
+
+	response := service.failUnknownEmail(ctx, "nobody@example.com", "guess", "198.51.100.1")
+	assert.Equal(t, http.StatusUnauthorized, response.HTTPStatus)
+	assert.Equal(t, invalidCredentials().MessageTitle, response.MessageTitle)
+
+	for i := 1; i < LoginLockoutAfter; i++ {
+		service.failUnknownEmail(ctx, "Nobody@example.com", "guess", "198.51.100.1")
+	}
+
+	response = service.failUnknownEmail(ctx, "nobody@example.com", "guess", "198.51.100.1")
+	assert.Equal(t, http.StatusLocked, response.HTTPStatus)
+}
+
+// accountStore stands in for the database holding a single account. It answers the queries of a
+// password login and keeps the failed attempts and lockout the way the SQL updates them.
+type accountStore struct {
+	mu           sync.Mutex
+	userID       uuid.UUID
+	email        string
+	passwordHash string
+	attempts     int
+	lastFailed   *time.Time
+	lockedUntil  *time.Time
+}
+
+func (s *accountStore) query(query string, args []driver.NamedValue) ([]string, [][]driver.Value) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case strings.HasPrefix(query, "SELECT * FROM \"users\""):
+		for _, arg := range args {
+			if arg.Value == s.email {
+				return []string{"id", "email"}, [][]driver.Value{{s.userID.String(), s.email}}
+			}
+		}
+	case strings.HasPrefix(query, "SELECT * FROM \"iam_auths\""):
+		return []string{"id", "user_id", "password", "failed_login_attempts", "last_failed_login_at", "locked_until"},
+			[][]driver.Value{{uuid.NewString(), s.userID.String(), s.passwordHash, int64(s.attempts), timeValue(s.lastFailed), timeValue(s.lockedUntil)}}
+	case strings.HasPrefix(query, "UPDATE \"iam_auths\""):
+		// the CASE of RecordFailedLogin: failures before since are forgotten
+		var since, at time.Time
+		for _, arg := range args {
+			if value, ok := arg.Value.(time.Time); ok {
+				if since.IsZero() || value.Before(since) {
+					since = value
+				}
+				if value.After(at) {
+					at = value
+				}
+			}
+		}
+		forgets := strings.Contains(query, "CASE WHEN last_failed_login_at >")
+		if !forgets || (s.lastFailed != nil && s.lastFailed.After(since)) {
+			s.attempts++
+		} else {
+			s.attempts = 1
+		}
+		s.lastFailed = &at
+		return []string{"failed_login_attempts"}, [][]driver.Value{{int64(s.attempts)}}
+	}
+	return []string{"id"}, nil
+}
+
+func (s *accountStore) exec(query string, args []driver.NamedValue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if strings.HasPrefix(query, "UPDATE \"iam_auths\" SET \"locked_until\"") {
+		until := args[0].Value.(time.Time)
+		s.lockedUntil = &until
+	}
+}
+
+// expire moves the account's failures and lockout a whole lockout period into the past
+func (s *accountStore) expire() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	shift := -(LoginLockoutPeriod + time.Minute)
+	if s.lastFailed != nil {
+		last := s.lastFailed.Add(shift)
+		s.lastFailed = &last
+	}
+	if s.lockedUntil != nil {
+		until := s.lockedUntil.Add(shift)
+		s.lockedUntil = &until
+	}
+}
+
+func timeValue(t *time.Time) driver.Value {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
+
+type accountConn struct{ store *accountStore }
+
+func (c accountConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c accountConn) Close() error                        { return nil }
+func (c accountConn) Begin() (driver.Tx, error)           { return c, nil }
+func (c accountConn) Commit() error                       { return nil }
+func (c accountConn) Rollback() error                     { return nil }
+
+func (c accountConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.store.exec(query, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (c accountConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	columns, rows := c.store.query(query, args)
+	return &accountRows{columns: columns, rows: rows}, nil
+}
+
+type accountRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *accountRows) Columns() []string { return r.columns }
+func (r *accountRows) Close() error      { return nil }
+func (r *accountRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+type accountConnector struct{ store *accountStore }
+
+func (c accountConnector) Connect(context.Context) (driver.Conn, error) { return accountConn(c), nil }
+func (c accountConnector) Driver() driver.Driver                        { return nil }
+
+func newLockoutService(t *testing.T, email string) (*UserService, *accountStore) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct-password"), bcrypt.MinCost)
+	require.NoError(t, err)
+	store := &accountStore{userID: uuid.New(), email: email, passwordHash: string(hash)}
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(accountConnector{store})}), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	return &UserService{UserRepo: repos.NewUserRepository(db), AuthRepo: repos.NewAuthRepository(db)}, store
+}
+
+// failLogins tries a wrong password n times and returns the status of each answer
+func failLogins(ctx context.Context, service *UserService, email, ip string, n int) []int {
+	statuses := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		response := service.LoginUser(ctx, &userDTO.LoginRequestDTO{Email: email, Password: "guess"}, ip)
+		statuses = append(statuses, response.HTTPStatus)
+	}
+	return statuses
+}
+
+func TestLoginUser_LockoutExpiresWithoutRelocking(t *testing.T) {
+	service, store := newLockoutService(t, "locked@example.com")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // skip the progressive delays
+
+	failLogins(ctx, service, store.email, "198.51.100.2", LoginLockoutAfter)
+	require.NotNil(t, store.lockedUntil)
+	assert.Equal(t, []int{http.StatusLocked}, failLogins(ctx, service, store.email, "198.51.100.2", 1))
+
+	store.expire()
+
+	assert.Equal(t, []int{http.StatusUnauthorized}, failLogins(ctx, service, store.email, "198.51.100.2", 1))
+	assert.Equal(t, 1, store.attempts)
+	assert.False(t, (&models.IamAuth{LockedUntil: store.lockedUntil}).IsLocked(time.Now()))
+}
+
+// TestLoginUser_UnknownEmailLocksLikeAccount checks that an account and an unknown email get the
+// same answers through a lockout, its expiry and the next lockout
+func TestLoginUser_UnknownEmailLocksLikeAccount(t *testing.T) {
+	service, store := newLockoutService(t, "member@example.com")
+	unknown := "stranger@example.com"
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	account := failLogins(ctx, service, store.email, "198.51.100.3", LoginLockoutAfter+1)
+	stranger := failLogins(ctx, service, unknown, "198.51.100.4", LoginLockoutAfter+1)
+	assert.Equal(t, http.StatusLocked, account[LoginLockoutAfter])
+	assert.Equal(t, account, stranger)
+
+	store.expire()
+	ratelimit.Default().Reset(ctx, loginUnknownEmailLocks, unknown)
+
+	account = failLogins(ctx, service, store.email, "198.51.100.3", LoginLockoutAfter+1)
+	stranger = failLogins(ctx, service, unknown, "198.51.100.4", LoginLockoutAfter+1)
+	assert.Equal(t, http.StatusUnauthorized, account[0])
+	assert.Equal(t, http.StatusLocked, account[LoginLockoutAfter])
+	assert.Equal(t, account, stranger)
+}
+
+// TestLoginUser_IPLimitIgnoresForwardedFor checks that a client rotating X-Forwarded-For keeps
+// adding to the failures counted against its own IP
+func TestLoginUser_IPLimitIgnoresForwardedFor(t *testing.T) {
+	service, _ := newLockoutService(t, "member@example.com")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	e := echo.New()
+	e.IPExtractor = ratelimit.IPExtractor(nil)
+
+	login := func(attempt int) base.Response {
+		req := httptest.NewRequest(http.MethodPost, "/auth/login", nil)
+		req.RemoteAddr = "198.51.100.5:4242"
+		req.Header.Set(echo.HeaderXForwardedFor, fmt.Sprintf("203.0.113.%d", attempt%250))
+		ip := e.NewContext(req, httptest.NewRecorder()).RealIP()
+		email := fmt.Sprintf("guess-%d@example.com", attempt)
+		return service.LoginUser(ctx, &userDTO.LoginRequestDTO{Email: email, Password: "guess"}, ip)
+	}
+
+	for attempt := 0; attempt < LoginIPFailureLimit; attempt++ {
+		require.Equal(t, http.StatusUnauthorized, login(attempt).HTTPStatus)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, login(LoginIPFailureLimit).HTTPStatus)
+}
@@ -3,33 +3,43 @@ package users
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/Al-Khaimah/khaimah-golang-backend/config"
 	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/logger"
 	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/utils"
+	audit "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/audit/enums"
+	auditService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/audit/services"
+	referralService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/referrals/services"
 	userDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/dtos"
+	users "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/enums"
 	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/models"
 	repos "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/repositories"
 )
 
 // OTPService handles OTP-related operations
 type OTPService struct {
-	UserRepo  *repos.UserRepository
-	AuthRepo  *repos.AuthRepository
-	JWTSecret []byte
+	UserRepo      *repos.UserRepository
+	AuthRepo      *repos.AuthRepository
+	TwoFactorRepo *repos.TwoFactorRepository
+	Config        *config.Config
+	Referrals     *referralService.ReferralService
 }
 
 // NewOTPService creates a new OTP service
-func NewOTPService(userRepo *repos.UserRepository, authRepo *repos.AuthRepository, jwtSecret []byte) *OTPService {
+func NewOTPService(userRepo *repos.UserRepository, authRepo *repos.AuthRepository, referrals *referralService.ReferralService, cfg *config.Config) *OTPService {
 	return &OTPService{
-		UserRepo:  userRepo,
-		AuthRepo:  authRepo,
-		JWTSecret: jwtSecret,
+		UserRepo:      userRepo,
+		AuthRepo:      authRepo,
+		TwoFactorRepo: repos.NewTwoFactorRepository(userRepo.DB),
+		Config:        cfg,
+		Referrals:     referrals,
 	}
 }
 
 // SendOTP sends an OTP to the provided email or mobile number
-func (s *OTPService) SendOTP(req *userDTO.SendOTPRequestDTO) base.Response {
-	ctx := context.Background()
+func (s *OTPService) SendOTP(ctx context.Context, req *userDTO.SendOTPRequestDTO) base.Response {
 	if req.Email == "" && req.Mobile == "" {
 		return base.SetErrorMessage("email or mobile is required")
 	}
@@ -52,6 +62,12 @@ func (s *OTPService) SendOTP(req *userDTO.SendOTPRequestDTO) base.Response {
 		return base.SetErrorMessage("خطأ في قاعدة البيانات")
 	}
 
+	if existingUser != nil {
+		if response, restricted := AccountRestricted(existingUser, time.Now()); restricted {
+			return response
+		}
+	}
+
 	otp := utils.GenerateOTP()
 
 	if err := utils.StoreOTP(ctx, identifier, otp); err != nil {
@@ -75,9 +91,11 @@ func (s *OTPService) SendOTP(req *userDTO.SendOTPRequestDTO) base.Response {
 		if req.Email != "" {
 			newUser.Mobile = ""
 			newUser.Email = req.Email
+			newUser.SignupMethod = users.SignupMethodEmailOTP
 		} else if req.Mobile != "" {
 			newUser.Email = fmt.Sprintf("mobile_%s@placeholder.com", req.Mobile)
 			newUser.Mobile = req.Mobile
+			newUser.SignupMethod = users.SignupMethodMobileOTP
 		}
 
 		createdUser, err := s.UserRepo.CreateUser(newUser)
@@ -93,14 +111,20 @@ func (s *OTPService) SendOTP(req *userDTO.SendOTPRequestDTO) base.Response {
 		if err := s.AuthRepo.CreateUserAuth(newUserAuth); err != nil {
 			return base.SetErrorMessage("فشل في إنشاء توثيق المستخدم")
 		}
+
+		if err := s.Referrals.LinkReferral(createdUser.ID, req.ReferralCode); err != nil {
+			logger.FromContext(ctx).Error("Failed to link referral", "new_user_id", createdUser.ID, "error", err)
+		}
+
+		notifyNewUser(ctx, s.Config.Messaging.SlackWebhookURL, createdUser)
 	}
 
 	var sendErr error
 	if req.Email != "" {
-		sendErr = utils.SendEmailOTP(req.Email, otp, req.FirstName)
+		sendErr = utils.SendEmailOTP(ctx, s.Config.Messaging, req.Email, otp, req.FirstName)
 	} else {
 		formattedMobile := utils.FormatMobileNumber(req.Mobile)
-		sendErr = utils.SendMobileOTP(formattedMobile, otp, req.FirstName)
+		sendErr = utils.SendMobileOTP(ctx, s.Config.Messaging, formattedMobile, otp, req.FirstName)
 	}
 
 	if sendErr != nil {
@@ -111,8 +135,7 @@ func (s *OTPService) SendOTP(req *userDTO.SendOTPRequestDTO) base.Response {
 }
 
 // VerifyOTP verifies the OTP and returns a JWT token if valid
-func (s *OTPService) VerifyOTP(req *userDTO.VerifyOTPRequestDTO) base.Response {
-	ctx := context.Background()
+func (s *OTPService) VerifyOTP(ctx context.Context, req *userDTO.VerifyOTPRequestDTO) base.Response {
 	// Validate that either email or mobile is provided
 	if req.Email == "" && req.Mobile == "" {
 		return base.SetErrorMessage("email or mobile is required")
@@ -150,21 +173,43 @@ func (s *OTPService) VerifyOTP(req *userDTO.VerifyOTPRequestDTO) base.Response {
 	}
 
 	_ = utils.DeleteOTP(ctx, identifier)
+	auditService.Record(ctx, auditService.Entry{
+		Action:     audit.ActionOTPVerified,
+		ActorID:    &user.ID,
+		TargetType: audit.TargetUser,
+		TargetID:   user.ID.String(),
+	})
+
+	if response, restricted := AccountRestricted(user, time.Now()); restricted {
+		return response
+	}
+	if response, challenged := startTwoFactorChallenge(ctx, s.TwoFactorRepo, user.ID); challenged {
+		return response
+	}
+
+	if err := s.UserRepo.CancelPendingDeletion(user); err != nil {
+		return base.SetErrorMessage("فشل في إلغاء حذف الحساب")
+	}
 
 	userAuth, err := s.AuthRepo.FindAuthByUserID(user.ID)
 	if err != nil {
 		return base.SetErrorMessage("خطأ في التوثيق")
 	}
 
+	// Proving control of the email or mobile lifts a password login lockout
 	userAuth.IsActive = true
+	userAuth.FailedLoginAttempts = 0
+	userAuth.LastFailedLoginAt = nil
+	userAuth.LockedUntil = nil
 	if err := s.AuthRepo.UpdateAuth(userAuth); err != nil {
 		return base.SetErrorMessage("فشل في تحديث حالة المستخدم")
 	}
 
-	tokenString, err := GenerateJWT(user)
+	tokenString, expiresAt, err := GenerateJWT(user)
 	if err != nil {
 		return base.SetErrorMessage("فشل في إنشاء الرمز")
 	}
+	recordLogin(ctx, user.ID, "otp")
 
 	response := userDTO.VerifyOTPResponseDTO{
 		ID:         user.ID.String(),
@@ -173,7 +218,7 @@ func (s *OTPService) VerifyOTP(req *userDTO.VerifyOTPRequestDTO) base.Response {
 		Mobile:     user.Mobile,
 		Categories: user.Categories,
 		Token:      tokenString,
-		ExpiresAt:  "never",
+		ExpiresAt:  tokenExpiry(expiresAt),
 	}
 
 	return base.SetData(response, "تم تسجيل الدخول بنجاح")
@@ -0,0 +1,401 @@
+package users
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/config"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/logger"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/ratelimit"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/redis"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/totp"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/utils"
+	userDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/dtos"
+	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/models"
+	repos "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/repositories"
+	"github.com/google/uuid"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	TwoFactorIssuer = "Khaimah"
+
+	TwoFactorChallengeTTL = 5 * time.Minute
+	// TwoFactorChallengeAttempts wrong codes invalidate a challenge, sending the user back to login
+	TwoFactorChallengeAttempts  = 5
+	TwoFactorChallengeKeyPrefix = "2fa_challenge:"
+
+	recoveryCodeCount    = 10
+	recoveryCodeLength   = 10
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+	qrCodeSize           = 256
+)
+
+var twoFactorChallengeFailures = ratelimit.Counter{Name: "2fa-challenge-failures", Window: TwoFactorChallengeTTL}
+
+// TwoFactorService handles TOTP enrollment and the second step of login
+type TwoFactorService struct {
+	UserRepo      *repos.UserRepository
+	AuthRepo      *repos.AuthRepository
+	TwoFactorRepo *repos.TwoFactorRepository
+	Config        *config.Config
+}
+
+func NewTwoFactorService(userRepo *repos.UserRepository, authRepo *repos.AuthRepository, twoFactorRepo *repos.TwoFactorRepository, cfg *config.Config) *TwoFactorService {
+	return &TwoFactorService{
+		UserRepo:      userRepo,
+		AuthRepo:      authRepo,
+		TwoFactorRepo: twoFactorRepo,
+		Config:        cfg,
+	}
+}
+
+// GetStatus reports whether two-factor authentication is enabled and whether the user must enable it
+func (s *TwoFactorService) GetStatus(userID string) base.Response {
+	user, twoFactor, response, ok := s.loadUser(userID)
+	if !ok {
+		return response
+	}
+
+	status := userDTO.TwoFactorStatusDTO{
+		Enabled:  twoFactor.IsEnabled(),
+		Required: len(user.Roles) > 0,
+	}
+	if status.Enabled {
+		remaining, err := s.TwoFactorRepo.CountUnusedRecoveryCodes(user.ID)
+		if err != nil {
+			return base.SetErrorMessage("خطأ في قاعدة البيانات")
+		}
+		status.RecoveryCodesRemaining = remaining
+	}
+
+	return base.SetData(status)
+}
+
+// Enroll starts enrollment with a new secret. It is confirmed by ConfirmEnrollment.
+func (s *TwoFactorService) Enroll(userID string) base.Response {
+	user, twoFactor, response, ok := s.loadUser(userID)
+	if !ok {
+		return response
+	}
+	if twoFactor.IsEnabled() {
+		return base.SetWarningMessage("التحقق بخطوتين مفعل مسبقاً")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return base.SetErrorMessage("فشل في إنشاء مفتاح التحقق")
+	}
+	encrypted, err := utils.Encrypt(s.Config.Auth.TwoFactorKey(), secret)
+	if err != nil {
+		return base.SetErrorMessage("فشل في إنشاء مفتاح التحقق")
+	}
+	if _, err := s.TwoFactorRepo.StartEnrollment(user.ID, encrypted); err != nil {
+		return base.SetErrorMessage("فشل في بدء تفعيل التحقق بخطوتين")
+	}
+
+	account := user.Email
+	if account == "" {
+		account = user.Mobile
+	}
+	uri := totp.URI(TwoFactorIssuer, account, secret)
+
+	png, err := qrcode.Encode(uri, qrcode.Medium, qrCodeSize)
+	if err != nil {
+		return base.SetErrorMessage("فشل في إنشاء رمز QR")
+	}
+
+	return base.SetData(userDTO.TwoFactorEnrollmentDTO{
+		Secret:     secret,
+		OTPAuthURI: uri,
+		QRCode:     "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	}, "امسح رمز QR بتطبيق المصادقة ثم أدخل الرمز لتأكيد التفعيل")
+}
+
+// ConfirmEnrollment enables two-factor authentication once the first code checks out, and
+// returns the recovery codes
+func (s *TwoFactorService) ConfirmEnrollment(userID string, dto userDTO.TwoFactorCodeDTO) base.Response {
+	_, twoFactor, response, ok := s.loadUser(userID)
+	if !ok {
+		return response
+	}
+	if twoFactor == nil {
+		return base.SetErrorMessage("لم يتم بدء تفعيل التحقق بخطوتين")
+	}
+	if twoFactor.IsEnabled() {
+		return base.SetWarningMessage("التحقق بخطوتين مفعل مسبقاً")
+	}
+
+	secret, err := utils.Decrypt(s.Config.Auth.TwoFactorKey(), twoFactor.Secret)
+	if err != nil {
+		return base.SetErrorMessage("فشل في قراءة مفتاح التحقق")
+	}
+	step, valid := totp.Validate(secret, dto.Code, time.Now())
+	if !valid {
+		return invalidTwoFactorCode()
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return base.SetErrorMessage("فشل في إنشاء رموز الاسترداد")
+	}
+	if err := s.TwoFactorRepo.Enable(twoFactor, step, hashes); err != nil {
+		return base.SetErrorMessage("فشل في تفعيل التحقق بخطوتين")
+	}
+
+	return base.SetData(userDTO.TwoFactorRecoveryCodesDTO{RecoveryCodes: codes}, "تم تفعيل التحقق بخطوتين، احفظ رموز الاسترداد في مكان آمن")
+}
+
+// Disable turns two-factor authentication off after checking a current code. Staff cannot
+// turn it off because RequirePermission insists on it.
+func (s *TwoFactorService) Disable(userID string, dto userDTO.TwoFactorCodeDTO) base.Response {
+	user, twoFactor, response, ok := s.loadUser(userID)
+	if !ok {
+		return response
+	}
+	if !twoFactor.IsEnabled() {
+		return base.SetErrorMessage("التحقق بخطوتين غير مفعل")
+	}
+	if len(user.Roles) > 0 {
+		response := base.SetErrorMessage("التحقق بخطوتين إلزامي لحسابات المشرفين")
+		response.HTTPStatus = http.StatusForbidden
+		return response
+	}
+
+	valid, err := s.verifyCode(twoFactor, dto.Code)
+	if err != nil {
+		return base.SetErrorMessage("فشل في التحقق من الرمز")
+	}
+	if !valid {
+		return invalidTwoFactorCode()
+	}
+
+	if err := s.TwoFactorRepo.Delete(user.ID); err != nil {
+		return base.SetErrorMessage("فشل في إيقاف التحقق بخطوتين")
+	}
+	return base.SetSuccessMessage("تم إيقاف التحقق بخطوتين")
+}
+
+// RegenerateRecoveryCodes replaces every recovery code after checking a current code
+func (s *TwoFactorService) RegenerateRecoveryCodes(userID string, dto userDTO.TwoFactorCodeDTO) base.Response {
+	user, twoFactor, response, ok := s.loadUser(userID)
+	if !ok {
+		return response
+	}
+	if !twoFactor.IsEnabled() {
+		return base.SetErrorMessage("التحقق بخطوتين غير مفعل")
+	}
+
+	valid, err := s.verifyCode(twoFactor, dto.Code)
+	if err != nil {
+		return base.SetErrorMessage("فشل في التحقق من الرمز")
+	}
+	if !valid {
+		return invalidTwoFactorCode()
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return base.SetErrorMessage("فشل في إنشاء رموز الاسترداد")
+	}
+	if err := s.TwoFactorRepo.ReplaceRecoveryCodes(user.ID, hashes); err != nil {
+		return base.SetErrorMessage("فشل في إنشاء رموز الاسترداد")
+	}
+
+	return base.SetData(userDTO.TwoFactorRecoveryCodesDTO{RecoveryCodes: codes}, "تم إنشاء رموز استرداد جديدة، والرموز السابقة لم تعد صالحة")
+}
+
+// CompleteChallenge finishes a login that stopped at the two-factor step and issues the token
+func (s *TwoFactorService) CompleteChallenge(ctx context.Context, dto userDTO.TwoFactorChallengeRequestDTO) base.Response {
+	key := TwoFactorChallengeKeyPrefix + dto.ChallengeToken
+	rawUserID, err := redis.Get(ctx, key)
+	if errors.Is(err, goredis.Nil) {
+		response := base.SetErrorMessage("انتهت صلاحية طلب التحقق، يرجى تسجيل الدخول مجدداً")
+		response.HTTPStatus = http.StatusUnauthorized
+		return response
+	}
+	if err != nil {
+		return base.SetErrorMessage("فشل في قراءة طلب التحقق")
+	}
+
+	userID, err := uuid.Parse(rawUserID)
+	if err != nil {
+		return base.SetErrorMessage("طلب التحقق غير صالح")
+	}
+	twoFactor, err := s.TwoFactorRepo.FindByUserID(userID)
+	if err != nil {
+		return base.SetErrorMessage("خطأ في قاعدة البيانات")
+	}
+	if !twoFactor.IsEnabled() {
+		return base.SetErrorMessage("التحقق بخطوتين غير مفعل")
+	}
+
+	valid, err := s.verifyCode(twoFactor, dto.Code)
+	if err != nil {
+		return base.SetErrorMessage("فشل في التحقق من الرمز")
+	}
+	if !valid {
+		limiter := ratelimit.Default()
+		if limiter.Increment(ctx, twoFactorChallengeFailures, dto.ChallengeToken) >= TwoFactorChallengeAttempts {
+			_ = redis.Delete(ctx, key)
+			logger.FromContext(ctx).Warn("Two-factor challenge abandoned after repeated wrong codes", "user_id", userID)
+		}
+		return invalidTwoFactorCode()
+	}
+	_ = redis.Delete(ctx, key)
+
+	user, err := s.UserRepo.FindOneByID(userID)
+	if err != nil || user == nil {
+		return base.SetErrorMessage("المستخدم غير موجود")
+	}
+	// The account may have been restricted while the challenge was pending
+	if response, restricted := AccountRestricted(user, time.Now()); restricted {
+		return response
+	}
+	if err := s.UserRepo.CancelPendingDeletion(user); err != nil {
+		return base.SetErrorMessage("فشل في إلغاء حذف الحساب")
+	}
+
+	userAuth, err := s.AuthRepo.FindAuthByUserID(user.ID)
+	if err != nil {
+		return base.SetErrorMessage("خطأ في التوثيق")
+	}
+	userAuth.IsActive = true
+	userAuth.FailedLoginAttempts = 0
+	userAuth.LastFailedLoginAt = nil
+	userAuth.LockedUntil = nil
+	if err := s.AuthRepo.UpdateAuth(userAuth); err != nil {
+		return base.SetErrorMessage("فشل في تحديث التوثيق")
+	}
+
+	token, expiresAt, err := GenerateMFAJWT(user)
+	if err != nil {
+		return base.SetErrorMessage("فشل في إنشاء الرمز")
+	}
+	recordLogin(ctx, user.ID, "two_factor")
+
+	return base.SetData(userDTO.TwoFactorLoginResponseDTO{
+		ID:         user.ID.String(),
+		FirstName:  user.FirstName,
+		LastName:   user.LastName,
+		Email:      user.Email,
+		Mobile:     user.Mobile,
+		Categories: user.Categories,
+		Token:      token,
+		ExpiresAt:  tokenExpiry(expiresAt),
+	}, "تم تسجيل الدخول بنجاح")
+}
+
+func (s *TwoFactorService) loadUser(userID string) (*models.User, *models.TwoFactor, base.Response, bool) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, nil, base.SetErrorMessage("الرقم التعريفي للمستخدم غير صالح"), false
+	}
+	user, err := s.UserRepo.FindOneByID(uid)
+	if err != nil {
+		return nil, nil, base.SetErrorMessage("خطأ في قاعدة البيانات"), false
+	}
+	if user == nil {
+		return nil, nil, base.SetErrorMessage("المستخدم غير موجود"), false
+	}
+	twoFactor, err := s.TwoFactorRepo.FindByUserID(uid)
+	if err != nil {
+		return nil, nil, base.SetErrorMessage("خطأ في قاعدة البيانات"), false
+	}
+	return user, twoFactor, base.Response{}, true
+}
+
+// verifyCode accepts a TOTP code that has not been used yet, or an unused recovery code
+func (s *TwoFactorService) verifyCode(twoFactor *models.TwoFactor, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		secret, err := utils.Decrypt(s.Config.Auth.TwoFactorKey(), twoFactor.Secret)
+		if err != nil {
+			return false, err
+		}
+		step, valid := totp.Validate(secret, code, time.Now())
+		if !valid {
+			return false, nil
+		}
+		return s.TwoFactorRepo.UseStep(twoFactor.ID, step)
+	}
+
+	return s.TwoFactorRepo.UseRecoveryCode(twoFactor.UserID, hashRecoveryCode(code))
+}
+
+// startTwoFactorChallenge stops a login at the two-factor step when the user has it enabled.
+// It reports false when the login can go ahead and issue a token.
+func startTwoFactorChallenge(ctx context.Context, twoFactorRepo *repos.TwoFactorRepository, userID uuid.UUID) (base.Response, bool) {
+	enabled, err := twoFactorRepo.IsEnabled(userID)
+	if err != nil {
+		return base.SetErrorMessage("خطأ في قاعدة البيانات"), true
+	}
+	if !enabled {
+		return base.Response{}, false
+	}
+
+	challenge := make([]byte, 32)
+	if _, err := rand.Read(challenge); err != nil {
+		return base.SetErrorMessage("فشل في إنشاء طلب التحقق"), true
+	}
+	token := hex.EncodeToString(challenge)
+	if err := redis.SetWithTTL(ctx, TwoFactorChallengeKeyPrefix+token, userID.String(), TwoFactorChallengeTTL); err != nil {
+		logger.FromContext(ctx).Error("Failed to store two-factor challenge", "user_id", userID, "error", err)
+		return base.SetErrorMessage("فشل في إنشاء طلب التحقق"), true
+	}
+
+	response := base.SetData(userDTO.TwoFactorChallengeDTO{
+		TwoFactorRequired: true,
+		ChallengeToken:    token,
+		ExpiresIn:         int(TwoFactorChallengeTTL.Seconds()),
+	}, "أدخل رمز التحقق من تطبيق المصادقة لإكمال تسجيل الدخول")
+	response.HTTPStatus = http.StatusAccepted
+	return response, true
+}
+
+func invalidTwoFactorCode() base.Response {
+	response := base.SetErrorMessage("رمز التحقق غير صحيح")
+	response.HTTPStatus = http.StatusUnauthorized
+	return response
+}
+
+// generateRecoveryCodes returns codes formatted for display, such as k7m2q-x9pw4, and their hashes
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	alphabetSize := big.NewInt(int64(len(recoveryCodeAlphabet)))
+
+	for i := range codes {
+		var code strings.Builder
+		for j := 0; j < recoveryCodeLength; j++ {
+			if j == recoveryCodeLength/2 {
+				code.WriteByte('-')
+			}
+			n, err := rand.Int(rand.Reader, alphabetSize)
+			if err != nil {
+				return nil, nil, err
+			}
+			code.WriteByte(recoveryCodeAlphabet[n.Int64()])
+		}
+		codes[i] = code.String()
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode ignores case, spaces and dashes so codes can be typed as displayed or not
+func hashRecoveryCode(code string) string {
+	normalized := strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
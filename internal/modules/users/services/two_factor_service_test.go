@@ -0,0 +1,47 @@
+package users
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, hashes, err := generateRecoveryCodes()
+
+	require.NoError(t, err)
+	require.Len(t, codes, recoveryCodeCount)
+	require.Len(t, hashes, recoveryCodeCount)
+
+	format := regexp.MustCompile(`^[a-z2-9]{5}-[a-z2-9]{5}$`)
+	seen := make(map[string]bool)
+	for i, code := range codes {
+		assert.Regexp(t, format, code)
+		assert.Equal(t, hashRecoveryCode(code), hashes[i])
+		assert.False(t, seen[code], "duplicate recovery code")
+		seen[code] = true
+	}
+}
+
+func TestHashRecoveryCode_IgnoresFormatting(t *testing.T) {
+	assert.Equal(t, hashRecoveryCode("k7m2q-x9pw4"), hashRecoveryCode(" K7M2Q X9PW4 "))
+	assert.Equal(t, hashRecoveryCode("k7m2q-x9pw4"), hashRecoveryCode(strings.ReplaceAll("k7m2q-x9pw4", "-", "")))
+	assert.NotEqual(t, hashRecoveryCode("k7m2q-x9pw4"), hashRecoveryCode("k7m2q-x9pw5"))
+}
+
+func TestTwoFactorSecretEncryption(t *testing.T) {
+	encrypted, err := utils.Encrypt("key", "JBSWY3DPEHPK3PXP")
+	require.NoError(t, err)
+	assert.NotContains(t, encrypted, "JBSWY3DPEHPK3PXP")
+
+	secret, err := utils.Decrypt("key", encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", secret)
+
+	_, err = utils.Decrypt("other-key", encrypted)
+	assert.Error(t, err)
+}
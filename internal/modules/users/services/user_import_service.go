@@ -0,0 +1,469 @@
+package users
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/jobs"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/logger"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/utils"
+	audit "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/audit/enums"
+	auditService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/audit/services"
+	categoryModels "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/categories/models"
+	categoryRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/categories/repositories"
+	userDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/dtos"
+	users "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/enums"
+	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/models"
+	repos "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/repositories"
+	"github.com/google/uuid"
+)
+
+const (
+	UserImportJobName = "users.import"
+	// MaxImportFileSize and MaxImportRows bound a single CSV import
+	MaxImportFileSize = 2 << 20
+	MaxImportRows     = 5000
+
+	// importCategorySeparator separates the category IDs of a row, so the column needs no quoting
+	importCategorySeparator = ";"
+	importNameMaxLength     = 100
+)
+
+// validImportMobile matches a normalized mobile number: digits only, with the country code
+var validImportMobile = regexp.MustCompile(`^[1-9][0-9]{9,14}$`)
+
+// importRow is a well-formed row of an import file
+type importRow struct {
+	Line        int
+	Mobile      string
+	FirstName   string
+	LastName    string
+	CategoryIDs []uuid.UUID
+}
+
+type UserImportService struct {
+	UserRepo     *repos.UserRepository
+	ImportRepo   *repos.ImportRepository
+	CategoryRepo *categoryRepository.CategoryRepository
+}
+
+func NewUserImportService(userRepo *repos.UserRepository, importRepo *repos.ImportRepository, categoryRepo *categoryRepository.CategoryRepository) *UserImportService {
+	return &UserImportService{
+		UserRepo:     userRepo,
+		ImportRepo:   importRepo,
+		CategoryRepo: categoryRepo,
+	}
+}
+
+// ImportUsers checks a CSV of users to pre-provision. A dry run answers with the report of what
+// the import would do; otherwise the file is saved and its users created by a background job.
+func (s *UserImportService) ImportUsers(adminID, fileName string, content []byte, dryRun bool) base.Response {
+	createdBy, err := uuid.Parse(adminID)
+	if err != nil {
+		response := base.SetErrorMessage("غير مصرح به")
+		response.HTTPStatus = http.StatusUnauthorized
+		return response
+	}
+
+	rows, report, err := parseImport(content)
+	if err != nil {
+		return base.SetErrorMessage("ملف الاستيراد غير صالح", err.Error())
+	}
+
+	if dryRun {
+		totalRows := len(rows) + len(report)
+		toCreate, checked, err := s.checkRows(rows)
+		if err != nil {
+			response := base.SetErrorMessage("فشل في التحقق من ملف الاستيراد", err)
+			response.HTTPStatus = http.StatusInternalServerError
+			return response
+		}
+		report = sortReport(append(report, checked...))
+
+		result := userDTO.UserImportDTO{
+			FileName:     fileName,
+			DryRun:       true,
+			TotalRows:    totalRows,
+			CreatedCount: len(toCreate),
+			Rows:         report,
+		}
+		result.SkippedCount, result.FailedCount = countOutcomes(report)
+		return base.SetData(result)
+	}
+
+	userImport := &models.UserImport{
+		CreatedBy: createdBy,
+		FileName:  fileName,
+		Status:    users.ImportStatusPending,
+		Content:   string(content),
+		TotalRows: len(rows) + len(report),
+	}
+	if err := s.ImportRepo.CreateImport(userImport); err != nil {
+		return base.SetErrorMessage("فشل في حفظ ملف الاستيراد")
+	}
+
+	if err := jobs.Enqueue(UserImportJobName, userImport.ID.String()); err != nil {
+		userImport.Status = users.ImportStatusFailed
+		userImport.Error = err.Error()
+		_ = s.ImportRepo.UpdateImport(userImport)
+		return base.SetErrorMessage("فشل في جدولة الاستيراد")
+	}
+
+	return base.SetData(mapUserImportDTO(userImport), "تم استلام ملف الاستيراد وستتم معالجته في الخلفية")
+}
+
+// GetImport returns the progress of an import, and its row report once it is processed
+func (s *UserImportService) GetImport(importID string) base.Response {
+	id, err := uuid.Parse(importID)
+	if err != nil {
+		return base.SetErrorMessage("الرقم التعريفي للاستيراد غير صالح")
+	}
+
+	userImport, err := s.ImportRepo.FindImportByID(id)
+	if err != nil {
+		return base.SetErrorMessage("خطأ في قاعدة البيانات")
+	}
+	if userImport == nil {
+		response := base.SetErrorMessage("لم يتم العثور على الاستيراد")
+		response.HTTPStatus = http.StatusNotFound
+		return response
+	}
+
+	return base.SetData(mapUserImportDTO(userImport))
+}
+
+// ResumePendingImports re-enqueues imports that were interrupted by a restart
+func (s *UserImportService) ResumePendingImports() {
+	ids, err := s.ImportRepo.FindImportIDsByStatus(users.ImportStatusPending, users.ImportStatusProcessing)
+	if err != nil {
+		return
+	}
+	for _, id := range ids {
+		_ = jobs.Enqueue(UserImportJobName, id.String())
+	}
+}
+
+// ProcessImport is the job handler that creates the users of an import. Rows are checked again,
+// since accounts and categories may have changed since the file was uploaded.
+func (s *UserImportService) ProcessImport(ctx context.Context, payload string) error {
+	importID, err := uuid.Parse(payload)
+	if err != nil {
+		return fmt.Errorf("invalid import id: %w", err)
+	}
+
+	userImport, err := s.ImportRepo.FindImportByID(importID)
+	if err != nil {
+		return err
+	}
+	if userImport == nil {
+		return fmt.Errorf("user import %s not found", importID)
+	}
+	if userImport.Status == users.ImportStatusCompleted || userImport.Status == users.ImportStatusFailed {
+		return nil
+	}
+
+	userImport.Status = users.ImportStatusProcessing
+	if err := s.ImportRepo.UpdateImport(userImport); err != nil {
+		return err
+	}
+
+	report, created, err := s.createUsers(userImport)
+	if err != nil {
+		userImport.Status = users.ImportStatusFailed
+		userImport.Error = err.Error()
+		_ = s.ImportRepo.UpdateImport(userImport)
+		return err
+	}
+
+	rowReport, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to encode import report: %w", err)
+	}
+	now := time.Now()
+	userImport.Status = users.ImportStatusCompleted
+	userImport.CreatedCount = created
+	userImport.SkippedCount, userImport.FailedCount = countOutcomes(report)
+	userImport.RowReport = rowReport
+	userImport.Content = ""
+	userImport.CompletedAt = &now
+	if err := s.ImportRepo.UpdateImport(userImport); err != nil {
+		return err
+	}
+
+	auditService.Record(ctx, auditService.Entry{
+		Action:     audit.ActionUsersImported,
+		ActorID:    &userImport.CreatedBy,
+		TargetType: audit.TargetUserImport,
+		TargetID:   userImport.ID.String(),
+		Metadata: map[string]interface{}{
+			"file_name":     userImport.FileName,
+			"total_rows":    userImport.TotalRows,
+			"created_count": userImport.CreatedCount,
+			"skipped_count": userImport.SkippedCount,
+			"failed_count":  userImport.FailedCount,
+		},
+	})
+	logger.FromContext(ctx).Info("Processed user import", "import_id", userImport.ID, "created", created, "skipped", userImport.SkippedCount, "failed", userImport.FailedCount)
+	return nil
+}
+
+func (s *UserImportService) createUsers(userImport *models.UserImport) ([]userDTO.ImportRowDTO, int, error) {
+	rows, report, err := parseImport([]byte(userImport.Content))
+	if err != nil {
+		return nil, 0, err
+	}
+	toCreate, checked, err := s.checkRows(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	report = append(report, checked...)
+
+	created := 0
+	for _, row := range toCreate {
+		categories := make([]categoryModels.Category, len(row.CategoryIDs))
+		for i, id := range row.CategoryIDs {
+			categories[i] = categoryModels.Category{Model: base.Model{ID: id}}
+		}
+
+		ok, err := s.UserRepo.CreateImportedUser(&models.User{
+			FirstName:    row.FirstName,
+			LastName:     row.LastName,
+			Mobile:       row.Mobile,
+			Email:        fmt.Sprintf("mobile_%s@placeholder.com", row.Mobile),
+			SignupMethod: users.SignupMethodImport,
+			Categories:   categories,
+		})
+		switch {
+		case err != nil:
+			report = append(report, userDTO.ImportRowDTO{Row: row.Line, Mobile: row.Mobile, Status: userDTO.ImportRowFailed, Errors: []string{"فشل في إنشاء المستخدم"}})
+		case !ok:
+			// The number signed up between the check and now
+			report = append(report, userDTO.ImportRowDTO{Row: row.Line, Mobile: row.Mobile, Status: userDTO.ImportRowSkipped, Errors: []string{"رقم الجوال مسجل مسبقاً"}})
+		default:
+			created++
+		}
+	}
+	return sortReport(report), created, nil
+}
+
+// checkRows splits well-formed rows into those to create and report entries for the ones whose
+// categories do not exist or whose mobile number already has an account
+func (s *UserImportService) checkRows(rows []importRow) ([]importRow, []userDTO.ImportRowDTO, error) {
+	var categoryIDs []uuid.UUID
+	mobiles := make([]string, len(rows))
+	seen := make(map[uuid.UUID]bool)
+	for i, row := range rows {
+		mobiles[i] = row.Mobile
+		for _, id := range row.CategoryIDs {
+			if !seen[id] {
+				seen[id] = true
+				categoryIDs = append(categoryIDs, id)
+			}
+		}
+	}
+
+	categories, err := s.CategoryRepo.FindCategoriesByIDs(categoryIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	known := make(map[uuid.UUID]bool, len(categories))
+	for _, category := range categories {
+		known[category.ID] = true
+	}
+	existing, err := s.UserRepo.FindExistingMobiles(mobiles)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var toCreate []importRow
+	var report []userDTO.ImportRowDTO
+	for _, row := range rows {
+		var problems []string
+		for _, id := range row.CategoryIDs {
+			if !known[id] {
+				problems = append(problems, fmt.Sprintf("التصنيف %s غير موجود", id))
+			}
+		}
+		switch {
+		case len(problems) > 0:
+			report = append(report, userDTO.ImportRowDTO{Row: row.Line, Mobile: row.Mobile, Status: userDTO.ImportRowInvalid, Errors: problems})
+		case existing[row.Mobile]:
+			report = append(report, userDTO.ImportRowDTO{Row: row.Line, Mobile: row.Mobile, Status: userDTO.ImportRowSkipped, Errors: []string{"رقم الجوال مسجل مسبقاً"}})
+		default:
+			toCreate = append(toCreate, row)
+		}
+	}
+	return toCreate, report, nil
+}
+
+// parseImport reads an import file: a header row naming at least the mobile and categories
+// columns, optionally first_name and last_name, and then one user per row. Other columns are
+// ignored, so a user export can be imported as is. It returns the well-formed rows, with mobile
+// numbers normalized, and a report entry for each row that is not. An error means the file as a
+// whole cannot be imported.
+func parseImport(content []byte) ([]importRow, []userDTO.ImportRowDTO, error) {
+	if len(content) > MaxImportFileSize {
+		return nil, nil, fmt.Errorf("file is larger than %d MB", MaxImportFileSize>>20)
+	}
+	// Spreadsheet programs often save CSVs with a byte order mark
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(content) {
+		return nil, nil, errors.New("file is not UTF-8 encoded")
+	}
+
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, errors.New("file is empty")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"mobile", "categories"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, fmt.Errorf("missing %s column", required)
+		}
+	}
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rows []importRow
+	var report []userDTO.ImportRowDTO
+	firstLine := make(map[string]int)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		if len(rows)+len(report) >= MaxImportRows {
+			return nil, nil, fmt.Errorf("file has more than %d rows", MaxImportRows)
+		}
+		line, _ := reader.FieldPos(0)
+
+		row := importRow{
+			Line:      line,
+			Mobile:    utils.FormatMobileNumber(field(record, "mobile")),
+			FirstName: field(record, "first_name"),
+			LastName:  field(record, "last_name"),
+		}
+
+		var problems []string
+		switch {
+		case row.Mobile == "":
+			problems = append(problems, "رقم الجوال مطلوب")
+		case !validImportMobile.MatchString(row.Mobile) || (strings.HasPrefix(row.Mobile, "966") && len(row.Mobile) != 12):
+			problems = append(problems, "رقم الجوال غير صالح")
+		case firstLine[row.Mobile] != 0:
+			problems = append(problems, fmt.Sprintf("رقم الجوال مكرر في السطر %d", firstLine[row.Mobile]))
+		default:
+			firstLine[row.Mobile] = line
+		}
+		if utf8.RuneCountInString(row.FirstName) > importNameMaxLength || utf8.RuneCountInString(row.LastName) > importNameMaxLength {
+			problems = append(problems, fmt.Sprintf("يجب ألا يتجاوز الاسم %d حرفاً", importNameMaxLength))
+		}
+
+		invalidCategory := false
+		for _, value := range strings.Split(field(record, "categories"), importCategorySeparator) {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				continue
+			}
+			id, err := uuid.Parse(value)
+			if err != nil {
+				invalidCategory = true
+				problems = append(problems, fmt.Sprintf("الرقم التعريفي للتصنيف %q غير صالح", value))
+				continue
+			}
+			if !containsID(row.CategoryIDs, id) {
+				row.CategoryIDs = append(row.CategoryIDs, id)
+			}
+		}
+		if len(row.CategoryIDs) == 0 && !invalidCategory {
+			problems = append(problems, "يجب اختيار تصنيف واحد على الأقل")
+		}
+
+		if len(problems) > 0 {
+			report = append(report, userDTO.ImportRowDTO{Row: line, Mobile: row.Mobile, Status: userDTO.ImportRowInvalid, Errors: problems})
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, report, nil
+}
+
+func containsID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, existing := range ids {
+		if existing == id {
+			return true
+		}
+	}
+	return false
+}
+
+func sortReport(report []userDTO.ImportRowDTO) []userDTO.ImportRowDTO {
+	sort.SliceStable(report, func(i, j int) bool { return report[i].Row < report[j].Row })
+	return report
+}
+
+func countRows(report []userDTO.ImportRowDTO, status userDTO.ImportRowStatus) int {
+	count := 0
+	for _, row := range report {
+		if row.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// countOutcomes returns how many reported rows were skipped, and how many were invalid or failed
+func countOutcomes(report []userDTO.ImportRowDTO) (skipped, failed int) {
+	skipped = countRows(report, userDTO.ImportRowSkipped)
+	return skipped, len(report) - skipped
+}
+
+func mapUserImportDTO(userImport *models.UserImport) userDTO.UserImportDTO {
+	dto := userDTO.UserImportDTO{
+		ID:           userImport.ID.String(),
+		FileName:     userImport.FileName,
+		Status:       userImport.Status,
+		TotalRows:    userImport.TotalRows,
+		CreatedCount: userImport.CreatedCount,
+		SkippedCount: userImport.SkippedCount,
+		FailedCount:  userImport.FailedCount,
+		Rows:         []userDTO.ImportRowDTO{},
+		RequestedAt:  &userImport.CreatedAt,
+		CompletedAt:  userImport.CompletedAt,
+		Error:        userImport.Error,
+	}
+	if len(userImport.RowReport) > 0 {
+		_ = json.Unmarshal(userImport.RowReport, &dto.Rows)
+	}
+	return dto
+}
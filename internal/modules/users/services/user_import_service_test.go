@@ -0,0 +1,91 @@
+package users
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	categoryModels "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/categories/models"
+	userDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/dtos"
+	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseImport_NormalizesAndReportsRows(t *testing.T) {
+	news, sports := uuid.New(), uuid.New()
+	content := "\xef\xbb\xbfMobile,First_Name,last_name,categories,id\n" +
+		"0501234567,Sara,Ali," + news.String() + ";" + sports.String() + ";" + news.String() + ",ignored\n" +
+		"+966501234567,Dup,,," + news.String() + "\n" +
+		"12ab,Bad,,not-a-uuid\n" +
+		"551234567,NoCategories,,\n" +
+		"\n" +
+		"971501234567,Omar,,\"" + sports.String() + "\"\n"
+
+	rows, report, err := parseImport([]byte(content))
+	require.NoError(t, err)
+
+	require.Len(t, rows, 2)
+	assert.Equal(t, importRow{Line: 2, Mobile: "966501234567", FirstName: "Sara", LastName: "Ali", CategoryIDs: []uuid.UUID{news, sports}}, rows[0])
+	assert.Equal(t, "971501234567", rows[1].Mobile)
+	assert.Equal(t, 7, rows[1].Line)
+
+	require.Len(t, report, 3)
+	assert.Equal(t, 3, report[0].Row)
+	assert.Contains(t, report[0].Errors[0], "2")
+	assert.Equal(t, 4, report[1].Row)
+	assert.Len(t, report[1].Errors, 2)
+	assert.Equal(t, 5, report[2].Row)
+	assert.Equal(t, "966551234567", report[2].Mobile)
+	for _, row := range report {
+		assert.Equal(t, userDTO.ImportRowInvalid, row.Status)
+	}
+}
+
+func TestParseImport_RejectsUnusableFiles(t *testing.T) {
+	for name, content := range map[string]string{
+		"empty":            "",
+		"missing column":   "mobile,first_name\n0501234567,Sara\n",
+		"not utf-8":        "mobile,categories\n\xff\xfe,x\n",
+		"too many rows":    "mobile,categories\n" + strings.Repeat("0501234567,x\n", MaxImportRows+1),
+		"too large":        "mobile,categories\n" + strings.Repeat("a", MaxImportFileSize),
+		"unbalanced quote": "mobile,categories\n\"0501234567,x\n",
+	} {
+		_, _, err := parseImport([]byte(content))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestCountOutcomes(t *testing.T) {
+	skipped, failed := countOutcomes([]userDTO.ImportRowDTO{
+		{Status: userDTO.ImportRowSkipped},
+		{Status: userDTO.ImportRowInvalid},
+		{Status: userDTO.ImportRowFailed},
+		{Status: userDTO.ImportRowSkipped},
+	})
+	assert.Equal(t, 2, skipped)
+	assert.Equal(t, 2, failed)
+}
+
+func TestExportUserRow_CanBeImportedAgain(t *testing.T) {
+	category := uuid.New()
+	row := exportUserRow(models.User{
+		FirstName:  "=HYPERLINK(\"x\")",
+		Mobile:     "966501234567",
+		Email:      "mobile_966501234567@placeholder.com",
+		Categories: []categoryModels.Category{{Model: base.Model{ID: category}}},
+	})
+
+	assert.Equal(t, "966501234567", row[0])
+	assert.Equal(t, "'=HYPERLINK(\"x\")", row[1])
+	assert.Equal(t, category.String(), row[3])
+	assert.Empty(t, row[5], "placeholder emails are not exported")
+
+	content := strings.Join(exportUsersHeader, ",") + "\n" + strings.Join([]string{row[0], "Sara", "", row[3]}, ",") + "\n"
+	rows, report, err := parseImport([]byte(content))
+	require.NoError(t, err)
+	assert.Empty(t, report)
+	require.Len(t, rows, 1)
+	assert.Equal(t, []uuid.UUID{category}, rows[0].CategoryIDs)
+}
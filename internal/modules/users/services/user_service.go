@@ -3,11 +3,23 @@ package users
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/Al-Khaimah/khaimah-golang-backend/config"
 	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/jwtkeys"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/logger"
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/ratelimit"
 	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/redis"
 	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/utils"
+	audit "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/audit/enums"
+	auditService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/audit/services"
+	referralService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/referrals/services"
+	roleRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/repositories"
+	subscriptionRepository "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/repositories"
+	subscriptionService "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/services"
 	userDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/dtos"
 	users "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/enums"
 	models "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/models"
@@ -24,17 +36,27 @@ type UserService struct {
 	UserRepo      *repos.UserRepository
 	AuthRepo      *repos.AuthRepository
 	BookmarksRepo *repos.BookmarkRepository
+	TwoFactorRepo *repos.TwoFactorRepository
+	RoleRepo      *roleRepository.RoleRepository
+	Referrals     *referralService.ReferralService
+	Entitlements  *subscriptionService.EntitlementService
+	Config        *config.Config
 }
 
-func NewUserService(userRepo *repos.UserRepository, authRepo *repos.AuthRepository, bookmarksRepo *repos.BookmarkRepository) *UserService {
+func NewUserService(userRepo *repos.UserRepository, authRepo *repos.AuthRepository, bookmarksRepo *repos.BookmarkRepository, referrals *referralService.ReferralService, cfg *config.Config) *UserService {
 	return &UserService{
 		UserRepo:      userRepo,
 		AuthRepo:      authRepo,
 		BookmarksRepo: bookmarksRepo,
+		TwoFactorRepo: repos.NewTwoFactorRepository(userRepo.DB),
+		RoleRepo:      roleRepository.NewRoleRepository(userRepo.DB),
+		Referrals:     referrals,
+		Entitlements:  subscriptionService.NewEntitlementService(subscriptionRepository.NewSubscriptionRepository(userRepo.DB)),
+		Config:        cfg,
 	}
 }
 
-func (s *UserService) CreateUser(user *userDTO.SignupRequestDTO) base.Response {
+func (s *UserService) CreateUser(ctx context.Context, user *userDTO.SignupRequestDTO) base.Response {
 	existingUser, err := s.UserRepo.FindOneByEmail(user.Email)
 	if err != nil {
 		return base.SetErrorMessage("خطأ في قاعدة البيانات")
@@ -45,10 +67,11 @@ func (s *UserService) CreateUser(user *userDTO.SignupRequestDTO) base.Response {
 
 	categories := utils.ConvertIDsToCategories(user.Categories)
 	newUser := &models.User{
-		FirstName:  user.FirstName,
-		LastName:   user.LastName,
-		Email:      utils.FormatEmail(user.Email),
-		Categories: categories,
+		FirstName:    user.FirstName,
+		LastName:     user.LastName,
+		Email:        utils.FormatEmail(user.Email),
+		SignupMethod: users.SignupMethodPassword,
+		Categories:   categories,
 	}
 
 	createdUser, err := s.UserRepo.CreateUser(newUser)
@@ -75,7 +98,11 @@ func (s *UserService) CreateUser(user *userDTO.SignupRequestDTO) base.Response {
 		return base.SetErrorMessage("فشل في إنشاء توثيق المستخدم")
 	}
 
-	token, err := GenerateJWT(createdUser)
+	if err := s.Referrals.LinkReferral(createdUser.ID, user.ReferralCode); err != nil {
+		logger.FromContext(ctx).Error("Failed to link referral", "new_user_id", createdUser.ID, "error", err)
+	}
+
+	token, expiresAt, err := GenerateJWT(createdUser)
 	if err != nil {
 		return base.SetErrorMessage("فشل في إنشاء الرمز")
 	}
@@ -86,34 +113,60 @@ func (s *UserService) CreateUser(user *userDTO.SignupRequestDTO) base.Response {
 		Email:      createdUser.Email,
 		Categories: createdUser.Categories,
 		Token:      token,
-		ExpiresAt:  "never",
+		ExpiresAt:  tokenExpiry(expiresAt),
 	}
 
-	slackMessage := fmt.Sprintf("🚀 New user account created:\n%s (%s)", createdUser.FirstName, createdUser.Email)
-	_ = utils.SendSlackNotification(slackMessage)
+	notifyNewUser(ctx, s.Config.Messaging.SlackWebhookURL, createdUser)
 
 	return base.SetData(userResponse, "تم انشاء الحساب بنجاح")
 }
 
-func (s *UserService) LoginUser(user *userDTO.LoginRequestDTO) base.Response {
+// LoginUser checks an email and password. Wrong emails and wrong passwords get the same answer,
+// and repeated failures are slowed down and then locked out per account and per IP. The IP must
+// come from the server's ratelimit.IPExtractor so that clients cannot reset their count by
+// sending a different X-Forwarded-For.
+func (s *UserService) LoginUser(ctx context.Context, user *userDTO.LoginRequestDTO, ip string) base.Response {
+	if ratelimit.Default().Count(ctx, loginIPFailures, ip) >= LoginIPFailureLimit {
+		return tooManyLoginAttempts()
+	}
+
 	existingUser, err := s.UserRepo.FindOneByEmail(user.Email)
 	if err != nil {
 		return base.SetErrorMessage("خطأ في قاعدة البيانات")
 	}
 	if existingUser == nil {
-		return base.SetErrorMessage("البريد الإلكتروني غير موجود")
+		return s.failUnknownEmail(ctx, user.Email, user.Password, ip)
 	}
 
 	userAuth, err := s.AuthRepo.FindAuthByUserID(existingUser.ID)
 	if err != nil {
 		return base.SetErrorMessage("خطأ في التوثيق")
 	}
+	if userAuth.IsLocked(time.Now()) {
+		return accountLocked()
+	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(userAuth.Password), []byte(user.Password)); err != nil {
-		return base.SetErrorMessage("كلمة المرور غير صحيحة")
+		return s.failPassword(ctx, existingUser.ID, ip)
+	}
+	if response, restricted := AccountRestricted(existingUser, time.Now()); restricted {
+		return response
+	}
+	if response, challenged := startTwoFactorChallenge(ctx, s.TwoFactorRepo, existingUser.ID); challenged {
+		return response
 	}
 
-	token, err := GenerateJWT(existingUser)
+	if userAuth.FailedLoginAttempts > 0 || userAuth.LockedUntil != nil {
+		userAuth.FailedLoginAttempts = 0
+		userAuth.LastFailedLoginAt = nil
+		userAuth.LockedUntil = nil
+	}
+
+	if err := s.UserRepo.CancelPendingDeletion(existingUser); err != nil {
+		return base.SetErrorMessage("فشل في إلغاء حذف الحساب")
+	}
+
+	token, expiresAt, err := GenerateJWT(existingUser)
 	if err != nil {
 		return base.SetErrorMessage("فشل في إنشاء الرمز")
 	}
@@ -122,6 +175,7 @@ func (s *UserService) LoginUser(user *userDTO.LoginRequestDTO) base.Response {
 	if err := s.AuthRepo.UpdateAuth(userAuth); err != nil {
 		return base.SetErrorMessage("فشل في تحديث التوثيق")
 	}
+	recordLogin(ctx, existingUser.ID, "password")
 
 	loginResponse := userDTO.LoginResponseDTO{
 		ID:         existingUser.ID.String(),
@@ -130,21 +184,68 @@ func (s *UserService) LoginUser(user *userDTO.LoginRequestDTO) base.Response {
 		Email:      existingUser.Email,
 		Categories: existingUser.Categories,
 		Token:      token,
-		ExpiresAt:  "never",
+		ExpiresAt:  tokenExpiry(expiresAt),
 	}
 
 	return base.SetData(loginResponse, "Logged in successfully")
 }
 
-func GenerateJWT(user *models.User) (string, error) {
-	jwtSecret := config.GetEnv("JWT_SECRET", "alkhaimah123")
+// GenerateJWT issues a token for the user and returns when it expires
+func GenerateJWT(user *models.User) (string, time.Time, error) {
+	return generateUserJWT(user, false)
+}
+
+// GenerateMFAJWT issues a token marked as having passed a two-factor challenge, which
+// routes behind RequirePermission insist on
+func GenerateMFAJWT(user *models.User) (string, time.Time, error) {
+	return generateUserJWT(user, true)
+}
+
+func generateUserJWT(user *models.User, mfa bool) (string, time.Time, error) {
+	keys := jwtkeys.Default()
+	if keys == nil {
+		return "", time.Time{}, jwtkeys.ErrNotInitialized
+	}
+
+	expiresAt := time.Now().Add(keys.TokenLifetime())
 	claims := jwt.MapClaims{
 		"user_id": user.ID.String(),
 		"email":   utils.FormatEmail(user.Email),
+		"exp":     expiresAt.Unix(),
+	}
+	if mfa {
+		claims["mfa"] = true
+	}
+
+	token, err := keys.Sign(claims)
+	return token, expiresAt, err
+}
+
+// tokenExpiry formats a token's expiry for the expires_at field of sign-in responses
+func tokenExpiry(expiresAt time.Time) string {
+	return expiresAt.UTC().Format(time.RFC3339)
+}
+
+// recordLogin writes the audit event for a completed sign-in; method is how the user proved who they are
+func recordLogin(ctx context.Context, userID uuid.UUID, method string) {
+	auditService.Record(ctx, auditService.Entry{
+		Action:     audit.ActionLogin,
+		ActorID:    &userID,
+		TargetType: audit.TargetUser,
+		TargetID:   userID.String(),
+		Metadata:   map[string]interface{}{"method": method},
+	})
+}
+
+// notifyNewUser announces a newly created account in the team's Slack channel
+func notifyNewUser(ctx context.Context, webhookURL string, user *models.User) {
+	identity := user.Email
+	if user.Mobile != "" {
+		identity = user.Mobile
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(jwtSecret))
+	slackMessage := fmt.Sprintf("🚀 New user account created:\n%s (%s)", user.FirstName, identity)
+	_ = utils.SendSlackNotification(ctx, webhookURL, slackMessage)
 }
 
 func (s *UserService) LogoutUser(c echo.Context) base.Response {
@@ -153,12 +254,18 @@ func (s *UserService) LogoutUser(c echo.Context) base.Response {
 		return base.SetErrorMessage("غير مصرح به")
 	}
 
-	userID, err := utils.ExtractUserIDFromToken(token)
+	claims, err := jwtkeys.Default().Parse(strings.TrimPrefix(token, "Bearer "))
 	if err != nil {
 		return base.SetErrorMessage("رمز غير صالح")
 	}
+	// Logging out would sign the impersonated user out of their own devices
+	if claims.ImpersonatorID != nil {
+		response := base.SetErrorMessage("لا يمكن تسجيل الخروج برمز انتحال الهوية")
+		response.HTTPStatus = http.StatusForbidden
+		return response
+	}
 
-	authRecord, err := s.AuthRepo.FindAuthByUserID(userID)
+	authRecord, err := s.AuthRepo.FindAuthByUserID(claims.UserID)
 	if err != nil {
 		return base.SetErrorMessage("فشل في العثور على توثيق المستخدم")
 	}
@@ -169,9 +276,51 @@ func (s *UserService) LogoutUser(c echo.Context) base.Response {
 		return base.SetErrorMessage("فشل في تسجيل الخروج")
 	}
 
+	auditService.Record(c.Request().Context(), auditService.Entry{
+		Action:     audit.ActionLogout,
+		ActorID:    &claims.UserID,
+		TargetType: audit.TargetUser,
+		TargetID:   claims.UserID.String(),
+	})
+
 	return base.SetSuccessMessage("Successfully logged out")
 }
 
+// RefreshToken issues a new token for the caller, keeping the two-factor mark of the one it
+// replaces, so that signed-in clients can renew it before it expires
+func (s *UserService) RefreshToken(c echo.Context) base.Response {
+	token := c.Request().Header.Get("Authorization")
+	if token == "" {
+		return base.SetErrorMessage("غير مصرح به")
+	}
+
+	claims, err := jwtkeys.Default().Parse(strings.TrimPrefix(token, "Bearer "))
+	if err != nil {
+		return base.SetErrorMessage("رمز غير صالح")
+	}
+	// Impersonation tokens are short-lived on purpose
+	if claims.ImpersonatorID != nil {
+		response := base.SetErrorMessage("لا يمكن تجديد رمز انتحال الهوية")
+		response.HTTPStatus = http.StatusForbidden
+		return response
+	}
+
+	user, err := s.UserRepo.FindOneByID(claims.UserID)
+	if err != nil || user == nil {
+		return base.SetErrorMessage("المستخدم غير موجود")
+	}
+
+	refreshed, expiresAt, err := generateUserJWT(user, claims.MFA)
+	if err != nil {
+		return base.SetErrorMessage("فشل في إنشاء الرمز")
+	}
+
+	return base.SetData(userDTO.RefreshTokenResponseDTO{
+		Token:     refreshed,
+		ExpiresAt: tokenExpiry(expiresAt),
+	}, "تم تجديد الرمز بنجاح")
+}
+
 func (s *UserService) GetUserProfile(userID string) base.Response {
 	uid, err := uuid.Parse(userID)
 	if err != nil {
@@ -272,7 +421,7 @@ func (s *UserService) UpdateUserPreferences(userID string, updateData userDTO.Up
 	return &preferencesResponse, nil
 }
 
-func (s *UserService) ChangePassword(userID string, req userDTO.ChangePasswordDTO) base.Response {
+func (s *UserService) ChangePassword(ctx context.Context, userID string, req userDTO.ChangePasswordDTO) base.Response {
 	uid, err := uuid.Parse(userID)
 	if err != nil {
 		return base.SetErrorMessage("الرقم التعريفي للمستخدم غير صالح")
@@ -304,50 +453,16 @@ func (s *UserService) ChangePassword(userID string, req userDTO.ChangePasswordDT
 		return base.SetErrorMessage("فشل في تغيير كلمة المرور")
 	}
 
-	return base.SetSuccessMessage("Password changed successfully")
-}
-
-func (s *UserService) GetAllUsers(c echo.Context) base.Response {
-	users, err := s.UserRepo.FindAllUsers()
-	if err != nil {
-		return base.SetErrorMessage("فشل في استرجاع المستخدمين")
-	}
-
-	var userResponses []interface{}
-	for _, user := range users {
-		userResponses = append(userResponses, userDTO.UserProfileDTO{
-			ID:        user.ID.String(),
-			FirstName: user.FirstName,
-			LastName:  user.LastName,
-			Email:     user.Email,
-		})
-	}
-	return base.SetDataPaginated(c, userResponses)
-}
-
-func (s *UserService) MarkUserAdmin(userID string) base.Response {
-	uid, err := uuid.Parse(userID)
-	if err != nil {
-		return base.SetErrorMessage("الرقم التعريفي للمستخدم غير صالح")
-	}
-
-	user, err := s.UserRepo.FindOneByID(uid)
-	if err != nil {
-		return base.SetErrorMessage("فشل في استرجاع المستخدم")
-	}
-	if user == nil {
-		return base.SetErrorMessage("المستخدم غير موجود")
-	}
+	auditService.Record(ctx, auditService.Entry{
+		Action:     audit.ActionPasswordChanged,
+		TargetType: audit.TargetUser,
+		TargetID:   uid.String(),
+	})
 
-	user.UserType = users.UserTypeAdmin
-	if err := s.UserRepo.UpdateUser(user); err != nil {
-		return base.SetErrorMessage("فشل في تحديث المستخدم")
-	}
-
-	return base.SetSuccessMessage("User marked as admin successfully")
+	return base.SetSuccessMessage("Password changed successfully")
 }
 
-func (s *UserService) DeleteUser(userID string) base.Response {
+func (s *UserService) DeleteUser(ctx context.Context, userID string) base.Response {
 	uid, err := uuid.Parse(userID)
 	if err != nil {
 		return base.SetErrorMessage("الرقم التعريفي للمستخدم غير صالح")
@@ -361,20 +476,29 @@ func (s *UserService) DeleteUser(userID string) base.Response {
 		return base.SetErrorMessage("لم يتم العثور على مستخدم بهذا الرقم التعريفي")
 	}
 
-	if user.UserType == users.UserTypeAdmin {
+	if len(user.Roles) > 0 {
 		return base.SetErrorMessage("لا يمكنك حذف حساب مستخدم يمتلك صلاحيات المشرف")
 	}
 
-	if user.DeletedAt.Valid {
-		return base.SetErrorMessage("تم حذف هذا الحساب مسبقاً")
+	if user.DeletionRequestedAt != nil {
+		return base.SetErrorMessage("تم طلب حذف هذا الحساب مسبقاً")
 	}
 
-	err = s.UserRepo.DeleteUser(uid)
+	requestedAt := time.Now()
+	err = s.UserRepo.MarkPendingDeletion(uid, requestedAt)
 	if err != nil {
 		return base.SetErrorMessage("فشل في حذف المستخدم")
 	}
 
-	return base.SetSuccessMessage("تم حذف حساب المستخدم بنجاح")
+	auditService.Record(ctx, auditService.Entry{
+		Action:     audit.ActionUserDeleted,
+		TargetType: audit.TargetUser,
+		TargetID:   uid.String(),
+		Before:     map[string]interface{}{"deletion_requested_at": nil},
+		After:      map[string]interface{}{"deletion_requested_at": requestedAt},
+	})
+
+	return base.SetSuccessMessage("تم حذف حساب المستخدم بنجاح", "سيتم حذف الحساب نهائياً بعد ٣٠ يوماً، ويمكن إلغاء الحذف بتسجيل الدخول مرة أخرى خلال هذه المدة")
 }
 
 func (s *UserService) GetUserCategoriesIDs(userID string) ([]string, error) {
@@ -410,9 +534,10 @@ func (s *UserService) GetUserBookmarks(userID string) base.Response {
 		return base.SetErrorMessage("فشل في استرجاع الإشارات المرجعية")
 	}
 
+	canAccessPremium, _ := s.Entitlements.CanAccessPodcast(uid, true)
 	bookmarksResponse := make([]interface{}, len(bookmarks))
 	for i, podcast := range bookmarks {
-		bookmarksResponse[i] = podcastDTO.MapToPodcastDTO(podcast, uid)
+		bookmarksResponse[i] = podcastDTO.MapToPodcastDTO(podcast, uid, canAccessPremium)
 	}
 
 	return base.SetData(bookmarksResponse)
@@ -460,9 +585,10 @@ func (s *UserService) GetDownloadedPodcasts(userID string) base.Response {
 		return base.SetErrorMessage("فشل في استرجاع البودكاست التي تم تنزيلها")
 	}
 
+	canAccessPremium, _ := s.Entitlements.CanAccessPodcast(uid, true)
 	response := make([]interface{}, len(downloads))
 	for i, podcast := range downloads {
-		response[i] = podcastDTO.MapToPodcastDTO(podcast, uid)
+		response[i] = podcastDTO.MapToPodcastDTO(podcast, uid, canAccessPremium)
 	}
 
 	return base.SetData(response)
@@ -1,6 +1,7 @@
 package users
 
 import (
+	"context"
 	"testing"
 
 	userDTO "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/dtos"
@@ -21,7 +22,7 @@ func TestUpdateUserProfile_InvalidUserID(t *testing.T) {
 // TestChangePassword_InvalidUserID tests validation of user ID
 func TestChangePassword_InvalidUserID(t *testing.T) {
 	service := UserService{}
-	response := service.ChangePassword("invalid-user-id", userDTO.ChangePasswordDTO{
+	response := service.ChangePassword(context.Background(), "invalid-user-id", userDTO.ChangePasswordDTO{
 		OldPassword: "oldPassword",
 		NewPassword: "newPassword",
 	})
@@ -31,7 +32,7 @@ func TestChangePassword_InvalidUserID(t *testing.T) {
 // TestDeleteUser_InvalidUserID tests validation of user ID
 func TestDeleteUser_InvalidUserID(t *testing.T) {
 	service := UserService{}
-	response := service.DeleteUser("invalid-user-id")
+	response := service.DeleteUser(context.Background(), "invalid-user-id")
 	assert.Equal(t, "Invalid User ID", response.MessageTitle)
 }
 
@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/health"
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterHealthRoutes(e *echo.Echo, checker *health.Checker) {
+	e.GET("/healthz", checker.Liveness)
+	e.HEAD("/healthz", checker.Liveness)
+	e.GET("/readyz", checker.Readiness)
+}
@@ -0,0 +1,10 @@
+package routes
+
+import (
+	"github.com/Al-Khaimah/khaimah-golang-backend/internal/base/jwtkeys"
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterJWKSRoutes(e *echo.Echo, keys *jwtkeys.Manager) {
+	e.GET(jwtkeys.JWKSPath, keys.JWKSHandler)
+}
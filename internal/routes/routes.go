@@ -1,21 +1,30 @@
 package routes
 
 import (
+	"github.com/Al-Khaimah/khaimah-golang-backend/config"
 	_ "github.com/Al-Khaimah/khaimah-golang-backend/docs"
+	audit "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/audit/routes"
 	categories "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/categories/routes"
 	notifications "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/notifications/routes"
 	podcasts "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/podcasts/routes"
+	referrals "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/referrals/routes"
+	roles "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/roles/routes"
+	subscriptions "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/subscriptions/routes"
 	users "github.com/Al-Khaimah/khaimah-golang-backend/internal/modules/users/routes"
 
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
 )
 
-func RegisterAllRoutes(e *echo.Echo, db *gorm.DB) {
-	users.RegisterRoutes(e, db)
-	categories.RegisterRoutes(e, db)
-	podcasts.RegisterRoutes(e, db)
+func RegisterAllRoutes(e *echo.Echo, db *gorm.DB, cfg *config.Config) {
+	users.RegisterRoutes(e, db, cfg)
+	categories.RegisterRoutes(e, db, cfg)
+	podcasts.RegisterRoutes(e, db, cfg)
 	notifications.RegisterRoutes(e)
+	roles.RegisterRoutes(e, db, cfg)
+	subscriptions.RegisterRoutes(e, db, cfg)
+	referrals.RegisterRoutes(e, db, cfg)
+	audit.RegisterRoutes(e, db)
 
 	RegisterSwaggerRoutes(e)
 }
@@ -57,7 +57,7 @@ go build -o alkhaimah cmd/*.go
 
 # 🏃 Run in background (new binary)
 echo "🏃 Starting the application in the background..."
-setsid ./alkhaimah > alkhaimah.log 2>&1 &
+setsid ./alkhaimah serve > alkhaimah.log 2>&1 &
 
 # 🎯 Reapply stash (only on server)
 if [[ -n "$IS_SERVER" ]] && git stash list | grep -q .; then